@@ -0,0 +1,84 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/trviph/collection"
+)
+
+// Take ownership of the files matching the given glob patterns, in the syntax of [filepath.Match],
+// as if they were archives of the Keeper, so that the logs orphaned by a change of the name, the extension
+// or the layouts count toward the retention of [WithMaxFiles] and [WithTotalSize], and are removed in turn.
+// The relative patterns are relative to the folder of the current log file, such as "old-app-*.log*".
+// The adopted files are ordered among the archives by modification time, and are neither compressed nor renamed,
+// see [Keeper.Migrate] to rename them instead. The current log file is never adopted.
+// Calling it again replaces the patterns set before, no pattern adopts nothing, which is the default.
+//
+// Example usage:
+//
+//	// The Keeper was named "api" before, and is now "gateway"
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithName("gateway"),
+//		lorekeeper.WithMaxFiles(10),
+//		lorekeeper.WithAdoptPattern("*-api.log*"),
+//	)
+func WithAdoptPattern(patterns ...string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		for _, pattern := range patterns {
+			if len(pattern) == 0 {
+				return nil, fmt.Errorf("failed to set adopt pattern, pattern must not be empty")
+			}
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("failed to set adopt pattern %q, caused by %w", pattern, err)
+			}
+		}
+		k.adoptPatterns = slices.Clone(patterns)
+		return k, nil
+	}
+}
+
+// Get the glob patterns of the adopted files, see [WithAdoptPattern].
+func (k *Keeper) getAdoptGlobPatterns() []string {
+	patterns := make([]string, 0, len(k.adoptPatterns))
+	for _, pattern := range k.adoptPatterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(k.folder, pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// Tell whether the archive was adopted rather than rotated by the Keeper, see [WithAdoptPattern].
+func (k *Keeper) isAdoptedArchive(path string) bool {
+	if len(k.adoptPatterns) == 0 {
+		return false
+	}
+	patterns, err := k.getArchiveGlobPatterns()
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove the current log file from the archives, which an adopt pattern may match,
+// and so may the archive name layout once the current log file is compressed, see [WithCompressedCurrent].
+func (k *Keeper) excludeCurrentFile(archives *collection.List[*fileInfo], size int) (*collection.List[*fileInfo], int) {
+	current := filepath.Clean(k.getCurrentFilePath())
+	kept := collection.NewList[*fileInfo]()
+	for _, archive := range archives.All() {
+		if filepath.Clean(archive.filePath) == current {
+			size -= archive.size
+			continue
+		}
+		kept.Append(archive)
+	}
+	return kept, size
+}
@@ -0,0 +1,61 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeeperWithAdoptPattern(t *testing.T) {
+	folder := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	for i, name := range []string{"2024-01-01-api.log", "2024-01-02-api.log.gz"} {
+		path := filepath.Join(folder, name)
+		if err := os.WriteFile(path, []byte("orphan\n"), 0644); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		modtime := old.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, modtime, modtime); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	k, err := New(
+		WithFolder(folder),
+		WithName("gateway"),
+		WithGzip(),
+		WithMaxFiles(2),
+		// Also matches the current log file, which is never adopted
+		WithAdoptPattern("*-api.log*", "gateway.log"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archives := k.Archives()
+	if len(archives) != 2 || filepath.Base(archives[0].Path) != "2024-01-01-api.log" {
+		t.Fatalf("expected the 2 orphans, oldest first, got %+v", archives)
+	}
+	// The adopted file is not compressed
+	if _, err := os.Stat(filepath.Join(folder, "2024-01-01-api.log")); err != nil {
+		t.Errorf("expected the orphan to be left uncompressed got %v", err)
+	}
+
+	if _, err := k.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// The oldest orphan makes room for the new archive
+	if _, err := os.Stat(filepath.Join(folder, "2024-01-01-api.log")); !os.IsNotExist(err) {
+		t.Errorf("expected the oldest orphan to be removed got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if _, err := New(WithAdoptPattern("[")); err == nil {
+		t.Errorf("expected an error for a malformed pattern")
+	}
+}
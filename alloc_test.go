@@ -0,0 +1,139 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// The options whose write path must not allocate.
+var allocFreeOpts = []struct {
+	name string
+	opt  Opt
+}{
+	{name: "default", opt: Options()},
+	{name: "double buffering", opt: WithDoubleBuffering(Mb)},
+	{name: "length-prefixed records", opt: WithLengthPrefixedRecords()},
+	{name: "record pattern", opt: WithRecordPattern(regexp.MustCompile(`^\{`))},
+	{name: "validate JSON", opt: WithValidateJSON()},
+	{name: "circuit breaker", opt: WithCircuitBreaker(3, 1)},
+	{name: "buffered", opt: WithBufferSize(64 * Kb)},
+	{name: "async writes", opt: WithAsyncWrites(16, DropNone)},
+	{name: "rate limit", opt: WithRateLimit(Gb, Gb)},
+	{name: "max lines", opt: WithMaxLines(1 << 20)},
+	{name: "quota", opt: WithQuota(Gb)},
+	{name: "hard max size", opt: WithHardMaxSize(Gb, HardMaxReject)},
+	{name: "recent", opt: WithRecent(16)},
+	{name: "latency window", opt: WithLatencyWindow(time.Minute)},
+}
+
+// Create a Keeper with the given option and write to it until its buffers and pools are warm.
+func newWarmKeeper(tb testing.TB, name string, opt Opt) *Keeper {
+	tb.Helper()
+	k, err := New(WithFolder(tb.TempDir()), WithName(name), opt)
+	if err != nil {
+		tb.Fatalf("expected no error got %v", err)
+	}
+	tb.Cleanup(func() { k.Close() })
+	for range 1000 {
+		if _, err := k.Write(allocMsg); err != nil {
+			tb.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := k.Flush(); err != nil {
+		tb.Fatalf("expected no error got %v", err)
+	}
+	return k
+}
+
+var allocMsg = []byte(`{"level":"info","msg":"hello"}` + "\n")
+
+func TestKeeperWriteAllocs(t *testing.T) {
+	for i, tt := range allocFreeOpts {
+		t.Run(tt.name, func(t *testing.T) {
+			k := newWarmKeeper(t, fmt.Sprintf("test-write-allocs-%d", i), tt.opt)
+			allocs := testing.AllocsPerRun(100, func() {
+				if _, err := k.Write(allocMsg); err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+			})
+			if allocs != 0 {
+				t.Errorf("expected 0 allocs per write got %v", allocs)
+			}
+		})
+	}
+}
+
+func TestKeeperWriteStringAllocs(t *testing.T) {
+	k := newWarmKeeper(t, "test-write-string-allocs", Options())
+	msg := string(allocMsg)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := k.WriteString(msg); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocs per write got %v", allocs)
+	}
+}
+
+func TestKeeperReadFromAllocs(t *testing.T) {
+	k := newWarmKeeper(t, "test-read-from-allocs", Options())
+	content := bytes.Repeat(allocMsg, 1000)
+	r := bytes.NewReader(content)
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Reset(content)
+		if _, err := k.ReadFrom(r); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocs per read got %v", allocs)
+	}
+}
+
+func BenchmarkKeeperWriteAllocs(b *testing.B) {
+	for i, tt := range allocFreeOpts {
+		b.Run(tt.name, func(b *testing.B) {
+			k := newWarmKeeper(b, fmt.Sprintf("bench-write-%d", i), tt.opt)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(allocMsg)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := k.Write(allocMsg); err != nil {
+					b.Fatalf("expected no error got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkKeeperWriteString(b *testing.B) {
+	k := newWarmKeeper(b, "bench-write-string", Options())
+	msg := string(allocMsg)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(msg)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := k.WriteString(msg); err != nil {
+			b.Fatalf("expected no error got %v", err)
+		}
+	}
+}
+
+func BenchmarkKeeperReadFrom(b *testing.B) {
+	k := newWarmKeeper(b, "bench-read-from", Options())
+	content := bytes.Repeat(allocMsg, 1000)
+	r := bytes.NewReader(content)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Reset(content)
+		if _, err := k.ReadFrom(r); err != nil {
+			b.Fatalf("expected no error got %v", err)
+		}
+	}
+}
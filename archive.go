@@ -0,0 +1,163 @@
+package lorekeeper
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// An [ArchiveInfo] describes an archived log file managed by a [Keeper].
+// Use [Keeper.Archives] to get the archives of a Keeper.
+type ArchiveInfo struct {
+	// Path to the archive file.
+	Path string
+	// Size of the archive file in bytes.
+	Size int
+	// Last modification time of the archive file.
+	ModTime time.Time
+	// Number of records in the archive, see [Stats.Records] for what a record is.
+	// It is -1 if unknown, for the archives that were not entirely written by this Keeper.
+	Records int
+	// Size of the archive before compression in bytes.
+	// It is -1 if the archive was not compressed by this Keeper.
+	OriginalSize int
+	// Wall time spent compressing the archive, zero if the archive was not compressed by this Keeper.
+	CompressionTime time.Duration
+	// Whether the archive is compressed, see [Keeper.OpenArchive] to read it decompressed.
+	Compressed bool
+}
+
+// Make sure that ArchiveInfo implements the [io.WriterTo] interface.
+var _ io.WriterTo = ArchiveInfo{}
+
+// Stream the raw content of the archive into w, the content is not decompressed.
+// This uses the fast paths of [os.File.WriteTo] and [io.ReaderFrom],
+// such as sendfile when w is a network connection or an [http.ResponseWriter],
+// so that large archives are copied with minimal overhead.
+func (a ArchiveInfo) WriteTo(w io.Writer) (int64, error) {
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open archive, caused by %w", err)
+	}
+	defer f.Close()
+	return f.WriteTo(w)
+}
+
+// Open the archive for reading, decompressing it on the fly if it is compressed,
+// so that log viewers and debug endpoints can read the archives listed by [Keeper.Archives] without globbing the folder.
+// Only the archives managed by the Keeper can be opened, the others fail with an error wrapping [fs.ErrNotExist].
+// With [WithDeferredRemoval], the archive is not removed before the reader is closed.
+//
+// Example usage:
+//
+//	archives := keeper.Archives()
+//	reader, err := keeper.OpenArchive(archives[len(archives)-1])
+//	if err != nil {
+//		return err
+//	}
+//	defer reader.Close()
+//	_, err = io.Copy(w, reader)
+func (k *Keeper) OpenArchive(info ArchiveInfo) (io.ReadCloser, error) {
+	k.mu.Lock()
+	managed := false
+	for _, archive := range k.archives.All() {
+		managed = managed || archive.filePath == info.Path
+	}
+	decompressor := k.archiveDecompressor(info.Path)
+	k.mu.Unlock()
+	if !managed {
+		return nil, fmt.Errorf("failed to open archive %q, caused by %w", info.Path, fs.ErrNotExist)
+	}
+
+	k.acquireHandle(info.Path)
+	reader, err := openLogFile(k.fsys, info.Path, decompressor)
+	if err != nil {
+		k.releaseHandle(info.Path)
+		return nil, fmt.Errorf("failed to open archive %q, caused by %w", info.Path, err)
+	}
+	return &trackedReader{ReadCloser: reader, release: func() { k.releaseHandle(info.Path) }}, nil
+}
+
+// Get the archives managed by the Keeper, ordered from oldest to newest.
+func (k *Keeper) Archives() []ArchiveInfo {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	archives := make([]ArchiveInfo, 0, k.archives.Length())
+	for _, archive := range k.archives.All() {
+		archives = append(archives, archive.toArchiveInfo(k.isCompressedArchive(archive.filePath)))
+	}
+	return archives
+}
+
+// A SortKey is the order of the archives returned by [Keeper.ArchivesSorted].
+type SortKey int
+
+const (
+	// Sort by the time of the rotation, taken from the archive name, or from the modification time if the name has none.
+	SortByRotationTime SortKey = iota
+	// Sort by the size of the archive file.
+	SortBySize
+	// Sort by the base name of the archive file.
+	SortByName
+)
+
+// Get the archives managed by the Keeper sorted by the given key, ascending if asc is true, descending otherwise.
+// Archives with equal keys are ordered from oldest to newest, so the order is deterministic.
+//
+// Example usage:
+//
+//	largest := keeper.ArchivesSorted(lorekeeper.SortBySize, false)[0]
+func (k *Keeper) ArchivesSorted(by SortKey, asc bool) []ArchiveInfo {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	type sortable struct {
+		info ArchiveInfo
+		time time.Time
+	}
+	archives := make([]sortable, 0, k.archives.Length())
+	for _, archive := range k.archives.All() {
+		archives = append(archives, sortable{info: archive.toArchiveInfo(k.isCompressedArchive(archive.filePath)), time: k.archiveTime(archive)})
+	}
+	slices.SortStableFunc(archives, func(a, b sortable) int {
+		var c int
+		switch by {
+		case SortBySize:
+			c = cmp.Compare(a.info.Size, b.info.Size)
+		case SortByName:
+			c = strings.Compare(filepath.Base(a.info.Path), filepath.Base(b.info.Path))
+		default:
+			c = a.time.Compare(b.time)
+		}
+		if !asc {
+			c = -c
+		}
+		return c
+	})
+
+	sorted := make([]ArchiveInfo, 0, len(archives))
+	for _, archive := range archives {
+		sorted = append(sorted, archive.info)
+	}
+	return sorted
+}
+
+func (f *fileInfo) toArchiveInfo(compressed bool) ArchiveInfo {
+	return ArchiveInfo{
+		Path:       f.filePath,
+		Size:       f.size,
+		ModTime:    f.modtime,
+		Records:    f.records,
+		Compressed: compressed,
+
+		OriginalSize:    f.originalSize,
+		CompressionTime: f.compressionTime,
+	}
+}
@@ -0,0 +1,143 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestArchiveInfoWriteTo(t *testing.T) {
+	k, err := New(
+		WithName("Test-Archive-WriteTo"),
+		WithFolder(t.TempDir()),
+		WithMaxSize(10),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"0123456789", "abc"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	if archives[0].Size != 10 {
+		t.Errorf("expected archive size of 10 got %d", archives[0].Size)
+	}
+
+	var buff bytes.Buffer
+	n, err := archives[0].WriteTo(&buff)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if n != 10 || buff.String() != "0123456789" {
+		t.Errorf("expected archive content got %q", buff.String())
+	}
+
+	rec := httptest.NewRecorder()
+	if _, err := archives[0].WriteTo(rec); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if rec.Body.String() != "0123456789" {
+		t.Errorf("expected archive content got %q", rec.Body.String())
+	}
+}
+
+func TestKeeperArchivesSorted(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	k, err := New(
+		WithName("test-archives-sorted"),
+		WithFolder(t.TempDir()),
+		WithNowFunc(func() time.Time {
+			now = now.Add(time.Minute)
+			return now
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"aaa", "a", "aa"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	sizes := func(archives []ArchiveInfo) []int {
+		var sizes []int
+		for _, archive := range archives {
+			sizes = append(sizes, archive.Size)
+		}
+		return sizes
+	}
+	tests := []struct {
+		by       SortKey
+		asc      bool
+		expected []int
+	}{
+		{SortByRotationTime, true, []int{3, 1, 2}},
+		{SortByRotationTime, false, []int{2, 1, 3}},
+		{SortBySize, true, []int{1, 2, 3}},
+		{SortBySize, false, []int{3, 2, 1}},
+		{SortByName, true, []int{3, 1, 2}},
+	}
+	for _, tt := range tests {
+		if got := sizes(k.ArchivesSorted(tt.by, tt.asc)); !slices.Equal(got, tt.expected) {
+			t.Errorf("expected sizes %v sorted by %d asc %v got %v", tt.expected, tt.by, tt.asc, got)
+		}
+	}
+}
+
+func TestKeeperOpenArchive(t *testing.T) {
+	k, err := New(
+		WithName("Test-Open-Archive"),
+		WithFolder(t.TempDir()),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("compressed content\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archives := k.Archives()
+	if len(archives) != 1 || !archives[0].Compressed {
+		t.Fatalf("expected 1 compressed archive got %+v", archives)
+	}
+
+	reader, err := k.OpenArchive(archives[0])
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "compressed content\n" {
+		t.Errorf("expected the decompressed content got %q", content)
+	}
+
+	if _, err := k.OpenArchive(ArchiveInfo{Path: "/etc/passwd"}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist for an unmanaged file got %v", err)
+	}
+}
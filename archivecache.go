@@ -0,0 +1,232 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Keep up to maxOpen compressed archives open and partially decompressed for the readers of the Keeper,
+// evicting the least recently used one, so that repeated queries over the same archives,
+// such as with [Keeper.Between], [Inspector.Grep] or [Inspector.Reader], do not decompress them from scratch every time.
+// A cached archive keeps what was decompressed so far in memory, and its file open until it is fully decompressed,
+// so size maxOpen with the decompressed size of the archives in mind.
+// An archive that changed on disk since it was cached, such as one that was removed and recreated, is read again.
+// The uncompressed log files are always read from the disk.
+// Set maxOpen <= 0 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	inspector, err := lorekeeper.Open(lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithGzip(), lorekeeper.WithReaderCache(4))
+func WithReaderCache(maxOpen int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if maxOpen <= 0 {
+			k.readerCache.close()
+			k.readerCache = nil
+			return k, nil
+		}
+		if k.readerCache == nil {
+			k.readerCache = &archiveCache{}
+		}
+		k.readerCache.resize(maxOpen)
+		return k, nil
+	}
+}
+
+// A bounded cache of decompressed archives, ordered from the least to the most recently used.
+type archiveCache struct {
+	mu      sync.Mutex
+	maxOpen int
+	entries []*cachedArchive
+}
+
+// An archive being decompressed, shared by all the readers of the archive.
+type cachedArchive struct {
+	path string
+	// The size and modification time of the archive when it was cached
+	size    int64
+	modtime time.Time
+
+	mu   sync.Mutex
+	file fs.File
+	dec  io.ReadCloser
+	// What was decompressed so far, and the error that stopped the decompression, io.EOF once done
+	data []byte
+	err  error
+	// The number of open readers, the resources of an evicted archive are freed once it has none
+	refs    int
+	evicted bool
+}
+
+// Get the max number of cached archives, zero if the cache is disabled.
+func (c *archiveCache) size() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.maxOpen
+}
+
+func (c *archiveCache) resize(maxOpen int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxOpen = maxOpen
+	c.evictExcess()
+}
+
+// Evict every cached archive.
+func (c *archiveCache) close() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxOpen = 0
+	c.evictExcess()
+}
+
+// Open the log file at path for reading, decompressing it with dec if it is not nil, see [openLogFile].
+// The compressed archives are read through the cache if it is enabled.
+func (c *archiveCache) open(fsys FS, path string, dec Compressor) (io.ReadCloser, error) {
+	if c == nil || dec == nil {
+		return openLogFile(fsys, path, dec)
+	}
+	stat, err := fsys.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := slices.IndexFunc(c.entries, func(e *cachedArchive) bool { return e.path == path })
+	if i >= 0 {
+		e := c.entries[i]
+		c.entries = slices.Delete(c.entries, i, i+1)
+		if e.size == stat.Size() && e.modtime.Equal(stat.ModTime()) && e.acquire() {
+			c.entries = append(c.entries, e)
+			return &cachedArchiveReader{e: e}, nil
+		}
+		e.evict()
+	}
+
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newDecompressingReader(dec, file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to decompress %q, caused by %w", path, err)
+	}
+	e := &cachedArchive{path: path, size: stat.Size(), modtime: stat.ModTime(), file: file, dec: reader, refs: 1}
+	c.entries = append(c.entries, e)
+	c.evictExcess()
+	return &cachedArchiveReader{e: e}, nil
+}
+
+// Evict the least recently used archives beyond the max, the lock of the cache must be held.
+func (c *archiveCache) evictExcess() {
+	for len(c.entries) > max(c.maxOpen, 0) {
+		c.entries[0].evict()
+		c.entries[0] = nil
+		c.entries = c.entries[1:]
+	}
+}
+
+// Add a reader to the archive, it returns false if the decompression failed and the archive must be read again.
+func (e *cachedArchive) acquire() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err != nil && !errors.Is(e.err, io.EOF) {
+		return false
+	}
+	e.refs++
+	return true
+}
+
+func (e *cachedArchive) release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refs--
+	if e.evicted && e.refs == 0 {
+		e.free()
+	}
+}
+
+func (e *cachedArchive) evict() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.evicted = true
+	if e.refs == 0 {
+		e.free()
+	}
+}
+
+// Close the file and drop the decompressed data, the lock of the archive must be held.
+func (e *cachedArchive) free() {
+	e.closeFile()
+	e.data = nil
+}
+
+// The lock of the archive must be held.
+func (e *cachedArchive) closeFile() {
+	if e.file == nil {
+		return
+	}
+	_ = e.dec.Close()
+	_ = e.file.Close()
+	e.dec, e.file = nil, nil
+}
+
+// Read the decompressed archive from offset into p, decompressing more of it if needed.
+func (e *cachedArchive) readAt(p []byte, offset int) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for offset >= len(e.data) && e.err == nil {
+		if cap(e.data)-len(e.data) < 32*Kb {
+			e.data = slices.Grow(e.data, 32*Kb)
+		}
+		n, err := e.dec.Read(e.data[len(e.data):cap(e.data)])
+		e.data = e.data[:len(e.data)+n]
+		if err != nil {
+			e.err = err
+			e.closeFile()
+		}
+	}
+	if offset < len(e.data) {
+		return copy(p, e.data[offset:]), nil
+	}
+	return 0, e.err
+}
+
+// A reader of a cached archive, reading from the start of the decompressed archive.
+type cachedArchiveReader struct {
+	e      *cachedArchive
+	offset int
+}
+
+func (r *cachedArchiveReader) Read(p []byte) (int, error) {
+	if r.e == nil {
+		return 0, os.ErrClosed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n, err := r.e.readAt(p, r.offset)
+	r.offset += n
+	return n, err
+}
+
+func (r *cachedArchiveReader) Close() error {
+	if r.e != nil {
+		r.e.release()
+		r.e = nil
+	}
+	return nil
+}
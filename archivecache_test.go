@@ -0,0 +1,139 @@
+package lorekeeper
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A gzip Decompressor counting the archives it decompresses.
+type countingDecompressor struct {
+	Decompressor
+	readers atomic.Int32
+}
+
+func (c *countingDecompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	c.readers.Add(1)
+	return c.Decompressor.NewReader(r)
+}
+
+func TestKeeperWithReaderCache(t *testing.T) {
+	dec := &countingDecompressor{Decompressor: gzipCompressor{level: gzip.DefaultCompression}}
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-reader-cache"),
+		WithCompressor(dec),
+		WithReaderCache(2),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{"first\n", "second\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	readAll := func() string {
+		t.Helper()
+		var content string
+		for record, err := range k.Between(time.Time{}, time.Now().Add(time.Hour)) {
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			content += string(record)
+		}
+		return content
+	}
+	for range 3 {
+		if got := readAll(); got != "first\nsecond\n" {
+			t.Errorf("expected both records got %q", got)
+		}
+	}
+	// Decompressed once, then read from the cache
+	if got := dec.readers.Load(); got != 2 {
+		t.Errorf("expected 2 decompressions got %d", got)
+	}
+
+	// An archive changed on disk is decompressed again
+	first := k.Archives()[0].Path
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(first, later, later); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := readAll(); got != "first\nsecond\n" {
+		t.Errorf("expected both records got %q", got)
+	}
+	if got := dec.readers.Load(); got != 3 {
+		t.Errorf("expected 3 decompressions got %d", got)
+	}
+
+	// Shrinking the cache evicts the least recently used archive, the first one,
+	// whose decompression then evicts the second one
+	if err := k.Reconfigure(WithReaderCache(1)); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := readAll(); got != "first\nsecond\n" {
+		t.Errorf("expected both records got %q", got)
+	}
+	if got := dec.readers.Load(); got != 5 {
+		t.Errorf("expected 5 decompressions got %d", got)
+	}
+}
+
+func TestArchiveCachePartialReads(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-reader-cache-partial"), WithGzip())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	path := k.Archives()[0].Path
+
+	cache := &archiveCache{maxOpen: 1}
+	dec := gzipCompressor{level: gzip.DefaultCompression}
+	first, err := cache.open(OSFS(), path, dec)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	buf := make([]byte, 4)
+	if n, err := first.Read(buf); err != nil || string(buf[:n]) != "0123" {
+		t.Fatalf("expected %q got %q and %v", "0123", buf[:n], err)
+	}
+
+	// Evicted while being read, the first reader keeps reading
+	cache.resize(0)
+	second, err := cache.open(OSFS(), path, dec)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	rest, err := io.ReadAll(first)
+	if err != nil || string(rest) != "456789" {
+		t.Errorf("expected %q got %q and %v", "456789", rest, err)
+	}
+	all, err := io.ReadAll(second)
+	if err != nil || string(all) != "0123456789" {
+		t.Errorf("expected %q got %q and %v", "0123456789", all, err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := second.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := first.Read(buf); err == nil {
+		t.Errorf("expected error since the reader is closed")
+	}
+}
@@ -0,0 +1,77 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Placeholder rendered into the archive name layout to locate the date fields.
+const layoutDateMarker = "\x08"
+
+// The default permissions of the subdirectories of the archive name layout, without [WithCreateFolder] or [WithTenant].
+const defaultArchiveDirMode os.FileMode = 0755
+
+// Set the values of {{ .year }}, {{ .month }}, {{ .day }} and {{ .hour }} in the data of the archive name layout,
+// to the same placeholder for all of them.
+func withDateVars(data map[string]any, placeholder string) map[string]any {
+	data["year"] = placeholder
+	data["month"] = placeholder
+	data["day"] = placeholder
+	data["hour"] = placeholder
+	return data
+}
+
+// Set the values of {{ .year }}, {{ .month }}, {{ .day }} and {{ .hour }} for an archive rotated at t.
+func dateVars(data map[string]any, t time.Time) map[string]any {
+	data["year"] = t.Format("2006")
+	data["month"] = t.Format("01")
+	data["day"] = t.Format("02")
+	data["hour"] = t.Format("15")
+	return data
+}
+
+// Reject an archive name layout placing the archives outside of their archive folder.
+func (k *Keeper) applyArchiveDirs() error {
+	if k.archiveNameLayout == nil {
+		return nil
+	}
+	if _, err := k.renderArchiveName(time.Time{}, ""); err != nil {
+		return fmt.Errorf("failed to set archive name layout, caused by %w", err)
+	}
+	return nil
+}
+
+// Create the subdirectories of the archive at path that the archive name layout places it in, see [WithArchiveNameLayout].
+func (k *Keeper) createArchiveDir(path string) error {
+	dir := filepath.Dir(path)
+	if slices.Contains(k.getArchiveFolders(), dir) {
+		return nil
+	}
+	mode := defaultArchiveDirMode
+	if k.folderMode != 0 {
+		mode = k.folderMode
+	} else if k.createFolderMode != 0 {
+		mode = k.createFolderMode
+	}
+	if err := k.fsys.MkdirAll(dir, mode); err != nil {
+		return fmt.Errorf("failed to create archive folder %s, caused by %w", dir, err)
+	}
+	return nil
+}
+
+// Remove the subdirectories of the removed archive at path that are left empty, up to its archive folder.
+func (k *Keeper) removeEmptyArchiveDirs(path string) {
+	folders := k.getArchiveFolders()
+	if _, err := getArchiveRelPath(folders, path); err != nil {
+		return
+	}
+	for dir := filepath.Dir(path); !slices.Contains(folders, dir) && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		// A folder that is not empty can not be removed, nor can the ones above it
+		if err := k.fsys.Remove(dir); err != nil {
+			return
+		}
+	}
+}
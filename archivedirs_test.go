@@ -0,0 +1,91 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveNameLayoutDirectories(t *testing.T) {
+	folder := t.TempDir()
+	opts := []Opt{
+		WithFolder(folder),
+		WithName("test-archive-dirs"),
+		WithArchiveNameLayout("{{ .year }}/{{ .month }}/{{ .name }}-{{ .time }}{{ .extension }}"),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archivePath, err := k.RotateNow()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	now := time.Now()
+	if got, want := filepath.Dir(archivePath), filepath.Join(folder, now.Format("2006"), now.Format("01")); got != want {
+		t.Errorf("expected the archive in %s got %s", want, got)
+	}
+	meta, err := k.ParseArchiveName(archivePath)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if meta.Time.IsZero() {
+		t.Errorf("expected the time of the archive to be parsed")
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The archives are found in their subdirectories on the next start
+	k, err = New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	archives := k.Archives()
+	if len(archives) != 2 || archives[0].Path != archivePath {
+		t.Errorf("expected 2 archives starting with %s got %v", archivePath, archives)
+	}
+}
+
+func TestArchiveNameLayoutRemovesEmptyDirectories(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-archive-dirs-prune"),
+		WithArchiveNameLayout("{{ .time }}/{{ .name }}{{ .extension }}"),
+		WithMaxFiles(1),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	first, err := k.RotateNow()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.RotateNow(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(first)); !os.IsNotExist(err) {
+		t.Errorf("expected the folder of the removed archive to be removed got %v", err)
+	}
+	if _, err := os.Stat(folder); err != nil {
+		t.Errorf("expected the archive folder to be kept got %v", err)
+	}
+}
+
+func TestArchiveNameLayoutOutsideFolder(t *testing.T) {
+	_, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-archive-dirs-outside"),
+		WithArchiveNameLayout("../{{ .time }}-{{ .name }}{{ .extension }}"),
+	)
+	if err == nil {
+		t.Errorf("expected an error for an archive outside of the archive folder")
+	}
+}
@@ -0,0 +1,132 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An ArchiveMeta describes what the name of an archive tells, see [Keeper.ParseArchiveName].
+type ArchiveMeta struct {
+	// The time of the rotation, zero if the archive name layout does not contain {{ .time }}.
+	Time time.Time
+	// The name of the Keeper.
+	Name string
+	// The extension of the log file.
+	Extension string
+	// Why the rotation happened, empty if the archive name layout does not contain {{ .reason }}.
+	Reason RotationReason
+	// Whether the archive is compressed.
+	Compressed bool
+	// The label of the rotation, see [RotateOpts].
+	Label string
+	// The sequence number of the archive, zero if the archive name layout does not contain {{ .seq }}.
+	Seq uint64
+	// The PID of the process that rotated the archive, zero if the archive name layout does not contain {{ .pid }}.
+	PID int
+	// The hostname of the machine that rotated the archive, empty if the archive name layout does not contain {{ .hostname }}.
+	Hostname string
+}
+
+// Placeholders rendered into the archive name layout to locate the time and the reason.
+const (
+	layoutTimeMarker   = "\x02"
+	layoutReasonMarker = "\x03"
+	layoutLabelMarker  = "\x04"
+)
+
+// Parse the path of an archive according to the archive name layout, inverting [WithArchiveNameLayout],
+// so that external tools can interpret the files without reimplementing the naming scheme.
+// The path is matched relative to the archive folder containing it, or by its base name if it is in none of them.
+// It fails if the name does not match the archive name layout, or if its time does not match the time layout.
+//
+// Example usage:
+//
+//	meta, err := keeper.ParseArchiveName("/var/log/app/2024-01-02-app.log.gz")
+//	fmt.Println(meta.Time, meta.Compressed)
+func (k *Keeper) ParseArchiveName(path string) (ArchiveMeta, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.parseArchiveName(path)
+}
+
+func (k *Keeper) parseArchiveName(path string) (ArchiveMeta, error) {
+	meta := ArchiveMeta{Name: k.name, Extension: k.extension}
+	rel, err := getArchiveRelPath(k.getArchiveFolders(), path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	re, err := k.archiveNameRegexp()
+	if err != nil {
+		return meta, fmt.Errorf("failed to parse archive name %q, caused by %w", path, err)
+	}
+	m := re.FindStringSubmatch(filepath.ToSlash(rel))
+	if m == nil {
+		return meta, fmt.Errorf("failed to parse archive name %q, it does not match the archive name layout", path)
+	}
+
+	if i := re.SubexpIndex("time"); i >= 0 {
+		if meta.Time, err = time.ParseInLocation(k.timeLayout, m[i], time.Local); err != nil {
+			return meta, fmt.Errorf("failed to parse time of archive name %q, caused by %w", path, err)
+		}
+	}
+	if i := re.SubexpIndex("reason"); i >= 0 {
+		meta.Reason = RotationReason(m[i])
+	}
+	meta.Compressed = len(k.compressionExt) > 0 && len(m[re.SubexpIndex("compressionExt")]) > 0
+	if i := re.SubexpIndex("label"); i >= 0 {
+		meta.Label = m[i]
+	}
+	if i := re.SubexpIndex("seq"); i >= 0 {
+		if meta.Seq, err = strconv.ParseUint(m[i], 10, 64); err != nil {
+			return meta, fmt.Errorf("failed to parse sequence number of archive name %q, caused by %w", path, err)
+		}
+	}
+	if i := re.SubexpIndex("pid"); i >= 0 {
+		if meta.PID, err = strconv.Atoi(m[i]); err != nil {
+			return meta, fmt.Errorf("failed to parse PID of archive name %q, caused by %w", path, err)
+		}
+	}
+	if i := re.SubexpIndex("hostname"); i >= 0 {
+		meta.Hostname = m[i]
+	}
+	return meta, nil
+}
+
+// Get a regexp matching the whole archive names relative to their archive folder,
+// with the groups "time", "reason", "seq", "pid", "hostname", "label" and "compressionExt" for the fields found in the archive name layout.
+func (k *Keeper) archiveNameRegexp() (*regexp.Regexp, error) {
+	var buff bytes.Buffer
+	data := k.archiveNameData(layoutTimeMarker, layoutReasonMarker, layoutCompressionExtMarker)
+	data = withNameVars(withDateVars(data, layoutDateMarker), layoutSeqMarker, layoutPIDMarker, layoutHostnameMarker)
+	if err := k.archiveNameLayout.Execute(&buff, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template, caused by %w", err)
+	}
+	name := filepath.ToSlash(buff.String())
+	// The label of a rotation goes before the compression extension, unless the layout places it
+	if !strings.Contains(name, layoutCompressionExtMarker) {
+		name += layoutLabelMarker + layoutCompressionExtMarker
+	}
+
+	// Only the first occurrence of a field is captured, the others match anything
+	groups := []struct{ marker, group, any string }{
+		{layoutTimeMarker, "(?P<time>.*)", ".*"},
+		{layoutReasonMarker, "(?P<reason>[a-z]*)", "[a-z]*"},
+		{layoutSeqMarker, "(?P<seq>[0-9]+)", "[0-9]+"},
+		{layoutPIDMarker, "(?P<pid>[0-9]+)", "[0-9]+"},
+		{layoutHostnameMarker, "(?P<hostname>[^/]*?)", "[^/]*?"},
+		{layoutDateMarker, "[0-9]+", "[0-9]+"},
+		{layoutLabelMarker, `(?:\.(?P<label>[A-Za-z0-9_-]+))??`, ""},
+		{layoutCompressionExtMarker, "(?P<compressionExt>" + regexp.QuoteMeta(k.compressionExt) + "|)", "(?:" + regexp.QuoteMeta(k.compressionExt) + "|)"},
+	}
+	pattern := regexp.QuoteMeta(name)
+	for _, g := range groups {
+		pattern = strings.Replace(pattern, g.marker, g.group, 1)
+		pattern = strings.ReplaceAll(pattern, g.marker, g.any)
+	}
+	return regexp.Compile("(?s)^" + pattern + "$")
+}
@@ -0,0 +1,115 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeeperParseArchiveName(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 6, time.Local)
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-parse-archive-name"),
+		WithArchiveNameLayout("{{ .name }}-{{ .reason }}-{{ .time }}{{ .extension }}"),
+		WithNowFunc(func() time.Time { return now }),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	meta, err := k.ParseArchiveName(k.Archives()[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	expected := ArchiveMeta{
+		Time:       now,
+		Name:       "test-parse-archive-name",
+		Extension:  ".log",
+		Reason:     RotationManual,
+		Compressed: true,
+	}
+	if !meta.Time.Equal(expected.Time) || meta.Name != expected.Name || meta.Extension != expected.Extension ||
+		meta.Reason != expected.Reason || meta.Compressed != expected.Compressed {
+		t.Errorf("expected %+v got %+v", expected, meta)
+	}
+
+	// An uncompressed archive outside of the archive folders is parsed by its base name
+	meta, err = k.ParseArchiveName(filepath.Join("elsewhere", "test-parse-archive-name-cron-"+now.Format(k.TimeLayout())+".log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if meta.Reason != RotationCron || meta.Compressed {
+		t.Errorf("expected an uncompressed cron archive got %+v", meta)
+	}
+
+	for _, name := range []string{"other-manual-x.log", "test-parse-archive-name-manual-not-a-time.log"} {
+		if _, err := k.ParseArchiveName(filepath.Join(folder, name)); err == nil {
+			t.Errorf("expected an error for %q", name)
+		}
+	}
+}
+
+func TestKeeperArchiveNameSeqPIDHostname(t *testing.T) {
+	folder := t.TempDir()
+	opts := []Opt{
+		WithFolder(folder),
+		WithName("test-name-vars"),
+		WithArchiveNameLayout("{{ .name }}-{{ .hostname }}-{{ .pid }}-{{ .seq }}{{ .extension }}"),
+		WithGzip(),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for range 3 {
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The sequence continues after a restart
+	k, err = New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	archives := k.Archives()
+	if len(archives) != 5 {
+		t.Fatalf("expected 5 archives got %d", len(archives))
+	}
+	seqs := make(map[uint64]bool)
+	for _, archive := range archives {
+		meta, err := k.ParseArchiveName(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if meta.PID != os.Getpid() || meta.Hostname != layoutHostname() || !meta.Compressed {
+			t.Errorf("unexpected meta %+v of %q", meta, archive.Path)
+		}
+		seqs[meta.Seq] = true
+	}
+	for seq := range uint64(5) {
+		if !seqs[seq+1] {
+			t.Errorf("expected an archive with sequence number %d got %v", seq+1, seqs)
+		}
+	}
+
+	if _, err := New(WithTemplateData(map[string]string{"seq": "1"})); err == nil {
+		t.Errorf("expected error since seq is reserved")
+	}
+}
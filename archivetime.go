@@ -0,0 +1,54 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"time"
+)
+
+// An ArchiveTimeSource is the moment that {{ .time }} stands for in the archive names, see [WithArchiveTimeSource].
+type ArchiveTimeSource int
+
+const (
+	// The time of the rotation, this is the default.
+	ArchiveTimeRotation ArchiveTimeSource = iota
+	// The time of the first message written to the archive, so that the name tells when the archive starts.
+	ArchiveTimeFirstWrite
+	// The time of the last message written to the archive, so that the name tells when the archive ends.
+	ArchiveTimeLastWrite
+)
+
+// Choose the moment that {{ .time }} stands for in the archive names, see [WithArchiveNameLayout],
+// since the tools searching and expiring the archives by their names expect different conventions.
+// The writes are tracked by the Keeper, so the time of the rotation is used instead when it does not know them,
+// such as for an archive without messages, or for the first write of a log file that already had content when the Keeper started.
+// The default value is [ArchiveTimeRotation].
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithArchiveTimeSource(lorekeeper.ArchiveTimeFirstWrite))
+func WithArchiveTimeSource(source ArchiveTimeSource) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if source < ArchiveTimeRotation || source > ArchiveTimeLastWrite {
+			return nil, fmt.Errorf("failed to set archive time source, unknown source %d", source)
+		}
+		k.archiveTimeSource = source
+		return k, nil
+	}
+}
+
+// Get the time of the archive being rotated according to the archive time source, the lock of the Keeper must be held.
+func (k *Keeper) archiveNameTime() time.Time {
+	// Without a write, or with writes before the Keeper started, the messages of the archive are not all known
+	if k.currentFirstWrite.IsZero() {
+		return k.now()
+	}
+	switch k.archiveTimeSource {
+	case ArchiveTimeFirstWrite:
+		if k.currentRecords >= 0 {
+			return k.currentFirstWrite
+		}
+	case ArchiveTimeLastWrite:
+		return k.lastWrite
+	}
+	return k.now()
+}
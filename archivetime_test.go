@@ -0,0 +1,63 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithArchiveTimeSource(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		source   ArchiveTimeSource
+		expected time.Time
+	}{
+		{ArchiveTimeRotation, start.Add(2 * time.Minute)},
+		{ArchiveTimeFirstWrite, start},
+		{ArchiveTimeLastWrite, start.Add(time.Minute)},
+	}
+	for _, tt := range tests {
+		now := start
+		k, err := New(
+			WithFolder(t.TempDir()),
+			WithName("test-archive-time"),
+			WithTimeLayout(time.RFC3339),
+			WithArchiveNameLayout("{{ .time }}{{ .extension }}"),
+			WithArchiveTimeSource(tt.source),
+			WithNowFunc(func() time.Time { return now }),
+		)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		for range 2 {
+			if _, err := k.Write([]byte("hello\n")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			now = now.Add(time.Minute)
+		}
+		archivePath, err := k.RotateNow()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if want := tt.expected.Format(time.RFC3339) + ".log"; filepath.Base(archivePath) != want {
+			t.Errorf("expected archive %q for source %d got %q", want, tt.source, filepath.Base(archivePath))
+		}
+		// Without messages, the archive is named after its rotation
+		now = now.Add(time.Minute)
+		archivePath, err = k.RotateNow()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if want := now.Format(time.RFC3339) + ".log"; filepath.Base(archivePath) != want {
+			t.Errorf("expected archive %q for source %d got %q", want, tt.source, filepath.Base(archivePath))
+		}
+		now = now.Add(time.Minute)
+		if err := k.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	if _, err := New(WithArchiveTimeSource(ArchiveTimeSource(7))); err == nil {
+		t.Errorf("expected an error for an unknown source")
+	}
+}
@@ -0,0 +1,272 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// A DropPolicy decides what happens to a write when the queue of [WithAsyncWrites] is full.
+type DropPolicy int
+
+const (
+	// Wait for room in the queue, so that no message is lost.
+	DropNone DropPolicy = iota
+	// Drop the oldest queued message to make room for the new one.
+	DropOldest
+	// Drop the new message.
+	DropNewest
+)
+
+// The queue of [WithAsyncWrites], drained to the log files by a goroutine of its own.
+type asyncQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	policy DropPolicy
+	// A ring of the queued messages, copied into pooled buffers
+	items    []*[]byte
+	head     int
+	count    int
+	draining bool
+	stopping bool
+	// The buffers of the written messages kept for the next ones, up to twice the size of the queue
+	// to cover the queued messages and the batch being written, since the writers and the drainer rarely share a pool shard
+	free      []*[]byte
+	maxPooled int
+	// The counter of the Keeper, so that it survives the queue
+	dropped *atomic.Uint64
+	done    chan struct{}
+}
+
+// Write the messages asynchronously for latency-sensitive services: [Keeper.Write] copies the message
+// into a queue of up to queueSize messages and returns immediately,
+// while a background goroutine writes the queued messages to the log files in order.
+// When the queue is full, the policy decides whether the write waits for room, or a message is dropped.
+// The dropped messages are counted in [Stats.AsyncDropped] and the queued ones in [Stats.AsyncQueued],
+// so that the loss can be alerted on. The errors of the queued writes go to the error handler.
+// [Keeper.Rotate], [Keeper.Flush], [Keeper.Sync] and [Keeper.Close] wait for the queued messages first.
+// It can not be used together with [WithDoubleBuffering] or [WithManualStepping].
+// Set queueSize < 1 to write synchronously, which is the default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithAsyncWrites(4096, lorekeeper.DropOldest))
+func WithAsyncWrites(queueSize int, policy DropPolicy) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if policy != DropNone && policy != DropOldest && policy != DropNewest {
+			return nil, fmt.Errorf("failed to set async writes, unknown drop policy %d", policy)
+		}
+		k.asyncSize = max(queueSize, 0)
+		k.asyncPolicy = policy
+		return k, nil
+	}
+}
+
+// Reject the combinations of [WithAsyncWrites] with the other background writers.
+func (k *Keeper) applyAsyncWrites() error {
+	if k.asyncSize > 0 && k.segmentSize > 0 {
+		return fmt.Errorf("failed to set async writes, it can not be used together with double buffering")
+	}
+	if k.asyncSize > 0 && k.manual {
+		return fmt.Errorf("failed to set async writes, it can not be used together with manual stepping")
+	}
+	return nil
+}
+
+// Start, resize or stop the queue of [WithAsyncWrites] to match the options, the lock of the Keeper must be held.
+func (k *Keeper) configureAsync() {
+	q := k.async.Load()
+	switch {
+	case k.asyncSize > 0 && q == nil:
+		q = &asyncQueue{items: make([]*[]byte, k.asyncSize), policy: k.asyncPolicy, maxPooled: k.maxPooledSize(), dropped: &k.asyncDropped, done: make(chan struct{})}
+		q.cond = sync.NewCond(&q.mu)
+		k.async.Store(q)
+		k.goSupervised("async", func() { k.drainAsync(q) }, func() { close(q.done) })
+	case k.asyncSize > 0:
+		q.mu.Lock()
+		q.resize(k.asyncSize)
+		q.policy = k.asyncPolicy
+		q.maxPooled = k.maxPooledSize()
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	case q != nil:
+		k.async.Store(nil)
+		q.stop()
+	}
+}
+
+// Queue a copy of the msg according to the drop policy.
+func (q *asyncQueue) append(msg []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.stopping && q.count == len(q.items) && q.policy == DropNone {
+		q.cond.Wait()
+	}
+	if q.stopping {
+		return 0, fmt.Errorf("failed to write, caused by %w", os.ErrClosed)
+	}
+	if q.count == len(q.items) {
+		q.dropped.Add(1)
+		if q.policy == DropNewest {
+			return 0, fmt.Errorf("failed to write, the async queue is full")
+		}
+		q.pop()
+	}
+	buf := q.getBuffer()
+	*buf = append(*buf, msg...)
+	q.items[(q.head+q.count)%len(q.items)] = buf
+	q.count++
+	q.cond.Broadcast()
+	return len(msg), nil
+}
+
+func (q *asyncQueue) pop() *[]byte {
+	buf := q.items[q.head]
+	q.items[q.head] = nil
+	q.head = (q.head + 1) % len(q.items)
+	q.count--
+	return buf
+}
+
+// Get an empty buffer, from the written messages if any, the mutex of the queue must be held.
+func (q *asyncQueue) getBuffer() *[]byte {
+	if n := len(q.free); n > 0 {
+		buf := q.free[n-1]
+		q.free[n-1] = nil
+		q.free = q.free[:n-1]
+		*buf = (*buf)[:0]
+		return buf
+	}
+	return getPooledBuffer()
+}
+
+// Keep the buffers of the written batch for the next messages, the mutex of the queue must be held.
+// The ones that grew past the max pooled buffer size are dropped, and the ones left over go back to the pool.
+func (q *asyncQueue) recycle(batch []*[]byte) {
+	for _, buf := range batch {
+		switch {
+		case cap(*buf) > q.maxPooled:
+		case len(q.free) < 2*len(q.items):
+			q.free = append(q.free, buf)
+		default:
+			bufferPool.Put(buf)
+		}
+	}
+}
+
+// Change the capacity of the queue, dropping the oldest messages that no longer fit.
+func (q *asyncQueue) resize(size int) {
+	if size == len(q.items) {
+		return
+	}
+	items := make([]*[]byte, size)
+	for q.count > size {
+		q.dropped.Add(1)
+		q.pop()
+	}
+	for i := range q.count {
+		items[i] = q.items[(q.head+i)%len(q.items)]
+	}
+	q.items, q.head = items, 0
+}
+
+// Wait until every message queued so far is written.
+func (q *asyncQueue) wait() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.count > 0 || q.draining {
+		q.cond.Wait()
+	}
+}
+
+// Stop the drainer once it has written every queued message, refusing new ones.
+func (q *asyncQueue) stop() {
+	q.mu.Lock()
+	q.stopping = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
+
+// Write a batch of queued messages to the log files.
+func (k *Keeper) writeAsyncBatch(batch []*[]byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, buf := range batch {
+		// The writer is long gone, report the failure instead
+		if _, err := k.writeLocked(*buf); err != nil {
+			k.handleError(err)
+		}
+	}
+}
+
+// Write the queued messages to the log files until the queue is stopped and empty.
+func (k *Keeper) drainAsync(q *asyncQueue) {
+	q.drain(k.writeAsyncBatch)
+}
+
+// Hand the queued messages over to write in batches until the queue is stopped and empty.
+func (q *asyncQueue) drain(write func(batch []*[]byte)) {
+	// Restarted after a panic, the batch being written is lost
+	q.mu.Lock()
+	q.draining = false
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	var batch []*[]byte
+	for {
+		q.mu.Lock()
+		for q.count == 0 && !q.stopping {
+			q.cond.Wait()
+		}
+		if q.count == 0 {
+			q.mu.Unlock()
+			return
+		}
+		for q.count > 0 {
+			batch = append(batch, q.pop())
+		}
+		q.draining = true
+		// Wake up the writers waiting for room
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		write(batch)
+
+		q.mu.Lock()
+		q.recycle(batch)
+		clear(batch)
+		batch = batch[:0]
+		q.draining = false
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// Wait until the messages queued by [WithAsyncWrites] so far are written, the lock of the Keeper must not be held.
+func (k *Keeper) waitAsync() {
+	if q := k.async.Load(); q != nil {
+		q.wait()
+	}
+}
+
+// Stop the drainer of [WithAsyncWrites] once every queued message is written,
+// the lock of the Keeper must not be held.
+func (k *Keeper) stopAsyncAndWait() {
+	if q := k.async.Swap(nil); q != nil {
+		q.stop()
+		<-q.done
+	}
+}
+
+// Get the number of messages queued by [WithAsyncWrites].
+func (k *Keeper) asyncQueued() uint64 {
+	q := k.async.Load()
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return uint64(q.count)
+}
@@ -0,0 +1,130 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestKeeperWithAsyncWrites(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-async"),
+		WithAsyncWrites(16, DropNone),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				if _, err := k.Write([]byte(fmt.Sprintf("%d-%d\n", i, j))); err != nil {
+					t.Errorf("expected no error got %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("closed\n")); err == nil {
+		t.Errorf("expected error since Keeper is closed")
+	}
+
+	if lines := strings.Count(readArchives(t, k), "\n"); lines != 1000 {
+		t.Errorf("expected 1000 lines got %d", lines)
+	}
+	if stats := k.Stats(); stats.AsyncDropped != 0 || stats.AsyncQueued != 0 {
+		t.Errorf("expected no dropped nor queued messages got %d and %d", stats.AsyncDropped, stats.AsyncQueued)
+	}
+}
+
+func TestKeeperWithAsyncWritesDropPolicies(t *testing.T) {
+	for _, policy := range []DropPolicy{DropOldest, DropNewest} {
+		t.Run(fmt.Sprint(policy), func(t *testing.T) {
+			folder := t.TempDir()
+			k, err := New(
+				WithFolder(folder),
+				WithName("test-async-drop"),
+				WithAsyncWrites(2, policy),
+			)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+
+			// The drainer can take at most one batch before waiting for the lock
+			k.mu.Lock()
+			var failed uint64
+			for i := range 10 {
+				if _, err := k.Write([]byte(fmt.Sprintf("%d\n", i))); err != nil {
+					failed++
+				}
+			}
+			queued := k.asyncQueued()
+			k.mu.Unlock()
+			if queued != 2 {
+				t.Errorf("expected 2 queued messages got %d", queued)
+			}
+
+			if err := k.Flush(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			stats := k.Stats()
+			if stats.AsyncDropped < 6 {
+				t.Errorf("expected at least 6 dropped messages got %d", stats.AsyncDropped)
+			}
+			if policy == DropNewest && failed != stats.AsyncDropped {
+				t.Errorf("expected %d failed writes got %d", stats.AsyncDropped, failed)
+			}
+			if policy == DropOldest && failed != 0 {
+				t.Errorf("expected no failed writes got %d", failed)
+			}
+			if err := k.Close(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(readArchives(t, k), "\n"), "\n")
+			if uint64(len(lines))+stats.AsyncDropped != 10 {
+				t.Errorf("expected %d lines got %d", 10-stats.AsyncDropped, len(lines))
+			}
+			// The newest message survives only if the oldest are dropped
+			if last := lines[len(lines)-1]; (last == "9") != (policy == DropOldest) {
+				t.Errorf("unexpected last line %q", last)
+			}
+		})
+	}
+}
+
+func TestKeeperWithAsyncWritesOptions(t *testing.T) {
+	if _, err := New(WithAsyncWrites(8, DropPolicy(42))); err == nil {
+		t.Errorf("expected error for unknown drop policy")
+	}
+	if _, err := New(WithAsyncWrites(8, DropNone), WithDoubleBuffering(32)); err == nil {
+		t.Errorf("expected error together with double buffering")
+	}
+	if _, err := New(WithAsyncWrites(8, DropNone), WithManualStepping()); err == nil {
+		t.Errorf("expected error together with manual stepping")
+	}
+}
+
+// Read the content of the archives of k, from oldest to newest.
+func readArchives(t *testing.T, k *Keeper) string {
+	t.Helper()
+	var content strings.Builder
+	for _, archive := range k.Archives() {
+		b, err := os.ReadFile(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		content.Write(b)
+	}
+	return content.String()
+}
@@ -0,0 +1,65 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The min size of the signing key of [NewAuditKeeper].
+const minAuditKeySize = 32
+
+// Create a new [Keeper] configured for audit logs, so that teams with audit requirements get a correct configuration in one call.
+// On top of the options of [New], it syncs every write to the disk, see [WithSyncWrites],
+// and makes the archives read-only, see [WithReadOnlyArchives].
+// The messages must be signed, so opts must contain [WithHashChain] with a key of at least 32 bytes.
+// The archives are never deleted, the only way for them to leave is to be offloaded with [WithUploader] and deleteAfterUpload,
+// so the options that delete archives or drop messages are rejected,
+// such as [WithMaxFiles], [WithTotalSize], [WithMaxAge], [WithRetentionPolicy], [WithMinDiskFree], [WithSampling] and the drop policies of [WithAsyncWrites],
+// both here and by [Keeper.Reconfigure], and the Keeper can not be added to a [Group].
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.NewAuditKeeper(
+//		lorekeeper.WithFolder("/var/log/app/audit"),
+//		lorekeeper.WithName("audit"),
+//		lorekeeper.WithHashChain(key),
+//		lorekeeper.WithGzip(),
+//		lorekeeper.WithUploader(uploader, true),
+//	)
+func NewAuditKeeper(opts ...Opt) (*Keeper, error) {
+	return New(append([]Opt{withAuditProfile(true), WithSyncWrites(), WithReadOnlyArchives()}, opts...)...)
+}
+
+func withAuditProfile(audit bool) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.audit = audit
+		return k, nil
+	}
+}
+
+// Check that the options keep the guarantees of [NewAuditKeeper].
+func (k *Keeper) applyAuditProfile() error {
+	if !k.audit {
+		return nil
+	}
+	var errs []error
+	if k.chain == nil || len(k.chain.key) < minAuditKeySize {
+		errs = append(errs, fmt.Errorf("the messages must be signed with a key of at least %d bytes, see WithHashChain", minAuditKeySize))
+	}
+	if !k.syncWrites {
+		errs = append(errs, fmt.Errorf("the writes must be synced, see WithSyncWrites"))
+	}
+	if !k.readOnlyArchives {
+		errs = append(errs, fmt.Errorf("the archives must be read-only, see WithReadOnlyArchives"))
+	}
+	if k.maxFiles > 0 || k.totalSize > 0 || k.maxAge > 0 || k.retentionPolicy != nil || k.minDiskFree > 0 || k.minDiskFreePercent > 0 {
+		errs = append(errs, fmt.Errorf("the archives must not be deleted by the retention, only offloaded, see WithUploader"))
+	}
+	if k.sampleRate < 1 || k.asyncPolicy != DropNone || (k.rateLimit > 0 && k.ratePolicy != DropNone) {
+		errs = append(errs, fmt.Errorf("the messages must not be dropped, see WithSampling, WithAsyncWrites and WithRateLimitPolicy"))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to set audit profile, caused by %w", errors.Join(errs...))
+	}
+	return nil
+}
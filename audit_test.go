@@ -0,0 +1,80 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNewAuditKeeper(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	k, err := NewAuditKeeper(WithFolder(t.TempDir()), WithName("test-audit"), WithHashChain(key))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !k.syncWrites || !k.readOnlyArchives {
+		t.Errorf("expected synced writes and read-only archives got %+v", k)
+	}
+	if _, err := k.Write([]byte("user 42 deleted invoice 7\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// Reconfiguring can not weaken the profile
+	if err := k.Reconfigure(WithMaxFiles(3)); err == nil {
+		t.Errorf("expected an error enabling the retention of an audit keeper")
+	}
+	group, err := NewGroup(Gb, 1)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := group.Add(k); err == nil {
+		t.Errorf("expected an error adding an audit keeper to a group")
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	stat, err := os.Stat(archives[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if stat.Mode().Perm()&0222 != 0 {
+		t.Errorf("expected a read-only archive got %v", stat.Mode())
+	}
+	content, err := os.ReadFile(archives[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if n, err := VerifyChain(bytes.NewReader(content), key); err != nil || n != 1 {
+		t.Errorf("expected 1 verified record got %d, %v", n, err)
+	}
+}
+
+func TestNewAuditKeeperRejectsWeakOptions(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name string
+		opts []Opt
+	}{
+		{"no key", nil},
+		{"short key", []Opt{WithHashChain([]byte("short"))}},
+		{"max files", []Opt{WithHashChain(key), WithMaxFiles(3)}},
+		{"total size", []Opt{WithHashChain(key), WithTotalSize(Gb)}},
+		{"max age", []Opt{WithHashChain(key), WithMaxAge(time.Hour)}},
+		{"retention policy", []Opt{WithHashChain(key), WithRetentionPolicy(MaxFilesPolicy(1))}},
+		{"sampling", []Opt{WithHashChain(key), WithSampling(0.5, nil)}},
+		{"writable archives", []Opt{WithHashChain(key), NoReadOnlyArchives()}},
+		{"unsynced writes", []Opt{WithHashChain(key), NoSyncWrites()}},
+	}
+	for _, tt := range tests {
+		opts := append([]Opt{WithFolder(t.TempDir()), WithName("test-audit-weak")}, tt.opts...)
+		if k, err := NewAuditKeeper(opts...); err == nil {
+			k.Close()
+			t.Errorf("expected an error for %s", tt.name)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !plan9
+
+package lorekeeper
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Check whether the error comes from a descriptor that is no longer valid, see [Keeper.writeFile].
+func isBadDescriptor(err error) bool {
+	return errors.Is(err, syscall.EBADF)
+}
@@ -0,0 +1,14 @@
+//go:build plan9
+
+package lorekeeper
+
+import (
+	"errors"
+	"os"
+)
+
+// Check whether the error comes from a file that is no longer open, see [Keeper.writeFile].
+// Plan 9 has no error number for an invalid descriptor, only the closing of the file itself is detected.
+func isBadDescriptor(err error) bool {
+	return errors.Is(err, os.ErrClosed)
+}
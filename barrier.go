@@ -0,0 +1,70 @@
+package lorekeeper
+
+import "fmt"
+
+// Run fn while no rotation nor removal of archives happens, such as for a backup or a snapshot routine
+// that needs a stable set of files. Unlike [Keeper.Pause], the writes still go to the current log file,
+// which may then grow beyond the max size of [WithMaxSize].
+// The rotations asked for while fn runs, whether by the size, the schedule or [Keeper.Rotate], are held,
+// then the first of them happens once fn returns, followed by the retention.
+// [Keeper.RotateNow] returns an empty path for a held rotation.
+// [Keeper.Close] still rotates the current log file. Calls may overlap, the rotations are held until all of them return.
+// It returns the error of fn.
+//
+// Example usage:
+//
+//	err := keeper.WithRotationPaused(func() error {
+//		return exec.Command("restic", "backup", keeper.Folder()).Run()
+//	})
+func (k *Keeper) WithRotationPaused(fn func() error) error {
+	k.mu.Lock()
+	k.rotationBarrier++
+	k.mu.Unlock()
+
+	fnErr := fn()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.rotationBarrier--
+	if k.rotationBarrier > 0 || k.closed {
+		return fnErr
+	}
+	if reason := k.heldRotation; len(reason) > 0 {
+		k.heldRotation = ""
+		if err := k.rotateLocked(reason, RotateOpts{}); err != nil {
+			k.handleError(fmt.Errorf("failed to rotate after the rotations were held, caused by %w", err))
+		}
+	}
+	for _, archivePath := range k.heldUploaded {
+		k.removeUploaded(archivePath)
+	}
+	k.heldUploaded = nil
+	if k.heldPrune {
+		k.heldPrune = false
+		if err := k.prune(); err != nil {
+			k.handleError(err)
+		}
+	}
+	return fnErr
+}
+
+// Hold the rotation for the given reason while [Keeper.WithRotationPaused] runs, telling whether it is held.
+func (k *Keeper) holdRotation(reason RotationReason) bool {
+	if k.rotationBarrier == 0 || reason == RotationClose {
+		return false
+	}
+	if len(k.heldRotation) == 0 {
+		k.heldRotation = reason
+	}
+	k.lastArchivePath = ""
+	return true
+}
+
+// Hold the removal of archives while [Keeper.WithRotationPaused] runs, telling whether it is held.
+func (k *Keeper) holdPrune() bool {
+	if k.rotationBarrier == 0 {
+		return false
+	}
+	k.heldPrune = true
+	return true
+}
@@ -0,0 +1,103 @@
+package lorekeeper
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestKeeperWithRotationPaused(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-rotation-paused"),
+		WithMaxSize(10),
+		WithMaxFiles(1),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for range 2 {
+		if _, err := k.Write([]byte("012345678\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	before := k.Archives()
+	if len(before) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(before))
+	}
+
+	sentinel := errors.New("backup failed")
+	err = k.WithRotationPaused(func() error {
+		// The writes go on in the current log file
+		for range 3 {
+			if _, err := k.Write([]byte("012345678\n")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		archives := k.Archives()
+		if len(archives) != 1 || archives[0].Path != before[0].Path {
+			t.Errorf("expected the archives to be unchanged got %v", archives)
+		}
+		stat, err := os.Stat(k.CurrentFilePath())
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if size := stat.Size(); size != 40 {
+			t.Errorf("expected a current log file of 40 bytes got %d", size)
+		}
+		return sentinel
+	})
+	if !errors.Is(err, sentinel) {
+		t.Errorf("expected error %v got %v", sentinel, err)
+	}
+
+	// The held rotation and the retention happen afterward
+	archives := k.Archives()
+	if len(archives) != 1 || archives[0].Path == before[0].Path {
+		t.Errorf("expected a new archive replacing the old one got %v", archives)
+	}
+	if archives[0].Size != 40 {
+		t.Errorf("expected the new archive to hold 40 bytes got %d", archives[0].Size)
+	}
+}
+
+func TestKeeperWithRotationPausedChunked(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-rotation-paused-chunked"),
+		WithMaxSize(10),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if _, err := k.Write([]byte("012345678\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The held rotation leaves the current log file full, the msg is written unsplit
+	msg := []byte("0123456789abcdefghijklmn\n")
+	k.mu.Lock()
+	k.rotationBarrier++
+	n, err := k.writeChunked(msg)
+	k.rotationBarrier--
+	k.mu.Unlock()
+	if err != nil || n != len(msg) {
+		t.Fatalf("expected %d bytes and no error got %d and %v", len(msg), n, err)
+	}
+	stat, err := os.Stat(k.CurrentFilePath())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if size := int(stat.Size()); size != 10+len(msg) {
+		t.Errorf("expected a current log file of %d bytes got %d", 10+len(msg), size)
+	}
+	if archives := k.Archives(); len(archives) != 0 {
+		t.Errorf("expected no archive got %v", archives)
+	}
+}
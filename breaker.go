@@ -0,0 +1,29 @@
+package lorekeeper
+
+import "errors"
+
+// Returned, wrapped, by [Keeper.Write] while the circuit breaker is open, see [WithCircuitBreaker].
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Check if writes should skip the current log file because the circuit breaker is open.
+func (k *Keeper) breakerOpen() bool {
+	return k.breakerThreshold > 0 &&
+		k.breakerFailures >= k.breakerThreshold &&
+		k.now().Before(k.breakerProbeAt)
+}
+
+// Record the result of a write to the current log file, tripping the circuit breaker if needed.
+func (k *Keeper) recordBreakerResult(err error) {
+	if k.breakerThreshold <= 0 {
+		return
+	}
+	if err == nil {
+		k.breakerFailures = 0
+		return
+	}
+	k.breakerFailures++
+	if k.breakerFailures >= k.breakerThreshold {
+		// A failed probe keeps the breaker open for another interval
+		k.breakerProbeAt = k.now().Add(k.breakerProbeInterval)
+	}
+}
@@ -0,0 +1,107 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeeperWithCircuitBreaker(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	clock := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	var fallback bytes.Buffer
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-circuit-breaker"),
+		WithNowFunc(func() time.Time { return clock }),
+		WithFallbackWriter(&fallback),
+		WithCircuitBreaker(2, time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// Break the primary path and trip the breaker
+	if err := os.RemoveAll(folder); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	_ = k.Reopen()
+	for _, msg := range []string{"a", "b"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if !k.breakerOpen() {
+		t.Fatalf("expected the breaker to be open")
+	}
+
+	// Writes skip the current log file even if the path is healed
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("c")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if fallback.String() != "abc" {
+		t.Errorf("expected fallback to contain %q got %q", "abc", fallback.String())
+	}
+	if _, err := os.Stat(filepath.Join(folder, "test-circuit-breaker.log")); err == nil {
+		t.Errorf("expected no disk IO while the breaker is open")
+	}
+
+	// The next write after the probe interval closes the breaker
+	clock = clock.Add(time.Minute)
+	if _, err := k.Write([]byte("d")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if k.breakerOpen() {
+		t.Errorf("expected the breaker to be closed")
+	}
+	content, err := os.ReadFile(filepath.Join(folder, "test-circuit-breaker.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "d" {
+		t.Errorf("expected %q got %q", "d", content)
+	}
+}
+
+func TestKeeperWithCircuitBreakerNoFallback(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-circuit-breaker-no-fallback"),
+		WithCircuitBreaker(1, time.Hour),
+		// Close fails since the current log file is gone, keep it out of the package-level registry
+		WithRegistry(NewRegistry()),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if err := os.RemoveAll(folder); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	_ = k.Reopen()
+	if _, err := k.Write([]byte("a")); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the first write to fail on disk got %v", err)
+	}
+	if _, err := k.Write([]byte("b")); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected %v got %v", ErrCircuitOpen, err)
+	}
+
+	if _, err := New(WithName("test-circuit-breaker-invalid"), WithCircuitBreaker(1, 0)); err == nil {
+		t.Errorf("expected error since probe interval is not positive")
+	}
+}
@@ -0,0 +1,155 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Create a read-only [http.Handler] to browse the files managed by the Keeper, a tiny log viewer for internal tools.
+// Mount it under a prefix with [http.StripPrefix], for example:
+//
+//	http.Handle("/debug/logs/", http.StripPrefix("/debug/logs", lorekeeper.NewBrowser(keeper)))
+//
+// The root lists the current log file and the archives,
+// the "from" and "to" query parameters, in [time.RFC3339] format, filter the listing by modification time.
+// Any other path serves the file of that name, supporting range requests.
+// Add the "decompress" query parameter to decompress a gzip archive on the fly.
+func NewBrowser(k *Keeper) http.Handler {
+	return &browser{k: k, fsys: k.FS()}
+}
+
+type browser struct {
+	k    *Keeper
+	fsys fs.FS
+}
+
+var browserListing = template.Must(template.New("lorekeeper-browser").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{ .name }}</title></head>
+<body>
+<h1>{{ .name }}</h1>
+<table>
+<tr><th>Name</th><th>Size</th><th>Modified</th></tr>
+{{- range .entries }}
+<tr><td><a href="{{ .Href }}">{{ .Name }}</a></td><td>{{ .Size }}</td><td>{{ .ModTime }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+type browserEntry struct {
+	Name    string
+	Href    string
+	Size    int64
+	ModTime string
+}
+
+func (b *browser) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if len(name) == 0 {
+		b.serveListing(w, r)
+		return
+	}
+	b.serveFile(w, r, name)
+}
+
+func (b *browser) serveListing(w http.ResponseWriter, r *http.Request) {
+	var from, to time.Time
+	for param, value := range map[string]*time.Time{"from": &from, "to": &to} {
+		raw := r.URL.Query().Get(param)
+		if len(raw) == 0 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid %q parameter, caused by %s", param, err), http.StatusBadRequest)
+			return
+		}
+		*value = t
+	}
+
+	dirEntries, err := fs.ReadDir(b.fsys, ".")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	entries := make([]browserEntry, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if (!from.IsZero() && info.ModTime().Before(from)) || (!to.IsZero() && info.ModTime().After(to)) {
+			continue
+		}
+		href := entry.Name()
+		if _, ok := b.decompressor(href).(Decompressor); ok {
+			href += "?decompress"
+		}
+		entries = append(entries, browserEntry{
+			Name:    entry.Name(),
+			Href:    href,
+			Size:    info.Size(),
+			ModTime: info.ModTime().Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = browserListing.Execute(w, map[string]any{
+		"name":    b.k.Name(),
+		"entries": entries,
+	})
+}
+
+func (b *browser) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	f, err := b.fsys.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	if c := b.decompressor(name); r.URL.Query().Has("decompress") && c != nil {
+		reader, err := newDecompressingReader(c, f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer reader.Close()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.Copy(w, reader)
+		return
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	seeker, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "file is not seekable", http.StatusInternalServerError)
+		return
+	}
+	if b.decompressor(name) == nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	http.ServeContent(w, r, name, stat.ModTime(), seeker)
+}
+
+func (b *browser) decompressor(name string) Compressor {
+	b.k.mu.Lock()
+	defer b.k.mu.Unlock()
+	return b.k.archiveDecompressor(name)
+}
@@ -0,0 +1,69 @@
+package lorekeeper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBrowser(t *testing.T) {
+	k, err := New(
+		WithName("Test-Browser"),
+		WithFolder(t.TempDir()),
+		WithMaxSize(10),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"0123456789", "abcdef"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	archiveName := filepath.Base(archives[0].Path)
+	handler := NewBrowser(k)
+
+	tests := []struct {
+		name       string
+		target     string
+		rangeHdr   string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "listing", target: "/", wantStatus: http.StatusOK, wantBody: "test-browser.log.gz"},
+		{name: "filtered listing", target: "/?to=2000-01-01T00:00:00Z", wantStatus: http.StatusOK},
+		{name: "invalid filter", target: "/?from=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "current log", target: "/test-browser.log", wantStatus: http.StatusOK, wantBody: "abcdef"},
+		{name: "range", target: "/test-browser.log", rangeHdr: "bytes=2-3", wantStatus: http.StatusPartialContent, wantBody: "cd"},
+		{name: "decompress", target: "/" + archiveName + "?decompress", wantStatus: http.StatusOK, wantBody: "0123456789"},
+		{name: "not managed", target: "/other.log", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if len(tt.rangeHdr) > 0 {
+				req.Header.Set("Range", tt.rangeHdr)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d got %d", tt.wantStatus, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain %q got %q", tt.wantBody, rec.Body.String())
+			}
+			if tt.name == "filtered listing" && strings.Contains(rec.Body.String(), "test-browser.log.gz") {
+				t.Errorf("expected archive to be filtered out")
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The default interval between the flushes of the write buffer, see [WithFlushInterval].
+const defaultFlushInterval = time.Second
+
+// Buffer up to n bytes of messages in memory before writing them to the current log file,
+// so that chatty loggers make a few large writes instead of many small ones.
+// A message that does not fit in the free space of the buffer flushes it first,
+// and a message of n bytes or more is written directly.
+// The buffer is flushed periodically, see [WithFlushInterval], and before the current log file is rotated or closed,
+// or on demand with [Keeper.Flush] and [Keeper.Sync]. [Keeper.WriteUrgent] also flushes it.
+// The buffered messages count in the size of the current log file, but readers such as [Keeper.Between]
+// do not see them until they are flushed, and they are lost if the process crashes.
+// If a flush fails, the buffered messages are kept for the next one.
+// Set n to zero to write every message directly, which is the default.
+func WithBufferSize(n int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if n < 0 {
+			return nil, fmt.Errorf("failed to set buffer size, size must not be negative")
+		}
+		k.bufferSize = n
+		return k, nil
+	}
+}
+
+// Flush the write buffer of [WithBufferSize] at most d after a message is buffered,
+// bounding how long a message may wait in memory. Set d to zero or negative to only flush on demand,
+// when the buffer is full, or before the current log file is rotated or closed.
+// The default value is 1 second.
+func WithFlushInterval(d time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.flushInterval = d
+		return k, nil
+	}
+}
+
+// Write the buffered messages to the current log file, see [WithBufferSize].
+func (k *Keeper) Flush() error {
+	k.waitAsync()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.flushBuffer()
+}
+
+// Write the buffered messages to the current log file, see [WithBufferSize],
+// then commit the current log file to the disk, so that it survives a crash of the host.
+func (k *Keeper) Sync() error {
+	k.waitAsync()
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.syncCurrentFile()
+}
+
+// Buffer the msg if it fits, or write it to the current log file, flushing the buffer first.
+func (k *Keeper) writeBuffered(msg []byte) (int, error) {
+	if len(k.writeBuf)+len(msg) > k.bufferSize {
+		if err := k.flushBuffer(); err != nil {
+			return 0, err
+		}
+	}
+	if len(msg) >= k.bufferSize {
+		return k.writeFile(msg)
+	}
+	if len(k.writeBuf) == 0 {
+		k.scheduleFlush()
+	}
+	k.writeBuf = append(k.writeBuf, msg...)
+	return len(msg), nil
+}
+
+// Write the buffered messages to the current log file, keeping what could not be written.
+func (k *Keeper) flushBuffer() error {
+	if len(k.writeBuf) == 0 {
+		return nil
+	}
+	if err := k.openCurrentFile(); err != nil {
+		return fmt.Errorf("failed to flush buffer, caused by %w", err)
+	}
+	n, err := k.writeFile(k.writeBuf)
+	k.writeBuf = k.writeBuf[:copy(k.writeBuf, k.writeBuf[n:])]
+	if err != nil {
+		return fmt.Errorf("failed to flush buffer, caused by %w", err)
+	}
+	if cap(k.writeBuf) > max(k.bufferSize, k.maxPooledSize()) {
+		k.writeBuf = nil
+	}
+	return nil
+}
+
+// Flush the buffer once the flush interval elapsed, see [WithFlushInterval].
+func (k *Keeper) scheduleFlush() {
+	k.bufferedAt = k.now()
+	if k.flushInterval <= 0 || k.closed || k.manual {
+		return
+	}
+	if k.flushTimer == nil {
+		k.flushTimer = k.clock.AfterFunc(k.flushInterval, k.supervised("flush", k.flushOnTimer))
+		return
+	}
+	k.flushTimer.Reset(k.flushInterval)
+}
+
+func (k *Keeper) flushOnTimer() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.flushBuffer(); err != nil {
+		k.handleError(err)
+	}
+	if err := k.flushCompressedCurrent(); err != nil {
+		k.handleError(err)
+	}
+}
+
+func (k *Keeper) stopFlushTimer() {
+	if k.flushTimer != nil {
+		k.flushTimer.Stop()
+		k.flushTimer = nil
+	}
+}
+
+// Flush the buffer and close the current log file if it is open.
+func (k *Keeper) closeCurrentFile() error {
+	flushErr := k.flushBuffer()
+	if k.currentFile == nil {
+		return flushErr
+	}
+	err := k.currentFile.Close()
+	if cf, ok := k.currentFile.(*compressedFile); ok && err == nil {
+		k.finishedCurrentSize = cf.size
+	}
+	k.currentFile = nil
+	return errors.Join(flushErr, err)
+}
@@ -0,0 +1,84 @@
+package lorekeeper
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestKeeperBufferedWrites(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-buffered-writes"),
+		WithBufferSize(Kb),
+		WithFlushInterval(0),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	assertContent := func(path, want string) {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if string(content) != want {
+			t.Errorf("expected %q got %q", want, content)
+		}
+	}
+
+	if _, err := k.Write([]byte("buffered\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	assertContent(k.CurrentFilePath(), "")
+	if err := k.Sync(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	assertContent(k.CurrentFilePath(), "buffered\n")
+
+	// The rotation flushes before archiving
+	if _, err := k.Write([]byte("rotated\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	assertContent(archives[0].Path, "buffered\nrotated\n")
+}
+
+func TestKeeperFlushInterval(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-flush-interval"),
+		WithBufferSize(Kb),
+		WithFlushInterval(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("flushed\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		content, err := os.ReadFile(k.CurrentFilePath())
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if string(content) == "flushed\n" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the buffer to be flushed got %q", content)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
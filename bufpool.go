@@ -0,0 +1,56 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"sync"
+)
+
+// The default max capacity of the buffers reused across writes, see [WithMaxPooledBufferSize].
+const defaultMaxPooledBufferSize = 64 * Kb
+
+// The byte buffers shared by all the Keepers for their internal copies, such as the messages buffered while paused.
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new([]byte)
+	},
+}
+
+// Set the max capacity of the buffers reused for the internal copies of the messages,
+// such as the JSON normalization, the record framing, the write buffer and the messages buffered while paused,
+// so that the steady-state allocations stay flat under sustained load.
+// A buffer grown past the given size by a large message is dropped instead of reused, so that it is not held forever.
+// Set zero to never reuse the buffers, defaults to 64 Kb, or 16 Kb with little memory, see [DefaultTuning].
+func WithMaxPooledBufferSize(n int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if n < 0 {
+			return nil, fmt.Errorf("failed to set max pooled buffer size, size must not be negative")
+		}
+		k.maxPooledBufferSize.Store(int64(n))
+		return k, nil
+	}
+}
+
+// Get an empty buffer from the pool.
+func getPooledBuffer() *[]byte {
+	buf := bufferPool.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// Return the buffer to the pool unless it grew past the max pooled buffer size.
+func (k *Keeper) putPooledBuffer(buf *[]byte) {
+	if cap(*buf) > k.maxPooledSize() {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// Tell whether a buffer of the given capacity may be kept for the next writes.
+func (k *Keeper) reusable(buf []byte) bool {
+	return cap(buf) <= k.maxPooledSize()
+}
+
+// Get the max capacity of the buffers reused across writes, see [WithMaxPooledBufferSize].
+func (k *Keeper) maxPooledSize() int {
+	return int(k.maxPooledBufferSize.Load())
+}
@@ -0,0 +1,42 @@
+package lorekeeper
+
+import (
+	"testing"
+)
+
+func TestWithMaxPooledBufferSize(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-max-pooled-buffer-size"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	msg := []byte("paused message\n")
+	cycle := func() {
+		k.Pause()
+		for range 4 {
+			if _, err := k.Write(msg); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+		if err := k.Resume(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	// The buffered messages reuse the pooled buffers once warm
+	cycle()
+	if allocs := testing.AllocsPerRun(100, cycle); !raceEnabled && allocs >= 1 {
+		t.Errorf("expected less than 1 alloc per pause cycle got %v", allocs)
+	}
+
+	// A buffer larger than the max size is not kept
+	if _, err := Options(WithMaxPooledBufferSize(8))(k); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if k.reusable(make([]byte, 0, 9)) {
+		t.Errorf("expected a buffer over the max size to be dropped")
+	}
+	if _, err := New(WithFolder(t.TempDir()), WithName("test-max-pooled-buffer-size-invalid"), WithMaxPooledBufferSize(-1)); err == nil {
+		t.Errorf("expected error for a negative size got nil")
+	}
+}
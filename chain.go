@@ -0,0 +1,286 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"slices"
+)
+
+// Returned, wrapped, by [VerifyChain] when a log file does not match its hash chain, see [WithHashChain].
+var ErrChainBroken = errors.New("hash chain broken")
+
+const (
+	// Ends every message written with a hash chain, followed by the hex of the hash and a new line
+	chainMarker = " chain="
+	// A line holding the hash the chain continues from, at the start of every log file
+	chainSeedPrefix = "chain-seed="
+	chainHexSize    = 2 * sha256.Size
+)
+
+// Chain the messages with SHA-256 hashes, so that altering, removing or reordering a message is detected by [VerifyChain].
+// Every message is written as one record, ended with " chain=" and the hex of the hash of the previous record and the message,
+// the trailing new line of the message being replaced by the one after the hash.
+// Every log file starts with a "chain-seed=" line holding the hash the chain continues from,
+// so that each log file is verified on its own, and the chain carries on across the rotations.
+// With a key, the hashes are HMAC-SHA256 signatures, which can not be recomputed by whoever alters the log files without the key,
+// without one, the chain only detects accidental changes. The key should be at least 32 random bytes.
+// A message is never split across log files, even if it is larger than [WithMaxSize].
+// The chain can not be combined with [WithLengthPrefixedRecords], [WithValidateJSON] and [WithWrapInvalidJSON].
+// Is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithHashChain(key))
+//	keeper.Write([]byte("user 42 deleted invoice 7\n"))
+//	// user 42 deleted invoice 7 chain=3f9a...
+func WithHashChain(key []byte) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		chain := newHashChain(key)
+		// Carry on the chain of the running Keeper, see [Keeper.Reconfigure]
+		if k.chain != nil {
+			chain.prev = k.chain.prev
+		}
+		k.chain = chain
+		return k, nil
+	}
+}
+
+// Write the messages as they are, this is the default.
+func NoHashChain() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.chain = nil
+		return k, nil
+	}
+}
+
+// Check that the records of [WithHashChain] can be written.
+func (k *Keeper) applyHashChain() error {
+	if k.chain == nil {
+		return nil
+	}
+	if k.lengthPrefixed {
+		return fmt.Errorf("failed to set hash chain, it can not be combined with length-prefixed records")
+	}
+	if k.jsonPolicy != jsonOff {
+		return fmt.Errorf("failed to set hash chain, it can not be embedded into JSON lines")
+	}
+	return nil
+}
+
+// The state of the hash chain of a Keeper.
+type hashChain struct {
+	key  []byte
+	hash hash.Hash
+	// The hash of the last record
+	prev [sha256.Size]byte
+}
+
+func newHashChain(key []byte) *hashChain {
+	c := &hashChain{key: slices.Clone(key)}
+	if len(key) > 0 {
+		c.hash = hmac.New(sha256.New, c.key)
+	} else {
+		c.hash = sha256.New()
+	}
+	return c
+}
+
+// Chain the body of a record to the previous one, and return its hash.
+func (c *hashChain) next(body []byte) []byte {
+	c.hash.Reset()
+	c.hash.Write(c.prev[:])
+	c.hash.Write(body)
+	c.hash.Sum(c.prev[:0])
+	return c.prev[:]
+}
+
+// Append the body framed as a record of the chain to dst.
+func (c *hashChain) appendRecord(dst, body []byte) []byte {
+	dst = append(dst, body...)
+	dst = append(dst, chainMarker...)
+	dst = hex.AppendEncode(dst, c.next(body))
+	return append(dst, '\n')
+}
+
+// Append the line holding the hash the chain continues from to dst.
+func (c *hashChain) appendSeed(dst []byte) []byte {
+	dst = append(dst, chainSeedPrefix...)
+	dst = hex.AppendEncode(dst, c.prev[:])
+	return append(dst, '\n')
+}
+
+// Write the msg as a record of the chain, rotating the current log file beforehand if rotate is set and it is due.
+func (k *Keeper) writeChained(msg []byte, rotate bool) (int, error) {
+	if len(msg) == 0 {
+		return 0, nil
+	}
+	body := bytes.TrimSuffix(msg, newLine)
+	buf := getPooledBuffer()
+	defer k.putPooledBuffer(buf)
+	// The hash depends on the log file the record goes to, so it is only known once rotated
+	*buf = append(*buf, body...)
+	*buf = append(*buf, chainMarker...)
+	*buf = append(*buf, make([]byte, chainHexSize)...)
+	*buf = append(*buf, '\n')
+	if rotate {
+		if reason, ok := k.rotationFor(*buf); ok {
+			if err := k.rotate(reason); err != nil {
+				return 0, err
+			}
+		}
+	}
+	prev := k.chain.prev
+	hex.Encode((*buf)[len(body)+len(chainMarker):], k.chain.next(body))
+
+	n, err := k.write(*buf)
+	// A record that is not written at all is left out of the chain
+	if n == 0 {
+		k.chain.prev = prev
+	}
+	if n == len(*buf) {
+		return len(msg), err
+	}
+	return min(n, len(body)), err
+}
+
+// Continue the chain from the last record of the current log file, the lock of the Keeper must be held.
+// A log file that does not end with a record of the chain, such as one written without it, gets a seed line.
+func (k *Keeper) scanCurrentChain() error {
+	if k.chain == nil {
+		return nil
+	}
+	// A trailing seed line, or a record whose hash ends the log file
+	tailSize := len(chainSeedPrefix) + chainHexSize + 1
+	var tail []byte
+	file, err := k.fsys.Open(k.getCurrentFilePath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to scan hash chain of current log file, caused by %w", err)
+	}
+	if err == nil {
+		defer file.Close()
+		stat, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to scan hash chain of current log file, caused by %w", err)
+		}
+		offset := max(stat.Size()-int64(tailSize), 0)
+		tail = make([]byte, stat.Size()-offset)
+		if err := readAt(file, tail, offset); err != nil {
+			return fmt.Errorf("failed to scan hash chain of current log file, caused by %w", err)
+		}
+	}
+	tail = append(tail, k.writeBuf...)
+	tail = tail[max(len(tail)-tailSize, 0):]
+
+	if len(tail) > 0 && tail[len(tail)-1] == '\n' {
+		if sum, ok := parseChainSeed(tail[:len(tail)-1]); ok {
+			k.chain.prev = sum
+			return nil
+		}
+		if _, sum, ok := parseChainRecord(tail[:len(tail)-1]); ok {
+			k.chain.prev = sum
+			return nil
+		}
+	}
+
+	var seed []byte
+	if len(tail) > 0 && tail[len(tail)-1] != '\n' {
+		seed = append(seed, '\n')
+	}
+	seed = k.chain.appendSeed(seed)
+	if _, err := k.writeCurrent(seed); err != nil {
+		return fmt.Errorf("failed to write hash chain seed, caused by %w", err)
+	}
+	return nil
+}
+
+// Split a line ending with the hash of a record into its body and its hash.
+func parseChainRecord(line []byte) (body []byte, sum [sha256.Size]byte, ok bool) {
+	end := len(line) - chainHexSize
+	if end < len(chainMarker) || string(line[end-len(chainMarker):end]) != chainMarker {
+		return nil, sum, false
+	}
+	if _, err := hex.Decode(sum[:], line[end:]); err != nil {
+		return nil, sum, false
+	}
+	return line[:end-len(chainMarker)], sum, true
+}
+
+// Get the hash of a seed line.
+func parseChainSeed(line []byte) (sum [sha256.Size]byte, ok bool) {
+	if len(line) != len(chainSeedPrefix)+chainHexSize || !bytes.HasPrefix(line, []byte(chainSeedPrefix)) {
+		return sum, false
+	}
+	if _, err := hex.Decode(sum[:], line[len(chainSeedPrefix):]); err != nil {
+		return sum, false
+	}
+	return sum, true
+}
+
+// Verify the hash chain of a log file written with [WithHashChain] and the same key,
+// returning the number of records verified.
+// The error wraps [ErrChainBroken] and tells the line of the first record that does not match,
+// either because it was altered, or because a record before it was removed or inserted,
+// as well as the lines not covered by the chain, such as a truncated last record.
+// The compressed or encrypted archives must be decompressed first, such as with [Inspector.Reader].
+//
+// Example usage:
+//
+//	file, err := os.Open("/var/log/app/app.log")
+//	...
+//	if _, err := lorekeeper.VerifyChain(file, key); errors.Is(err, lorekeeper.ErrChainBroken) {
+//		// Alert on the tampering
+//	}
+func VerifyChain(r io.Reader, key []byte) (int, error) {
+	chain := newHashChain(key)
+	reader := bufio.NewReader(r)
+	var pending []byte
+	records, line, start := 0, 0, 0
+	for {
+		text, readErr := reader.ReadBytes('\n')
+		if len(text) > 0 {
+			line++
+			text = bytes.TrimSuffix(text, newLine)
+			if len(pending) == 0 {
+				start = line
+			}
+			if seed, ok := parseChainSeed(text); ok {
+				if len(pending) > 0 {
+					return records, fmt.Errorf("failed to verify hash chain, lines %d to %d are not chained, caused by %w", start, line-1, ErrChainBroken)
+				}
+				chain.prev = seed
+			} else if body, sum, ok := parseChainRecord(text); ok {
+				if len(pending) > 0 {
+					pending = append(pending, body...)
+					body = pending
+				}
+				if !hmac.Equal(chain.next(body), sum[:]) {
+					return records, fmt.Errorf("failed to verify hash chain, the record at line %d does not match, caused by %w", line, ErrChainBroken)
+				}
+				records++
+				pending = pending[:0]
+			} else {
+				// A line of a record of several lines
+				pending = append(pending, text...)
+				pending = append(pending, '\n')
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return records, fmt.Errorf("failed to verify hash chain, caused by %w", readErr)
+		}
+	}
+	if len(pending) > 0 {
+		return records, fmt.Errorf("failed to verify hash chain, lines %d to %d are not chained, caused by %w", start, line, ErrChainBroken)
+	}
+	return records, nil
+}
@@ -0,0 +1,103 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestKeeperWithHashChain(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-hash-chain"),
+		WithHashChain(key),
+		WithMaxSize(200),
+		WithFileHeader(func(w io.Writer) error {
+			_, err := io.WriteString(w, "# header\n")
+			return err
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for i := range 10 {
+		if _, err := fmt.Fprintf(k, "message %d\nsecond line\n", i); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The chain carries on into a new Keeper
+	k, err = New(WithFolder(folder), WithName("test-hash-chain"), WithHashChain(key))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("after restart\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	archives := k.Archives()
+	if len(archives) < 3 {
+		t.Fatalf("expected several archives got %d", len(archives))
+	}
+	records := 0
+	var content []byte
+	for _, archive := range archives {
+		archived, err := os.ReadFile(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		n, err := VerifyChain(bytes.NewReader(archived), key)
+		if err != nil {
+			t.Errorf("expected archive %s to verify got %v", archive.Path, err)
+		}
+		records += n
+		if bytes.Contains(archived, []byte("message 0")) {
+			content = archived
+		}
+	}
+	// The messages and a header per log file written with it
+	if records < 11 {
+		t.Errorf("expected at least 11 records got %d", records)
+	}
+	if _, err := VerifyChain(bytes.NewReader(content), []byte("another key")); !errors.Is(err, ErrChainBroken) {
+		t.Errorf("expected the chain to be broken with another key got %v", err)
+	}
+	tampered := bytes.Replace(content, []byte("message 0"), []byte("message 9"), 1)
+	if _, err := VerifyChain(bytes.NewReader(tampered), key); !errors.Is(err, ErrChainBroken) {
+		t.Errorf("expected the chain to be broken by an altered record got %v", err)
+	}
+	// Remove the header record following the seed
+	lines := strings.SplitAfter(string(content), "\n")
+	removed := strings.Join(append(lines[:1:1], lines[2:]...), "")
+	if _, err := VerifyChain(strings.NewReader(removed), key); !errors.Is(err, ErrChainBroken) {
+		t.Errorf("expected the chain to be broken by a removed record got %v", err)
+	}
+}
+
+func TestWithHashChainConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Opt
+	}{
+		{"length-prefixed", WithLengthPrefixedRecords()},
+		{"validate-json", WithValidateJSON()},
+		{"wrap-json", WithWrapInvalidJSON()},
+	}
+	for _, tt := range tests {
+		if err := ValidateOptions(WithHashChain(nil), tt.opt); err == nil {
+			t.Errorf("expected an error combining the hash chain with %s", tt.name)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Returned, wrapped, by [Keeper.VerifyArchives] for an archive that does not match its checksum.
+var ErrChecksumMismatch = errors.New("archive checksum mismatch")
+
+// A ChecksumAlgorithm is a digest of the archives, see [WithChecksum].
+// It is also the extension of the sidecar files holding the checksums.
+type ChecksumAlgorithm string
+
+const (
+	ChecksumSHA256 ChecksumAlgorithm = "sha256"
+	ChecksumSHA512 ChecksumAlgorithm = "sha512"
+)
+
+// The known algorithms, whose sidecar files are never taken for archives.
+var checksumAlgorithms = []ChecksumAlgorithm{ChecksumSHA256, ChecksumSHA512}
+
+func (a ChecksumAlgorithm) new() (hash.Hash, bool) {
+	switch a {
+	case ChecksumSHA256:
+		return sha256.New(), true
+	case ChecksumSHA512:
+		return sha512.New(), true
+	default:
+		return nil, false
+	}
+}
+
+// Compute a digest of every archive once rotated, compressed and encrypted, and write it into a sidecar file
+// named after the archive with the algorithm as extension, such as "app.log.gz.sha256",
+// in the format of sha256sum, so that `sha256sum -c` checks it as well.
+// The archives are then checked against their sidecar files by [Keeper.VerifyArchives], which detects the corrupted
+// and tampered archives. The sidecar files are removed along with their archive, and are read-only with [WithReadOnlyArchives].
+// The failures go to the handler of [WithErrorHandler]. Is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithGzip(), lorekeeper.WithChecksum(lorekeeper.ChecksumSHA256))
+func WithChecksum(algo ChecksumAlgorithm) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if _, ok := algo.new(); !ok {
+			return nil, fmt.Errorf("failed to set checksum, unknown algorithm %q", algo)
+		}
+		k.checksum = algo
+		return k, nil
+	}
+}
+
+// Do not compute the checksums of the archives, this is the default.
+func NoChecksum() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.checksum = ""
+		return k, nil
+	}
+}
+
+// Check every archive of the Keeper against the checksum of its sidecar file, see [WithChecksum],
+// returning the number of archives that match.
+// The error joins the errors of every other archive, wrapping [ErrChecksumMismatch] for the archives that were altered,
+// or [fs.ErrNotExist] for the archives without a sidecar file, such as the ones rotated before the checksums were enabled.
+//
+// Example usage:
+//
+//	if _, err := keeper.VerifyArchives(); errors.Is(err, lorekeeper.ErrChecksumMismatch) {
+//		// Alert on the tampering
+//	}
+func (k *Keeper) VerifyArchives() (int, error) {
+	k.mu.Lock()
+	paths := make([]string, 0, k.archives.Length())
+	for _, archive := range k.archives.All() {
+		paths = append(paths, archive.filePath)
+	}
+	preferred, fsys := k.checksum, k.fsys
+	k.mu.Unlock()
+
+	verified := 0
+	var errs []error
+	for _, path := range paths {
+		if err := verifyChecksum(fsys, path, preferred); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		verified++
+	}
+	return verified, errors.Join(errs...)
+}
+
+// Check the archive against its sidecar file, preferring the given algorithm if it has several.
+func verifyChecksum(fsys FS, path string, preferred ChecksumAlgorithm) error {
+	algos := checksumAlgorithms
+	if len(preferred) > 0 {
+		algos = append([]ChecksumAlgorithm{preferred}, algos...)
+	}
+	for _, algo := range algos {
+		content, err := readFile(fsys, path+"."+string(algo))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read checksum of %q, caused by %w", path, err)
+		}
+		expected, _, _ := bytes.Cut(content, []byte(" "))
+		digest, err := checksum(fsys, path, algo)
+		if err != nil {
+			return fmt.Errorf("failed to verify checksum of %q, caused by %w", path, err)
+		}
+		if digest != string(bytes.TrimSpace(expected)) {
+			return fmt.Errorf("failed to verify archive %q, caused by %w", path, ErrChecksumMismatch)
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to verify archive %q, it has no checksum, caused by %w", path, fs.ErrNotExist)
+}
+
+// Write the checksum of the archive into its sidecar file, see [WithChecksum].
+func (k *Keeper) writeChecksum(path string) error {
+	if len(k.checksum) == 0 {
+		return nil
+	}
+	digest, err := checksum(k.fsys, path, k.checksum)
+	if err != nil {
+		return fmt.Errorf("failed to compute checksum of %q, caused by %w", path, err)
+	}
+	mode := k.fileMode
+	if k.readOnlyArchives {
+		mode &^= 0222
+	}
+	sidecar := path + "." + string(k.checksum)
+	// A read-only sidecar left by an earlier attempt can not be opened for writing
+	_ = k.fsys.Remove(sidecar)
+	if err := writeFile(k.fsys, sidecar, []byte(digest+"  "+filepath.Base(path)+"\n"), mode); err != nil {
+		return fmt.Errorf("failed to write checksum of %q, caused by %w", path, err)
+	}
+	return nil
+}
+
+// Get the hex of the digest of the file with the given algorithm.
+func checksum(fsys FS, path string, algo ChecksumAlgorithm) (string, error) {
+	h, ok := algo.new()
+	if !ok {
+		return "", fmt.Errorf("unknown checksum algorithm %q", algo)
+	}
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Tell whether the path is the sidecar file of a checksum rather than an archive.
+func isChecksumSidecar(path string) bool {
+	for _, algo := range checksumAlgorithms {
+		if strings.HasSuffix(path, "."+string(algo)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove the sidecar files of the checksums of a removed archive.
+// An orphaned sidecar file is not worth failing the removal of its archive, so the errors are ignored.
+func removeChecksums(fsys FS, path string) {
+	for _, algo := range checksumAlgorithms {
+		removeSidecar(fsys, path+"."+string(algo))
+	}
+}
+
+func removeSidecar(fsys FS, sidecar string) {
+	// Read-only files can not be removed on Windows, see WithReadOnlyArchives
+	if err := fsys.Remove(sidecar); errors.Is(err, fs.ErrPermission) && fsys.Chmod(sidecar, 0600) == nil {
+		_ = fsys.Remove(sidecar)
+	}
+}
+
+// Move the sidecar files of the checksums of an archive moved from src to dst, naming dst in their content.
+func moveChecksums(fsys FS, src, dst string) error {
+	for _, algo := range checksumAlgorithms {
+		sidecar := src + "." + string(algo)
+		content, err := readFile(fsys, sidecar)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to move checksum %q, caused by %w", sidecar, err)
+		}
+		stat, err := fsys.Stat(sidecar)
+		if err != nil {
+			return fmt.Errorf("failed to move checksum %q, caused by %w", sidecar, err)
+		}
+		digest, _, _ := bytes.Cut(content, []byte(" "))
+		moved := []byte(string(digest) + "  " + filepath.Base(dst) + "\n")
+		if err := writeFile(fsys, dst+"."+string(algo), moved, stat.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to move checksum %q, caused by %w", sidecar, err)
+		}
+		removeSidecar(fsys, sidecar)
+	}
+	return nil
+}
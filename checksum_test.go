@@ -0,0 +1,59 @@
+package lorekeeper
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestKeeperWithChecksum(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-checksum"),
+		WithGzip(),
+		WithChecksum(ChecksumSHA256),
+		WithMaxFiles(2),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for range 3 {
+		if _, err := k.Write([]byte("hello\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if n, err := k.VerifyArchives(); n != 2 || err != nil {
+		t.Errorf("expected 2 verified archives got %d, %v", n, err)
+	}
+	// The sidecar files are neither archives nor left behind by the retention
+	if len(k.Archives()) != 2 {
+		t.Errorf("expected 2 archives got %d", len(k.Archives()))
+	}
+	sidecars, err := fs.Glob(os.DirFS(k.Folder()), "*.sha256")
+	if err != nil || len(sidecars) != 2 {
+		t.Errorf("expected 2 sidecar files got %v, %v", sidecars, err)
+	}
+
+	archives := k.Archives()
+	if err := os.WriteFile(archives[0].Path, []byte("tampered"), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := os.Remove(archives[1].Path + ".sha256"); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	n, err := k.VerifyArchives()
+	if n != 0 || !errors.Is(err, ErrChecksumMismatch) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a mismatch and a missing checksum got %d, %v", n, err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if _, err := New(WithChecksum("md5")); err == nil {
+		t.Errorf("expected an error for an unknown algorithm")
+	}
+}
@@ -0,0 +1,126 @@
+package lorekeeper
+
+import (
+	"time"
+)
+
+// A Clock tells the time to a Keeper and schedules its timers, see [WithClock].
+// It must be safe for concurrent use.
+type Clock interface {
+	// Get the current time.
+	Now() time.Time
+	// Call f in its own goroutine once d elapsed on the clock, like [time.AfterFunc].
+	// Tickers are built on top of it, by scheduling the next call from f.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// A Timer is a call scheduled by [Clock.AfterFunc], which [time.Timer] implements.
+type Timer interface {
+	// Prevent the call if it did not happen yet, it returns false if the call already happened or was stopped.
+	Stop() bool
+	// Schedule the call again once d elapsed, it returns false if the call already happened or was stopped.
+	Reset(d time.Duration) bool
+}
+
+// The wall clock, which is the default clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// A nowFuncClock tells the time with the function of [WithNowFunc], and schedules the timers on the clock it embeds.
+type nowFuncClock struct {
+	now func() time.Time
+	Clock
+}
+
+func (c nowFuncClock) Now() time.Time {
+	return c.now()
+}
+
+// Set the clock of the Keeper, which tells the time like [WithNowFunc]
+// and schedules the rotations of [WithCron], the flushes of [WithFlushInterval] and the closing of [WithCloseAfterIdle],
+// while the rotations of [WithRotateEvery] and [WithDailyRotation] only need to tell the time.
+// This lets the applications test their rotation setups deterministically, without sleeping,
+// with a fake clock that calls the timers that are due when it is moved forward, such as the one of the lorekeepertest package.
+// A nil clock falls back to the wall clock, which is the default. See [WithManualStepping] to step the Keeper by hand instead.
+// This replaces the function of a previous [WithNowFunc].
+//
+// Example usage:
+//
+//	clock := lorekeepertest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	keeper, err := lorekeeper.New(lorekeeper.WithClock(clock), lorekeeper.WithCron("@hourly"))
+//	if err != nil {
+//		return err
+//	}
+//	clock.Advance(time.Hour) // Rotates
+func WithClock(c Clock) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if c == nil {
+			c = systemClock{}
+		}
+		k.clock = c
+		k.moveTimers()
+		return k, nil
+	}
+}
+
+// Move the pending timers onto the clock of the Keeper, the rotations of [WithCron] are scheduled again by configureCron.
+func (k *Keeper) moveTimers() {
+	k.stopCronTimer()
+	if k.idleTimer != nil {
+		k.stopIdleTimer()
+		k.resetIdleTimer()
+	}
+	if k.flushTimer != nil {
+		k.stopFlushTimer()
+		k.flushTimer = k.clock.AfterFunc(k.flushInterval, k.supervised("flush", k.flushOnTimer))
+	}
+}
+
+// Schedule the next rotation of [WithCron] on the clock of the Keeper if none is, the lock of the Keeper must be held.
+func (k *Keeper) configureCron() {
+	if k.cronSchedule == nil || k.cronTimer != nil || k.manual || k.closed {
+		return
+	}
+	now := k.now()
+	generation := k.cronGeneration
+	k.cronTimer = k.clock.AfterFunc(k.cronSchedule.Next(now).Sub(now), k.supervised("cron", func() {
+		k.rotateOnCron(generation)
+	}))
+}
+
+// Rotate for [WithCron] then schedule the next rotation, unless the schedule changed in the meantime.
+func (k *Keeper) rotateOnCron(generation uint64) {
+	k.mu.Lock()
+	stale := generation != k.cronGeneration
+	k.mu.Unlock()
+	if stale {
+		return
+	}
+	_, err := k.rotateBy(RotationCron)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err != nil {
+		k.handleError(err)
+	}
+	if generation == k.cronGeneration {
+		k.cronTimer = nil
+		k.configureCron()
+	}
+}
+
+// Stop the rotations of [WithCron] until configureCron schedules them again.
+func (k *Keeper) stopCronTimer() {
+	k.cronGeneration++
+	if k.cronTimer != nil {
+		k.cronTimer.Stop()
+		k.cronTimer = nil
+	}
+}
@@ -0,0 +1,111 @@
+package lorekeeper
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// A clock whose timers are only called when fired by the test.
+type stubClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	delays []time.Duration
+	calls  []func()
+}
+
+func (c *stubClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *stubClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delays = append(c.delays, d)
+	c.calls = append(c.calls, f)
+	return time.NewTimer(time.Hour)
+}
+
+// Move the clock to at and call the last scheduled timer.
+func (c *stubClock) fire(at time.Time) {
+	c.mu.Lock()
+	c.now = at
+	f := c.calls[len(c.calls)-1]
+	c.mu.Unlock()
+	f()
+}
+
+func TestWithClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
+	clock := &stubClock{now: start}
+	k, err := New(WithFolder(t.TempDir()), WithName("test-with-clock"), WithClock(clock), WithCron("@hourly"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if len(clock.delays) != 1 || clock.delays[0] != 30*time.Minute {
+		t.Fatalf("expected the rotation to be scheduled in 30m on the clock got %v", clock.delays)
+	}
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	clock.fire(start.Add(30 * time.Minute))
+	if got := k.LastRotationReason(); got != RotationCron {
+		t.Errorf("expected a cron rotation got %q", got)
+	}
+	if got := k.LastRotation(); !got.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("expected the rotation at the time of the clock got %v", got)
+	}
+	// The next rotation is scheduled on the clock as well
+	if len(clock.delays) != 2 || clock.delays[1] != time.Hour {
+		t.Errorf("expected the next rotation to be scheduled in 1h got %v", clock.delays)
+	}
+}
+
+func TestWithClockNoCron(t *testing.T) {
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)}
+	k, err := New(WithFolder(t.TempDir()), WithName("test-with-clock-no-cron"), WithClock(clock), WithCron("@hourly"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if err := k.Reconfigure(NoCron()); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// A timer of the previous schedule does nothing
+	clock.fire(clock.Now().Add(30 * time.Minute))
+	if got := k.LastRotationReason(); got != "" {
+		t.Errorf("expected no rotation got %q", got)
+	}
+}
+
+func TestWithNowFuncAndClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
+	told := start.Add(time.Hour)
+	nowFunc := func() time.Time { return told }
+
+	// The function tells the time, the timers stay on the clock set before
+	clock := &stubClock{now: start}
+	k, err := configureDetached(WithClock(clock), WithNowFunc(nowFunc))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !k.now().Equal(told) {
+		t.Errorf("expected the time of the function %v got %v", told, k.now())
+	}
+	if k.clock.AfterFunc(time.Minute, func() {}); len(clock.delays) != 1 {
+		t.Errorf("expected the timers to be scheduled on the clock got %v", clock.delays)
+	}
+
+	// The clock replaces the function
+	k, err = configureDetached(WithNowFunc(nowFunc), WithClock(clock))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !k.now().Equal(start) {
+		t.Errorf("expected the time of the clock %v got %v", start, k.now())
+	}
+}
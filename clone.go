@@ -0,0 +1,173 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Create a new [Keeper] with the same configuration as this Keeper, with the given options applied on top.
+// The new Keeper must have a different name, since Keepers are identified by their name,
+// so opts must contain [WithName], and usually [WithFolder] as well.
+// This is useful to derive per-component Keepers from a base template.
+//
+// Example usage:
+//
+//	base, _ := lorekeeper.New(lorekeeper.WithName("app"), lorekeeper.WithMaxSize(10*lorekeeper.Mb))
+//	db, err := base.Clone(lorekeeper.WithName("app-db"))
+func (k *Keeper) Clone(opts ...Opt) (*Keeper, error) {
+	finalOpts := append(k.options(), opts...)
+
+	clone, err := configureDetached(finalOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone keeper, caused by %w", err)
+	}
+	if clone.name == k.Name() {
+		return nil, fmt.Errorf("failed to clone keeper, the clone must have a different name than %q", clone.name)
+	}
+	return New(finalOpts...)
+}
+
+// Get the options that reproduce the configuration of the Keeper.
+func (k *Keeper) options() []Opt {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.optionsLocked()
+}
+
+// Get the options that reproduce the configuration of the Keeper, the lock of the Keeper must be held.
+func (k *Keeper) optionsLocked() []Opt {
+	opts := []Opt{
+		WithFolders(k.getFolders()...),
+		WithName(k.name),
+		WithExtension(k.extension),
+		WithTimeLayout(k.timeLayout),
+		WithMaxSize(k.maxSize),
+		WithMaxLines(k.maxLines),
+		WithArchiveNameLayout(k.archiveNameLayoutText),
+		WithMaxFiles(k.maxFiles),
+		WithCompressor(k.compressor),
+		WithTotalSize(k.totalSize),
+		WithMaxAge(k.maxAge),
+		WithCompaction(k.compactionAge, k.compactionSize),
+		WithRetentionPolicy(k.retentionPolicy),
+		WithClock(k.clock),
+		WithRegistry(k.registry),
+		WithMaxAgeAtStartup(k.maxAgeAtStartup),
+		WithCloseAfterIdle(k.closeAfterIdle),
+		WithFallbackWriter(k.fallbackWriter),
+		WithErrorHandler(k.errorHandler),
+		WithCircuitBreaker(k.breakerThreshold, k.breakerProbeInterval),
+		WithPausePolicy(k.pausePolicy, k.pauseMaxBuffer),
+		WithRecordPattern(k.recordPattern),
+		WithTimestampParser(k.timestampParser),
+		withJSONPolicy(k.jsonPolicy),
+		WithDoubleBuffering(k.segmentSize),
+		WithBackgroundWorkers(k.backgroundWorkers),
+		WithSampling(k.sampleRate, k.sampleMatcher),
+		WithTemplateData(k.templateData),
+		WithDeletionHistory(k.deletionHistory),
+		WithDeletionLog(k.deletionLog),
+		WithRecent(len(k.recent)),
+		WithOnRotate(k.onRotate),
+		WithOnArchiveRemoved(k.onArchiveRemoved),
+		WithBufferSize(k.bufferSize),
+		WithFlushInterval(k.flushInterval),
+		withRotationPeriod(k.rotationPeriod),
+		WithMaxPooledBufferSize(k.maxPooledSize()),
+		WithUploader(k.uploader, k.deleteAfterUpload),
+		WithUploadRetry(k.uploadAttempts, k.uploadBackoff),
+		WithReopenSignal(k.reopenSignals...),
+		WithCreateFolder(k.createFolderMode),
+		WithFileMode(k.fileMode),
+		withModTimePolicy(k.modTimePolicy),
+		WithAsyncWrites(k.asyncSize, k.asyncPolicy),
+		WithRateLimit(k.rateLimit, k.rateBurst),
+		WithRateLimitPolicy(k.ratePolicy),
+		WithRotationCoalescing(k.coalescingWindow),
+		WithMinDiskFree(k.minDiskFree),
+		WithMinDiskFreePercent(k.minDiskFreePercent),
+		WithQuota(k.quota),
+		WithHardMaxSize(k.hardMaxSize, k.hardMaxPolicy),
+		WithStartupCompaction(k.startupCompaction),
+		WithCurrentFileCheck(k.currentFileCheck),
+		WithOldDir(k.oldDir),
+		WithDropSummary(k.dropSummary),
+		WithFS(k.fsys),
+		WithCurrentNameLayout(k.currentNameLayoutText),
+		WithCurrentSymlink(k.currentSymlink),
+		WithFileHeader(k.fileHeader),
+		WithTags(k.tags...),
+		WithAdoptPattern(k.adoptPatterns...),
+		WithArchiveTimeSource(k.archiveTimeSource),
+		WithLatencyWindow(k.latency.getWindow()),
+		WithReaderCache(k.readerCache.size()),
+	}
+	// Overrides the folders above, the root is the parent of the tenant folder
+	if len(k.tenant) > 0 {
+		opts = append(opts, WithTenant(filepath.Dir(k.folder), k.tenant))
+	}
+	if k.lengthPrefixed {
+		opts = append(opts, WithLengthPrefixedRecords())
+	}
+	if k.forwarder != nil {
+		opts = append(opts, WithForwarder(k.forwarder.w, k.forwarder.size, k.forwarder.policy))
+	}
+	if k.compressedCurrent {
+		opts = append(opts, WithCompressedCurrent(k.compressedFlushSize))
+	}
+	if k.deferredRemoval {
+		opts = append(opts, WithDeferredRemoval())
+	}
+	if k.lockFile {
+		opts = append(opts, WithLockFile())
+	}
+	if k.strictNames {
+		opts = append(opts, WithStrictNames())
+	}
+	if len(k.uniqueSuffix) > 0 {
+		opts = append(opts, WithUniqueSuffix())
+	}
+	if k.encryption != nil {
+		opts = append(opts, WithEncryption(k.encryption.key))
+	}
+	if k.chain != nil {
+		opts = append(opts, WithHashChain(k.chain.key))
+	}
+	if k.syncWrites {
+		opts = append(opts, WithSyncWrites())
+	}
+	if k.audit {
+		opts = append(opts, withAuditProfile(true))
+	}
+	if len(k.checksum) > 0 {
+		opts = append(opts, WithChecksum(k.checksum))
+	}
+	if k.manifest {
+		opts = append(opts, WithManifest())
+	}
+	if k.manifestDiscovery {
+		opts = append(opts, WithManifestDiscovery(k.manifestVerify))
+	}
+	if k.skipEmptyRotation {
+		opts = append(opts, WithSkipEmptyRotation())
+	}
+	if k.readOnlyArchives {
+		opts = append(opts, WithReadOnlyArchives())
+	}
+	if k.rotateOnStart {
+		opts = append(opts, WithRotateOnStart())
+	}
+	if k.pendingUploads {
+		opts = append(opts, WithPendingUploads())
+	}
+	if k.manual {
+		opts = append(opts, WithManualStepping())
+	}
+	if len(k.dateFormat) > 0 {
+		opts = append(opts, WithDateext(k.dateFormat))
+	}
+	if len(k.cronSpec) > 0 {
+		opts = append(opts, WithCron(k.cronSpec))
+	}
+	return opts
+}
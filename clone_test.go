@@ -0,0 +1,224 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestKeeperClone(t *testing.T) {
+	folder := t.TempDir()
+	base, err := New(
+		WithName("Test-Clone-Base"),
+		WithFolder(folder),
+		WithMaxSize(Mb),
+		WithMaxFiles(3),
+		WithCron("@daily"),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer base.Close()
+
+	clone, err := base.Clone(WithName("Test-Clone-Derived"), WithMaxFiles(5))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer clone.Close()
+
+	if clone == base {
+		t.Fatalf("expected a different keeper")
+	}
+	if clone.Name() != "test-clone-derived" || clone.Folder() != folder {
+		t.Errorf("expected name and folder to be set got %s", clone)
+	}
+	if clone.MaxSize() != Mb || clone.Schedule() != "@daily" || clone.CompressionExtension() != ".gz" {
+		t.Errorf("expected configuration to be copied got %s", clone)
+	}
+	if clone.MaxFiles() != 5 || base.MaxFiles() != 3 {
+		t.Errorf("expected override to only apply to the clone got %d and %d", clone.MaxFiles(), base.MaxFiles())
+	}
+
+	if _, err := base.Clone(WithMaxFiles(1)); err == nil {
+		t.Errorf("expected error when the name is not changed")
+	}
+	if base.MaxFiles() != 3 {
+		t.Errorf("expected failed clone to not modify the base got %d", base.MaxFiles())
+	}
+}
+
+func TestKeeperCloneOptions(t *testing.T) {
+	folder := t.TempDir()
+	noop := func(...any) {}
+	for _, tc := range []struct {
+		name string
+		opts []Opt
+	}{
+		{
+			name: "general",
+			opts: []Opt{
+				WithFolders(filepath.Join(folder, "a"), filepath.Join(folder, "b")),
+				WithName("clone-general"),
+				WithExtension(".txt"),
+				WithTimeLayout("20060102150405"),
+				WithArchiveNameLayout("{{ .year }}/{{ .name }}-{{ .time }}{{ .extension }}"),
+				WithMaxSize(Mb),
+				WithMaxLines(100),
+				WithMaxFiles(3),
+				WithGzipLevel(9),
+				WithTotalSize(10 * Mb),
+				WithMaxAge(time.Hour),
+				WithRetentionPolicy(MaxFilesPolicy(2)),
+				WithClock(&stubClock{now: time.Unix(0, 0)}),
+				WithRegistry(NewRegistry()),
+				WithMaxAgeAtStartup(time.Hour),
+				WithCloseAfterIdle(time.Minute),
+				WithFallbackWriter(io.Discard),
+				WithErrorHandler(func(err error) { noop(err) }),
+				WithCircuitBreaker(3, time.Second),
+				WithPausePolicy(PauseDrop, Kb),
+				WithRecordPattern(regexp.MustCompile(`^\d`)),
+				WithTimestampParser(func(record []byte) (time.Time, error) { return time.Time{}, nil }),
+				WithValidateJSON(),
+				WithDoubleBuffering(Kb),
+				WithBackgroundWorkers(2),
+				WithSampling(0.5, func(msg []byte) bool { return true }),
+				WithTemplateData(map[string]string{"env": "test"}),
+				WithDeletionHistory(5),
+				WithDeletionLog(io.Discard),
+				WithRecent(5),
+				WithOnRotate(func(archivePath string) { noop(archivePath) }),
+				WithOnArchiveRemoved(func(path string) { noop(path) }),
+				WithBufferSize(Kb),
+				WithFlushInterval(time.Second),
+				WithRotateEvery(time.Hour),
+				WithMaxPooledBufferSize(Kb),
+				WithUploader(UploaderFunc(func(ctx context.Context, localPath string) error { return nil }), false),
+				WithUploadRetry(5, time.Second),
+				WithReopenSignal(os.Interrupt),
+				WithCreateFolder(0700),
+				WithFileMode(0600),
+				WithTrustModTime(true),
+				WithRateLimit(Kb, Kb),
+				WithRateLimitPolicy(DropNewest),
+				WithRotationCoalescing(time.Second),
+				WithMinDiskFree(Mb),
+				WithMinDiskFreePercent(5),
+				WithQuota(Mb),
+				WithHardMaxSize(2*Mb, HardMaxReject),
+				WithCurrentFileCheck(10),
+				WithDropSummary(time.Minute),
+				WithFS(OSFS()),
+				WithCurrentSymlink("clone-general-current"),
+				WithFileHeader(func(w io.Writer) error { return nil }),
+				WithTags("a", "b"),
+				WithAdoptPattern("legacy-*.log"),
+				WithArchiveTimeSource(ArchiveTimeLastWrite),
+				WithLatencyWindow(time.Minute),
+				WithReaderCache(2),
+				WithForwarder(io.Discard, 16, DropOldest),
+				WithDeferredRemoval(),
+				WithLockFile(),
+				WithStrictNames(),
+				WithSyncWrites(),
+				WithChecksum(ChecksumSHA512),
+				WithManifest(),
+				WithManifestDiscovery(true),
+				WithSkipEmptyRotation(),
+				WithReadOnlyArchives(),
+				WithRotateOnStart(),
+				WithPendingUploads(),
+			},
+		},
+		{
+			name: "tenant",
+			opts: []Opt{
+				WithTenant(folder, "acme/eu"),
+				WithName("clone-tenant"),
+				WithOldDir("old"),
+				WithDateext("-%Y%m%d"),
+				WithCron("@daily"),
+				WithCurrentNameLayout("{{ .name }}-current"),
+				WithNowFunc(time.Now),
+				WithLengthPrefixedRecords(),
+				WithWrapInvalidJSON(),
+				WithCompaction(time.Hour, Mb),
+				WithStartupCompaction(Mb),
+				WithCompressedCurrent(Kb),
+				WithManualStepping(),
+				WithGzip(),
+			},
+		},
+		{
+			name: "audit",
+			opts: []Opt{
+				withAuditProfile(true),
+				WithSyncWrites(),
+				WithReadOnlyArchives(),
+				WithFolder(folder),
+				WithName("clone-audit"),
+				WithHashChain(bytes.Repeat([]byte("k"), minAuditKeySize)),
+				WithEncryption(bytes.Repeat([]byte("k"), 32)),
+				WithUniqueSuffix(),
+				WithAsyncWrites(16, DropNone),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := configureDetached(tc.opts...)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			clone, err := configureDetached(k.optionsLocked()...)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if !slices.Equal(clone.getFolders(), k.getFolders()) {
+				t.Errorf("expected folders %q got %q", k.getFolders(), clone.getFolders())
+			}
+			original, cloned := reflect.ValueOf(k).Elem(), reflect.ValueOf(clone).Elem()
+			for i := range original.NumField() {
+				field := original.Type().Field(i)
+				// Bumped on every configuration of the schedule, and nil for a single folder
+				if field.Name == "cronGeneration" || field.Name == "folders" {
+					continue
+				}
+				a, b := original.Field(i), cloned.Field(i)
+				switch field.Type.Kind() {
+				case reflect.Func, reflect.Pointer, reflect.Chan:
+					// Recreated by the options, only whether they are set can be compared
+					if a.IsNil() != b.IsNil() {
+						t.Errorf("expected %s to be cloned got %v and %v", field.Name, a.IsNil(), b.IsNil())
+					}
+				case reflect.Interface:
+					// Compared by their dynamic type, a clone may wrap the same value again
+					if got, want := dynamicType(b), dynamicType(a); got != want {
+						t.Errorf("expected %s to be cloned as %s got %s", field.Name, want, got)
+					}
+				case reflect.Struct:
+					// The state of the Keeper, such as its locks and counters
+				default:
+					if got, want := fmt.Sprintf("%#v", b), fmt.Sprintf("%#v", a); got != want {
+						t.Errorf("expected %s to be cloned as %s got %s", field.Name, want, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func dynamicType(v reflect.Value) string {
+	if v.IsNil() {
+		return "nil"
+	}
+	return v.Elem().Type().String()
+}
@@ -0,0 +1,118 @@
+// Command lorekeeper provides tools to plan and inspect folders managed by Lorekeeper.
+//
+// Usage:
+//
+//	lorekeeper simulate -name <name> [flags]
+//
+// The simulate subcommand projects the growth of a log folder and reports when and which files would be deleted,
+// run "lorekeeper simulate -h" for the available flags.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/trviph/lorekeeper"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "simulate":
+		err = simulate(os.Args[2:], os.Stdout)
+	case "-h", "-help", "--help", "help":
+		usage(os.Stdout)
+		return
+	default:
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: lorekeeper <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  simulate  project the growth of a log folder and report deletions")
+}
+
+func simulate(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	var (
+		folder     = flags.String("folder", os.TempDir(), "folder of the log files")
+		name       = flags.String("name", "", "name of the Keeper, required")
+		extension  = flags.String("extension", ".log", "extension of the log files")
+		timeLayout = flags.String("time-layout", "", "time layout of archive names")
+		layout     = flags.String("layout", "", "archive name layout")
+		maxSize    = flags.Int("max-size", 15*lorekeeper.Mb, "maximum size in bytes per log file")
+		maxFiles   = flags.Int("max-files", 0, "maximum number of archives to keep")
+		totalSize  = flags.Int("total-size", 0, "maximum total size in bytes of all archives")
+		maxAge     = flags.Duration("max-age", 0, "maximum age of the archives to keep")
+		cronSpec   = flags.String("cron", "", "cron schedule for rotation")
+		gzip       = flags.Bool("gzip", false, "compress archives with gzip")
+		duration   = flags.Duration("duration", 30*24*time.Hour, "how long into the future to simulate")
+		rate       = flags.Int("rate", lorekeeper.GB, "projected bytes written per day")
+		ratio      = flags.Float64("ratio", 0, "archive size relative to the rotated log size")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	// Without a name, the Keeper would be named after this command instead of the simulated one
+	if len(*name) == 0 {
+		return fmt.Errorf("failed to simulate, the -name flag is required")
+	}
+
+	opts := []lorekeeper.Opt{
+		lorekeeper.WithFolder(*folder),
+		lorekeeper.WithName(*name),
+		lorekeeper.WithExtension(*extension),
+		lorekeeper.WithArchiveNameLayout(*layout),
+		lorekeeper.WithMaxSize(*maxSize),
+		lorekeeper.WithMaxFiles(*maxFiles),
+		lorekeeper.WithTotalSize(*totalSize),
+		lorekeeper.WithMaxAge(*maxAge),
+	}
+	if len(*timeLayout) > 0 {
+		opts = append(opts, lorekeeper.WithTimeLayout(*timeLayout))
+	}
+	if len(*cronSpec) > 0 {
+		opts = append(opts, lorekeeper.WithCron(*cronSpec))
+	}
+	if *gzip {
+		opts = append(opts, lorekeeper.WithGzip())
+	}
+
+	report, err := lorekeeper.Simulate(
+		lorekeeper.Simulation{Duration: *duration, BytesPerDay: *rate, CompressionRatio: *ratio},
+		opts...,
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "rotations: %d\n", report.Rotations)
+	fmt.Fprintf(w, "archives at the end: %d (%d bytes)\n", report.Archives, report.ArchivesSize)
+	fmt.Fprintf(w, "deletions: %d\n", len(report.Deletions))
+	if len(report.Deletions) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSIZE\tEXISTING\tPATH")
+	for _, deletion := range report.Deletions {
+		fmt.Fprintf(tw, "%s\t%d\t%t\t%s\n", deletion.Time.Format(time.RFC3339), deletion.Size, deletion.Existing, deletion.Path)
+	}
+	return tw.Flush()
+}
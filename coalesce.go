@@ -0,0 +1,52 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"time"
+)
+
+// Coalesce the scheduled rotations of [WithCron], [WithRotateEvery] and [WithDailyRotation]
+// with a rotation that happened less than window before, such as a size rotation a second before midnight,
+// instead of rotating twice back-to-back and producing a near-empty archive.
+// The current log file then keeps its messages until the next rotation.
+// The size rotations of [WithMaxSize] are never coalesced, since the current log file would exceed its max size,
+// and neither are the rotations asked for explicitly, such as with [Keeper.Rotate].
+// The coalesced rotations are counted in [Stats.CoalescedRotations].
+// Set window <= 0 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithMaxSize(100*lorekeeper.Mb),
+//		lorekeeper.WithCron("@daily"),
+//		lorekeeper.WithRotationCoalescing(time.Minute),
+//	)
+func WithRotationCoalescing(window time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if window > 24*time.Hour {
+			return nil, fmt.Errorf("failed to set rotation coalescing, window must be at most a day got %s", window)
+		}
+		k.coalescingWindow = max(window, 0)
+		return k, nil
+	}
+}
+
+// Tell whether a rotation for the given reason is coalesced with the last rotation, see [WithRotationCoalescing].
+func (k *Keeper) coalesced(reason RotationReason) bool {
+	if k.coalescingWindow <= 0 || k.lastRotation.IsZero() {
+		return false
+	}
+	if reason != RotationCron && reason != RotationInterval {
+		return false
+	}
+	now := k.now()
+	if now.Sub(k.lastRotation) >= k.coalescingWindow {
+		return false
+	}
+	// The boundary that triggered this rotation is consumed
+	if k.rotationPeriod.enabled() {
+		k.rotateAt = k.rotationPeriod.next(now)
+	}
+	k.stats.CoalescedRotations++
+	return true
+}
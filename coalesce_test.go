@@ -0,0 +1,99 @@
+package lorekeeper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithRotationCoalescing(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 59, 58, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-rotation-coalescing"),
+		WithNowFunc(func() time.Time { return now }),
+		WithMaxSize(8),
+		WithRotateEvery(time.Hour),
+		WithRotationCoalescing(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// A size rotation right before the boundary
+	if _, err := k.Write([]byte("1234567\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("a\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := len(k.Archives()); got != 1 {
+		t.Fatalf("expected 1 archive got %d", got)
+	}
+
+	for _, tc := range []struct {
+		at        time.Time
+		archives  int
+		coalesced uint64
+	}{
+		// The boundary is coalesced with the size rotation
+		{at: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), archives: 1, coalesced: 1},
+		{at: time.Date(2024, 3, 1, 11, 0, 30, 0, time.UTC), archives: 1, coalesced: 1},
+		{at: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), archives: 2, coalesced: 1},
+	} {
+		now = tc.at
+		if _, err := k.Write([]byte("b\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if got := len(k.Archives()); got != tc.archives {
+			t.Errorf("expected %d archives at %v got %d", tc.archives, tc.at, got)
+		}
+		if got := k.Stats().CoalescedRotations; got != tc.coalesced {
+			t.Errorf("expected %d coalesced rotations at %v got %d", tc.coalesced, tc.at, got)
+		}
+	}
+
+	// Explicit rotations are never coalesced
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := len(k.Archives()); got != 3 {
+		t.Errorf("expected 3 archives got %d", got)
+	}
+}
+
+func TestWithRotationCoalescingChunked(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 59, 58, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-rotation-coalescing-chunked"),
+		WithNowFunc(func() time.Time { return now }),
+		WithMaxSize(8),
+		WithRotateEvery(time.Hour),
+		WithRotationCoalescing(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	// A size rotation right before the boundary
+	for _, msg := range []string{"1234567\n", "a\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	// The boundary is coalesced, while the size rotations of the chunks are not
+	now = time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)
+	msg := []byte("0123456789abcdefghi\n")
+	if n, err := k.Write(msg); err != nil || n != len(msg) {
+		t.Fatalf("expected %d bytes and no error got %d and %v", len(msg), n, err)
+	}
+	if got := k.Stats().CoalescedRotations; got != 1 {
+		t.Errorf("expected 1 coalesced rotation got %d", got)
+	}
+	archives := k.Archives()
+	if len(archives) != 3 || archives[1].Size != 8 || archives[2].Size != 8 {
+		t.Errorf("expected 3 archives of 8 bytes got %v", archives)
+	}
+}
@@ -0,0 +1,278 @@
+package lorekeeper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Merge the tiny archives left by crash-restart loops or aggressive rotation schedules when the Keeper starts,
+// so that the folder and the log shippers do not have to deal with thousands of files.
+// The adjacent archives smaller than size bytes are concatenated in chronological order into archives of up to size bytes,
+// the oldest archive of each run receiving the content of the next ones, which are then removed.
+// Only the archives of the same folder and the same compression are merged, since the streams of [WithGzip]
+// and the other Decompressors can be read one after another, and the labeled and adopted archives are left as is.
+// A merged archive keeps the name of its oldest archive and the modification time of its newest one,
+// so that it keeps its place among the archives. The merged archives are counted in [Stats.CompactedArchives].
+// It can not be used together with [WithEncryption], [WithHashChain] or [WithUploader],
+// whose archives can not be merged without breaking them or uploading them again.
+// Set size < 1 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithRotateEvery(time.Minute), lorekeeper.WithStartupCompaction(lorekeeper.Mb))
+func WithStartupCompaction(size int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.startupCompaction = max(size, 0)
+		return k, nil
+	}
+}
+
+// Reject the combinations of [WithStartupCompaction] with the archives that can not be merged.
+func (k *Keeper) applyStartupCompaction() error {
+	if k.startupCompaction == 0 {
+		return nil
+	}
+	if k.encryption != nil {
+		return fmt.Errorf("failed to set startup compaction, it can not be used together with encryption")
+	}
+	if k.chain != nil {
+		return fmt.Errorf("failed to set startup compaction, it can not be used together with hash chain")
+	}
+	if k.uploader != nil {
+		return fmt.Errorf("failed to set startup compaction, it can not be used together with an uploader")
+	}
+	return nil
+}
+
+// Merge the runs of tiny adjacent archives, see [WithStartupCompaction].
+func (k *Keeper) compactArchives() {
+	if k.startupCompaction <= 0 || k.archives.Length() < 2 {
+		return
+	}
+	runs := k.compactionRuns(k.startupCompaction, nil)
+	if len(runs) == 0 {
+		return
+	}
+
+	merged := make(map[*fileInfo]bool)
+	for _, run := range runs {
+		for _, archive := range k.mergeArchives(run) {
+			merged[archive] = true
+		}
+	}
+	k.archives = keptArchives(k.archiveList(), merged)
+}
+
+// Get the runs of adjacent archives smaller than size bytes that can be merged into archives of up to size bytes,
+// among the archives accepted by eligible, or all of them if it is nil.
+func (k *Keeper) compactionRuns(size int, eligible func(archive *fileInfo) bool) [][]*fileInfo {
+	var runs [][]*fileInfo
+	var run []*fileInfo
+	runSize := 0
+	end := func() {
+		if len(run) > 1 {
+			runs = append(runs, run)
+		}
+		run, runSize = nil, 0
+	}
+	for _, archive := range k.archives.All() {
+		if archive.size >= size || k.isLabeledArchive(archive.filePath) || k.isAdoptedArchive(archive.filePath) ||
+			(eligible != nil && !eligible(archive)) {
+			end()
+			continue
+		}
+		if len(run) > 0 && (runSize+archive.size > size || !k.mergeable(run[0], archive)) {
+			end()
+		}
+		run = append(run, archive)
+		runSize += archive.size
+	}
+	end()
+	return runs
+}
+
+// Merge the small archives older than olderThan into archives of up to targetSize bytes in the background,
+// such as the hundreds of tiny archives a week of hourly rotations produces. After every rotation,
+// the adjacent archives modified more than olderThan ago and smaller than targetSize are concatenated in chronological order,
+// like [WithStartupCompaction], the oldest archive of each run receiving the content of the next ones, which are then removed,
+// and the archive list and the retention limits account for the merged archives from then on.
+// The Keeper is locked while a run is merged, so targetSize bounds how long the writes may wait.
+// A merged archive keeps the modification time of its newest archive, so [WithMaxAge] removes it once all its content expired.
+// The archives being read, see [Keeper.OpenArchive], are not merged. With [WithManualStepping], it runs in [Keeper.Step].
+// It can not be used together with [WithEncryption], [WithHashChain] or [WithUploader].
+// Set targetSize < 1 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithCron("@hourly"),
+//		lorekeeper.WithGzip(),
+//		lorekeeper.WithCompaction(24*time.Hour, 64*lorekeeper.Mb),
+//	)
+func WithCompaction(olderThan time.Duration, targetSize int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if olderThan < 0 {
+			return nil, fmt.Errorf("failed to set compaction, age must not be negative")
+		}
+		k.compactionAge = olderThan
+		k.compactionSize = max(targetSize, 0)
+		return k, nil
+	}
+}
+
+// Reject the combinations of [WithCompaction] with the archives that can not be merged.
+func (k *Keeper) applyCompaction() error {
+	if k.compactionSize == 0 {
+		return nil
+	}
+	if k.encryption != nil {
+		return fmt.Errorf("failed to set compaction, it can not be used together with encryption")
+	}
+	if k.chain != nil {
+		return fmt.Errorf("failed to set compaction, it can not be used together with hash chain")
+	}
+	if k.uploader != nil {
+		return fmt.Errorf("failed to set compaction, it can not be used together with an uploader")
+	}
+	return nil
+}
+
+// Start merging the old archives in the background unless it is already running, see [WithCompaction].
+func (k *Keeper) scheduleCompaction() {
+	if k.compactionSize <= 0 || k.compacting != nil || k.manual || k.closed {
+		return
+	}
+	done := make(chan struct{})
+	k.compacting = done
+	k.goSupervised("compaction", k.compactOldArchives, func() {
+		k.mu.Lock()
+		k.compacting = nil
+		k.mu.Unlock()
+		close(done)
+	})
+}
+
+// Merge the runs of old archives, locking the Keeper for one run at a time.
+func (k *Keeper) compactOldArchives() {
+	runs := func() [][]*fileInfo {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		return k.oldArchiveRuns()
+	}()
+	for _, run := range runs {
+		func() {
+			k.mu.Lock()
+			defer k.mu.Unlock()
+			k.compactRun(run)
+		}()
+	}
+}
+
+// Get the runs of archives that [WithCompaction] merges, the lock of the Keeper must be held.
+func (k *Keeper) oldArchiveRuns() [][]*fileInfo {
+	if k.compactionSize <= 0 || k.closed || k.archives.Length() < 2 {
+		return nil
+	}
+	oldest := k.now().Add(-k.compactionAge)
+	return k.compactionRuns(k.compactionSize, func(archive *fileInfo) bool {
+		return archive.modtime.Before(oldest) && k.openHandles[archive.filePath] == 0
+	})
+}
+
+// Merge a run of archives found by oldArchiveRuns, the lock of the Keeper must be held.
+// The run is skipped if the archives changed since, such as when the retention removed some of them.
+func (k *Keeper) compactRun(run []*fileInfo) {
+	if k.closed || k.rotationBarrier > 0 {
+		return
+	}
+	archives := k.archiveList()
+	start := slices.Index(archives, run[0])
+	if start < 0 || len(archives)-start < len(run) || !slices.Equal(archives[start:start+len(run)], run) {
+		return
+	}
+	merged := make(map[*fileInfo]bool)
+	for _, archive := range k.mergeArchives(run) {
+		merged[archive] = true
+	}
+	k.archives = keptArchives(archives, merged)
+}
+
+// Wait until the archives being merged by [WithCompaction] are merged, or until ctx is done,
+// returning false if the merge was left unfinished. The lock of the Keeper must not be held.
+func (k *Keeper) waitCompaction(ctx context.Context) bool {
+	k.mu.Lock()
+	done := k.compacting
+	k.mu.Unlock()
+	if done == nil {
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Tell whether two archives can be concatenated into one.
+func (k *Keeper) mergeable(a, b *fileInfo) bool {
+	return filepath.Dir(a.filePath) == filepath.Dir(b.filePath) &&
+		k.isCompressedArchive(a.filePath) == k.isCompressedArchive(b.filePath) &&
+		filepath.Ext(a.filePath) == filepath.Ext(b.filePath)
+}
+
+// Append the archives of the run to its oldest one, returning the archives merged into it.
+// Each archive is removed right after being appended, so that an interruption duplicates at most one archive,
+// and never loses one.
+func (k *Keeper) mergeArchives(run []*fileInfo) []*fileInfo {
+	target := run[0]
+	// A read-only archive can not be appended to, see WithReadOnlyArchives
+	if k.readOnlyArchives {
+		if err := k.fsys.Chmod(target.filePath, k.fileMode); err != nil {
+			k.handleError(fmt.Errorf("failed to compact archive %q, caused by %w", target.filePath, err))
+			return nil
+		}
+	}
+
+	var merged []*fileInfo
+	for _, archive := range run[1:] {
+		if err := copyFile(k.fsys, archive.filePath, target.filePath, os.O_APPEND); err != nil {
+			k.handleError(fmt.Errorf("failed to compact archive %q into %q, caused by %w", archive.filePath, target.filePath, err))
+			break
+		}
+		// The archive is only lost from the retention if it can not be removed
+		if err := removeArchive(k.fsys, archive); err != nil {
+			k.handleError(fmt.Errorf("failed to compact archive %q, caused by %w", archive.filePath, err))
+		}
+		_ = k.fsys.Chtimes(target.filePath, archive.modtime, archive.modtime)
+		target.size += archive.size
+		target.modtime = archive.modtime
+		target.records = sumKnown(target.records, archive.records)
+		target.originalSize = sumKnown(target.originalSize, archive.originalSize)
+		target.compressionTime += archive.compressionTime
+		merged = append(merged, archive)
+		k.stats.CompactedArchives++
+	}
+
+	if err := k.sealArchive(target.filePath); err != nil {
+		k.handleError(err)
+	}
+	if len(merged) > 0 {
+		if err := k.writeChecksum(target.filePath); err != nil {
+			k.handleError(err)
+		}
+	}
+	return merged
+}
+
+// Add two counts that are -1 when unknown.
+func sumKnown(a, b int) int {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	return a + b
+}
@@ -0,0 +1,173 @@
+package lorekeeper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Write count messages of 10 bytes into a Keeper rotating after each of them.
+func writeTinyArchives(t *testing.T, folder, name string, count int, opts ...Opt) {
+	t.Helper()
+	k, err := New(append([]Opt{WithFolder(folder), WithName(name), WithMaxSize(10), WithSkipEmptyRotation()}, opts...)...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for i := range count {
+		if _, err := fmt.Fprintf(k, "message-%d\n", i); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+}
+
+func TestWithStartupCompaction(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Opt
+	}{
+		{name: "plain"},
+		{name: "gzip", opts: []Opt{WithGzip()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			folder := t.TempDir()
+			writeTinyArchives(t, folder, "test-compaction", 5, tc.opts...)
+
+			k, err := New(append([]Opt{WithFolder(folder), WithName("test-compaction"), WithMaxSize(10), WithSkipEmptyRotation()}, tc.opts...)...)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			before := readArchives(t, k)
+			archives := len(k.Archives())
+			if err := k.Close(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+
+			size := 0
+			for _, archive := range k.Archives() {
+				size = max(size, archive.Size*2)
+			}
+			k, err = New(append([]Opt{WithFolder(folder), WithName("test-compaction"), WithMaxSize(10), WithSkipEmptyRotation(), WithStartupCompaction(size)}, tc.opts...)...)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			defer k.Close()
+			if got, want := len(k.Archives()), (archives+1)/2; got != want {
+				t.Errorf("expected %d archives got %d", want, got)
+			}
+			if got := k.Stats().CompactedArchives; got != uint64(archives/2) {
+				t.Errorf("expected %d compacted archives got %d", archives/2, got)
+			}
+			if after := readArchives(t, k); after != before {
+				t.Errorf("expected %q got %q", before, after)
+			}
+		})
+	}
+}
+
+func TestWithStartupCompactionRejectsHashChain(t *testing.T) {
+	_, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-compaction-chain"),
+		WithHashChain([]byte("key")),
+		WithStartupCompaction(Mb),
+	)
+	if err == nil {
+		t.Errorf("expected an error for startup compaction with a hash chain")
+	}
+}
+
+func TestWithCompaction(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-background-compaction"),
+		WithMaxSize(10),
+		WithSkipEmptyRotation(),
+		WithGzip(),
+		WithCompaction(0, Kb),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	var want string
+	for i := range 5 {
+		msg := fmt.Sprintf("message-%d\n", i)
+		want += msg
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	k.waitCompaction(context.Background())
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	k.waitCompaction(context.Background())
+
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected the archives to be merged into one got %+v", archives)
+	}
+	if got := readGzip(t, archives[0].Path); got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+	if got := k.Stats().CompactedArchives; got != 4 {
+		t.Errorf("expected 4 compacted archives got %d", got)
+	}
+}
+
+func TestWithCompactionOlderThan(t *testing.T) {
+	now := time.Now()
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-compaction-older-than"),
+		WithMaxSize(10),
+		WithSkipEmptyRotation(),
+		WithManualStepping(),
+		WithNowFunc(func() time.Time { return now }),
+		WithCompaction(time.Hour, Kb),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for i := range 4 {
+		now = now.Add(time.Second)
+		if _, err := fmt.Fprintf(k, "message-%d\n", i); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	before := readArchives(t, k)
+	if err := k.Step(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := len(k.Archives()); got != 3 {
+		t.Errorf("expected the recent archives to be left as is got %d archives", got)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := k.Step(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := len(k.Archives()); got != 1 {
+		t.Errorf("expected the old archives to be merged got %d archives", got)
+	}
+	if after := readArchives(t, k); after != before {
+		t.Errorf("expected %q got %q", before, after)
+	}
+}
+
+func TestWithCompactionRejectsHashChain(t *testing.T) {
+	_, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-background-compaction-chain"),
+		WithHashChain([]byte("key")),
+		WithCompaction(time.Hour, Mb),
+	)
+	if err == nil {
+		t.Errorf("expected an error for compaction with a hash chain")
+	}
+}
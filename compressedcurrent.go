@@ -0,0 +1,300 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Write the current log file itself compressed with the compressor of [WithGzip] or [WithCompressor],
+// instead of compressing the log files only once rotated, such as for high-volume audit logs whose disk usage
+// would otherwise be dominated by the current log file. The current log file gets the extension of the compressor,
+// such as "app.log.gz", and a rotation simply finalizes the stream and renames it, even with [RotateOpts.NoCompression].
+// The stream is flushed every flushSize bytes of messages, at the interval of [WithFlushInterval], and on every sync,
+// so that the messages written up to the last flush point can be read back even if the process crashes:
+// the messages written since then are lost if it does, set flushSize <= 0 to flush after every message,
+// at the cost of the compression ratio. The compressor must be a [Decompressor] whose writers have a Flush() error method,
+// like the ones of gzip and zstd. The sizes of the current log file, such as for [WithMaxSize], are the compressed sizes
+// of what was flushed to disk, so a rotation on size happens later than with an uncompressed current log file.
+// A current log file left unfinished by a previous process, such as one that crashed, is repaired when it is reopened,
+// keeping the messages up to its last flush point, which reads the whole file once.
+// It can not be used together with [WithEncryption], [WithHashChain], [WithMaxLines] and [WithSkipEmptyRotation],
+// nor with [Keeper.SetCrashOutput]. Is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithGzip(), lorekeeper.WithCompressedCurrent(64*lorekeeper.Kb))
+func WithCompressedCurrent(flushSize int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.compressedCurrent = true
+		k.compressedFlushSize = max(flushSize, 0)
+		return k, nil
+	}
+}
+
+// Write the current log file uncompressed, this is the default.
+func NoCompressedCurrent() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.compressedCurrent = false
+		k.compressedFlushSize = 0
+		return k, nil
+	}
+}
+
+// A writer that can make a flush point in its stream, like the writers of gzip and zstd.
+type flusher interface {
+	Flush() error
+}
+
+// Reject a compressed current log file whose compressor can not flush or read back its streams,
+// or together with the options that read or rewrite the current log file as it is.
+func (k *Keeper) applyCompressedCurrent() error {
+	if !k.compressedCurrent {
+		return nil
+	}
+	if k.compressor == nil {
+		return fmt.Errorf("failed to set compressed current log file, it needs a compressor")
+	}
+	if k.encryption != nil {
+		return fmt.Errorf("failed to set compressed current log file, it can not be used together with encryption")
+	}
+	if k.chain != nil {
+		return fmt.Errorf("failed to set compressed current log file, it can not be used together with hash chain")
+	}
+	if k.maxLines > 0 {
+		return fmt.Errorf("failed to set compressed current log file, it can not be used together with max lines")
+	}
+	if k.skipEmptyRotation {
+		return fmt.Errorf("failed to set compressed current log file, it can not be used together with skip empty rotation")
+	}
+	if _, ok := k.compressor.(Decompressor); !ok {
+		return fmt.Errorf("failed to set compressed current log file, the compressor %T is not a Decompressor", k.compressor)
+	}
+	w, err := k.compressor.NewWriter(io.Discard)
+	if err != nil {
+		return fmt.Errorf("failed to set compressed current log file, caused by %w", err)
+	}
+	defer w.Close()
+	if _, ok := w.(flusher); !ok {
+		return fmt.Errorf("failed to set compressed current log file, the writers of %T have no Flush method", k.compressor)
+	}
+	return nil
+}
+
+// Wrap the opened current log file to compress what is written to it, repairing it first if it was left unfinished.
+func (k *Keeper) compressCurrentFile(file File) (File, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// The Keeper finalized the stream itself when it last closed the file
+	if stat.Size() > 0 && stat.Size() != k.finishedCurrentSize {
+		repaired, err := k.repairCurrentFile()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if repaired {
+			file.Close()
+			if file, err = k.fsys.OpenFile(k.getCurrentFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, k.fileMode); err != nil {
+				return nil, err
+			}
+			if stat, err = file.Stat(); err != nil {
+				file.Close()
+				return nil, err
+			}
+		}
+	}
+	return &compressedFile{File: file, compressor: k.compressor, size: stat.Size(), flushSize: k.compressedFlushSize}, nil
+}
+
+// Rewrite the current log file as a finished stream if its stream can not be read to the end,
+// keeping what can be read of it. Tell whether it was rewritten.
+func (k *Keeper) repairCurrentFile() (bool, error) {
+	path := k.getCurrentFilePath()
+	src, readErr := openLogFile(k.fsys, path, k.compressor)
+	if errors.Is(readErr, fs.ErrNotExist) {
+		return false, nil
+	}
+	if readErr == nil {
+		_, readErr = io.Copy(io.Discard, src)
+		src.Close()
+	}
+	if readErr == nil {
+		return false, nil
+	}
+
+	tempPath := path + ".tmp"
+	dst, err := k.fsys.OpenFile(tempPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, k.fileMode)
+	if err != nil {
+		return false, fmt.Errorf("failed to repair compressed current log file, caused by %w", err)
+	}
+	recovered, err := k.recompress(path, dst)
+	if err = errors.Join(err, dst.Close()); err != nil {
+		_ = k.fsys.Remove(tempPath)
+		return false, fmt.Errorf("failed to repair compressed current log file, caused by %w", err)
+	}
+	if err := k.fsys.Rename(tempPath, path); err != nil {
+		_ = k.fsys.Remove(tempPath)
+		return false, fmt.Errorf("failed to repair compressed current log file, caused by %w", err)
+	}
+	k.handleError(fmt.Errorf("repaired unfinished compressed current log file %q, kept %d bytes up to its last flush point, caused by %w", path, recovered, readErr))
+	return true, nil
+}
+
+// Compress what can be read of the compressed log file at path into a finished stream written to dst,
+// which is left empty if not even the start of the stream can be read.
+func (k *Keeper) recompress(path string, dst io.Writer) (int64, error) {
+	src, err := openLogFile(k.fsys, path, k.compressor)
+	if err != nil {
+		return 0, nil
+	}
+	defer src.Close()
+	w, err := k.compressor.NewWriter(dst)
+	if err != nil {
+		return 0, err
+	}
+	// The stream ends with an error at the last flush point, what precedes it is kept
+	recovered, _ := io.Copy(w, src)
+	return recovered, w.Close()
+}
+
+// Make a flush point in the stream of the current log file, see [WithCompressedCurrent].
+func (k *Keeper) flushCompressedCurrent() error {
+	cf, ok := k.currentFile.(*compressedFile)
+	if !ok {
+		return nil
+	}
+	if err := cf.flush(); err != nil {
+		return fmt.Errorf("failed to flush compressed current log file, caused by %w", err)
+	}
+	k.currentFileSize = int(cf.size) + len(k.writeBuf)
+	return nil
+}
+
+// Get the size of the current log file once n more bytes of a message were written to it.
+// The size of a compressed current log file is the size of what was flushed to disk, see [WithCompressedCurrent].
+func (k *Keeper) grownCurrentFileSize(n int) int {
+	if cf, ok := k.currentFile.(*compressedFile); ok {
+		// The stream got bytes that are not flushed yet
+		if cf.unflushed > 0 && cf.unflushed <= n && len(k.writeBuf) == 0 {
+			k.scheduleFlush()
+		}
+		return int(cf.size) + len(k.writeBuf)
+	}
+	return k.currentFileSize + n
+}
+
+// A compressedFile compresses what is written to the current log file, see [WithCompressedCurrent].
+// The stream is started on the first write, so that a file closed before any write stays empty.
+type compressedFile struct {
+	File
+	compressor Compressor
+	w          io.WriteCloser
+	// The size of the file on disk, and the number of bytes written since the last flush point
+	size      int64
+	unflushed int
+	flushSize int
+}
+
+func (f *compressedFile) Write(p []byte) (int, error) {
+	if f.w == nil {
+		w, err := f.compressor.NewWriter(fileCounter{f})
+		if err != nil {
+			return 0, err
+		}
+		f.w = w
+	}
+	n, err := f.w.Write(p)
+	f.unflushed += n
+	if err != nil {
+		return n, err
+	}
+	if f.unflushed >= f.flushSize {
+		return n, f.flush()
+	}
+	return n, nil
+}
+
+// Make a flush point, after which all that was written can be read back from the file.
+func (f *compressedFile) flush() error {
+	if f.w == nil || f.unflushed == 0 {
+		return nil
+	}
+	f.unflushed = 0
+	return f.w.(flusher).Flush()
+}
+
+// Flush the stream before syncing the file.
+func (f *compressedFile) Sync() error {
+	if err := f.flush(); err != nil {
+		return err
+	}
+	if syncer, ok := f.File.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Finish the stream before closing the file.
+func (f *compressedFile) Close() error {
+	var err error
+	if f.w != nil {
+		err = f.w.Close()
+		f.w = nil
+	}
+	return errors.Join(err, f.File.Close())
+}
+
+// A fileCounter writes the compressed stream to the file, counting its size.
+type fileCounter struct {
+	f *compressedFile
+}
+
+func (c fileCounter) Write(p []byte) (int, error) {
+	n, err := c.f.File.Write(p)
+	c.f.size += int64(n)
+	return n, err
+}
+
+// A decompressor reading the current log file while it is written, whose stream ends at the last flush point.
+type unfinishedDecompressor struct {
+	Decompressor
+}
+
+func (d unfinishedDecompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	reader, err := d.Decompressor.NewReader(r)
+	// Nothing was flushed yet
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unfinishedReader{reader}, nil
+}
+
+// An unfinishedReader ends at the last flush point instead of failing there.
+type unfinishedReader struct {
+	io.ReadCloser
+}
+
+func (r unfinishedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Get the decompressor of the log file at path, which may be the current log file, nil if it is not compressed.
+func (k *Keeper) logFileDecompressor(path string) Compressor {
+	if k.compressedCurrent && path == k.getCurrentFilePath() {
+		return unfinishedDecompressor{k.compressor.(Decompressor)}
+	}
+	return k.archiveDecompressor(path)
+}
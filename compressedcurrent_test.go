@@ -0,0 +1,182 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithCompressedCurrent(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-compressed-current"), WithGzip(), WithCompressedCurrent(0))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	current := filepath.Join(folder, "test-compressed-current.log.gz")
+	if got := k.CurrentFilePath(); got != current {
+		t.Errorf("expected the current log file %s got %s", current, got)
+	}
+	for _, msg := range []string{"abc\n", "def\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	// Readable up to the last flush point while it is written
+	var records []string
+	for record, err := range k.Between(time.Time{}, time.Now().Add(time.Hour)) {
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		records = append(records, string(record))
+	}
+	if strings.Join(records, "") != "abc\ndef\n" {
+		t.Errorf("expected the records %q got %q", "abc\ndef\n", records)
+	}
+	stat, err := os.Stat(current)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := k.Stats().CurrentFileSize; got != stat.Size() {
+		t.Errorf("expected the compressed size %d got %d", stat.Size(), got)
+	}
+
+	// The rotation finalizes the stream
+	archivePath, err := k.RotateNow()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !strings.HasSuffix(archivePath, ".log.gz") {
+		t.Errorf("expected a compressed archive got %s", archivePath)
+	}
+	if got := readGzip(t, archivePath); got != "abc\ndef\n" {
+		t.Errorf("expected %q got %q", "abc\ndef\n", got)
+	}
+	if stat, err := os.Stat(current); err != nil || stat.Size() != 0 {
+		t.Errorf("expected an empty current log file got %v", err)
+	}
+}
+
+func TestWithCompressedCurrentRepair(t *testing.T) {
+	folder := t.TempDir()
+	current := filepath.Join(folder, "test-compressed-repair.log.gz")
+
+	// A stream left unfinished by a crash, with a message after its last flush point
+	var unfinished bytes.Buffer
+	gw := gzip.NewWriter(&unfinished)
+	gw.Write([]byte("abc\n"))
+	gw.Flush()
+	gw.Write(bytes.Repeat([]byte("lost"), 16*Kb))
+	if err := os.WriteFile(current, unfinished.Bytes(), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	var errs []error
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-compressed-repair"),
+		WithGzip(),
+		WithCompressedCurrent(0),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if len(errs) != 1 {
+		t.Errorf("expected the repair to be reported got %v", errs)
+	}
+	if _, err := k.Write([]byte("def\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archivePath, err := k.RotateNow()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := readGzip(t, archivePath); got != "abc\ndef\n" {
+		t.Errorf("expected %q got %q", "abc\ndef\n", got)
+	}
+}
+
+func TestWithCompressedCurrentInvalid(t *testing.T) {
+	if _, err := New(WithFolder(t.TempDir()), WithName("test-compressed-invalid"), WithCompressedCurrent(0)); err == nil {
+		t.Errorf("expected an error without a compressor")
+	}
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	return string(b)
+}
+
+func TestWithCompressedCurrentRetention(t *testing.T) {
+	folder := t.TempDir()
+	opts := []Opt{
+		WithFolder(folder), WithName("test-compressed-current-retention"), WithGzip(), WithCompressedCurrent(0),
+		WithArchiveNameLayout("{{ .name }}{{ .time }}{{ .extension }}"), WithMaxFiles(1),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, msg := range []string{"abc\n", "def\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	k, err = New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, archive := range k.Archives() {
+		if archive.Path == k.CurrentFilePath() {
+			t.Fatalf("expected the current log file not to be an archive")
+		}
+	}
+	// The rotations run the retention, which must keep the current log file
+	for _, msg := range []string{"ghi\n", "jkl\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if _, err := k.Write([]byte("mno\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	records, err := k.Tail(1)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(records) != 1 || string(records[0]) != "mno\n" {
+		t.Errorf("expected the current log file to keep %q got %q", "mno\n", records)
+	}
+}
@@ -0,0 +1,75 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// Placeholders rendered into the archive name layout to locate the compression extension.
+const (
+	layoutFieldMarker          = "\x00"
+	layoutCompressionExtMarker = "\x01"
+)
+
+// Check whether the archive name layout places the compression extension itself with {{ .compressionExt }}.
+func (k *Keeper) layoutHasCompressionExt() bool {
+	var buff bytes.Buffer
+	err := k.archiveNameLayout.Execute(&buff, k.archiveNameData("", "", layoutCompressionExtMarker))
+	return err == nil && strings.Contains(buff.String(), layoutCompressionExtMarker)
+}
+
+// Split the path of an archive around the place of its compression extension,
+// which is the end of the name unless the archive name layout contains {{ .compressionExt }}.
+// The extension itself is not part of before and after, compressed tells whether the path has it.
+func (k *Keeper) splitCompressionExt(path string) (before, after string, compressed bool) {
+	if len(k.compressionExt) == 0 {
+		return path, "", false
+	}
+	if k.layoutHasCompressionExt() {
+		if re := k.compressionExtRegexp(); re != nil {
+			if m := re.FindStringSubmatchIndex(path); m != nil {
+				return path[:m[2]], path[m[3]:], m[3] > m[2]
+			}
+		}
+	}
+	if strings.HasSuffix(path, k.compressionExt) {
+		return strings.TrimSuffix(path, k.compressionExt), "", true
+	}
+	return path, "", false
+}
+
+// Get a regexp matching the end of the path of an archive, whose first group is the compression extension if any.
+func (k *Keeper) compressionExtRegexp() *regexp.Regexp {
+	var buff bytes.Buffer
+	data := k.archiveNameData(layoutFieldMarker, layoutFieldMarker, layoutCompressionExtMarker)
+	data = withNameVars(data, layoutFieldMarker, layoutFieldMarker, layoutFieldMarker)
+	if err := k.archiveNameLayout.Execute(&buff, data); err != nil {
+		return nil
+	}
+	before, after, _ := strings.Cut(buff.String(), layoutCompressionExtMarker)
+	toPattern := func(s string) string {
+		return strings.ReplaceAll(regexp.QuoteMeta(s), layoutFieldMarker, ".*?")
+	}
+	pattern := "(?s)" + toPattern(before) + "(" + regexp.QuoteMeta(k.compressionExt) + "|)" + toPattern(after) + "$"
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// Check whether the archive at path is compressed.
+func (k *Keeper) isCompressedArchive(path string) bool {
+	_, _, compressed := k.splitCompressionExt(path)
+	return compressed
+}
+
+// Get the path of the compressed copy of the archive at path.
+func (k *Keeper) compressedArchivePath(path string) string {
+	before, after, compressed := k.splitCompressionExt(path)
+	if compressed {
+		return path
+	}
+	return before + k.compressionExt + after
+}
@@ -0,0 +1,86 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestKeeperCompressionExtInLayout(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-compression-ext"),
+		WithArchiveNameLayout("{{ .name }}-{{ .time }}{{ .compressionExt }}{{ .extension }}"),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	name := filepath.Base(archives[0].Path)
+	if !regexp.MustCompile(`^test-compression-ext-.+\.gz\.log$`).MatchString(name) {
+		t.Errorf("expected the compression extension before the extension got %q", name)
+	}
+	matches, _ := filepath.Glob(filepath.Join(folder, "*"))
+	if len(matches) != 2 {
+		t.Errorf("expected only the archive and the current log file got %v", matches)
+	}
+
+	// The archive is found again and decompressed when read
+	inspector, err := Open(
+		WithFolder(folder),
+		WithName("test-compression-ext"),
+		WithArchiveNameLayout("{{ .name }}-{{ .time }}{{ .compressionExt }}{{ .extension }}"),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	found, err := inspector.Grep(regexp.MustCompile("hello"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(found) != 1 || found[0].Path != archives[0].Path {
+		t.Errorf("expected 1 match in %q got %+v", archives[0].Path, found)
+	}
+}
+
+func TestKeeperSplitCompressionExt(t *testing.T) {
+	tests := []struct {
+		layout       string
+		path         string
+		compressed   string
+		isCompressed bool
+	}{
+		{"{{ .time }}-{{ .name }}{{ .extension }}", "t-app.log", "t-app.log.gz", false},
+		{"{{ .time }}-{{ .name }}{{ .extension }}", "t-app.log.gz", "t-app.log.gz", true},
+		{"{{ .time }}{{ .compressionExt }}{{ .extension }}", "t.log", "t.gz.log", false},
+		{"{{ .time }}{{ .compressionExt }}{{ .extension }}", "t.gz.log", "t.gz.log", true},
+		{"{{ .name }}{{ .compressionExt }}-{{ .time }}{{ .extension }}", "app-t.log", "app.gz-t.log", false},
+	}
+	for _, tt := range tests {
+		k, err := configureDetached(WithName("app"), WithArchiveNameLayout(tt.layout), WithGzip())
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if got := k.isCompressedArchive(tt.path); got != tt.isCompressed {
+			t.Errorf("%s: expected %q compressed %v got %v", tt.layout, tt.path, tt.isCompressed, got)
+		}
+		if got := k.compressedArchivePath(tt.path); got != tt.compressed {
+			t.Errorf("%s: expected compressed path %q got %q", tt.layout, tt.compressed, got)
+		}
+	}
+}
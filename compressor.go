@@ -0,0 +1,153 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// A Compressor compresses the archives, see [WithCompressor].
+// Implementations must be safe for concurrent use, since archives may be compressed concurrently.
+// NewWriter is called for every archive, so compressors that are expensive to set up, such as zstd ones,
+// should reuse their writers, for example through a [sync.Pool] returning them on Close.
+type Compressor interface {
+	// Wrap w so that what is written to the returned writer is compressed into w.
+	// The compressed archive is complete once the returned writer is closed, closing it must not close w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// The extension of the compressed archives, such as ".gz" or ".zst".
+	Extension() string
+}
+
+// A Decompressor is a [Compressor] that can also read its archives back,
+// used by [Keeper.Between], [Inspector] and [NewBrowser] to decompress the archives on the fly.
+// Archives of a Compressor that is not a Decompressor can not be read decompressed.
+type Decompressor interface {
+	Compressor
+	// Wrap r so that what is read from the returned reader is decompressed from r, closing it must not close r.
+	// The compressed stream may be made of several members or frames written one after another,
+	// such as the compressed current log file of [WithCompressedCurrent] resumed after a restart, which must be read as a single stream.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// The built-in [Compressor] of [WithGzip] and [WithGzipLevel].
+type gzipCompressor struct {
+	level int
+}
+
+// Make sure that gzipCompressor implements the [Decompressor] interface.
+var _ Decompressor = gzipCompressor{}
+
+// The pools of gzip writers, one per level from [gzip.HuffmanOnly] to [gzip.BestCompression],
+// since a writer can only be reset to its own level.
+var gzipWriterPools [gzip.BestCompression - gzip.HuffmanOnly + 1]sync.Pool
+
+// A gzip writer returned to its pool once closed.
+type pooledGzipWriter struct {
+	*gzip.Writer
+	pool *sync.Pool
+}
+
+func (w *pooledGzipWriter) Close() error {
+	if w.Writer == nil {
+		return nil
+	}
+	err := w.Writer.Close()
+	w.pool.Put(w.Writer)
+	w.Writer = nil
+	return err
+}
+
+// Reuse a pooled writer of the level, so that frequent rotations do not allocate a new compressor for every archive.
+func (c gzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	pool := &gzipWriterPools[c.level-gzip.HuffmanOnly]
+	if gw, ok := pool.Get().(*gzip.Writer); ok {
+		gw.Reset(w)
+		return &pooledGzipWriter{Writer: gw, pool: pool}, nil
+	}
+	gw, err := gzip.NewWriterLevel(w, c.level)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledGzipWriter{Writer: gw, pool: pool}, nil
+}
+
+func (c gzipCompressor) Extension() string {
+	return ".gz"
+}
+
+// The gzip reader is in multistream mode by default, reading the concatenated members as one stream.
+func (c gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// Compress the archives with the given [Compressor], such as a zstd or lz4 one, which are far faster than gzip
+// for high-volume archives. A nil compressor disables the compression, same as [NoCompression].
+// The extension of the compressor must not be empty, so that compressed archives can be told apart.
+// Archives left uncompressed by a previous process, for example one that exited mid-rotation,
+// are compressed when the next Keeper starts.
+//
+// Example usage:
+//
+//	type zstdCompressor struct{}
+//
+//	func (zstdCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) }
+//	func (zstdCompressor) Extension() string { return ".zst" }
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithCompressor(zstdCompressor{}))
+func WithCompressor(c Compressor) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if c == nil {
+			k.compressor = nil
+			k.compressionExt = ""
+			return k, nil
+		}
+		if len(c.Extension()) == 0 {
+			return nil, fmt.Errorf("failed to set compressor, the extension of %T is empty", c)
+		}
+		k.compressor = c
+		k.compressionExt = c.Extension()
+		return k, nil
+	}
+}
+
+// Archive will be compressed with Gzip.
+// Archives left uncompressed by a previous process, for example one that exited mid-rotation,
+// are compressed when the next Keeper starts.
+func WithGzip() Opt {
+	return WithGzipLevel(gzip.DefaultCompression)
+}
+
+// Archive will be compressed with Gzip, see [gzip.NoCompression] for available levels.
+func WithGzipLevel(level int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		var temp *bytes.Buffer
+		if _, err := gzip.NewWriterLevel(temp, level); err != nil {
+			return nil, fmt.Errorf("failed to create compress, caused by %w", err)
+		}
+		return WithCompressor(gzipCompressor{level: level})(k)
+	}
+}
+
+// No compression
+func NoCompression() Opt {
+	return WithCompressor(nil)
+}
+
+// Get the decompressor of the archive, nil if the archive is not compressed.
+func (k *Keeper) archiveDecompressor(path string) Compressor {
+	if !k.isCompressedArchive(path) {
+		return nil
+	}
+	return k.compressor
+}
+
+// Wrap r to decompress it with c, which fails if c is not a [Decompressor].
+func newDecompressingReader(c Compressor, r io.Reader) (io.ReadCloser, error) {
+	d, ok := c.(Decompressor)
+	if !ok {
+		return nil, fmt.Errorf("failed to decompress, the compressor %T is not a Decompressor", c)
+	}
+	return d.NewReader(r)
+}
@@ -0,0 +1,167 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// A flate compressor standing in for a third-party one, such as zstd.
+type flateCompressor struct{}
+
+func (flateCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.BestSpeed)
+}
+
+func (flateCompressor) Extension() string {
+	return ".flate"
+}
+
+func (flateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+func TestWithCompressor(t *testing.T) {
+	opts := []Opt{WithFolder(t.TempDir()), WithName("test-compressor"), WithCompressor(flateCompressor{})}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("compressed\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archives := k.Archives()
+	if len(archives) != 1 || !strings.HasSuffix(archives[0].Path, ".flate") {
+		t.Fatalf("expected 1 archive compressed with flate got %+v", archives)
+	}
+
+	inspector, err := Open(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	reader, err := inspector.Reader()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "compressed\n" {
+		t.Errorf("expected the decompressed archive got %q", content)
+	}
+}
+
+func TestWithCompressorInvalid(t *testing.T) {
+	if err := ValidateOptions(WithCompressor(trackingCompressor{})); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if err := ValidateOptions(WithCompressor(emptyExtCompressor{})); err == nil {
+		t.Errorf("expected error for a compressor without extension")
+	}
+}
+
+type emptyExtCompressor struct{ flateCompressor }
+
+func (emptyExtCompressor) Extension() string {
+	return ""
+}
+
+func TestGzipCompressorPooling(t *testing.T) {
+	c := gzipCompressor{level: gzip.BestSpeed}
+	// Each archive must be complete even though the writers are reused
+	for _, msg := range []string{"first archive\n", "second archive\n", "third archive\n"} {
+		var compressed bytes.Buffer
+		w, err := c.NewWriter(&compressed)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if _, err := io.WriteString(w, msg); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		// A second Close must not return the writer to the pool twice
+		if err := w.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+
+		r, err := c.NewReader(&compressed)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if string(content) != msg {
+			t.Errorf("expected %q got %q", msg, content)
+		}
+	}
+}
+
+func TestGzipCompressorMultiMember(t *testing.T) {
+	c := gzipCompressor{level: gzip.DefaultCompression}
+	// Every writer appends a new member to the stream
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	for _, msg := range []string{"before restart\n", "after restart\n"} {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		w, err := c.NewWriter(f)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if _, err := io.WriteString(w, msg); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := errors.Join(w.Close(), f.Close()); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	r, err := openLogFile(OSFS(), path, c)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "before restart\nafter restart\n" {
+		t.Errorf("expected both members got %q", content)
+	}
+}
+
+func BenchmarkGzipCompressorNewWriter(b *testing.B) {
+	c := gzipCompressor{level: gzip.DefaultCompression}
+	msg := []byte("a small archive\n")
+	b.ReportAllocs()
+	for range b.N {
+		w, err := c.NewWriter(io.Discard)
+		if err != nil {
+			b.Fatalf("expected no error got %v", err)
+		}
+		if _, err := w.Write(msg); err != nil {
+			b.Fatalf("expected no error got %v", err)
+		}
+		if err := w.Close(); err != nil {
+			b.Fatalf("expected no error got %v", err)
+		}
+	}
+}
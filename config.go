@@ -0,0 +1,188 @@
+package lorekeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A Size is a number of bytes read from a configuration file, either as a number of bytes
+// or as a human-readable string such as "512Kb" or "100MB", see [ParseSize].
+type Size int
+
+// Make sure that Size can be read from both JSON numbers and strings.
+var _ json.Unmarshaler = (*Size)(nil)
+
+func (s *Size) UnmarshalText(text []byte) error {
+	size, err := ParseSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = Size(size)
+	return nil
+}
+
+func (s *Size) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return s.UnmarshalText([]byte(text))
+	}
+	var size int
+	if err := json.Unmarshal(data, &size); err != nil {
+		return fmt.Errorf("failed to parse size %s, expected a number of bytes or a string such as \"100MB\"", data)
+	}
+	*s = Size(size)
+	return nil
+}
+
+// The multipliers of the size units, following [Kb], [KB] and the other size constants of the package.
+var sizeUnits = map[string]int{
+	"":    1,
+	"B":   1,
+	"Kb":  Kb,
+	"KiB": Kb,
+	"KB":  KB,
+	"kB":  KB,
+	"Mb":  Mb,
+	"MiB": Mb,
+	"MB":  MB,
+	"Gb":  Gb,
+	"GiB": Gb,
+	"GB":  GB,
+}
+
+// Parse a human-readable size into bytes, such as "512Kb" or "1.5 GB".
+// The units follow the size constants of the package: "Kb", "Mb" and "Gb" are powers of 1024,
+// like "KiB", "MiB" and "GiB", while "KB", "MB" and "GB" are powers of 1000.
+// A number without unit, or with "B", is a number of bytes.
+//
+// Example usage:
+//
+//	size, err := lorekeeper.ParseSize("100MB") // 100000000
+func ParseSize(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	end := strings.LastIndexAny(s, "0123456789.") + 1
+	unit, ok := sizeUnits[strings.TrimSpace(s[end:])]
+	if !ok {
+		return 0, fmt.Errorf("failed to parse size %q, unknown unit %q", s, strings.TrimSpace(s[end:]))
+	}
+	value, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil || value < 0 || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("failed to parse size %q, expected a positive number followed by a unit such as \"MB\"", s)
+	}
+	size := math.Round(value * float64(unit))
+	// math.MaxInt rounds up to 2^63 as a float64, which is already out of range
+	if size >= math.MaxInt {
+		return 0, fmt.Errorf("failed to parse size %q, too large", s)
+	}
+	return int(size), nil
+}
+
+// A Config describes a [Keeper] in a configuration file, see [NewFromConfig].
+// The zero value of a field keeps the default of its option.
+type Config struct {
+	// See [WithFolder].
+	Folder string `json:"folder,omitempty" yaml:"folder,omitempty"`
+	// See [WithName].
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// See [WithExtension].
+	Extension string `json:"extension,omitempty" yaml:"extension,omitempty"`
+	// See [WithMaxSize], a negative size disables the size rotation.
+	MaxSize Size `json:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+	// See [WithMaxLines].
+	MaxLines int `json:"maxLines,omitempty" yaml:"maxLines,omitempty"`
+	// See [WithMaxFiles].
+	MaxFiles int `json:"maxFiles,omitempty" yaml:"maxFiles,omitempty"`
+	// See [WithTotalSize].
+	TotalSize Size `json:"totalSize,omitempty" yaml:"totalSize,omitempty"`
+	// See [WithCron].
+	Cron string `json:"cron,omitempty" yaml:"cron,omitempty"`
+	// Either "none" or "gzip", see [WithGzip].
+	Compression string `json:"compression,omitempty" yaml:"compression,omitempty"`
+	// The level of the gzip compression, see [WithGzipLevel].
+	CompressionLevel int `json:"compressionLevel,omitempty" yaml:"compressionLevel,omitempty"`
+	// See [WithTimeLayout].
+	TimeLayout string `json:"timeLayout,omitempty" yaml:"timeLayout,omitempty"`
+	// See [WithArchiveNameLayout].
+	ArchiveNameLayout string `json:"archiveNameLayout,omitempty" yaml:"archiveNameLayout,omitempty"`
+	// See [WithMinDiskFree].
+	MinDiskFree Size `json:"minDiskFree,omitempty" yaml:"minDiskFree,omitempty"`
+	// See [WithQuota].
+	Quota Size `json:"quota,omitempty" yaml:"quota,omitempty"`
+}
+
+// Get the options described by the configuration, to combine them with other options.
+func (cfg Config) Options() ([]Opt, error) {
+	var opts []Opt
+	if len(cfg.Folder) > 0 {
+		opts = append(opts, WithFolder(cfg.Folder))
+	}
+	if len(cfg.Name) > 0 {
+		opts = append(opts, WithName(cfg.Name))
+	}
+	if len(cfg.Extension) > 0 {
+		opts = append(opts, WithExtension(cfg.Extension))
+	}
+	if cfg.MaxSize != 0 {
+		opts = append(opts, WithMaxSize(int(cfg.MaxSize)))
+	}
+	if cfg.MaxLines != 0 {
+		opts = append(opts, WithMaxLines(cfg.MaxLines))
+	}
+	if cfg.MaxFiles != 0 {
+		opts = append(opts, WithMaxFiles(cfg.MaxFiles))
+	}
+	if cfg.TotalSize != 0 {
+		opts = append(opts, WithTotalSize(int(cfg.TotalSize)))
+	}
+	if len(cfg.Cron) > 0 {
+		opts = append(opts, WithCron(cfg.Cron))
+	}
+	switch cfg.Compression {
+	case "", "none":
+		if cfg.CompressionLevel != 0 {
+			return nil, fmt.Errorf("failed to read config, compression level requires the gzip compression")
+		}
+	case "gzip":
+		if cfg.CompressionLevel != 0 {
+			opts = append(opts, WithGzipLevel(cfg.CompressionLevel))
+		} else {
+			opts = append(opts, WithGzip())
+		}
+	default:
+		return nil, fmt.Errorf("failed to read config, unknown compression %q, expected \"none\" or \"gzip\"", cfg.Compression)
+	}
+	if len(cfg.TimeLayout) > 0 {
+		opts = append(opts, WithTimeLayout(cfg.TimeLayout))
+	}
+	if len(cfg.ArchiveNameLayout) > 0 {
+		opts = append(opts, WithArchiveNameLayout(cfg.ArchiveNameLayout))
+	}
+	if cfg.MinDiskFree != 0 {
+		opts = append(opts, WithMinDiskFree(int(cfg.MinDiskFree)))
+	}
+	if cfg.Quota != 0 {
+		opts = append(opts, WithQuota(int(cfg.Quota)))
+	}
+	return opts, nil
+}
+
+// Create a new [Keeper] from a configuration, such as one read from a JSON or YAML file,
+// so that the teams driving everything from configuration files do not map every field to an option by hand.
+//
+// Example usage:
+//
+//	var cfg lorekeeper.Config
+//	if err := json.Unmarshal([]byte(`{"name": "app", "maxSize": "100MB", "compression": "gzip"}`), &cfg); err != nil {
+//		return err
+//	}
+//	keeper, err := lorekeeper.NewFromConfig(cfg)
+func NewFromConfig(cfg Config) (*Keeper, error) {
+	opts, err := cfg.Options()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new keeper, caused by %w", err)
+	}
+	return New(opts...)
+}
@@ -0,0 +1,73 @@
+package lorekeeper
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		size int
+	}{
+		{s: "42", size: 42},
+		{s: "42B", size: 42},
+		{s: "512Kb", size: 512 * Kb},
+		{s: "512KiB", size: 512 * Kb},
+		{s: "100MB", size: 100 * MB},
+		{s: "1.5 Gb", size: 3 * Gb / 2},
+		{s: " 2GB ", size: 2 * GB},
+	} {
+		size, err := ParseSize(tc.s)
+		if err != nil {
+			t.Errorf("expected no error for %q got %v", tc.s, err)
+			continue
+		}
+		if size != tc.size {
+			t.Errorf("expected %d bytes for %q got %d", tc.size, tc.s, size)
+		}
+	}
+	for _, s := range []string{"", "MB", "12TB", "-1MB", "1..5MB", "8589934592Gb"} {
+		if _, err := ParseSize(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	folder := t.TempDir()
+	data, err := json.Marshal(map[string]any{
+		"folder":      folder,
+		"name":        "test-new-from-config",
+		"maxSize":     "1Mb",
+		"maxFiles":    3,
+		"totalSize":   10240,
+		"compression": "gzip",
+	})
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	k, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if k.Folder() != folder || k.Name() != "test-new-from-config" || k.Extension() != ".log" {
+		t.Errorf("unexpected naming %s", k)
+	}
+	if k.MaxSize() != Mb || k.MaxFiles() != 3 || k.TotalSize() != 10240 || k.CompressionExtension() != ".gz" {
+		t.Errorf("unexpected limits %s", k)
+	}
+
+	if err := json.Unmarshal([]byte(`{"maxSize": "12 parsecs"}`), &cfg); err == nil {
+		t.Errorf("expected error for an invalid size")
+	}
+	if _, err := NewFromConfig(Config{Compression: "zip"}); err == nil {
+		t.Errorf("expected error for an unknown compression")
+	}
+}
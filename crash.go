@@ -0,0 +1,96 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+// The Keeper receiving the crash output of the process, see [Keeper.SetCrashOutput].
+var crashOutput struct {
+	mu     sync.Mutex
+	keeper *Keeper
+}
+
+// Recover a panic, write it with its stack trace through the Keeper with [Keeper.WriteUrgent],
+// rotate so that the crash gets an archive of its own, then panic again with the same value.
+// It must be deferred directly, and does nothing if there is no panic.
+//
+// Example usage:
+//
+//	func main() {
+//		keeper, _ := lorekeeper.New(lorekeeper.WithName("app"))
+//		defer keeper.RecoverPanic()
+//		...
+//	}
+func (k *Keeper) RecoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	_, _ = k.WriteUrgent(fmt.Appendf(nil, "panic: %v\n\n%s", r, debug.Stack()))
+	_, _ = k.rotateBy(RotationPanic)
+	panic(r)
+}
+
+// Make the runtime write the fatal errors of the process, such as unrecovered panics in any goroutine
+// or concurrent map writes, to the current log file with [debug.SetCrashOutput], in addition to standard error.
+// The crash output follows the current log file across rotations until the Keeper is closed.
+// Since there is only one crash output per process, the last Keeper calling this wins.
+func (k *Keeper) SetCrashOutput() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	crashOutput.mu.Lock()
+	defer crashOutput.mu.Unlock()
+	if err := k.setCrashOutputFile(); err != nil {
+		return err
+	}
+	crashOutput.keeper = k
+	return nil
+}
+
+// Point the crash output to the current log file, the lock of the Keeper must be held.
+func (k *Keeper) setCrashOutputFile() error {
+	if k.compressedCurrent {
+		return fmt.Errorf("failed to set crash output, the current log file is compressed")
+	}
+	f, err := k.fsys.OpenFile(k.getCurrentFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, k.fileMode)
+	if err != nil {
+		return fmt.Errorf("failed to set crash output, caused by %w", err)
+	}
+	// The runtime keeps its own duplicate of the file descriptor
+	defer f.Close()
+	file, ok := f.(*os.File)
+	if !ok {
+		return fmt.Errorf("failed to set crash output, the current log file is not on the file system of the operating system")
+	}
+	if err := debug.SetCrashOutput(file, debug.CrashOptions{}); err != nil {
+		return fmt.Errorf("failed to set crash output, caused by %w", err)
+	}
+	return nil
+}
+
+// Move the crash output to the new current log file after a rotation, if the Keeper has it.
+func (k *Keeper) followCrashOutput() {
+	crashOutput.mu.Lock()
+	defer crashOutput.mu.Unlock()
+	if crashOutput.keeper != k {
+		return
+	}
+	if err := k.setCrashOutputFile(); err != nil {
+		k.handleError(err)
+	}
+}
+
+// Stop the crash output of the process if the Keeper has it.
+func (k *Keeper) releaseCrashOutput() {
+	crashOutput.mu.Lock()
+	defer crashOutput.mu.Unlock()
+	if crashOutput.keeper != k {
+		return
+	}
+	crashOutput.keeper = nil
+	_ = debug.SetCrashOutput(nil, debug.CrashOptions{})
+}
@@ -0,0 +1,62 @@
+package lorekeeper
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestKeeperRecoverPanic(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-recover-panic"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected the panic to go on with %q got %v", "boom", r)
+			}
+		}()
+		defer k.RecoverPanic()
+		panic("boom")
+	}()
+
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	if reason := k.LastRotationReason(); reason != RotationPanic {
+		t.Errorf("expected reason %q got %q", RotationPanic, reason)
+	}
+	content, err := os.ReadFile(archives[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !strings.HasPrefix(string(content), "panic: boom\n\n") || !strings.Contains(string(content), "TestKeeperRecoverPanic") {
+		t.Errorf("expected the panic and its stack trace got %q", content)
+	}
+}
+
+func TestKeeperSetCrashOutput(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-crash-output"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.SetCrashOutput(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	crashOutput.mu.Lock()
+	defer crashOutput.mu.Unlock()
+	if crashOutput.keeper != nil {
+		t.Errorf("expected the crash output to be released on close")
+	}
+}
@@ -0,0 +1,88 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ErrCurrentFileLost is reported to the error handler when the current log file was removed, replaced or truncated
+// by another process, see [WithCurrentFileCheck].
+var ErrCurrentFileLost = errors.New("current log file lost")
+
+// Check every given number of messages that the current log file is still the one at its path,
+// since an operator or a misconfigured logrotate removing or truncating it leaves the Keeper writing
+// into a file that nobody can read anymore, with a size that no longer matches the one on disk.
+// A current log file that was removed or replaced is reopened at its path, creating it if needed,
+// and a truncated one is reopened to reset its size, the messages waiting in the buffer of [WithBufferSize] are kept.
+// Each recovery is counted in [Stats.LostCurrentFiles] and reported to the error handler with an error wrapping
+// [ErrCurrentFileLost], see [WithErrorHandler]. Set every to 1 to check before every message,
+// a larger value trades how many messages may be lost for the cost of a stat call.
+// Only a removal and a truncation can be detected with another [FS] than the one of the operating system.
+// Set every < 1 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithCurrentFileCheck(100))
+func WithCurrentFileCheck(every int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.currentFileCheck = max(every, 0)
+		k.uncheckedWrites = 0
+		return k, nil
+	}
+}
+
+// Reopen the current log file if it was lost since the last check, see [WithCurrentFileCheck].
+func (k *Keeper) checkCurrentFile() {
+	if k.currentFileCheck <= 0 || k.currentFile == nil {
+		return
+	}
+	k.uncheckedWrites++
+	if k.uncheckedWrites < k.currentFileCheck {
+		return
+	}
+	k.uncheckedWrites = 0
+
+	what := k.currentFileLoss()
+	if len(what) == 0 {
+		return
+	}
+	path := k.getCurrentFilePath()
+	// The buffer is not flushed into the lost file, it goes to the reopened one instead
+	_ = k.currentFile.Close()
+	k.currentFile = nil
+	k.stats.LostCurrentFiles++
+	if err := k.openCurrentFile(); err != nil {
+		k.handleError(fmt.Errorf("failed to recover current log file %q that was %s, caused by %w", path, what, errors.Join(ErrCurrentFileLost, err)))
+		return
+	}
+	k.handleError(fmt.Errorf("current log file %q was %s, reopened it, caused by %w", path, what, ErrCurrentFileLost))
+}
+
+// Tell how the current log file was lost, empty if it was not.
+func (k *Keeper) currentFileLoss() string {
+	file, ok := k.currentFile.(fs.File)
+	if !ok {
+		return ""
+	}
+	opened, err := file.Stat()
+	if err != nil {
+		return ""
+	}
+	onDisk, err := k.fsys.Stat(k.getCurrentFilePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return "removed"
+	}
+	if err != nil {
+		return ""
+	}
+	// Only the file system of the operating system tells whether two files are the same
+	if _, ok := k.fsys.(osFS); ok && !os.SameFile(opened, onDisk) {
+		return "replaced"
+	}
+	if int(onDisk.Size()) < k.currentFileSize-len(k.writeBuf) {
+		return "truncated"
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+//go:build unix
+
+package lorekeeper
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithCurrentFileCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		lose func(path string) error
+		want string
+	}{
+		{name: "removed", lose: os.Remove, want: "def\n"},
+		{name: "replaced", lose: func(path string) error {
+			if err := os.Rename(path, path+".old"); err != nil {
+				return err
+			}
+			return os.WriteFile(path, []byte("other\n"), 0644)
+		}, want: "other\ndef\n"},
+		{name: "truncated", lose: func(path string) error { return os.Truncate(path, 0) }, want: "def\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			folder := t.TempDir()
+			var errs []error
+			k, err := New(
+				WithFolder(folder),
+				WithName("test-current-file-check"),
+				WithCurrentFileCheck(1),
+				WithErrorHandler(func(err error) { errs = append(errs, err) }),
+			)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			defer k.Close()
+			if _, err := k.Write([]byte("abc\n")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+
+			current := filepath.Join(folder, "test-current-file-check.log")
+			if err := tc.lose(current); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if _, err := k.Write([]byte("def\n")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			b, err := os.ReadFile(current)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if string(b) != tc.want {
+				t.Errorf("expected %q got %q", tc.want, b)
+			}
+			if got := k.Stats(); got.LostCurrentFiles != 1 || got.CurrentFileSize != int64(len(tc.want)) {
+				t.Errorf("expected 1 lost current file of %d bytes got %d of %d bytes", len(tc.want), got.LostCurrentFiles, got.CurrentFileSize)
+			}
+			if len(errs) != 1 || !errors.Is(errs[0], ErrCurrentFileLost) {
+				t.Errorf("expected an error wrapping ErrCurrentFileLost got %v", errs)
+			}
+		})
+	}
+}
+
+func TestWithCurrentFileCheckEvery(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-current-file-check-every"), WithCurrentFileCheck(3))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	current := filepath.Join(folder, "test-current-file-check-every.log")
+	if err := os.Remove(current); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// The second message still goes to the removed file, the third one is checked
+	if _, err := k.Write([]byte("def\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(current); err == nil {
+		t.Fatalf("expected the current log file to be checked every 3 messages")
+	}
+	if _, err := k.Write([]byte("ghi\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if b, err := os.ReadFile(current); err != nil || string(b) != "ghi\n" {
+		t.Errorf("expected %q and no error got %q and %v", "ghi\n", b, err)
+	}
+}
@@ -0,0 +1,91 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Set the file name layout of the current log file, such as "{{ .name }}-{{ .hostname }}{{ .extension }}",
+// for the ingestion agents that key on file name patterns.
+// If the layout is empty the name is "{{ .name }}{{ .extension }}", which is the default.
+// The layout is parsed using the [text/template] package, the supported arguments are:
+//   - {{ .name }} the name of the Keeper.
+//   - {{ .extension }} the extension of the file.
+//   - {{ .time }} the time when the current log file is started, formatted with [WithTimeLayout].
+//   - {{ .pid }} the PID of the process.
+//   - {{ .hostname }} the hostname of the machine, see [WithArchiveNameLayout].
+//   - any key set with [WithTemplateData], such as {{ .region }}.
+//
+// The name is resolved when the options of the Keeper are applied and after every rotation,
+// see [Keeper.CurrentFilePath]. A current log file left under another name, such as by a previous process
+// with {{ .time }} in the layout, is not archived. The name must not match the archive name layout.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithName("api"),
+//		lorekeeper.WithCurrentNameLayout("{{ .name }}-{{ .hostname }}{{ .extension }}"),
+//	)
+func WithCurrentNameLayout(layout string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if len(layout) == 0 {
+			k.currentNameLayout = nil
+			k.currentNameLayoutText = ""
+			return k, nil
+		}
+		templ, err := template.New("lorekeeper-current-template").Parse(layout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set current name layout, caused by %w", err)
+		}
+		k.currentNameLayout = templ
+		k.currentNameLayoutText = layout
+		return k, nil
+	}
+}
+
+// Resolve the name of the current log file, see [WithCurrentNameLayout].
+func (k *Keeper) applyCurrentNameLayout() error {
+	name, err := k.renderCurrentName()
+	if err != nil {
+		return err
+	}
+	k.currentName = name
+	return nil
+}
+
+// Render the name of a current log file started now.
+func (k *Keeper) renderCurrentName() (string, error) {
+	if k.currentNameLayout == nil {
+		return k.name + k.extension, nil
+	}
+	data := make(map[string]any, len(k.templateData)+5)
+	for key, value := range k.templateData {
+		data[key] = value
+	}
+	data["name"] = k.name
+	data["extension"] = k.extension
+	data["time"] = k.now().Format(k.timeLayout)
+	withNameVars(data, "", strconv.Itoa(os.Getpid()), layoutHostname())
+
+	var buff bytes.Buffer
+	if err := k.currentNameLayout.Execute(&buff, data); err != nil {
+		return "", fmt.Errorf("failed to set current name layout, caused by %w", err)
+	}
+	name := buff.String()
+	if len(name) == 0 || strings.ContainsAny(name, "/\\\x00") {
+		return "", fmt.Errorf("failed to set current name layout, %q is not a valid file name", name)
+	}
+	if k.archiveNameLayout != nil {
+		if pattern, err := k.renderArchiveGlobPattern(); err == nil {
+			if match, _ := filepath.Match(pattern, name); match {
+				return "", fmt.Errorf("failed to set current name layout, %q matches the archive name layout", name)
+			}
+		}
+	}
+	return name, nil
+}
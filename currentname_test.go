@@ -0,0 +1,58 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeeperWithCurrentNameLayout(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-current-name"),
+		WithNowFunc(func() time.Time { return now }),
+		WithTimeLayout("2006-01-02"),
+		WithCurrentNameLayout("{{ .name }}-{{ .hostname }}-{{ .time }}{{ .extension }}"),
+		WithArchiveNameLayout("{{ .name }}-{{ .hostname }}-{{ .time }}{{ .extension }}.{{ .seq }}"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	expected := filepath.Join(folder, "test-current-name-"+layoutHostname()+"-2024-03-01.log")
+	if path := k.CurrentFilePath(); path != expected {
+		t.Errorf("expected current file %q got %q", expected, path)
+	}
+	if _, err := k.Write([]byte("msg\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The next current log file is named after the time of the rotation
+	now = now.AddDate(0, 0, 1)
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	expected = filepath.Join(folder, "test-current-name-"+layoutHostname()+"-2024-03-02.log")
+	if path := k.CurrentFilePath(); path != expected {
+		t.Errorf("expected current file %q got %q", expected, path)
+	}
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	if archives[0].Size != 4 {
+		t.Errorf("expected the archive to contain the first current log file got %d bytes", archives[0].Size)
+	}
+
+	// The current log file must not look like an archive
+	if _, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-current-name-conflict"),
+		WithCurrentNameLayout("{{ .time }}-{{ .name }}{{ .extension }}"),
+	); err == nil {
+		t.Errorf("expected error since the current name matches the archive name layout")
+	}
+}
@@ -0,0 +1,122 @@
+package lorekeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// The default number of deletions kept in memory, see [WithDeletionHistory].
+const defaultDeletionHistory = 32
+
+// A DeletionPolicy tells which retention limit deleted an archive, see [Deletion].
+type DeletionPolicy string
+
+const (
+	// The archive was deleted because the archives exceeded the max number of files, see [WithMaxFiles].
+	DeletionMaxFiles DeletionPolicy = "max-files"
+	// The archive was deleted because the archives exceeded the total size, see [WithTotalSize].
+	DeletionTotalSize DeletionPolicy = "total-size"
+	// The archive was deleted because the archives of the [Group] exceeded its total size.
+	DeletionGroupTotalSize DeletionPolicy = "group-total-size"
+	// The archive was deleted because it was older than the max age, see [WithMaxAge].
+	DeletionMaxAge DeletionPolicy = "max-age"
+	// The archive was deleted because the retention policy evicted it, see [WithRetentionPolicy].
+	DeletionRetentionPolicy DeletionPolicy = "retention-policy"
+	// The archive was deleted to keep free disk space, see [WithMinDiskFree].
+	DeletionDiskFree DeletionPolicy = "disk-free"
+	// The archive was deleted once uploaded, see [WithUploader].
+	DeletionUploaded DeletionPolicy = "uploaded"
+)
+
+// A Deletion records the removal of an archive by the retention, see [Keeper.Deletions].
+type Deletion struct {
+	// The path of the removed archive.
+	Path string `json:"path"`
+	// The size of the removed archive in bytes.
+	Size int `json:"size"`
+	// The retention limit that expired the archive.
+	Policy DeletionPolicy `json:"policy"`
+	// A human readable explanation, such as "12 archives over the max files of 10".
+	Reason string `json:"reason"`
+	// When the archive was removed.
+	Time time.Time `json:"time"`
+}
+
+// Keep the last n deletions of archives in memory, see [Keeper.Deletions].
+// Set n to zero to keep none, is 32 by default.
+func WithDeletionHistory(n int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if n < 0 {
+			return nil, fmt.Errorf("failed to set deletion history, size must not be negative")
+		}
+		k.deletionHistory = n
+		if len(k.deletions) > n {
+			k.deletions = slices.Clone(k.deletions[len(k.deletions)-n:])
+		}
+		return k, nil
+	}
+}
+
+// Append a JSON line for every archive removed by the retention to w, such as a file or another Keeper,
+// answering "where did yesterday's log go?" long after [Keeper.Deletions] forgot about it.
+// The line is written while the Keeper is locked, so w must not call the methods of the Keeper.
+// The write errors go to the handler of [WithErrorHandler].
+// A nil writer disables the deletion log, which is the default.
+//
+// Example usage:
+//
+//	audit, _ := lorekeeper.New(lorekeeper.WithName("deletions"))
+//	keeper, err := lorekeeper.New(lorekeeper.WithName("app"), lorekeeper.WithDeletionLog(audit))
+func WithDeletionLog(w io.Writer) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.deletionLog = w
+		return k, nil
+	}
+}
+
+// Get the last deletions of archives by the retention, from oldest to newest, see [WithDeletionHistory].
+func (k *Keeper) Deletions() []Deletion {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return slices.Clone(k.deletions)
+}
+
+// Mark the archive as expired by the given policy, the deletion is recorded once it is removed.
+func expire(archive *fileInfo, policy DeletionPolicy, reason string) *fileInfo {
+	archive.expiredBy = policy
+	archive.expiredReason = reason
+	return archive
+}
+
+// Record the removal of an expired archive, and remove the subdirectories it leaves empty.
+func (k *Keeper) recordDeletion(archive *fileInfo) {
+	k.removeEmptyArchiveDirs(archive.filePath)
+	k.notifyArchiveRemoved(archive.filePath)
+	deletion := Deletion{
+		Path:   archive.filePath,
+		Size:   archive.size,
+		Policy: archive.expiredBy,
+		Reason: archive.expiredReason,
+		Time:   k.now(),
+	}
+	if k.deletionHistory > 0 {
+		if len(k.deletions) >= k.deletionHistory {
+			k.deletions = slices.Delete(k.deletions, 0, len(k.deletions)-k.deletionHistory+1)
+		}
+		k.deletions = append(k.deletions, deletion)
+	}
+	if k.deletionLog == nil {
+		return
+	}
+	line, err := json.Marshal(deletion)
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to encode deletion of %q, caused by %w", archive.filePath, err))
+		return
+	}
+	if _, err := k.deletionLog.Write(append(line, '\n')); err != nil {
+		k.handleError(fmt.Errorf("failed to write deletion of %q, caused by %w", archive.filePath, err))
+	}
+}
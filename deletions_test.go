@@ -0,0 +1,51 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestKeeperDeletions(t *testing.T) {
+	var audit bytes.Buffer
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-deletions"),
+		WithMaxFiles(1),
+		WithDeletionHistory(1),
+		WithDeletionLog(&audit),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for range 3 {
+		if _, err := k.Write([]byte("message\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	deletions := k.Deletions()
+	if len(deletions) != 1 {
+		t.Fatalf("expected the last deletion only got %+v", deletions)
+	}
+	if deletions[0].Policy != DeletionMaxFiles || deletions[0].Size != len("message\n") || deletions[0].Reason == "" {
+		t.Errorf("expected a max files deletion got %+v", deletions[0])
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(audit.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines in the deletion log got %q", audit.String())
+	}
+	var last Deletion
+	if err := json.Unmarshal(lines[1], &last); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if last.Path != deletions[0].Path || last.Policy != DeletionMaxFiles {
+		t.Errorf("expected %+v got %+v", deletions[0], last)
+	}
+}
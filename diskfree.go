@@ -0,0 +1,73 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Delete the oldest archives on rotation until the filesystem of the log folder has at least size bytes free,
+// so that logging does not fill the disk of small nodes where a static [WithTotalSize] is hard to tune.
+// The current log file is never deleted, so the disk may still fill up if the archives are not enough.
+// If both this and [WithMinDiskFreePercent] are set, the Keeper keeps whichever is larger free.
+// It is supported on Linux, macOS, FreeBSD, DragonFly BSD and Windows.
+// Set size < 1 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithMinDiskFree(2 * lorekeeper.Gb))
+func WithMinDiskFree(size int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if size > 0 {
+			if err := checkDiskUsage(); err != nil {
+				return nil, fmt.Errorf("failed to set min disk free, caused by %w", err)
+			}
+		}
+		k.minDiskFree = max(size, 0)
+		return k, nil
+	}
+}
+
+// Like [WithMinDiskFree], but as a percentage of the size of the filesystem, such as 10 for 10%.
+// Set percent <= 0 to disable, is disabled by default.
+func WithMinDiskFreePercent(percent float64) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if percent >= 100 {
+			return nil, fmt.Errorf("failed to set min disk free percent, expected less than 100 got %v", percent)
+		}
+		if percent > 0 {
+			if err := checkDiskUsage(); err != nil {
+				return nil, fmt.Errorf("failed to set min disk free percent, caused by %w", err)
+			}
+		}
+		k.minDiskFreePercent = max(percent, 0)
+		return k, nil
+	}
+}
+
+// Get the free and total bytes of the filesystem of the path, replaced by the tests.
+var diskUsage = getDiskUsage
+
+// Check whether the disk usage can be measured on this platform.
+func checkDiskUsage() error {
+	if _, _, err := diskUsage("."); errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+	return nil
+}
+
+// Get the number of bytes to free for the log folder to reach the min disk free, zero if there is enough.
+func (k *Keeper) diskDeficit() int64 {
+	if k.minDiskFree <= 0 && k.minDiskFreePercent <= 0 {
+		return 0
+	}
+	free, total, err := diskUsage(k.folder)
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to get disk usage of %q, caused by %w", k.folder, err))
+		return 0
+	}
+	want := max(uint64(k.minDiskFree), uint64(float64(total)*k.minDiskFreePercent/100))
+	if free >= want {
+		return 0
+	}
+	return int64(want - free)
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || dragonfly || windows)
+
+package lorekeeper
+
+import "errors"
+
+// Measuring the disk usage is not supported on this platform, see [WithMinDiskFree].
+func getDiskUsage(path string) (free, total uint64, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
@@ -0,0 +1,94 @@
+package lorekeeper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKeeperWithMinDiskFree(t *testing.T) {
+	if _, _, err := getDiskUsage(t.TempDir()); errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("disk usage is not supported on this platform")
+	}
+	// Fake a filesystem of 1000 bytes, whose free space follows the archives
+	var free uint64 = 1000
+	diskUsage = func(string) (uint64, uint64, error) { return free, 1000, nil }
+	defer func() { diskUsage = getDiskUsage }()
+
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-min-disk-free"),
+		WithMinDiskFree(700),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for range 4 {
+		if _, err := k.Write([]byte("0123456789\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		free -= 100
+	}
+	// 300 bytes are missing for the 700 bytes to keep free, the archives are 11 bytes each
+	free -= 200
+	if _, err := k.Write([]byte("0123456789\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if got := len(k.Archives()); got != 0 {
+		t.Errorf("expected every archive to be deleted got %d", got)
+	}
+	deletions := k.Deletions()
+	if len(deletions) != 5 {
+		t.Fatalf("expected 5 deletions got %d", len(deletions))
+	}
+	for _, deletion := range deletions {
+		if deletion.Policy != DeletionDiskFree {
+			t.Errorf("expected policy %q got %q", DeletionDiskFree, deletion.Policy)
+		}
+	}
+}
+
+func TestKeeperWithMinDiskFreePercent(t *testing.T) {
+	if _, _, err := getDiskUsage(t.TempDir()); errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("disk usage is not supported on this platform")
+	}
+	var free uint64 = 200
+	diskUsage = func(string) (uint64, uint64, error) { return free, 1000, nil }
+	defer func() { diskUsage = getDiskUsage }()
+
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-min-disk-free-percent"),
+		WithMinDiskFreePercent(10),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"first\n", "second\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		// 5 bytes short for the next rotation, the oldest archive is enough
+		free = 95
+	}
+	if got := len(k.Archives()); got != 1 {
+		t.Errorf("expected 1 archive got %d", got)
+	}
+
+	if _, err := New(WithMinDiskFreePercent(100)); err == nil {
+		t.Errorf("expected error for a percent of 100")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd || dragonfly
+
+package lorekeeper
+
+import "syscall"
+
+// Get the free and total bytes of the filesystem of the path, with statfs(2).
+func getDiskUsage(path string) (free, total uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	// The blocks available to unprivileged users, not the ones reserved for root
+	return uint64(stat.Bavail) * uint64(stat.Bsize), uint64(stat.Blocks) * uint64(stat.Bsize), nil
+}
@@ -73,6 +73,10 @@
 // Every time the [Keeper.Write] is invoked, the Keeper will first check if the current log should be rotated before writing the message to the log.
 //
 // A rotation will happen if the current log size exceeds the max size, configured by using [WithMaxSize] option.
+// A message larger than the max size is split into chunks that fill up consecutive log files, so that a log file does not exceed the max size.
+// A log file can still exceed it when its rotation is skipped, such as when the archive of [WithDateext] already exists
+// or while the rotations are held by [Keeper.WithRotationPaused] or [WithRotationCoalescing],
+// and when a message is never split, such as with [WithHashChain].
 // During a rotation, the Keeper archives the current log by closing and renaming it based on the name template configured by using [WithArchiveNameLayout] and then opens a new log to replace the archived one.
 // Afterwards if the number of archives exceeds the maximum number of allowed files, configured by [WithMaxFiles], the Keeper will keep deleting the oldest archives based on its last modified time until the number of archives is smaller than the configured value.
 // A rotation can also happen depending on a cron schedule configured with [WithCron].
@@ -85,6 +89,7 @@
 // Tl;dr
 //
 //   - To avoid races make sure to give Keepers across multiple goroutines or multiple processes unique names (and/or different folders), and specify all the available arguments in [WithArchiveNameLayout].
+//   - [WithUniqueSuffix] gives each process a unique name in one option.
 //   - To avoid goroutine and memory leakage use [Keeper.Close] when a Keeper is no longer needed.
 //
 // Designed to be configurable as reasonably as possible.
@@ -93,6 +98,7 @@
 // The [Keeper] struct holds a [sync.Mutex] and use it with any [Keeper.Write], [Keeper.Rotate], [Keeper.Close] so it is safe to use one single copy of Keeper in multiple gorountines.
 // It is also safe to use multiple copies of a [Keeper] with the same name in a single process.
 // Lorekeeper ensures this by keeping a registry of all created Keepers via [New] so that in one process there is no more than one copy of Keepers with the same name running at the same time.
+// Keepers created with [WithRegistry] are kept in their own [Registry] instead, and are only unique by name within that registry.
 //
 // However, a data race can still happen if the Keeper is not configured properly. See the below example:
 //
@@ -0,0 +1,109 @@
+package lorekeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Write a summary line into the log at most once per interval whenever messages were discarded since the last one,
+// such as "lorekeeper dropped 42 records in the last 1m0s due to rate limit (40), sampling (2)",
+// so that the gap is visible in the log files and their archives, not only in [Keeper.Stats].
+// The discarded messages are the ones dropped by the rate limit of [WithRateLimitPolicy], left out by [WithSampling],
+// dropped by a full queue of [WithAsyncWrites], or rejected by [WithQuota] or [WithHardMaxSize].
+// The summary is written before the next message once the interval elapsed, and when the Keeper is closed,
+// it is wrapped in a JSON object when the messages are validated with [WithValidateJSON].
+// It is neither sampled nor rate limited, and it is left out while the Keeper is paused, see [Keeper.Pause].
+// Set interval <= 0 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithRateLimit(1<<20, 1<<20), lorekeeper.WithDropSummary(time.Minute))
+func WithDropSummary(interval time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.dropSummary = max(interval, 0)
+		return k, nil
+	}
+}
+
+// The number of messages discarded by each policy, see [WithDropSummary].
+type dropCounts struct {
+	rateLimited uint64
+	sampledOut  uint64
+	queueFull   uint64
+	quota       uint64
+	hardMax     uint64
+}
+
+// Get the number of messages discarded by each policy since the Keeper started.
+func (k *Keeper) dropCounts() dropCounts {
+	return dropCounts{
+		rateLimited: k.rateDropped.Load(),
+		sampledOut:  k.stats.SampledOut,
+		queueFull:   k.asyncDropped.Load(),
+		quota:       k.stats.QuotaRejections,
+		hardMax:     k.stats.HardMaxRejections,
+	}
+}
+
+// Describe the messages discarded since the prev counts over the elapsed time, or return an empty string if there are none.
+func (c dropCounts) summarySince(prev dropCounts, elapsed time.Duration) string {
+	reasons := []struct {
+		name  string
+		count uint64
+	}{
+		{"rate limit", c.rateLimited - prev.rateLimited},
+		{"sampling", c.sampledOut - prev.sampledOut},
+		{"full queue", c.queueFull - prev.queueFull},
+		{"quota", c.quota - prev.quota},
+		{"hard max size", c.hardMax - prev.hardMax},
+	}
+	var total uint64
+	var causes []string
+	for _, reason := range reasons {
+		if reason.count > 0 {
+			total += reason.count
+			causes = append(causes, fmt.Sprintf("%s (%d)", reason.name, reason.count))
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("lorekeeper dropped %d records in the last %s due to %s", total, elapsed.Round(time.Second), strings.Join(causes, ", "))
+}
+
+// Write the summary of the messages discarded since the last one if the interval of [WithDropSummary] elapsed,
+// or regardless of the interval if force is true.
+func (k *Keeper) summarizeDrops(force bool) {
+	if k.dropSummary <= 0 || k.paused || k.closed {
+		return
+	}
+	now := k.now()
+	if k.dropSummaryAt.IsZero() {
+		k.dropSummaryAt = now
+	}
+	if !force && now.Sub(k.dropSummaryAt) < k.dropSummary {
+		return
+	}
+	counts := k.dropCounts()
+	summary := counts.summarySince(k.dropSummaryCounts, now.Sub(k.dropSummaryAt))
+	k.dropSummaryAt, k.dropSummaryCounts = now, counts
+	if len(summary) == 0 {
+		return
+	}
+	line := []byte(summary + "\n")
+	if k.jsonPolicy != jsonOff {
+		wrapped, err := json.Marshal(struct {
+			Message string `json:"message"`
+		}{Message: summary})
+		if err != nil {
+			k.handleError(fmt.Errorf("failed to wrap drop summary, caused by %w", err))
+			return
+		}
+		line = append(wrapped, '\n')
+	}
+	if _, err := k.writeMessage(line); err != nil {
+		k.handleError(fmt.Errorf("failed to write drop summary, caused by %w", err))
+	}
+}
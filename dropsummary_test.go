@@ -0,0 +1,61 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithDropSummary(t *testing.T) {
+	folder := t.TempDir()
+	now := time.Now()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-drop-summary"),
+		WithNowFunc(func() time.Time { return now }),
+		WithSampling(0, func(msg []byte) bool { return bytes.HasPrefix(msg, []byte("debug")) }),
+		WithHardMaxSize(128, HardMaxReject),
+		WithDropSummary(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{"debug 1\n", "info 1\n", "debug 2\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if _, err := k.Write(bytes.Repeat([]byte("x"), 256)); err == nil {
+		t.Fatalf("expected an error for a message over the hard max size")
+	}
+
+	// Not summarized before the interval elapsed
+	now = now.Add(30 * time.Second)
+	if _, err := k.Write([]byte("info 2\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("info 3\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	want := "lorekeeper dropped 3 records in the last 1m0s due to sampling (2), hard max size (1)\ninfo 3\n"
+	if b, err := os.ReadFile(filepath.Join(folder, "test-drop-summary.log")); err != nil || string(b) != want {
+		t.Errorf("expected %q and no error got %q and %v", want, b, err)
+	}
+
+	// Nothing dropped since the last summary
+	now = now.Add(time.Minute)
+	if _, err := k.Write([]byte("info 4\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	want += "info 4\n"
+	if b, err := os.ReadFile(filepath.Join(folder, "test-drop-summary.log")); err != nil || string(b) != want {
+		t.Errorf("expected %q and no error got %q and %v", want, b, err)
+	}
+}
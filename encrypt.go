@@ -0,0 +1,309 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The format of the archives encrypted by [WithEncryption]:
+//
+//	header: magic "LKE1" | 8 random bytes, the nonce prefix
+//	chunks: 4 bytes big-endian length of the sealed chunk | AES-GCM sealed chunk
+//
+// Every chunk seals up to 64 Kb of the archive, the nonce of the chunk i is the nonce prefix
+// followed by i as 4 bytes big-endian, with the highest bit set for the last chunk,
+// so that a truncated or reordered archive fails to decrypt instead of being silently cut short.
+const (
+	encryptionMagic       = "LKE1"
+	encryptionPrefixSize  = 8
+	encryptionChunkSize   = 64 * Kb
+	encryptionLastChunk   = 1 << 31
+	encryptionExt         = ".enc"
+	encryptionHeaderSize  = len(encryptionMagic) + encryptionPrefixSize
+	encryptionLengthSize  = 4
+	maxEncryptionChunkNum = encryptionLastChunk - 1
+)
+
+// Returned, wrapped, when an encrypted archive can not be authenticated,
+// because it was truncated, modified, or encrypted with another key.
+var ErrArchiveCorrupted = errors.New("encrypted archive is corrupted or the key is wrong")
+
+// Encrypt the archives at rest with AES-GCM, using the given key of 16, 24 or 32 bytes for AES-128, AES-192 or AES-256.
+// The archives are encrypted during their rotation, after their compression if any,
+// and get the ".enc" extension after the one of the compression, such as "app.log.gz.enc".
+// The current log file is not encrypted. The archives are decrypted on the fly when read by [Keeper.Between],
+// [Inspector] and [NewBrowser], or with [DecryptArchive].
+// The key is not stored anywhere, archives encrypted with a lost key can not be recovered.
+// A nil key disables the encryption, which is the default.
+//
+// Example usage:
+//
+//	key, err := hex.DecodeString(os.Getenv("LOG_ENCRYPTION_KEY"))
+//	if err != nil {
+//		return err
+//	}
+//	keeper, err := lorekeeper.New(lorekeeper.WithGzip(), lorekeeper.WithEncryption(key))
+func WithEncryption(key []byte) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if key == nil {
+			k.encryption = nil
+			return k, nil
+		}
+		aead, err := newArchiveAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set encryption, caused by %w", err)
+		}
+		k.encryption = &encryptingCompressor{key: append([]byte(nil), key...), aead: aead}
+		return k, nil
+	}
+}
+
+// Decrypt the archive at path encrypted by [WithEncryption] with the given key, writing its content to dst.
+// The content is still compressed if the archive was, such as a gzip stream for an "app.log.gz.enc" archive.
+// It fails with an error wrapping [ErrArchiveCorrupted] if the archive can not be authenticated,
+// in which case dst may have received the chunks before the corrupted one.
+//
+// Example usage:
+//
+//	var compressed bytes.Buffer
+//	if err := lorekeeper.DecryptArchive("/var/log/app/app-2024-01-01.log.gz.enc", key, &compressed); err != nil {
+//		return err
+//	}
+//	content, err := gzip.NewReader(&compressed)
+func DecryptArchive(path string, key []byte, dst io.Writer) error {
+	aead, err := newArchiveAEAD(key)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %q, caused by %w", path, err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %q, caused by %w", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(dst, newDecryptingReader(aead, f)); err != nil {
+		return fmt.Errorf("failed to decrypt %q, caused by %w", path, err)
+	}
+	return nil
+}
+
+func newArchiveAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Wrap the compressor of the Keeper, if any, so that the archives are encrypted after being compressed.
+func (k *Keeper) applyEncryption() error {
+	if enc, ok := k.compressor.(*encryptingCompressor); ok {
+		k.compressor = enc.inner
+	}
+	if k.encryption == nil {
+		k.compressionExt = ""
+		if k.compressor != nil {
+			k.compressionExt = k.compressor.Extension()
+		}
+		return nil
+	}
+	enc := *k.encryption
+	enc.inner = k.compressor
+	k.compressor = &enc
+	k.compressionExt = enc.Extension()
+	return nil
+}
+
+// An encryptingCompressor encrypts what its inner compressor, if any, produces.
+type encryptingCompressor struct {
+	key   []byte
+	aead  cipher.AEAD
+	inner Compressor
+}
+
+// Make sure that encryptingCompressor implements the [Decompressor] interface.
+var _ Decompressor = (*encryptingCompressor)(nil)
+
+func (c *encryptingCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	var prefix [encryptionPrefixSize]byte
+	if _, err := rand.Read(prefix[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce, caused by %w", err)
+	}
+	if _, err := io.WriteString(w, encryptionMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(prefix[:]); err != nil {
+		return nil, err
+	}
+	enc := &encryptingWriter{aead: c.aead, w: w, prefix: prefix, buf: make([]byte, 0, encryptionChunkSize)}
+	if c.inner == nil {
+		return enc, nil
+	}
+	compressor, err := c.inner.NewWriter(enc)
+	if err != nil {
+		return nil, err
+	}
+	return &chainedWriteCloser{WriteCloser: compressor, next: enc}, nil
+}
+
+func (c *encryptingCompressor) Extension() string {
+	if c.inner == nil {
+		return encryptionExt
+	}
+	return c.inner.Extension() + encryptionExt
+}
+
+func (c *encryptingCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	dec := newDecryptingReader(c.aead, r)
+	if c.inner == nil {
+		return io.NopCloser(dec), nil
+	}
+	return newDecompressingReader(c.inner, dec)
+}
+
+// A chainedWriteCloser closes the next writer once its own writer is closed.
+type chainedWriteCloser struct {
+	io.WriteCloser
+	next io.Closer
+}
+
+func (w *chainedWriteCloser) Close() error {
+	return errors.Join(w.WriteCloser.Close(), w.next.Close())
+}
+
+// An encryptingWriter seals what is written to it chunk by chunk, the last chunk is sealed on Close.
+type encryptingWriter struct {
+	aead   cipher.AEAD
+	w      io.Writer
+	prefix [encryptionPrefixSize]byte
+	buf    []byte
+	sealed []byte
+	chunk  uint32
+	closed bool
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more comes, since the last chunk must be sealed as such
+		if len(e.buf) == encryptionChunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):encryptionChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (e *encryptingWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.seal(true)
+}
+
+func (e *encryptingWriter) seal(last bool) error {
+	if e.chunk > maxEncryptionChunkNum {
+		return fmt.Errorf("failed to encrypt, the archive is too large")
+	}
+	nonce := chunkNonce(e.prefix, e.chunk, last)
+	e.sealed = binary.BigEndian.AppendUint32(e.sealed[:0], uint32(len(e.buf)+e.aead.Overhead()))
+	e.sealed = e.aead.Seal(e.sealed, nonce[:], e.buf, nil)
+	if _, err := e.w.Write(e.sealed); err != nil {
+		return fmt.Errorf("failed to write encrypted chunk, caused by %w", err)
+	}
+	e.buf = e.buf[:0]
+	e.chunk++
+	return nil
+}
+
+func chunkNonce(prefix [encryptionPrefixSize]byte, chunk uint32, last bool) [encryptionPrefixSize + 4]byte {
+	var nonce [encryptionPrefixSize + 4]byte
+	copy(nonce[:], prefix[:])
+	if last {
+		chunk |= encryptionLastChunk
+	}
+	binary.BigEndian.PutUint32(nonce[encryptionPrefixSize:], chunk)
+	return nonce
+}
+
+// A decryptingReader opens the chunks of an encrypted archive one by one.
+type decryptingReader struct {
+	aead    cipher.AEAD
+	r       *bufio.Reader
+	prefix  [encryptionPrefixSize]byte
+	started bool
+	done    bool
+	chunk   uint32
+	sealed  []byte
+	plain   []byte
+	unread  []byte
+}
+
+func newDecryptingReader(aead cipher.AEAD, r io.Reader) *decryptingReader {
+	return &decryptingReader{aead: aead, r: bufio.NewReader(r)}
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.unread) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.unread)
+	d.unread = d.unread[n:]
+	return n, nil
+}
+
+// Open the next chunk, which is the last one if nothing follows it.
+func (d *decryptingReader) next() error {
+	if !d.started {
+		var header [encryptionHeaderSize]byte
+		if _, err := io.ReadFull(d.r, header[:]); err != nil || string(header[:len(encryptionMagic)]) != encryptionMagic {
+			return fmt.Errorf("failed to read encryption header, caused by %w", ErrArchiveCorrupted)
+		}
+		copy(d.prefix[:], header[len(encryptionMagic):])
+		d.started = true
+	}
+	var length [encryptionLengthSize]byte
+	if _, err := io.ReadFull(d.r, length[:]); err != nil {
+		return fmt.Errorf("failed to read chunk %d, caused by %w", d.chunk, ErrArchiveCorrupted)
+	}
+	size := int(binary.BigEndian.Uint32(length[:]))
+	if size < d.aead.Overhead() || size > encryptionChunkSize+d.aead.Overhead() {
+		return fmt.Errorf("failed to read chunk %d, caused by %w", d.chunk, ErrArchiveCorrupted)
+	}
+	if cap(d.sealed) < size {
+		d.sealed = make([]byte, size)
+	}
+	d.sealed = d.sealed[:size]
+	if _, err := io.ReadFull(d.r, d.sealed); err != nil {
+		return fmt.Errorf("failed to read chunk %d, caused by %w", d.chunk, ErrArchiveCorrupted)
+	}
+	_, err := d.r.Peek(1)
+	last := errors.Is(err, io.EOF)
+	nonce := chunkNonce(d.prefix, d.chunk, last)
+	plain, err := d.aead.Open(d.plain[:0], nonce[:], d.sealed, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open chunk %d, caused by %w", d.chunk, ErrArchiveCorrupted)
+	}
+	d.plain = plain
+	d.unread = plain
+	d.done = last
+	d.chunk++
+	return nil
+}
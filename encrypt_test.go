@@ -0,0 +1,86 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWithEncryption(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	opts := []Opt{WithFolder(t.TempDir()), WithName("test-encryption"), WithEncryption(key), WithGzip()}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// Large enough to span several chunks
+	content := strings.Repeat("secret customer record\n", 10000)
+	if _, err := k.Write([]byte(content)); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archives := k.Archives()
+	if len(archives) != 1 || !strings.HasSuffix(archives[0].Path, ".gz.enc") {
+		t.Fatalf("expected 1 archive ending with .gz.enc got %v", archives)
+	}
+	raw, err := os.ReadFile(archives[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if bytes.Contains(raw, []byte("secret")) {
+		t.Errorf("expected the archive to be encrypted")
+	}
+
+	// The archives are read back decrypted
+	inspector, err := Open(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	reader, err := inspector.IncludeCurrentFile(false).Reader()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	read, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(read) != content {
+		t.Errorf("expected the archive to be read back, got %d bytes", len(read))
+	}
+
+	var compressed bytes.Buffer
+	if err := DecryptArchive(archives[0].Path, key, &compressed); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	gz, err := gzip.NewReader(&compressed)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if decrypted, err := io.ReadAll(gz); err != nil || string(decrypted) != content {
+		t.Errorf("expected the decrypted archive to match, got error %v", err)
+	}
+
+	// A wrong key or a truncated archive can not be authenticated
+	if err := DecryptArchive(archives[0].Path, bytes.Repeat([]byte{8}, 32), io.Discard); !errors.Is(err, ErrArchiveCorrupted) {
+		t.Errorf("expected ErrArchiveCorrupted for a wrong key got %v", err)
+	}
+	if err := os.WriteFile(archives[0].Path, raw[:len(raw)-100], 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := DecryptArchive(archives[0].Path, key, io.Discard); !errors.Is(err, ErrArchiveCorrupted) {
+		t.Errorf("expected ErrArchiveCorrupted for a truncated archive got %v", err)
+	}
+
+	if _, err := New(WithFolder(t.TempDir()), WithName("test-encryption-invalid"), WithEncryption([]byte("short"))); err == nil {
+		t.Errorf("expected error for an invalid key got nil")
+	}
+}
@@ -0,0 +1,128 @@
+package lorekeeper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+)
+
+// An ExportFormat is the output format of [Inspector.Export].
+type ExportFormat int
+
+const (
+	// One JSON object per line.
+	ExportJSONL ExportFormat = iota
+	// Comma-separated values with a header row.
+	ExportCSV
+)
+
+// Options of [Inspector.Export].
+type ExportOptions struct {
+	// The output format, the default value is [ExportJSONL].
+	Format ExportFormat
+	// Parse a record into its fields, required.
+	// Records that can not be parsed, for which Parse returns an error, are skipped.
+	Parse func(record []byte) (map[string]any, error)
+	// The columns of [ExportCSV], in order. Fields that are not in the columns are left out.
+	// If empty, the sorted fields of the first parsed record are used.
+	// This is ignored by [ExportJSONL].
+	Columns []string
+}
+
+// An ExportReport summarizes an export, see [Inspector.Export].
+type ExportReport struct {
+	// The number of records written to the output.
+	Records int
+	// The number of records skipped because they could not be parsed.
+	Skipped int
+}
+
+// Export the records of all the log files, from the oldest archive to the current log file,
+// parsed and normalized into JSON lines or CSV, so that old logs can be loaded into analytics tools
+// without external scripts. See [Keeper.Between] for what a record is.
+//
+// Example usage:
+//
+//	report, err := inspector.Export(os.Stdout, lorekeeper.ExportOptions{
+//		Format:  lorekeeper.ExportCSV,
+//		Columns: []string{"time", "level", "msg"},
+//		Parse: func(record []byte) (map[string]any, error) {
+//			fields := make(map[string]any)
+//			return fields, json.Unmarshal(record, &fields)
+//		},
+//	})
+func (i *Inspector) Export(w io.Writer, opts ExportOptions) (ExportReport, error) {
+	var report ExportReport
+	if opts.Parse == nil {
+		return report, fmt.Errorf("failed to export, a record parser is required")
+	}
+	if opts.Format != ExportJSONL && opts.Format != ExportCSV {
+		return report, fmt.Errorf("failed to export, unknown format %d", opts.Format)
+	}
+	paths, err := i.paths()
+	if err != nil {
+		return report, err
+	}
+
+	encoder := json.NewEncoder(w)
+	csvWriter := csv.NewWriter(w)
+	columns := opts.Columns
+	for _, path := range paths {
+		reader, err := i.openLogFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to open %q, caused by %w", path, err)
+		}
+		for record, err := range readRecords(reader, i.k.lengthPrefixed, i.k.recordPattern) {
+			if err != nil {
+				reader.Close()
+				return report, fmt.Errorf("failed to read %q, caused by %w", path, err)
+			}
+			fields, err := opts.Parse(record)
+			if err != nil {
+				report.Skipped++
+				continue
+			}
+
+			if opts.Format == ExportJSONL {
+				err = encoder.Encode(fields)
+			} else {
+				if report.Records == 0 {
+					if len(columns) == 0 {
+						columns = slices.Sorted(maps.Keys(fields))
+					}
+					if err := csvWriter.Write(columns); err != nil {
+						reader.Close()
+						return report, fmt.Errorf("failed to write CSV header, caused by %w", err)
+					}
+				}
+				row := make([]string, len(columns))
+				for idx, column := range columns {
+					if value, ok := fields[column]; ok && value != nil {
+						row[idx] = fmt.Sprint(value)
+					}
+				}
+				err = csvWriter.Write(row)
+			}
+			if err != nil {
+				reader.Close()
+				return report, fmt.Errorf("failed to write record, caused by %w", err)
+			}
+			report.Records++
+		}
+		reader.Close()
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return report, fmt.Errorf("failed to write CSV, caused by %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,79 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestInspectorExport(t *testing.T) {
+	opts := []Opt{
+		WithFolder(t.TempDir()),
+		WithName("test-export"),
+		WithMaxSize(40),
+		WithGzip(),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{
+		`{"level":"info","msg":"one"}` + "\n",
+		"not json\n",
+		`{"level":"error","msg":"two, three"}` + "\n",
+	} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	inspector, err := Open(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	parse := func(record []byte) (map[string]any, error) {
+		fields := make(map[string]any)
+		return fields, json.Unmarshal(record, &fields)
+	}
+	tests := []struct {
+		name string
+		opts ExportOptions
+		want string
+	}{
+		{
+			name: "JSONL",
+			opts: ExportOptions{Format: ExportJSONL, Parse: parse},
+			want: `{"level":"info","msg":"one"}` + "\n" + `{"level":"error","msg":"two, three"}` + "\n",
+		},
+		{
+			name: "CSV with columns",
+			opts: ExportOptions{Format: ExportCSV, Parse: parse, Columns: []string{"msg", "level", "missing"}},
+			want: "msg,level,missing\none,info,\n\"two, three\",error,\n",
+		},
+		{
+			name: "CSV without columns",
+			opts: ExportOptions{Format: ExportCSV, Parse: parse},
+			want: "level,msg\ninfo,one\nerror,\"two, three\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buff bytes.Buffer
+			report, err := inspector.Export(&buff, tt.opts)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if report != (ExportReport{Records: 2, Skipped: 1}) {
+				t.Errorf("unexpected report %+v", report)
+			}
+			if buff.String() != tt.want {
+				t.Errorf("expected %q got %q", tt.want, buff.String())
+			}
+		})
+	}
+
+	if _, err := inspector.Export(new(bytes.Buffer), ExportOptions{}); err == nil {
+		t.Errorf("expected error since the parser is missing")
+	}
+}
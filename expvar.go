@@ -0,0 +1,29 @@
+package lorekeeper
+
+import (
+	"expvar"
+	"fmt"
+)
+
+// Get an [expvar.Var] reporting the [Stats] of the Keeper as a JSON object, computed on every read,
+// such as to nest it in an [expvar.Map] of the Keepers of the process.
+func (k *Keeper) ExpvarStats() expvar.Var {
+	return expvar.Func(func() any { return k.Stats() })
+}
+
+// Publish the [Stats] of the Keeper as the expvar of the given name, so that they are served by /debug/vars
+// along with the other variables of the process, see [expvar]. It fails if the name is already published.
+// The variable can not be unpublished, so publish a Keeper that lives as long as the process.
+//
+// Example usage:
+//
+//	keeper, _ := lorekeeper.New(lorekeeper.WithName("app"))
+//	err := keeper.PublishExpvar("lorekeeper_app")
+//	// curl localhost:8080/debug/vars shows {"lorekeeper_app": {"BytesWritten": 1024, "Rotations": 2, ...}}
+func (k *Keeper) PublishExpvar(name string) error {
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("failed to publish expvar, %q is already published", name)
+	}
+	expvar.Publish(name, k.ExpvarStats())
+	return nil
+}
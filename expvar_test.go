@@ -0,0 +1,45 @@
+package lorekeeper
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// Counts the expvar names published by the tests, since expvar can not unpublish a name for the next runs of a test.
+var expvarRuns atomic.Int64
+
+// Get an expvar name unique to this run of the test.
+func expvarName(t *testing.T) string {
+	return fmt.Sprintf("lorekeeper_%s_%d", strings.ToLower(t.Name()), expvarRuns.Add(1))
+}
+
+func TestKeeperPublishExpvar(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-expvar"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	name := expvarName(t)
+	if err := k.PublishExpvar(name); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.PublishExpvar(name); err == nil {
+		t.Errorf("expected error since the name is already published")
+	}
+	if _, err := k.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	var stats Stats
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &stats); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if stats.BytesWritten != 6 || stats.CurrentFileSize != 6 || stats.Records != 1 {
+		t.Errorf("expected the stats of the write got %+v", stats)
+	}
+}
@@ -0,0 +1,143 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Chain the uploaders into one that tries each of them in order until one succeeds,
+// such as an S3 bucket, then an SFTP server, so that the archives are shipped across partial outages.
+// It fails with the errors of all the uploaders if none succeeds, and stops trying them once ctx is done,
+// see [WithPendingUploads] to keep the archive locally and retry it later.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithUploader(lorekeeper.FailoverUploader(s3Uploader, sftpUploader), true),
+//		lorekeeper.WithPendingUploads(),
+//	)
+func FailoverUploader(uploaders ...Uploader) Uploader {
+	return UploaderFunc(func(ctx context.Context, localPath string) error {
+		errs := make([]error, 0, len(uploaders))
+		for i, u := range uploaders {
+			// The next uploaders are not tried once the upload is canceled, such as by a Shutdown
+			if err := ctx.Err(); err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+			err := u.Upload(ctx, localPath)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, fmt.Errorf("uploader %d failed, caused by %w", i, err))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// Keep the archives that could not be uploaded after all the attempts of [WithUploadRetry] in a pending list,
+// and retry them on every following rotation, so that the delivery recovers once the remote storage is back.
+// The list is persisted next to the current log file, so that the uploads are also retried after a restart.
+// The archives removed by the retention in the meantime are dropped from the list.
+// Is disabled by default.
+func WithPendingUploads() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.pendingUploads = true
+		return k, nil
+	}
+}
+
+// Give up on the archives that could not be uploaded, this is the default.
+func NoPendingUploads() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.pendingUploads = false
+		return k, nil
+	}
+}
+
+// Get the path of the file persisting the pending uploads, see [WithPendingUploads].
+func (k *Keeper) pendingUploadsPath() string {
+	return filepath.Join(k.folder, "."+k.name+".uploads")
+}
+
+// Load the pending uploads left by a previous process and retry them.
+func (k *Keeper) resumePendingUploads() {
+	if !k.pendingUploads || k.uploader == nil {
+		return
+	}
+	f, err := k.fsys.Open(k.pendingUploadsPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to load pending uploads, caused by %w", err))
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if path := scanner.Text(); len(path) > 0 && !slices.Contains(k.pending, path) {
+			k.pending = append(k.pending, path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		k.handleError(fmt.Errorf("failed to load pending uploads, caused by %w", err))
+	}
+	k.retryPendingUploads()
+}
+
+// Queue the pending uploads that are not queued yet, the lock of the Keeper must be held.
+func (k *Keeper) retryPendingUploads() {
+	for _, path := range k.pending {
+		if k.pendingQueued[path] {
+			continue
+		}
+		if k.pendingQueued == nil {
+			k.pendingQueued = make(map[string]bool)
+		}
+		k.pendingQueued[path] = true
+		k.queueUpload(path)
+	}
+}
+
+// Record the outcome of an upload in the pending uploads, the lock of the Keeper must be held.
+func (k *Keeper) settlePendingUpload(archivePath string, failed bool) {
+	delete(k.pendingQueued, archivePath)
+	before := len(k.pending)
+	if !failed {
+		k.pending = slices.DeleteFunc(k.pending, func(path string) bool { return path == archivePath })
+	} else if !slices.Contains(k.pending, archivePath) {
+		k.pending = append(k.pending, archivePath)
+	}
+	if len(k.pending) == before {
+		return
+	}
+	if err := k.savePendingUploads(); err != nil {
+		k.handleError(err)
+	}
+}
+
+// Persist the pending uploads, removing the file once there is none.
+func (k *Keeper) savePendingUploads() error {
+	path := k.pendingUploadsPath()
+	if len(k.pending) == 0 {
+		if err := k.fsys.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to save pending uploads, caused by %w", err)
+		}
+		return nil
+	}
+	// Replace the file atomically, so that a crash does not lose the list
+	tmp := path + ".tmp"
+	if err := writeFile(k.fsys, tmp, []byte(strings.Join(k.pending, "\n")+"\n"), k.fileMode); err != nil {
+		return fmt.Errorf("failed to save pending uploads, caused by %w", err)
+	}
+	if err := k.fsys.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save pending uploads, caused by %w", err)
+	}
+	return nil
+}
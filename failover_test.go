@@ -0,0 +1,126 @@
+package lorekeeper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFailoverUploader(t *testing.T) {
+	var calls []string
+	failing := UploaderFunc(func(ctx context.Context, localPath string) error {
+		calls = append(calls, "failing")
+		return errors.New("unavailable")
+	})
+	working := UploaderFunc(func(ctx context.Context, localPath string) error {
+		calls = append(calls, "working")
+		return nil
+	})
+
+	if err := FailoverUploader(failing, working, failing).Upload(context.Background(), "a.log"); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(calls) != 2 || calls[1] != "working" {
+		t.Errorf("expected to stop at the first working uploader got %v", calls)
+	}
+	if err := FailoverUploader(failing, failing).Upload(context.Background(), "a.log"); err == nil {
+		t.Errorf("expected error since no uploader works")
+	}
+
+	calls = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	canceling := UploaderFunc(func(ctx context.Context, localPath string) error {
+		calls = append(calls, "canceling")
+		cancel()
+		return ctx.Err()
+	})
+	if err := FailoverUploader(canceling, working).Upload(ctx, "a.log"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("expected to stop trying once canceled got %v", calls)
+	}
+}
+
+func TestWithPendingUploads(t *testing.T) {
+	var mu sync.Mutex
+	var uploaded []string
+	available := false
+	uploader := UploaderFunc(func(ctx context.Context, localPath string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !available {
+			return errors.New("unavailable")
+		}
+		content, err := os.ReadFile(localPath)
+		if err != nil {
+			return err
+		}
+		uploaded = append(uploaded, string(content))
+		return nil
+	})
+	folder := t.TempDir()
+	opts := []Opt{
+		WithFolder(folder),
+		WithName("test-pending-uploads"),
+		WithManualStepping(),
+		WithUploader(uploader, true),
+		WithUploadRetry(1, time.Millisecond),
+		WithPendingUploads(),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	for _, msg := range []string{"first\n", "second\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Step(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if errors := k.Stats().UploadErrors; errors != 3 {
+		t.Errorf("expected 3 upload errors got %d", errors)
+	}
+	if archives := k.Archives(); len(archives) != 2 {
+		t.Fatalf("expected the archives to be kept got %d", len(archives))
+	}
+	if _, err := os.Stat(k.pendingUploadsPath()); err != nil {
+		t.Fatalf("expected the pending uploads to be persisted got %v", err)
+	}
+
+	// The pending uploads survive a restart
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	mu.Lock()
+	available = true
+	mu.Unlock()
+	k, err = New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if err := k.Step(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// Along with the empty archive rotated by Close
+	if len(uploaded) != 3 || uploaded[0] != "first\n" || uploaded[1] != "second\n" || uploaded[2] != "" {
+		t.Errorf("expected the pending archives to be uploaded in order got %q", uploaded)
+	}
+	if archives := k.Archives(); len(archives) != 0 {
+		t.Errorf("expected the uploaded archives to be removed got %d", len(archives))
+	}
+	if _, err := os.Stat(k.pendingUploadsPath()); !os.IsNotExist(err) {
+		t.Errorf("expected the pending uploads to be removed got %v", err)
+	}
+}
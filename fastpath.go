@@ -0,0 +1,70 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"io"
+)
+
+// The size of the chunks read by [Keeper.ReadFrom].
+const readFromChunkSize = 32 * Kb
+
+var (
+	_ io.StringWriter = (*Keeper)(nil)
+	_ io.ReaderFrom   = (*Keeper)(nil)
+)
+
+// Write the msg to the current log file like [Keeper.Write], without converting it to a byte slice first.
+// The msg is copied into a pooled buffer, so that writing a string does not allocate, see [WithMaxPooledBufferSize].
+func (k *Keeper) WriteString(msg string) (int, error) {
+	buf := getPooledBuffer()
+	*buf = append(*buf, msg...)
+	n, err := k.Write(*buf)
+	k.putPooledBuffer(buf)
+	return n, err
+}
+
+// Write everything read from r until EOF to the current log file, such as the output of a child process,
+// returning the number of bytes read. r is read in chunks of 32 Kb into a pooled buffer, and each chunk is written
+// up to its last newline, the rest being carried over to the next chunk, so that a line is never split
+// across writes unless it is longer than a chunk. Also makes the Keeper an [io.ReaderFrom], used by [io.Copy].
+// Whatever was read is written before an error of r is returned.
+func (k *Keeper) ReadFrom(r io.Reader) (int64, error) {
+	buf := getPooledBuffer()
+	defer k.putPooledBuffer(buf)
+	if cap(*buf) < readFromChunkSize {
+		*buf = make([]byte, 0, readFromChunkSize)
+	}
+	chunk := (*buf)[:readFromChunkSize]
+
+	var total int64
+	pending := 0
+	for {
+		n, err := r.Read(chunk[pending:])
+		total += int64(n)
+		pending += n
+		if err != nil {
+			if pending > 0 {
+				if _, werr := k.Write(chunk[:pending]); werr != nil {
+					return total, werr
+				}
+			}
+			if err == io.EOF {
+				return total, nil
+			}
+			return total, err
+		}
+
+		end := bytes.LastIndexByte(chunk[:pending], '\n') + 1
+		if end == 0 {
+			if pending < len(chunk) {
+				continue
+			}
+			// A line longer than a chunk
+			end = pending
+		}
+		if _, err := k.Write(chunk[:end]); err != nil {
+			return total, err
+		}
+		pending = copy(chunk, chunk[end:pending])
+	}
+}
@@ -0,0 +1,71 @@
+package lorekeeper
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestKeeperWriteString(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-write-string"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if n, err := io.WriteString(k, "abc\n"); err != nil || n != 4 {
+		t.Fatalf("expected 4 bytes written and no error got %d and %v", n, err)
+	}
+	if b, err := os.ReadFile(filepath.Join(folder, "test-write-string.log")); err != nil || string(b) != "abc\n" {
+		t.Errorf("expected %q and no error got %q and %v", "abc\n", b, err)
+	}
+}
+
+func TestKeeperReadFrom(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-read-from"), WithRecent(8))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	content := "abc\ndefgh\nij\nunterminated"
+	n, err := k.ReadFrom(iotest.HalfReader(strings.NewReader(content)))
+	if err != nil || n != int64(len(content)) {
+		t.Fatalf("expected %d bytes read and no error got %d and %v", len(content), n, err)
+	}
+	if b, err := os.ReadFile(filepath.Join(folder, "test-read-from.log")); err != nil || string(b) != content {
+		t.Errorf("expected %q and no error got %q and %v", content, b, err)
+	}
+	// Every write ends at a newline but the last one
+	recent := k.Recent()
+	for i, msg := range recent[:len(recent)-1] {
+		if !strings.HasSuffix(string(msg), "\n") {
+			t.Errorf("expected write %d to end at a newline got %q", i, msg)
+		}
+	}
+	if last := string(recent[len(recent)-1]); last != "unterminated" {
+		t.Errorf("expected the rest to be written at EOF got %q", last)
+	}
+}
+
+func TestKeeperReadFromError(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-read-from-error"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	failure := errors.New("broken pipe")
+	n, err := k.ReadFrom(io.MultiReader(strings.NewReader("abc\nde"), iotest.ErrReader(failure)))
+	if !errors.Is(err, failure) || n != 6 {
+		t.Errorf("expected 6 bytes read and the error of the reader got %d and %v", n, err)
+	}
+	// What was read is not lost
+	if b, err := os.ReadFile(filepath.Join(folder, "test-read-from-error.log")); err != nil || string(b) != "abc\nde" {
+		t.Errorf("expected %q and no error got %q and %v", "abc\nde", b, err)
+	}
+}
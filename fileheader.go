@@ -0,0 +1,64 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Call fn to write a header at the start of every new current log file, when the Keeper starts with an empty one
+// and after every rotation, such as a machine-readable preamble with the version, the hostname, the start time
+// and the field schema, so that downstream parsers can interpret every log file on its own.
+// What fn writes is buffered and written at once, and counts in the size of the log file, see [WithMaxSize].
+// The header must be smaller than the max size, a larger one is left out and reported to the error handler.
+// fn runs under the lock of the Keeper, so it must not call the methods of the Keeper.
+// Its errors go to the error handler, see [WithErrorHandler], and the log file is then left without a header.
+// A log file holding only its header is empty for [WithSkipEmptyRotation].
+// A nil fn disables it, which is the default.
+//
+// Example usage:
+//
+//	lorekeeper.WithFileHeader(func(w io.Writer) error {
+//		_, err := fmt.Fprintf(w, "# version=%s host=%s start=%s\n", version, hostname, time.Now().Format(time.RFC3339))
+//		return err
+//	})
+func WithFileHeader(fn func(w io.Writer) error) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.fileHeader = fn
+		return k, nil
+	}
+}
+
+// Write the header of [WithFileHeader] into the new current log file, the lock of the Keeper must be held.
+// With [WithHashChain], the header starts with the seed of the chain, and the preamble is chained as a record.
+func (k *Keeper) writeFileHeader() {
+	k.headerSize = 0
+	if k.fileHeader == nil && k.chain == nil {
+		return
+	}
+	var header bytes.Buffer
+	if k.chain != nil {
+		header.Write(k.chain.appendSeed(nil))
+	}
+	if k.fileHeader != nil {
+		var preamble bytes.Buffer
+		if err := k.fileHeader(&preamble); err != nil {
+			k.handleError(fmt.Errorf("failed to write file header, caused by %w", err))
+		} else if size := header.Len() + preamble.Len(); k.maxSize > 0 && size >= k.maxSize {
+			// No message would fit in the log file after the header
+			k.handleError(fmt.Errorf("failed to write file header, the header of %d bytes must be smaller than the max size of %d bytes", size, k.maxSize))
+		} else if k.chain != nil && preamble.Len() > 0 {
+			header.Write(k.chain.appendRecord(nil, bytes.TrimSuffix(preamble.Bytes(), newLine)))
+		} else {
+			header.Write(preamble.Bytes())
+		}
+	}
+	if header.Len() == 0 {
+		return
+	}
+	n, err := k.writeCurrent(header.Bytes())
+	k.headerSize = n
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to write file header, caused by %w", err))
+	}
+}
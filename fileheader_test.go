@@ -0,0 +1,139 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeeperWithFileHeader(t *testing.T) {
+	headers := 0
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-file-header"),
+		WithFileHeader(func(w io.Writer) error {
+			headers++
+			_, err := fmt.Fprintf(w, "# header %d\n", headers)
+			return err
+		}),
+		WithSkipEmptyRotation(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if _, err := k.Write([]byte("first\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// Holding only its header, the current log file is empty and its rotation is skipped
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := k.Stats().SkippedRotations; got != 1 {
+		t.Errorf("expected 1 skipped rotation got %d", got)
+	}
+	if _, err := k.Write([]byte("second\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	archives := k.Archives()
+	want := []string{"# header 1\nfirst\n", "# header 3\nsecond\n"}
+	if len(archives) != len(want) {
+		t.Fatalf("expected %d archives got %d", len(want), len(archives))
+	}
+	for i, archive := range archives {
+		content, err := os.ReadFile(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if string(content) != want[i] {
+			t.Errorf("expected archive %d to be %q got %q", i, want[i], content)
+		}
+	}
+}
+
+func TestKeeperWithFileHeaderError(t *testing.T) {
+	var errs []error
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-file-header-error"),
+		WithFileHeader(func(w io.Writer) error { return errors.New("no header") }),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "no header") {
+		t.Errorf("expected the error of the header got %v", errs)
+	}
+	if got := k.Stats().CurrentFileSize; got != 0 {
+		t.Errorf("expected an empty current log file got %d bytes", got)
+	}
+
+	// An existing current log file gets no header
+	if err := os.WriteFile(filepath.Join(folder, "test-file-header-existing.log"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	other, err := New(
+		WithFolder(folder),
+		WithName("test-file-header-existing"),
+		WithFileHeader(func(w io.Writer) error {
+			t.Errorf("expected no header for an existing current log file")
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := other.Stats().CurrentFileSize; got != 6 {
+		t.Errorf("expected the current log file to be kept got %d bytes", got)
+	}
+	other.mu.Lock()
+	other.fileHeader = nil
+	other.mu.Unlock()
+	if err := other.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+}
+
+func TestKeeperWithFileHeaderTooLarge(t *testing.T) {
+	var errs []error
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-file-header-too-large"),
+		WithMaxSize(10),
+		WithFileHeader(func(w io.Writer) error {
+			_, err := io.WriteString(w, "# header 16 byte")
+			return err
+		}),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "max size") {
+		t.Errorf("expected the header to be rejected got %v", errs)
+	}
+
+	msg := []byte("0123456789abcdefghijklmn\n")
+	if n, err := k.Write(msg); err != nil || n != len(msg) {
+		t.Fatalf("expected %d bytes and no error got %d and %v", len(msg), n, err)
+	}
+	if archives := k.Archives(); len(archives) != 2 || archives[0].Size != 10 || archives[1].Size != 10 {
+		t.Errorf("expected 2 archives of 10 bytes without header got %v", archives)
+	}
+}
@@ -1,7 +1,10 @@
 package lorekeeper
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"time"
@@ -13,12 +16,33 @@ type fileInfo struct {
 	filePath string
 	size     int
 	modtime  time.Time
+	// The number of records in the file, -1 if unknown
+	records int
+	// The size before compression, -1 if the file was not compressed by the Keeper
+	originalSize    int
+	compressionTime time.Duration
+	// Why the retention expired the archive, see [Deletion]
+	expiredBy     DeletionPolicy
+	expiredReason string
 }
 
-func getArchives(pattern string) (*collection.List[*fileInfo], int, error) {
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get archived, caused by %w", err)
+func getArchives(fsys FS, patterns ...string) (*collection.List[*fileInfo], int, error) {
+	var matches []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		found, err := glob(fsys, pattern)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get archived, caused by %w", err)
+		}
+		for _, match := range found {
+			if isChecksumSidecar(match) || isUploadState(match) {
+				continue
+			}
+			if !seen[match] {
+				seen[match] = true
+				matches = append(matches, match)
+			}
+		}
 	}
 
 	minHeap, err := collection.NewHeap(func(current, other *fileInfo) bool {
@@ -28,7 +52,7 @@ func getArchives(pattern string) (*collection.List[*fileInfo], int, error) {
 		return nil, 0, fmt.Errorf("failed to get heap, caused by %w", err)
 	}
 	for _, match := range matches {
-		info, err := getFileInfo(match)
+		info, err := getFileInfo(fsys, match)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to get file info %s, caused by %w", match, err)
 		}
@@ -48,8 +72,8 @@ func getArchives(pattern string) (*collection.List[*fileInfo], int, error) {
 	return l, totalSize, nil
 }
 
-func getFileInfo(filePath string) (*fileInfo, error) {
-	stat, err := os.Stat(filePath)
+func getFileInfo(fsys FS, filePath string) (*fileInfo, error) {
+	stat, err := fsys.Stat(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed get file stat, caused by %w", err)
 	}
@@ -57,5 +81,107 @@ func getFileInfo(filePath string) (*fileInfo, error) {
 		filePath: filePath,
 		modtime:  stat.ModTime(),
 		size:     int(stat.Size()),
+		records:  -1,
+		// Unknown until the Keeper compresses it
+		originalSize: -1,
 	}, nil
 }
+
+// Move a file from src to dst, falling back to copying when a rename is not possible,
+// for example when src and dst are on different devices. Nothing is done if src and dst are the same file.
+func moveFile(fsys FS, src, dst string) error {
+	if filepath.Clean(src) == filepath.Clean(dst) {
+		return nil
+	}
+	if err := fsys.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(fsys, src, dst, os.O_TRUNC); err != nil {
+		return err
+	}
+	if err := fsys.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove %s, caused by %w", src, err)
+	}
+	return nil
+}
+
+// Move a file from src to dst like moveFile, failing with an error wrapping [fs.ErrExist] if dst already exists.
+// dst is claimed before the move, so that a file created at dst in the meantime is never replaced.
+func moveFileExclusive(fsys FS, src, dst string) error {
+	if filepath.Clean(src) == filepath.Clean(dst) {
+		return nil
+	}
+	stat, err := fsys.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s, caused by %w", src, err)
+	}
+	claim, err := fsys.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, stat.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to claim %s, caused by %w", dst, err)
+	}
+	if err := claim.Close(); err != nil {
+		return fmt.Errorf("failed to claim %s, caused by %w", dst, err)
+	}
+	if err := moveFile(fsys, src, dst); err != nil {
+		_ = fsys.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// Move a file from src to dst, appending its content to dst if dst already exists.
+// Nothing is done if src and dst are the same file, which would otherwise be appended to itself forever.
+func appendFile(fsys FS, src, dst string) error {
+	if filepath.Clean(src) == filepath.Clean(dst) {
+		return nil
+	}
+	if _, err := fsys.Stat(dst); errors.Is(err, fs.ErrNotExist) {
+		return moveFile(fsys, src, dst)
+	}
+	if err := copyFile(fsys, src, dst, os.O_APPEND); err != nil {
+		return err
+	}
+	if err := fsys.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove %s, caused by %w", src, err)
+	}
+	return nil
+}
+
+// Copy the content of src into dst, flag decides whether dst is truncated or appended to.
+// A new dst gets the permissions of src. If appending fails partway, dst is truncated back to its original size
+// when its file supports it, like [os.File], so that a retry does not duplicate the copied part.
+func copyFile(fsys FS, src, dst string, flag int) error {
+	in, err := fsys.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s, caused by %w", src, err)
+	}
+	defer in.Close()
+	stat, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s, caused by %w", src, err)
+	}
+
+	out, err := fsys.OpenFile(dst, flag|os.O_CREATE|os.O_WRONLY, stat.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to open %s, caused by %w", dst, err)
+	}
+	var original int64
+	if flag&os.O_APPEND != 0 {
+		outStat, err := out.Stat()
+		if err != nil {
+			out.Close()
+			return fmt.Errorf("failed to stat %s, caused by %w", dst, err)
+		}
+		original = outStat.Size()
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		if t, ok := out.(interface{ Truncate(size int64) error }); ok && flag&os.O_APPEND != 0 {
+			if truncErr := t.Truncate(original); truncErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to restore %s, caused by %w", dst, truncErr))
+			}
+		}
+		out.Close()
+		return fmt.Errorf("failed to copy %s to %s, caused by %w", src, dst, err)
+	}
+	return out.Close()
+}
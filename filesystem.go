@@ -0,0 +1,225 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// A FS is the file system holding the log files of a Keeper, see [WithFS].
+// It extends [fs.StatFS] and [fs.ReadDirFS] with the operations of the os package that write and rotate the log files,
+// so that the Keeper can run against an in-memory file system in tests, such as the one of lorekeepertest.NewMemFS,
+// or be backed by a custom storage.
+// Unlike the slash-separated paths of [io/fs], the names are the paths built by the Keeper from its folder,
+// as they would be given to the os package.
+// If the FS also implements [fs.GlobFS], its Glob finds the archives, otherwise they are found with ReadDir.
+// The methods must be safe for concurrent use, and their errors should wrap the ones of [io/fs], such as [fs.ErrNotExist].
+type FS interface {
+	fs.StatFS
+	fs.ReadDirFS
+	// Open the named file with the flags of the os package, like [os.OpenFile].
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	// Rename the file, replacing newpath if it exists, like [os.Rename].
+	Rename(oldpath, newpath string) error
+	// Remove the file or the empty folder, like [os.Remove].
+	Remove(name string) error
+	// Create the folder and its missing parents, like [os.MkdirAll].
+	MkdirAll(path string, perm fs.FileMode) error
+	// Change the permissions of the file, like [os.Chmod].
+	Chmod(name string, mode fs.FileMode) error
+	// Change the access and modification times of the file, like [os.Chtimes].
+	Chtimes(name string, atime, mtime time.Time) error
+}
+
+// A File is a file opened by [FS.OpenFile].
+// The current log file is also synced to its storage if it has a Sync() error method, like [os.File].
+type File interface {
+	fs.File
+	io.Writer
+}
+
+// Get the [FS] of the operating system, which is the default.
+func OSFS() FS {
+	return osFS{}
+}
+
+type osFS struct{}
+
+// Make sure that osFS implements the [FS] and [fs.GlobFS] interfaces.
+var (
+	_ FS        = osFS{}
+	_ fs.GlobFS = osFS{}
+)
+
+func (osFS) Open(name string) (fs.File, error)                 { return os.Open(name) }
+func (osFS) Stat(name string) (fs.FileInfo, error)             { return os.Stat(name) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error)        { return os.ReadDir(name) }
+func (osFS) Glob(pattern string) ([]string, error)             { return filepath.Glob(pattern) }
+func (osFS) Rename(oldpath, newpath string) error              { return os.Rename(oldpath, newpath) }
+func (osFS) Remove(name string) error                          { return os.Remove(name) }
+func (osFS) MkdirAll(path string, perm fs.FileMode) error      { return os.MkdirAll(path, perm) }
+func (osFS) Chmod(name string, mode fs.FileMode) error         { return os.Chmod(name, mode) }
+func (osFS) Chtimes(name string, atime, mtime time.Time) error { return os.Chtimes(name, atime, mtime) }
+
+func (osFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+// Keep the log files on the given [FS] instead of the file system of the operating system,
+// such as an in-memory file system to test the rotations and the retention without touching the disk,
+// see lorekeepertest.NewMemFS. A nil fsys is the file system of the operating system, which is the default.
+// The features that depend on the operating system, [WithLockFile], [WithCurrentSymlink], [WithMinDiskFree]
+// and [WithMinDiskFreePercent], can not be used together with another FS,
+// and [ArchiveInfo.WriteTo], which reads an archive without its Keeper, always reads from the operating system.
+// The readers of the Keeper, such as [Keeper.FS], [Keeper.OpenArchive] and [NewBrowser], read from the given FS.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithFolder("/logs"), lorekeeper.WithFS(lorekeepertest.NewMemFS()))
+func WithFS(fsys FS) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if fsys == nil {
+			fsys = OSFS()
+		}
+		k.fsys = fsys
+		return k, nil
+	}
+}
+
+// Reject the features that depend on the operating system with another [FS].
+func (k *Keeper) applyFS() error {
+	if _, ok := k.fsys.(osFS); ok || k.fsys == nil {
+		return nil
+	}
+	var features []string
+	if k.lockFile {
+		features = append(features, "WithLockFile")
+	}
+	if len(k.currentSymlink) > 0 {
+		features = append(features, "WithCurrentSymlink")
+	}
+	if k.minDiskFree > 0 || k.minDiskFreePercent > 0 {
+		features = append(features, "WithMinDiskFree")
+	}
+	if len(features) > 0 {
+		return fmt.Errorf("failed to set file system, %s can only be used with the file system of the operating system", strings.Join(features, ", "))
+	}
+	return nil
+}
+
+// Find the files matching the pattern, in the syntax of [filepath.Match], like [filepath.Glob].
+func glob(fsys FS, pattern string) ([]string, error) {
+	if globber, ok := fsys.(fs.GlobFS); ok {
+		return globber.Glob(pattern)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	if !hasGlobMeta(pattern) {
+		if _, err := fsys.Stat(pattern); err != nil {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	dir, file := filepath.Split(pattern)
+	dir = cleanGlobDir(dir)
+	if !hasGlobMeta(dir) {
+		return globDir(fsys, dir, file, nil), nil
+	}
+	dirs, err := glob(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, d := range dirs {
+		matches = globDir(fsys, d, file, matches)
+	}
+	return matches, nil
+}
+
+// Append the files of dir matching the pattern to matches, ignoring the errors of reading dir like [filepath.Glob].
+func globDir(fsys FS, dir, pattern string, matches []string) []string {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return matches
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			matches = append(matches, filepath.Join(dir, name))
+		}
+	}
+	return matches
+}
+
+func cleanGlobDir(dir string) string {
+	switch dir {
+	case "":
+		return "."
+	case string(filepath.Separator):
+		return dir
+	default:
+		return dir[:len(dir)-1]
+	}
+}
+
+func hasGlobMeta(path string) bool {
+	magic := `*?[`
+	if filepath.Separator != '\\' {
+		magic = `*?[\`
+	}
+	return strings.ContainsAny(path, magic)
+}
+
+// Read the whole file, like [os.ReadFile].
+func readFile(fsys FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// Write data to the file, creating or truncating it, like [os.WriteFile].
+func writeFile(fsys FS, name string, data []byte, perm fs.FileMode) error {
+	f, err := fsys.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return errors.Join(err, f.Close())
+}
+
+// Read len(p) bytes of the file from offset, seeking or skipping to it if the file is not an [io.ReaderAt].
+// Reaching the end of the file is not an error.
+func readAt(file fs.File, p []byte, offset int64) error {
+	var err error
+	switch f := file.(type) {
+	case io.ReaderAt:
+		_, err = f.ReadAt(p, offset)
+	case io.Seeker:
+		if _, err = f.Seek(offset, io.SeekStart); err == nil {
+			_, err = io.ReadFull(file, p)
+		}
+	default:
+		if _, err = io.CopyN(io.Discard, file, offset); err == nil {
+			_, err = io.ReadFull(file, p)
+		}
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return err
+}
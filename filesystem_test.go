@@ -0,0 +1,59 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// A FS without the Glob of the operating system, which finds the archives with ReadDir instead.
+type readDirFS struct {
+	FS
+}
+
+func TestWithFSReadDirGlob(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-fs-glob"),
+		WithFS(readDirFS{OSFS()}),
+		WithMaxSize(10),
+		WithMaxFiles(2),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for range 5 {
+		if _, err := k.Write([]byte("012345678\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if got := len(k.Archives()); got != 2 {
+		t.Errorf("expected 2 archives got %d", got)
+	}
+
+	want, err := filepath.Glob(filepath.Join(folder, "*"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	got, err := glob(readDirFS{OSFS()}, filepath.Join(folder, "*"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+}
+
+func TestWithFSRejectsOSFeatures(t *testing.T) {
+	_, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-fs-lock-file"),
+		WithFS(readDirFS{OSFS()}),
+		WithLockFile(),
+	)
+	if err == nil {
+		t.Errorf("expected an error for a lock file on another file system")
+	}
+}
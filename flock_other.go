@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || dragonfly || netbsd || openbsd || windows)
+
+package lorekeeper
+
+import "os"
+
+// Locking a file is not supported on this platform, the lock file of [WithLockFile] is updated without a lock.
+func lockFileExclusive(f *os.File) error {
+	return nil
+}
+
+// Release the lock taken by lockFileExclusive.
+func unlockFile(f *os.File) error {
+	return nil
+}
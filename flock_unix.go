@@ -0,0 +1,23 @@
+//go:build linux || darwin || freebsd || dragonfly || netbsd || openbsd
+
+package lorekeeper
+
+import (
+	"os"
+	"syscall"
+)
+
+// Take an exclusive lock on the file shared with the other processes, waiting for it, with flock(2).
+func lockFileExclusive(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// Release the lock taken by lockFileExclusive.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,51 @@
+//go:build windows
+
+package lorekeeper
+
+import (
+	"math"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procLockFileEx   = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+	procUnlockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("UnlockFileEx")
+)
+
+// LOCKFILE_EXCLUSIVE_LOCK of LockFileEx.
+const lockfileExclusiveLock = 0x2
+
+// Take an exclusive lock on the file shared with the other processes, waiting for it, with LockFileEx.
+func lockFileExclusive(f *os.File) error {
+	var overlapped syscall.Overlapped
+	r, _, err := procLockFileEx.Call(
+		f.Fd(),
+		lockfileExclusiveLock,
+		0,
+		math.MaxUint32,
+		math.MaxUint32,
+		uintptr(unsafe.Pointer(&overlapped)),
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// Release the lock taken by lockFileExclusive.
+func unlockFile(f *os.File) error {
+	var overlapped syscall.Overlapped
+	r, _, err := procUnlockFileEx.Call(
+		f.Fd(),
+		0,
+		math.MaxUint32,
+		math.MaxUint32,
+		uintptr(unsafe.Pointer(&overlapped)),
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
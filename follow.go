@@ -0,0 +1,213 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// The size of the chunks read backward from the end of the current log file by [Keeper.Tail].
+const tailChunkSize = 32 * Kb
+
+// The number of messages a follower of [Keeper.Follow] may fall behind before messages are dropped.
+const followBufferSize = 256
+
+// Get the last n records written to the log files, from the oldest to the newest, such as for a debug endpoint.
+// The current log file is read backward from its end, and the archives are read from the newest one,
+// decompressed, only if the current log file holds fewer than n records. See [Keeper.Between] for what a record is.
+// The messages buffered by [WithBufferSize] or queued by [WithAsyncWrites] are written first.
+//
+// Example usage:
+//
+//	http.HandleFunc("/debug/logs", func(w http.ResponseWriter, r *http.Request) {
+//		records, err := keeper.Tail(100)
+//		if err != nil {
+//			http.Error(w, err.Error(), http.StatusInternalServerError)
+//			return
+//		}
+//		for _, record := range records {
+//			w.Write(record)
+//		}
+//	})
+func (k *Keeper) Tail(n int) ([][]byte, error) {
+	if n < 1 {
+		return nil, nil
+	}
+	k.waitAsync()
+	k.mu.Lock()
+	if err := k.flushBuffer(); err != nil {
+		k.mu.Unlock()
+		return nil, fmt.Errorf("failed to tail, caused by %w", err)
+	}
+	paths := append(k.pathsOf(k.archiveList()), k.getCurrentFilePath())
+	current := paths[len(paths)-1]
+	backward := k.logFileDecompressor(current) == nil && !k.lengthPrefixed && k.recordPattern == nil
+	fsys := k.fsys
+	k.mu.Unlock()
+
+	var tail [][]byte
+	for i := len(paths) - 1; i >= 0 && len(tail) < n; i-- {
+		var records [][]byte
+		var err error
+		if i == len(paths)-1 && backward {
+			records, err = k.tailLogFile(fsys, current, n-len(tail))
+		} else {
+			records, err = k.lastRecords(paths[i], n-len(tail))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to tail, caused by %w", err)
+		}
+		tail = append(records, tail...)
+	}
+	return tail, nil
+}
+
+// Get the paths of the archives.
+func (k *Keeper) pathsOf(archives []*fileInfo) []string {
+	paths := make([]string, len(archives))
+	for i, archive := range archives {
+		paths[i] = archive.filePath
+	}
+	return paths
+}
+
+// Get the last n records of the log file at path, reading all of its records.
+func (k *Keeper) lastRecords(path string, n int) ([][]byte, error) {
+	var last [][]byte
+	for record, err := range k.recordsOf([]string{path}) {
+		if err != nil {
+			return nil, err
+		}
+		if len(last) == n {
+			last = append(last[:0], last[1:]...)
+		}
+		last = append(last, record)
+	}
+	return last, nil
+}
+
+// Get the last n lines of the plain log file at path, reading it backward from its end.
+func (k *Keeper) tailLogFile(fsys FS, path string, n int) ([][]byte, error) {
+	k.acquireHandle(path)
+	defer k.releaseHandle(path)
+	f, err := fsys.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q, caused by %w", path, err)
+	}
+	defer f.Close()
+	r, ok := f.(io.ReaderAt)
+	if !ok {
+		return k.lastRecords(path, n)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %q, caused by %w", path, err)
+	}
+	lines, err := tailLines(r, stat.Size(), n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q, caused by %w", path, err)
+	}
+	return lines, nil
+}
+
+// Get the last n lines of the size bytes of r, each keeping its trailing new line,
+// reading chunks backward until n complete lines are found or the start is reached.
+func tailLines(r io.ReaderAt, size int64, n int) ([][]byte, error) {
+	var data []byte
+	start := size
+	for start > 0 {
+		chunk := min(start, int64(tailChunkSize))
+		start -= chunk
+		buf := make([]byte, chunk, int(chunk)+len(data))
+		if _, err := r.ReadAt(buf, start); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(buf, data...)
+		// The n last lines are complete once n new lines precede the last byte
+		if bytes.Count(data[:len(data)-1], []byte{'\n'}) >= n {
+			break
+		}
+	}
+
+	var lines [][]byte
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n') + 1
+		if end == 0 {
+			end = len(data)
+		}
+		lines = append(lines, data[:end:end])
+		data = data[end:]
+	}
+	return lines[max(len(lines)-n, 0):], nil
+}
+
+// Stream the messages written to the Keeper from now on, until ctx is done or the Keeper is closed,
+// which closes the channel, such as to show the logs live on a debug endpoint.
+// A message is sent as it was written, before it is split into records, see [Keeper.Write].
+// The writes never wait for a follower: a follower that falls 256 messages behind misses the next ones,
+// which are counted in [Stats.FollowDropped]. The messages left out by [WithSampling] are not sent.
+//
+// Example usage:
+//
+//	http.HandleFunc("/debug/logs/follow", func(w http.ResponseWriter, r *http.Request) {
+//		messages, err := keeper.Follow(r.Context())
+//		if err != nil {
+//			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//			return
+//		}
+//		for msg := range messages {
+//			w.Write(msg)
+//			w.(http.Flusher).Flush()
+//		}
+//	})
+func (k *Keeper) Follow(ctx context.Context) (<-chan []byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return nil, fmt.Errorf("failed to follow, caused by %w", os.ErrClosed)
+	}
+	ch := make(chan []byte, followBufferSize)
+	if k.followers == nil {
+		k.followers = make(map[chan []byte]struct{})
+	}
+	k.followers[ch] = struct{}{}
+	context.AfterFunc(ctx, func() {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		k.unfollow(ch)
+	})
+	return ch, nil
+}
+
+// Send a copy of the msg to the followers of [Keeper.Follow], the lock of the Keeper must be held.
+func (k *Keeper) notifyFollowers(msg []byte) {
+	for ch := range k.followers {
+		select {
+		case ch <- bytes.Clone(msg):
+		default:
+			k.stats.FollowDropped++
+		}
+	}
+}
+
+// Stop sending the messages to the follower and close its channel, the lock of the Keeper must be held.
+func (k *Keeper) unfollow(ch chan []byte) {
+	if _, ok := k.followers[ch]; ok {
+		delete(k.followers, ch)
+		close(ch)
+	}
+}
+
+// Close the channels of all the followers, the lock of the Keeper must be held.
+func (k *Keeper) unfollowAll() {
+	for ch := range k.followers {
+		k.unfollow(ch)
+	}
+}
@@ -0,0 +1,162 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeeperTail(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Opt
+	}{
+		{name: "plain"},
+		{name: "gzip", opts: []Opt{WithGzip()}},
+		{name: "buffered", opts: []Opt{WithBufferSize(Kb)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := New(append([]Opt{WithFolder(t.TempDir()), WithName("test-tail"), WithMaxSize(30), WithSkipEmptyRotation()}, tc.opts...)...)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			defer k.Close()
+			var all []string
+			for i := range 10 {
+				msg := fmt.Sprintf("message-%d\n", i)
+				all = append(all, msg)
+				if _, err := k.Write([]byte(msg)); err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+			}
+			if tc.name != "buffered" && len(k.Archives()) == 0 {
+				t.Fatalf("expected archives got none")
+			}
+
+			for _, n := range []int{0, 1, 3, 10, 20} {
+				records, err := k.Tail(n)
+				if err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+				got := make([]string, len(records))
+				for i, record := range records {
+					got[i] = string(record)
+				}
+				want := all[max(len(all)-n, 0):]
+				if n == 0 {
+					want = nil
+				}
+				if strings.Join(got, "") != strings.Join(want, "") {
+					t.Errorf("expected the last %d records %q got %q", n, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	var data bytes.Buffer
+	for i := range 10000 {
+		fmt.Fprintf(&data, "line-%d\n", i)
+	}
+	data.WriteString("partial")
+
+	lines, err := tailLines(bytes.NewReader(data.Bytes()), int64(data.Len()), 3)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got, want := fmt.Sprintf("%q", lines), fmt.Sprintf("%q", []string{"line-9998\n", "line-9999\n", "partial"}); got != want {
+		t.Errorf("expected %s got %s", want, got)
+	}
+
+	// Crossing several chunks
+	lines, err = tailLines(bytes.NewReader(data.Bytes()), int64(data.Len()), 10001)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(lines) != 10001 || string(lines[0]) != "line-0\n" {
+		t.Errorf("expected all the 10001 lines from line-0 got %d lines", len(lines))
+	}
+
+	lines, err = tailLines(bytes.NewReader(nil), 0, 3)
+	if err != nil || len(lines) != 0 {
+		t.Errorf("expected no lines got %q, %v", lines, err)
+	}
+}
+
+func TestKeeperFollow(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-follow"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages, err := k.Follow(ctx)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, msg := range []string{"first\n", "second\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	for _, want := range []string{"first\n", "second\n"} {
+		select {
+		case got := <-messages:
+			if string(got) != want {
+				t.Errorf("expected %q got %q", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %q got nothing", want)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-messages:
+		if ok {
+			t.Errorf("expected the channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the channel to be closed on cancel")
+	}
+}
+
+func TestKeeperFollowDropped(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-follow-dropped"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	messages, err := k.Follow(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for i := range followBufferSize + 10 {
+		if _, err := fmt.Fprintf(k, "message-%d\n", i); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if got := k.Stats().FollowDropped; got != 10 {
+		t.Errorf("expected 10 dropped messages got %d", got)
+	}
+
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	received := 0
+	for range messages {
+		received++
+	}
+	if received != followBufferSize {
+		t.Errorf("expected %d messages got %d", followBufferSize, received)
+	}
+	if _, err := k.Follow(context.Background()); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected %v got %v", os.ErrClosed, err)
+	}
+}
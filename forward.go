@@ -0,0 +1,192 @@
+package lorekeeper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// The default number of messages queued by [WithForwarder].
+const defaultForwardQueueSize = 1024
+
+// The writer and the queue of [WithForwarder].
+type forwardConfig struct {
+	w      io.Writer
+	size   int
+	policy DropPolicy
+}
+
+// The queue of [WithForwarder], drained to the writer by a goroutine of its own.
+type forwardQueue struct {
+	asyncQueue
+	config *forwardConfig
+	// Guarded by the mutex of the queue, since it changes with Reconfigure
+	report func(error)
+	// The number of messages of the batch being forwarded
+	sending int
+	// Only used by the drainer
+	failing bool
+	// The counters of the Keeper, so that they survive the queue
+	forwarded *atomic.Uint64
+	errors    *atomic.Uint64
+}
+
+// Forward every message written to the Keeper to w as well, such as a connection to a remote collector,
+// while the log files keep being rotated locally as the durable copy. The messages are copied into a queue
+// of up to queueSize messages, 1024 if queueSize < 1, and written to w in order by a background goroutine,
+// so that a slow or unreachable collector does not slow the writes down.
+// When the queue is full, the policy decides whether the write waits for room, which is backpressure on the writers,
+// or a message is dropped from the queue. A message that w fails to write is dropped as well,
+// the error that starts an outage goes to the error handler, see [WithErrorHandler], and the next ones are only counted,
+// so w should reconnect by itself, such as on its next write. The forwarded, failed, dropped and queued messages are counted
+// in [Stats.Forwarded], [Stats.ForwardErrors], [Stats.ForwardDropped] and [Stats.ForwardQueued].
+// The messages left out by [WithSampling] are not forwarded. [Keeper.Close] waits for the queued messages to be forwarded,
+// see [Keeper.Shutdown] to bound the wait. w is only written to by one goroutine at a time.
+// Set w to nil to disable, which is the default.
+//
+// Example usage:
+//
+//	conn, err := net.Dial("tcp", "collector:5140")
+//	if err != nil {
+//		return err
+//	}
+//	keeper, err := lorekeeper.New(lorekeeper.WithForwarder(conn, 4096, lorekeeper.DropOldest))
+func WithForwarder(w io.Writer, queueSize int, policy DropPolicy) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if policy != DropNone && policy != DropOldest && policy != DropNewest {
+			return nil, fmt.Errorf("failed to set forwarder, unknown drop policy %d", policy)
+		}
+		if w == nil {
+			k.forwarder = nil
+			return k, nil
+		}
+		if queueSize < 1 {
+			queueSize = defaultForwardQueueSize
+		}
+		k.forwarder = &forwardConfig{w: w, size: queueSize, policy: policy}
+		return k, nil
+	}
+}
+
+// Start or stop the queue of [WithForwarder] to match the options, the lock of the Keeper must be held.
+// A new queue only starts forwarding once the previous one forwarded its messages, so that they stay in order.
+func (k *Keeper) configureForward() {
+	q := k.forward.Load()
+	if q != nil && q.config == k.forwarder {
+		q.mu.Lock()
+		q.report = k.errorHandler
+		q.maxPooled = k.maxPooledSize()
+		q.mu.Unlock()
+		return
+	}
+	var prev chan struct{}
+	if q != nil {
+		k.forward.Store(nil)
+		q.stop()
+		prev = q.done
+	}
+	if k.forwarder == nil {
+		return
+	}
+	q = &forwardQueue{
+		asyncQueue: asyncQueue{items: make([]*[]byte, k.forwarder.size), policy: k.forwarder.policy, maxPooled: k.maxPooledSize(), dropped: &k.forwardDropped, done: make(chan struct{})},
+		config:     k.forwarder,
+		report:     k.errorHandler,
+		forwarded:  &k.forwarded,
+		errors:     &k.forwardErrors,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	k.forward.Store(q)
+	k.goSupervised("forward", func() {
+		if prev != nil {
+			<-prev
+		}
+		q.drain(q.send)
+	}, func() { close(q.done) })
+}
+
+// Queue a copy of the msg to be forwarded, see [WithForwarder].
+func (k *Keeper) forwardMessage(msg []byte) {
+	if q := k.forward.Load(); q != nil {
+		// A dropped message is counted by the queue, the write itself succeeds
+		_, _ = q.append(msg)
+	}
+}
+
+// Write a batch of queued messages to the writer of the forwarder.
+func (q *forwardQueue) send(batch []*[]byte) {
+	q.mu.Lock()
+	q.sending = len(batch)
+	q.mu.Unlock()
+	for _, buf := range batch {
+		_, err := q.config.w.Write(*buf)
+		q.mu.Lock()
+		q.sending--
+		q.mu.Unlock()
+		if err == nil {
+			q.failing = false
+			q.forwarded.Add(1)
+			continue
+		}
+		q.errors.Add(1)
+		if !q.failing {
+			q.failing = true
+			q.mu.Lock()
+			report := q.report
+			q.mu.Unlock()
+			if report != nil {
+				report(fmt.Errorf("failed to forward message, dropping the messages that fail to be forwarded until the forwarder recovers, caused by %w", err))
+			}
+		}
+	}
+}
+
+// Refuse the messages to forward from now on, the queued ones are still forwarded.
+func (k *Keeper) stopForward() {
+	if q := k.forward.Load(); q != nil {
+		q.stop()
+	}
+}
+
+// Wait until the messages queued by [WithForwarder] are forwarded, once the Keeper is closed.
+func (k *Keeper) waitForward() {
+	if q := k.forward.Load(); q != nil {
+		<-q.done
+	}
+}
+
+// Wait until the messages queued by [WithForwarder] are forwarded like waitForward, or until ctx is done,
+// in which case the messages still queued are dropped. Returns the number of messages left unforwarded,
+// including the ones of the batch being forwarded.
+func (k *Keeper) waitForwardContext(ctx context.Context) int {
+	q := k.forward.Load()
+	if q == nil {
+		return 0
+	}
+	select {
+	case <-q.done:
+		return 0
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := q.count + q.sending
+	for q.count > 0 {
+		q.pop()
+	}
+	return n
+}
+
+// Get the number of messages queued by [WithForwarder].
+func (k *Keeper) forwardQueued() uint64 {
+	q := k.forward.Load()
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return uint64(q.count)
+}
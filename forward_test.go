@@ -0,0 +1,130 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A writer safe for concurrent use, failing while fail is set.
+type forwardSink struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	fail bool
+}
+
+func (s *forwardSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return 0, errors.New("collector unreachable")
+	}
+	return s.buf.Write(p)
+}
+
+func (s *forwardSink) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func TestWithForwarder(t *testing.T) {
+	folder := t.TempDir()
+	sink := &forwardSink{}
+	k, err := New(WithFolder(folder), WithName("test-forwarder"), WithForwarder(sink, 0, DropNone))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, msg := range []string{"abc\n", "def\n", "ghi\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := sink.String(); got != "abc\ndef\nghi\n" {
+		t.Errorf("expected the messages to be forwarded in order got %q", got)
+	}
+	if stats := k.Stats(); stats.Forwarded != 3 || stats.ForwardQueued != 0 {
+		t.Errorf("expected 3 forwarded messages and none queued got %+v", stats)
+	}
+}
+
+func TestWithForwarderFailing(t *testing.T) {
+	folder := t.TempDir()
+	sink := &forwardSink{fail: true}
+	var mu sync.Mutex
+	var errs []error
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-forwarder-failing"),
+		WithForwarder(sink, 0, DropNone),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{"abc\n", "def\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for k.Stats().ForwardErrors < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := k.Stats().ForwardErrors; got != 2 {
+		t.Errorf("expected 2 forward errors got %d", got)
+	}
+	mu.Lock()
+	if len(errs) != 1 {
+		t.Errorf("expected the outage to be reported once got %v", errs)
+	}
+	mu.Unlock()
+	// The log files are unaffected
+	if b, err := os.ReadFile(filepath.Join(folder, "test-forwarder-failing.log")); err != nil || string(b) != "abc\ndef\n" {
+		t.Errorf("expected %q and no error got %q and %v", "abc\ndef\n", b, err)
+	}
+}
+
+// A writer blocking until released.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestWithForwarderShutdown(t *testing.T) {
+	w := blockingWriter{release: make(chan struct{})}
+	defer close(w.release)
+	k, err := New(WithFolder(t.TempDir()), WithName("test-forwarder-shutdown"), WithForwarder(w, 0, DropNone))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for range 3 {
+		if _, err := k.Write([]byte("abc\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = k.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "messages to forward") {
+		t.Errorf("expected the messages left to forward to be reported got %v", err)
+	}
+}
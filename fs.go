@@ -0,0 +1,138 @@
+package lorekeeper
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Get a read-only [fs.FS] view of the files managed by the Keeper.
+// The view contains the current log file and the archives, named relative to their folders,
+// any other file in the folders is hidden.
+// The view is live, files appear and disappear as the Keeper rotates.
+func (k *Keeper) FS() fs.FS {
+	return keeperFS{k: k}
+}
+
+type keeperFS struct {
+	k *Keeper
+}
+
+// Make sure that keeperFS implements the [fs.ReadDirFS] interface.
+var _ fs.ReadDirFS = keeperFS{}
+
+func (f keeperFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		entries, err := f.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		stat, err := f.k.fsys.Stat(f.k.Folder())
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &dirFile{info: stat, entries: entries}, nil
+	}
+
+	for _, file := range f.k.getManagedFiles() {
+		if file.name == name {
+			f.k.acquireHandle(file.path)
+			opened, err := f.k.fsys.Open(file.path)
+			if err != nil {
+				f.k.releaseHandle(file.path)
+				return nil, err
+			}
+			return &trackedFile{File: opened, release: func() { f.k.releaseHandle(file.path) }}, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (f keeperFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	var entries []fs.DirEntry
+	for _, file := range f.k.getManagedFiles() {
+		stat, err := f.k.fsys.Stat(file.path)
+		if err != nil {
+			// The file may be removed by a rotation in the meantime
+			continue
+		}
+		entries = append(entries, renamedDirEntry{DirEntry: fs.FileInfoToDirEntry(stat), name: file.name})
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+type managedFile struct {
+	// The name of the file in the [fs.FS] view.
+	name string
+	// The path to the file on disk.
+	path string
+}
+
+// Get the current log file and the archives managed by the Keeper.
+func (k *Keeper) getManagedFiles() []managedFile {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	currentPath := k.getCurrentFilePath()
+	files := []managedFile{{name: filepath.Base(currentPath), path: currentPath}}
+	folders := k.getArchiveFolders()
+	for _, archive := range k.archives.All() {
+		rel, err := getArchiveRelPath(folders, archive.filePath)
+		if err != nil {
+			continue
+		}
+		files = append(files, managedFile{name: filepath.ToSlash(rel), path: archive.filePath})
+	}
+	return files
+}
+
+// A dirFile is the root directory of the [fs.FS] view.
+type dirFile struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+// Make sure that dirFile implements the [fs.ReadDirFile] interface.
+var _ fs.ReadDirFile = (*dirFile)(nil)
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dirFile) Close() error               { return nil }
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: errors.New("is a directory")}
+}
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
+
+// A renamedDirEntry is a [fs.DirEntry] named relative to the [fs.FS] view.
+type renamedDirEntry struct {
+	fs.DirEntry
+	name string
+}
+
+func (e renamedDirEntry) Name() string { return e.name }
@@ -0,0 +1,56 @@
+package lorekeeper
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestKeeperFS(t *testing.T) {
+	k, err := New(
+		WithName("Test-FS"),
+		WithFolder(t.TempDir()),
+		WithMaxSize(10),
+		WithArchiveNameLayout("{{ .name }}-{{ .time }}{{ .extension }}"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"0123456789", "abc"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+
+	fsys := k.FS()
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries got %d", len(entries))
+	}
+	if err := fstest.TestFS(fsys, entries[0].Name(), entries[1].Name()); err != nil {
+		t.Error(err)
+	}
+
+	content, err := fs.ReadFile(fsys, "test-fs.log")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "abc" {
+		t.Errorf("expected current log content got %q", content)
+	}
+	if _, err := fsys.Open("../test-fs.log"); err == nil {
+		t.Errorf("expected error for invalid path")
+	}
+	if _, err := fsys.Open("other.log"); err == nil {
+		t.Errorf("expected error for unmanaged file")
+	}
+}
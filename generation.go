@@ -0,0 +1,32 @@
+package lorekeeper
+
+// Get the generation of the current log file.
+// The generation starts at zero when the Keeper is created and increases by one on every rotation,
+// so the archive created by the n-th rotation since then contains the records of generation n-1.
+func (k *Keeper) Generation() uint64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.generation
+}
+
+// Write the msg like [Keeper.Write], and get the generation of the log file that received the end of it,
+// see [Keeper.Generation]. This is useful for adapters that need to correlate records to log files.
+// With [WithDoubleBuffering], this waits for the msg to be written, and the errors are returned as well.
+func (k *Keeper) WriteGeneration(msg []byte) (n int, generation uint64, err error) {
+	if err := k.throttle(len(msg)); err != nil {
+		return 0, 0, err
+	}
+	if s := k.segments.Load(); s != nil {
+		done := make(chan segmentResult, 1)
+		if _, err := s.append(msg, done); err != nil {
+			return 0, 0, err
+		}
+		result := <-done
+		return result.n, result.generation, result.err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	n, err = k.writeLocked(msg)
+	return n, k.generation, err
+}
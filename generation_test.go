@@ -0,0 +1,108 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestKeeperWriteRotateOrdering(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Opt
+	}{
+		{name: "direct", opt: WithDoubleBuffering(0)},
+		{name: "double buffering", opt: WithDoubleBuffering(256)},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := New(
+				WithFolder(t.TempDir()),
+				WithName(fmt.Sprintf("test-ordering-%d", i)),
+				WithMaxSize(0),
+				tt.opt,
+			)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+
+			const writers, writes = 8, 50
+			generations := make(map[string]uint64)
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			stop := make(chan struct{})
+			rotated := make(chan struct{})
+			go func() {
+				defer close(rotated)
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						if err := k.Rotate(); err != nil {
+							t.Errorf("expected no error got %v", err)
+						}
+					}
+				}
+			}()
+			for w := range writers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range writes {
+						msg := fmt.Sprintf("%d-%d", w, i)
+						_, generation, err := k.WriteGeneration([]byte(msg + "\n"))
+						if err != nil {
+							t.Errorf("expected no error got %v", err)
+						}
+						mu.Lock()
+						generations[msg] = generation
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+			close(stop)
+			<-rotated
+			if err := k.Close(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+
+			// Every record is in the archive of its generation, and the records of each writer are in order
+			last := make([]int, writers)
+			for w := range last {
+				last[w] = -1
+			}
+			found := 0
+			for generation, archive := range k.Archives() {
+				f, err := os.Open(archive.Path)
+				if err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+				scanner := bufio.NewScanner(f)
+				for scanner.Scan() {
+					msg := scanner.Text()
+					found++
+					if got := generations[msg]; got != uint64(generation) {
+						t.Errorf("expected %q in generation %d got %d", msg, got, generation)
+					}
+					var w, i int
+					if _, err := fmt.Sscanf(strings.Replace(msg, "-", " ", 1), "%d %d", &w, &i); err != nil {
+						t.Fatalf("expected no error got %v", err)
+					}
+					if i <= last[w] {
+						t.Errorf("expected the records of writer %d in order, got %d after %d", w, i, last[w])
+					}
+					last[w] = i
+				}
+				f.Close()
+			}
+			if found != writers*writes {
+				t.Errorf("expected %d records got %d", writers*writes, found)
+			}
+		})
+	}
+}
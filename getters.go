@@ -0,0 +1,157 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"time"
+)
+
+// Get the name of the Keeper, see [WithName].
+func (k *Keeper) Name() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.name
+}
+
+// Get the folder of the current log file, see [WithFolder].
+func (k *Keeper) Folder() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.folder
+}
+
+// Get all the folders that archives are stored in, see [WithFolders].
+// The first folder is always the folder of the current log file.
+func (k *Keeper) Folders() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]string(nil), k.getArchiveFolders()...)
+}
+
+// Get the extension of the log files, see [WithExtension].
+func (k *Keeper) Extension() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.extension
+}
+
+// Get the time layout of archive names, see [WithTimeLayout].
+func (k *Keeper) TimeLayout() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.timeLayout
+}
+
+// Get the layout of archive names, see [WithArchiveNameLayout].
+func (k *Keeper) ArchiveNameLayout() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.archiveNameLayoutText
+}
+
+// Get the maximum size in bytes per log file, see [WithMaxSize].
+func (k *Keeper) MaxSize() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.maxSize
+}
+
+// Get the maximum number of archives to keep, see [WithMaxFiles].
+func (k *Keeper) MaxFiles() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.maxFiles
+}
+
+// Get the maximum total size in bytes of all archives, see [WithTotalSize].
+func (k *Keeper) TotalSize() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.totalSize
+}
+
+// Get the maximum age of the archives, see [WithMaxAge].
+func (k *Keeper) MaxAge() time.Duration {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.maxAge
+}
+
+// Get the cron schedule of rotations, see [WithCron].
+// It is empty if no schedule is configured.
+func (k *Keeper) Schedule() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.cronSpec
+}
+
+// Get the extension appended to compressed archives, see [WithGzip].
+// It is empty if compression is disabled.
+func (k *Keeper) CompressionExtension() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.compressionExt
+}
+
+// Get the path to the current log file.
+func (k *Keeper) CurrentFilePath() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.getCurrentFilePath()
+}
+
+// Make sure that Keeper implements the [fmt.Stringer] interface.
+var _ fmt.Stringer = (*Keeper)(nil)
+
+// Summarize the effective configuration and the live state of the Keeper in one line, for example:
+//
+//	Keeper(name="app" folder="/var/log" max_size=15728640 max_files=0 total_size=0 compression="none" schedule="none" current_size=120 archives=2 archives_size=4096)
+func (k *Keeper) String() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	compression, schedule := "none", "none"
+	if len(k.compressionExt) > 0 {
+		compression = k.compressionExt
+	}
+	if len(k.cronSpec) > 0 {
+		schedule = k.cronSpec
+	}
+	archives := 0
+	if k.archives != nil {
+		archives = k.archives.Length()
+	}
+	return fmt.Sprintf(
+		"Keeper(name=%q folder=%q max_size=%d max_files=%d total_size=%d compression=%q schedule=%q current_size=%d archives=%d archives_size=%d)",
+		k.name, k.folder, k.maxSize, k.maxFiles, k.totalSize, compression, schedule,
+		k.currentFileSize, archives, k.archivesSize,
+	)
+}
+
+// Get the time of the last rotation.
+// When the Keeper is created, it is the modification time of the newest archive,
+// or the zero time if there is no archive.
+func (k *Keeper) LastRotation() time.Time {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lastRotation
+}
+
+// Get why the last rotation done by this Keeper happened.
+// It is empty if the Keeper has not rotated yet.
+func (k *Keeper) LastRotationReason() RotationReason {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lastRotationReason
+}
+
+// Get the time of the next scheduled rotation, see [WithCron].
+// It is the zero time if no schedule is configured.
+// Rotations triggered by the max size are not scheduled and can happen earlier.
+func (k *Keeper) NextRotation() time.Time {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.cronSchedule == nil {
+		return time.Time{}
+	}
+	return k.cronSchedule.Next(k.now())
+}
@@ -0,0 +1,113 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeeperGetters(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithName("Test Getters"),
+		WithFolder(folder),
+		WithExtension("txt"),
+		WithTimeLayout("20060102"),
+		WithArchiveNameLayout("{{ .name }}-{{ .time }}{{ .extension }}"),
+		WithMaxSize(Mb),
+		WithMaxFiles(3),
+		WithTotalSize(10*Mb),
+		WithCron("@daily"),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "Name", got: k.Name(), want: "test-getters"},
+		{name: "Folder", got: k.Folder(), want: folder},
+		{name: "Folders", got: len(k.Folders()), want: 1},
+		{name: "Extension", got: k.Extension(), want: ".txt"},
+		{name: "TimeLayout", got: k.TimeLayout(), want: "20060102"},
+		{name: "ArchiveNameLayout", got: k.ArchiveNameLayout(), want: "{{ .name }}-{{ .time }}{{ .extension }}"},
+		{name: "MaxSize", got: k.MaxSize(), want: Mb},
+		{name: "MaxFiles", got: k.MaxFiles(), want: 3},
+		{name: "TotalSize", got: k.TotalSize(), want: 10 * Mb},
+		{name: "Schedule", got: k.Schedule(), want: "@daily"},
+		{name: "CompressionExtension", got: k.CompressionExtension(), want: ".gz"},
+		{name: "CurrentFilePath", got: k.CurrentFilePath(), want: filepath.Join(folder, "test-getters.txt")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeeperString(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithName("Test String"),
+		WithFolder(folder),
+		WithMaxSize(10),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	want := fmt.Sprintf(
+		`Keeper(name="test-string" folder=%q max_size=10 max_files=0 total_size=0 compression=".gz" schedule="none" current_size=0 archives=0 archives_size=0)`,
+		folder,
+	)
+	if got := k.String(); got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+}
+
+func TestKeeperRotationTimes(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	k, err := New(
+		WithName("Test-Rotation-Times"),
+		WithFolder(t.TempDir()),
+		WithNowFunc(func() time.Time { return clock }),
+		WithCron("0 * * * *"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if !k.LastRotation().IsZero() {
+		t.Errorf("expected no rotation yet got %v", k.LastRotation())
+	}
+	if want := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC); !k.NextRotation().Equal(want) {
+		t.Errorf("NextRotation() = %v, want %v", k.NextRotation(), want)
+	}
+
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !k.LastRotation().Equal(clock) {
+		t.Errorf("LastRotation() = %v, want %v", k.LastRotation(), clock)
+	}
+
+	noCron, err := k.Clone(WithName("Test-Rotation-Times-No-Cron"), NoCron())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer noCron.Close()
+	if !noCron.NextRotation().IsZero() {
+		t.Errorf("expected no scheduled rotation got %v", noCron.NextRotation())
+	}
+}
@@ -1,14 +1,131 @@
 package lorekeeper
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// A [Registry] keeps track of [Keeper]s by their name,
+// so that there is no more than one Keeper of the same name in the registry at the same time.
+// All Keepers are registered in a package-level registry by default,
+// use [NewRegistry] and [WithRegistry] to scope Keepers per subsystem, or to keep tests hermetic.
+type Registry struct {
+	keepers sync.Map
+
+	// See [Registry.SetMaxConcurrentRotations] for documentation
+	mu            sync.Mutex
+	rotationSlots chan struct{}
+}
 
 // Keeping track of all Keeper instances by their name.
-var registry *sync.Map = new(sync.Map)
+var registry *Registry = NewRegistry()
+
+// Create a new empty [Registry].
+func NewRegistry() *Registry {
+	return new(Registry)
+}
+
+// Create a new [Keeper] registered in this registry, see [New].
+func (r *Registry) New(opts ...Opt) (*Keeper, error) {
+	return New(append(opts, WithRegistry(r))...)
+}
+
+// Get the registered [Keeper] of the given name, see [WithName].
+func (r *Registry) Get(name string) (*Keeper, bool) {
+	val, ok := r.keepers.Load(normalizeName(name))
+	if !ok {
+		return nil, false
+	}
+	return val.(*Keeper), true
+}
+
+// Get all the registered Keepers, sorted by their name.
+func (r *Registry) Keepers() []*Keeper {
+	var names []string
+	r.keepers.Range(func(key, _ any) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	slices.Sort(names)
+	keepers := make([]*Keeper, 0, len(names))
+	for _, name := range names {
+		// Closed in the meantime
+		if keeper, ok := r.Get(name); ok {
+			keepers = append(keepers, keeper)
+		}
+	}
+	return keepers
+}
+
+// Close all the registered Keepers.
+func (r *Registry) CloseAll() error {
+	return r.Shutdown(context.Background())
+}
+
+// Close all the registered Keepers, see [Shutdown].
+func (r *Registry) Shutdown(ctx context.Context) error {
+	keepers := r.Keepers()
+
+	results := make(chan error, len(keepers))
+	for _, keeper := range keepers {
+		go func() {
+			if err := keeper.Shutdown(ctx); err != nil {
+				results <- fmt.Errorf("failed to close keeper %q, caused by %w", keeper.Name(), err)
+				return
+			}
+			results <- nil
+		}()
+	}
+
+	var errs []error
+	for range keepers {
+		select {
+		case err := <-results:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Limit how many Keepers of the registry rotate at the same time, compression and pruning included,
+// so that a rotation scheduled at the top of the hour across many Keepers does not saturate the disk and the CPU at once.
+// A Keeper waiting for its turn blocks its writers until it rotates.
+// Set n to zero or negative to remove the limit, which is the default.
+// Rotations already waiting or in progress keep the limit they started with.
+func (r *Registry) SetMaxConcurrentRotations(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n <= 0 {
+		r.rotationSlots = nil
+		return
+	}
+	r.rotationSlots = make(chan struct{}, n)
+}
+
+// Wait for a rotation slot, see [Registry.SetMaxConcurrentRotations].
+// The returned function releases the slot.
+func (r *Registry) acquireRotation() func() {
+	r.mu.Lock()
+	slots := r.rotationSlots
+	r.mu.Unlock()
+	if slots == nil {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
 
 // Register the Keeper to the registry if it's not yet created,
 // else return the registered one.
-func register(name string, keeper *Keeper) (k *Keeper, new bool) {
-	val, loaded := registry.LoadOrStore(name, keeper)
+func (r *Registry) register(name string, keeper *Keeper) (k *Keeper, new bool) {
+	val, loaded := r.keepers.LoadOrStore(name, keeper)
 	if loaded {
 		go func() {
 			_ = keeper.free()
@@ -18,6 +135,57 @@ func register(name string, keeper *Keeper) (k *Keeper, new bool) {
 }
 
 // Unregister the Keeper of a given name.
-func unregister(name string) {
-	registry.Delete(name)
+func (r *Registry) unregister(name string) {
+	r.keepers.Delete(name)
+}
+
+// Close all the Keepers of the package-level registry, suitable for wiring into signal handlers,
+// so applications with many Keepers do not need to track them individually.
+// The Keepers are shut down concurrently with [Keeper.Shutdown], if ctx is done before all of them are closed,
+// Shutdown returns the error of ctx while the remaining Keepers give up on their unfinished work in the background.
+//
+// Example usage:
+//
+//	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer cancel()
+//	<-ctx.Done()
+//
+//	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := lorekeeper.Shutdown(shutdownCtx); err != nil {
+//		// Handle error
+//	}
+func Shutdown(ctx context.Context) error {
+	return registry.Shutdown(ctx)
+}
+
+// Get all the Keepers of the package-level registry, sorted by their name,
+// such as to report on the Keepers created dynamically per tenant or per subsystem.
+func Keepers() []*Keeper {
+	return registry.Keepers()
+}
+
+// Get the Keeper of the given name from the package-level registry, see [WithName].
+//
+// Example usage:
+//
+//	if keeper, ok := lorekeeper.Get("tenant-42"); ok {
+//		keeper.Write(msg)
+//	}
+func Get(name string) (*Keeper, bool) {
+	return registry.Get(name)
+}
+
+// Close all the Keepers of the package-level registry, waiting for all of them, see [Shutdown].
+//
+// Example usage:
+//
+//	defer lorekeeper.CloseAll()
+func CloseAll() error {
+	return registry.CloseAll()
+}
+
+// Limit how many Keepers of the package-level registry rotate at the same time, see [Registry.SetMaxConcurrentRotations].
+func SetMaxConcurrentRotations(n int) {
+	registry.SetMaxConcurrentRotations(n)
 }
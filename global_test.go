@@ -1,15 +1,23 @@
 package lorekeeper
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestRegistry(t *testing.T) {
 	keeper1, err := New(
 		WithName("unique-name"),
-		WithFolder("."),
+		WithFolder(t.TempDir()),
 		WithMaxSize(10*Mb),
 		WithCron("* * * * *"),
 		WithArchiveNameLayout("test-output-{{ .name }}{{.extension}}{{ .time }}"),
@@ -27,13 +35,15 @@ func TestRegistry(t *testing.T) {
 		t.Errorf("expect no error but got %v", err)
 	}
 
+	defer keeper1.Close()
+
 	if keeper1 != keeper2 {
 		t.Errorf("expect to be the same instance")
 	}
 	if keeper1.maxSize != keeper2.maxSize && keeper1.maxSize == 20*Mb {
 		t.Errorf("expect maxSize to be the same")
 	}
-	if keeper1.cronScheduler != nil {
+	if keeper1.cronSchedule != nil {
 		t.Errorf("expect cron to be stop")
 	}
 
@@ -53,12 +63,13 @@ func TestRegistry(t *testing.T) {
 	// Create new keeper
 	keeper3, err := New(
 		WithName("another-unique-name"),
-		WithFolder("."),
+		WithFolder(t.TempDir()),
 		WithMaxSize(10*Mb),
 	)
 	if err != nil {
 		t.Errorf("expect no error but got %v", err)
 	}
+	defer keeper3.Close()
 	if (keeper1 == keeper3) || (keeper2 == keeper3) {
 		t.Errorf("expect to be not the same instance")
 	}
@@ -67,3 +78,175 @@ func TestRegistry(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestShutdown(t *testing.T) {
+	// A private registry, so that the Keepers of the other tests are left open
+	r := NewRegistry()
+	folder := t.TempDir()
+	var keepers []*Keeper
+	for _, name := range []string{"Test-Shutdown-1", "Test-Shutdown-2"} {
+		k, err := r.New(WithName(name), WithFolder(folder))
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		keepers = append(keepers, k)
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, k := range keepers {
+		if _, ok := r.Get(k.name); ok {
+			t.Errorf("expected %s to be unregistered", k.name)
+		}
+		if _, err := k.Write([]byte("closed")); err == nil {
+			t.Errorf("expected %s to be closed", k.name)
+		}
+	}
+
+	k, err := r.New(WithName("Test-Shutdown-Canceled"), WithFolder(folder))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	// Hold the lock so the Keeper can not be closed
+	k.mu.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context error got %v", err)
+	}
+	k.mu.Unlock()
+}
+
+func TestRegistryScoped(t *testing.T) {
+	first, second := NewRegistry(), NewRegistry()
+	k1, err := first.New(WithName("Test Scoped"), WithFolder(t.TempDir()))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	k2, err := New(WithName("Test Scoped"), WithFolder(t.TempDir()), WithRegistry(second))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if k1 == k2 {
+		t.Errorf("expected keepers in different registries to be independent")
+	}
+
+	if got, ok := first.Get("Test Scoped"); !ok || got != k1 {
+		t.Errorf("expected to get the keeper from its registry")
+	}
+	if _, ok := registry.Get("Test Scoped"); ok {
+		t.Errorf("expected the package-level registry to be untouched")
+	}
+
+	if err := first.CloseAll(); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if _, ok := first.Get("Test Scoped"); ok {
+		t.Errorf("expected the registry to be empty")
+	}
+	if _, ok := second.Get("Test Scoped"); !ok {
+		t.Errorf("expected the other registry to be untouched")
+	}
+	if err := second.CloseAll(); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+}
+
+// A compressor that tracks how many compressions run at the same time.
+type trackingCompressor struct {
+	active, peak *atomic.Int32
+}
+
+func (c trackingCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	n := c.active.Add(1)
+	for p := c.peak.Load(); n > p && !c.peak.CompareAndSwap(p, n); p = c.peak.Load() {
+	}
+	time.Sleep(10 * time.Millisecond)
+	return trackingWriter{Writer: w, active: c.active}, nil
+}
+
+func (c trackingCompressor) Extension() string {
+	return ".raw"
+}
+
+type trackingWriter struct {
+	io.Writer
+	active *atomic.Int32
+}
+
+func (w trackingWriter) Close() error {
+	w.active.Add(-1)
+	return nil
+}
+
+func TestRegistryMaxConcurrentRotations(t *testing.T) {
+	r := NewRegistry()
+	r.SetMaxConcurrentRotations(1)
+	var active, peak atomic.Int32
+	compressor := trackingCompressor{active: &active, peak: &peak}
+
+	folder := t.TempDir()
+	var keepers []*Keeper
+	for i := range 4 {
+		k, err := r.New(WithFolder(folder), WithName(fmt.Sprintf("test-max-rotations-%d", i)), WithCompressor(compressor))
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		keepers = append(keepers, k)
+	}
+	defer r.CloseAll()
+
+	var wg sync.WaitGroup
+	for _, k := range keepers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := k.Rotate(); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got != 1 {
+		t.Errorf("expected 1 rotation at a time got %d", got)
+	}
+}
+
+func TestKeepersGetCloseAll(t *testing.T) {
+	folder := t.TempDir()
+	names := []string{"Test-Close-All-2", "Test-Close-All-1"}
+	for _, name := range names {
+		if _, err := New(WithName(name), WithFolder(folder)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	var listed []string
+	for _, k := range Keepers() {
+		if strings.HasPrefix(k.Name(), "test-close-all-") {
+			listed = append(listed, k.Name())
+		}
+	}
+	if !slices.Equal(listed, []string{"test-close-all-1", "test-close-all-2"}) {
+		t.Errorf("expected the Keepers sorted by name got %v", listed)
+	}
+	// The name is normalized
+	k, ok := Get("Test-Close-All-1")
+	if !ok || k.Name() != "test-close-all-1" {
+		t.Fatalf("expected to get Test-Close-All-1")
+	}
+
+	if err := CloseAll(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, name := range names {
+		if _, ok := Get(name); ok {
+			t.Errorf("expected %s to be unregistered", name)
+		}
+	}
+	if _, err := k.Write([]byte("closed")); err == nil {
+		t.Errorf("expected %s to be closed", k.Name())
+	}
+}
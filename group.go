@@ -0,0 +1,259 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// A [Group] owns several [Keeper]s sharing a total size budget and a pool of background workers,
+// for services writing many different log files on one disk, where per-Keeper budgets do not compose.
+// When the archives of all the members exceed the budget, the oldest archives across all the members are removed first.
+// The members keep their own retention settings as well, see [WithMaxFiles] and [WithTotalSize].
+// Use [NewGroup] to create a new Group.
+type Group struct {
+	totalSize int
+	workers   int
+	// Bounds the background work of all the members together
+	slots chan struct{}
+
+	mu      sync.Mutex
+	members []*Keeper
+	closed  bool
+
+	// Serializes the prunes of the group
+	pruneMu sync.Mutex
+	trigger chan struct{}
+	done    chan struct{}
+}
+
+// Create a new [Group] whose members share a budget of totalSize bytes of archives,
+// and at most workers goroutines for their background work, such as removing the expired archives.
+// Set totalSize to zero or negative to only share the workers.
+//
+// Example usage:
+//
+//	group, err := lorekeeper.NewGroup(10*lorekeeper.Gb, 4)
+//	access, err := group.New(lorekeeper.WithName("access"))
+//	audit, err := group.New(lorekeeper.WithName("audit"))
+func NewGroup(totalSize, workers int) (*Group, error) {
+	if workers < 1 {
+		return nil, fmt.Errorf("failed to create group, expected at least 1 worker got %d", workers)
+	}
+	g := &Group{
+		totalSize: totalSize,
+		workers:   workers,
+		slots:     make(chan struct{}, workers),
+		trigger:   make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	go g.pruneOnTrigger()
+	return g, nil
+}
+
+// Create a new [Keeper] with the given options and add it to the group, see [Group.Add].
+func (g *Group) New(opts ...Opt) (*Keeper, error) {
+	k, err := New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group member, caused by %w", err)
+	}
+	if err := g.Add(k); err != nil {
+		return nil, errors.Join(err, k.Close())
+	}
+	return k, nil
+}
+
+// Add the [Keeper] to the group, the group then owns it and closes it on [Group.Close].
+// A Keeper belongs to at most one group, and leaves it once closed.
+func (g *Group) Add(k *Keeper) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return fmt.Errorf("failed to add keeper to group, the group is closed")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.group != nil {
+		return fmt.Errorf("failed to add keeper %q to group, it already belongs to a group", k.name)
+	}
+	if k.audit {
+		return fmt.Errorf("failed to add keeper %q to group, the archives of an audit keeper are never deleted", k.name)
+	}
+	k.group = g
+	g.members = append(g.members, k)
+	g.schedulePrune()
+	return nil
+}
+
+// Get the members of the group.
+func (g *Group) Keepers() []*Keeper {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.members)
+}
+
+// Remove the oldest archives across all the members until they fit in the total size budget of the group.
+// This happens in the background after every rotation of a member, calling it is only needed to prune right away.
+// Archives that could not be removed are kept and retried on the next prune.
+func (g *Group) Prune() error {
+	g.pruneMu.Lock()
+	defer g.pruneMu.Unlock()
+	if g.totalSize <= 0 {
+		return nil
+	}
+
+	members := g.Keepers()
+	total := 0
+	for _, k := range members {
+		k.mu.Lock()
+		total += k.archivesSize
+		k.mu.Unlock()
+	}
+
+	// The archives of each member are ordered from oldest to newest,
+	// so the oldest archive of the group is always the first archive of one of the members.
+	// Only one member is locked at a time, so that this never deadlocks with a rotating member.
+	type groupArchive struct {
+		keeper  *Keeper
+		archive *fileInfo
+	}
+	var expired []groupArchive
+	for total > g.totalSize {
+		var oldest groupArchive
+		for _, k := range members {
+			k.mu.Lock()
+			first, err := k.archives.Index(0)
+			k.mu.Unlock()
+			if err == nil && (oldest.archive == nil || first.modtime.Before(oldest.archive.modtime)) {
+				oldest = groupArchive{keeper: k, archive: first}
+			}
+		}
+		if oldest.archive == nil {
+			break
+		}
+
+		k := oldest.keeper
+		k.mu.Lock()
+		// The member may have pruned the archive in the meantime
+		if first, err := k.archives.Index(0); err == nil && first == oldest.archive {
+			_, _ = k.archives.Dequeue()
+			k.archivesSize -= oldest.archive.size
+			expire(oldest.archive, DeletionGroupTotalSize, fmt.Sprintf("%d bytes of archives over the group total size of %d", total, g.totalSize))
+			// An archive open for reading is removed by its member once closed, see WithDeferredRemoval
+			if !k.deferredRemoval || len(k.deferOpenArchives([]*fileInfo{oldest.archive})) > 0 {
+				expired = append(expired, oldest)
+			}
+		}
+		k.mu.Unlock()
+		total -= oldest.archive.size
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(expired))
+	g.run(len(expired), func(i int) {
+		errs[i] = removeArchive(expired[i].keeper.fsys, expired[i].archive)
+	})
+
+	failed := make(map[*Keeper][]*fileInfo)
+	var failures []error
+	for i, removed := range expired {
+		k := removed.keeper
+		k.mu.Lock()
+		if errs[i] != nil {
+			failed[k] = append(failed[k], removed.archive)
+			failures = append(failures, errs[i])
+			k.archivesSize += removed.archive.size
+			k.stats.RemoveErrors++
+		} else {
+			k.stats.RemovedArchives++
+			k.recordDeletion(removed.archive)
+		}
+		k.mu.Unlock()
+	}
+	for k, archives := range failed {
+		k.mu.Lock()
+		// Prepend adds the values one by one at the start, so the newest of the failed archives goes first
+		slices.Reverse(archives)
+		k.archives.Prepend(archives...)
+		k.mu.Unlock()
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf(
+			"failed to remove %d of %d expired archives of the group, caused by %w",
+			len(failures), len(expired), errors.Join(failures...),
+		)
+	}
+	return nil
+}
+
+// Remove the closed [Keeper] from the members of the group, see [Keeper.Close].
+func (g *Group) detach(k *Keeper) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.members = slices.DeleteFunc(g.members, func(member *Keeper) bool { return member == k })
+}
+
+// Close all the members and stop the background prunes of the group.
+func (g *Group) Close() error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return nil
+	}
+	g.closed = true
+	members := g.members
+	g.members = nil
+	close(g.done)
+	g.mu.Unlock()
+
+	var errs []error
+	for _, k := range members {
+		if err := k.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close keeper %q, caused by %w", k.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ask for a prune in the background, coalescing with the prune already asked for if any.
+func (g *Group) schedulePrune() {
+	select {
+	case g.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (g *Group) pruneOnTrigger() {
+	for {
+		select {
+		case <-g.done:
+			return
+		case <-g.trigger:
+			if err := g.Prune(); err != nil {
+				g.handleError(err)
+			}
+		}
+	}
+}
+
+// Report an error of the group to the error handlers of the members, see [WithErrorHandler].
+func (g *Group) handleError(err error) {
+	for _, k := range g.Keepers() {
+		k.mu.Lock()
+		k.handleError(err)
+		k.mu.Unlock()
+	}
+}
+
+// Call fn for every index in [0, count), sharing the workers of the group with all its members.
+func (g *Group) run(count int, fn func(i int)) {
+	runWorkers(g.workers, count, func(i int) {
+		g.slots <- struct{}{}
+		defer func() { <-g.slots }()
+		fn(i)
+	})
+}
@@ -0,0 +1,130 @@
+package lorekeeper
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGroupPrune(t *testing.T) {
+	group, err := NewGroup(25, 2)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer group.Close()
+
+	folder := t.TempDir()
+	a, err := group.New(WithFolder(folder), WithName("test-group-a"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	b, err := group.New(WithFolder(folder), WithName("test-group-b"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// Three archives of 10 bytes, the oldest one belongs to a
+	for _, k := range []*Keeper{a, b, a} {
+		if _, err := k.Write([]byte(strings.Repeat("x", 10))); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := group.Prune(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if len(a.Archives()) != 1 || len(b.Archives()) != 1 {
+		t.Errorf("expected 1 archive each got %d and %d", len(a.Archives()), len(b.Archives()))
+	}
+	if got := a.Stats().RemovedArchives + b.Stats().RemovedArchives; got != 1 {
+		t.Errorf("expected 1 removed archive got %d", got)
+	}
+	if a.Stats().RemovedArchives != 1 {
+		t.Errorf("expected the oldest archive of the group to be removed")
+	}
+	if len(group.Keepers()) != 2 {
+		t.Errorf("expected 2 members got %d", len(group.Keepers()))
+	}
+	if err := group.Add(a); err == nil {
+		t.Errorf("expected an error when adding a member twice")
+	}
+}
+
+func TestGroupPruneDeferredRemoval(t *testing.T) {
+	group, err := NewGroup(15, 1)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer group.Close()
+
+	k, err := group.New(WithFolder(t.TempDir()), WithName("test-group-deferred"), WithDeferredRemoval())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte(strings.Repeat("x", 10))); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	oldest := k.Archives()[0].Path
+	f, err := k.FS().Open(filepath.Base(oldest))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The oldest archive goes over the budget of the group while it is open
+	if _, err := k.Write([]byte(strings.Repeat("x", 10))); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := group.Prune(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(oldest); err != nil {
+		t.Fatalf("expected the open archive to be kept got %v", err)
+	}
+	if stats := k.Stats(); stats.DeferredRemovals != 1 || stats.RemovedArchives != 0 {
+		t.Errorf("expected 1 deferred removal and no removed archive got %+v", stats)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(oldest); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the archive to be removed once closed got %v", err)
+	}
+}
+
+func TestGroupCloseMember(t *testing.T) {
+	group, err := NewGroup(0, 1)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer group.Close()
+
+	k, err := group.New(WithFolder(t.TempDir()), WithName("test-group-close-member"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(group.Keepers()) != 0 {
+		t.Errorf("expected the closed keeper to leave the group got %d members", len(group.Keepers()))
+	}
+}
+
+func TestNewGroupInvalidWorkers(t *testing.T) {
+	if _, err := NewGroup(0, 0); err == nil {
+		t.Errorf("expected an error for 0 workers")
+	}
+}
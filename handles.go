@@ -0,0 +1,97 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// Count a reader of the log file at path, see [WithDeferredRemoval].
+func (k *Keeper) acquireHandle(path string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.openHandles == nil {
+		k.openHandles = make(map[string]int)
+	}
+	k.openHandles[path]++
+}
+
+// Release a reader of the log file at path, removing the file if it expired while being read.
+func (k *Keeper) releaseHandle(path string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.openHandles[path]--
+	if k.openHandles[path] > 0 {
+		return
+	}
+	delete(k.openHandles, path)
+
+	archive, ok := k.pendingRemovals[path]
+	if !ok {
+		return
+	}
+	delete(k.pendingRemovals, path)
+	if err := removeArchive(k.fsys, archive); err != nil {
+		k.stats.RemoveErrors++
+		k.handleError(err)
+		return
+	}
+	k.stats.RemovedArchives++
+	k.recordDeletion(archive)
+}
+
+// Set aside the expired archives that are open for reading, returning the ones that can be removed right away.
+func (k *Keeper) deferOpenArchives(expired []*fileInfo) []*fileInfo {
+	removable := expired[:0]
+	for _, archive := range expired {
+		if k.openHandles[archive.filePath] == 0 {
+			removable = append(removable, archive)
+			continue
+		}
+		if k.pendingRemovals == nil {
+			k.pendingRemovals = make(map[string]*fileInfo)
+		}
+		k.pendingRemovals[archive.filePath] = archive
+		k.stats.DeferredRemovals++
+	}
+	return removable
+}
+
+// A trackedReader releases its handle once closed.
+type trackedReader struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *trackedReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// A trackedFile releases its handle once closed.
+type trackedFile struct {
+	fs.File
+	once    sync.Once
+	release func()
+}
+
+// Seek within the file if the [FS] supports it, such as to serve it with [http.FS].
+func (f *trackedFile) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := f.File.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("failed to seek, caused by %w", errors.ErrUnsupported)
+	}
+	return seeker.Seek(offset, whence)
+}
+
+func (f *trackedFile) Close() error {
+	err := f.File.Close()
+	f.once.Do(f.release)
+	return err
+}
@@ -0,0 +1,84 @@
+package lorekeeper
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeeperDeferredRemoval(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-deferred-removal"),
+		WithMaxFiles(1),
+		WithDeferredRemoval(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("first\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	oldest := k.Archives()[0].Path
+	f, err := k.FS().Open(filepath.Base(oldest))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The oldest archive expires while it is open
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(oldest); err != nil {
+		t.Fatalf("expected the open archive to be kept got %v", err)
+	}
+	if stats := k.Stats(); stats.DeferredRemovals != 1 || stats.RemovedArchives != 0 {
+		t.Errorf("expected 1 deferred removal and no removed archive got %+v", stats)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(oldest); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the archive to be removed once closed got %v", err)
+	}
+	if stats := k.Stats(); stats.RemovedArchives != 1 {
+		t.Errorf("expected 1 removed archive got %+v", stats)
+	}
+}
+
+func TestKeeperNoDeferredRemoval(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-no-deferred-removal"),
+		WithMaxFiles(1),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	oldest := k.Archives()[0].Path
+	f, err := k.FS().Open(filepath.Base(oldest))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer f.Close()
+
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(oldest); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the archive to be removed right away got %v", err)
+	}
+}
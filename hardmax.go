@@ -0,0 +1,122 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Returned, wrapped, by [Keeper.Write] when the message does not fit under the hard max size, see [WithHardMaxSize].
+var ErrHardMaxSize = errors.New("hard max size exceeded")
+
+// A HardMaxPolicy decides what happens to a message that does not fit under the hard max size of [WithHardMaxSize].
+type HardMaxPolicy int
+
+const (
+	// Split the message at the hard max size, writing the rest of it to the next log files.
+	HardMaxSplit HardMaxPolicy = iota
+	// Reject the message, it goes to the fallback writer of [WithFallbackWriter] if any.
+	HardMaxReject
+)
+
+// Guarantee that no log file ever exceeds size bytes, such as to stay under the limit of a transfer or of a tool,
+// while [WithMaxSize] stays the soft max size that triggers the rotations.
+// The soft max size lets a log file grow past it, for example with a record of [WithRecordPattern] that does not fit,
+// with a length-prefixed record of [WithLengthPrefixedRecords], or while the rotations are held by [Keeper.WithRotationPaused].
+// The hard max size rotates before any message that would exceed it, and if the message does not fit in a log file
+// of its own either, the policy decides whether it is split across log files, or rejected with an error wrapping [ErrHardMaxSize].
+// While the rotations are held, the messages that do not fit are rejected with either policy.
+// The rejected messages are counted in [Stats.HardMaxRejections], they are also counted as dropped or fallback writes.
+// [HardMaxSplit] can not be used together with [WithHashChain] or [WithLengthPrefixedRecords], whose records can not be split.
+// Set size < 1 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	// Rotate at 90 Mb, but never upload a file over 100 Mb
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithMaxSize(90*lorekeeper.Mb),
+//		lorekeeper.WithHardMaxSize(100*lorekeeper.Mb, lorekeeper.HardMaxSplit),
+//	)
+func WithHardMaxSize(size int, policy HardMaxPolicy) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if policy != HardMaxSplit && policy != HardMaxReject {
+			return nil, fmt.Errorf("failed to set hard max size, unknown policy %d", policy)
+		}
+		k.hardMaxSize = max(size, 0)
+		k.hardMaxPolicy = policy
+		return k, nil
+	}
+}
+
+// Reject the combinations of [HardMaxSplit] with the records that can not be split.
+func (k *Keeper) applyHardMaxSize() error {
+	if k.hardMaxSize == 0 || k.hardMaxPolicy != HardMaxSplit {
+		return nil
+	}
+	if k.chain != nil {
+		return fmt.Errorf("failed to set hard max size, splitting can not be used together with hash chain")
+	}
+	if k.lengthPrefixed {
+		return fmt.Errorf("failed to set hard max size, splitting can not be used together with length-prefixed records")
+	}
+	return nil
+}
+
+// Check whether writing n more bytes would exceed the hard max size of [WithHardMaxSize].
+func (k *Keeper) hardMaxExceeded(n int) bool {
+	return k.hardMaxSize > 0 && k.currentFileSize+n > k.hardMaxSize
+}
+
+// Check whether a message of n bytes needs a new log file to stay under the hard max size of [WithHardMaxSize].
+// A message that would be rejected from an empty log file as well is not worth a rotation.
+func (k *Keeper) rotatesForHardMax(n int) bool {
+	if k.currentFileSize == 0 || !k.hardMaxExceeded(n) {
+		return false
+	}
+	return k.hardMaxPolicy == HardMaxSplit || n <= k.hardMaxSize
+}
+
+// Get the error of a message rejected by the hard max size of [WithHardMaxSize].
+func (k *Keeper) hardMaxError(n int) error {
+	k.stats.HardMaxRejections++
+	return fmt.Errorf(
+		"failed to write to current log file, %d bytes do not fit in %d bytes under the hard max size of %d, caused by %w",
+		n, max(k.hardMaxSize-k.currentFileSize, 0), k.hardMaxSize, ErrHardMaxSize,
+	)
+}
+
+// Write a msg that does not fit under the hard max size, rotating and splitting it according to the policy.
+func (k *Keeper) writeHardMax(msg []byte) (int, error) {
+	if k.hardMaxPolicy == HardMaxReject {
+		if k.rotatesForHardMax(len(msg)) {
+			if err := k.rotate(RotationSize); err != nil {
+				return 0, err
+			}
+		}
+		return k.writeChecked(msg)
+	}
+
+	written := 0
+	for written < len(msg) {
+		room := k.hardMaxSize - k.currentFileSize
+		if room <= 0 {
+			size := k.currentFileSize
+			if size > 0 {
+				if err := k.rotate(RotationSize); err != nil {
+					return written, err
+				}
+			}
+			// The rotations are held, or the header of the log file leaves no room
+			if k.currentFileSize == size || k.currentFileSize >= k.hardMaxSize {
+				n, err := k.writeChecked(msg[written:])
+				return written + n, err
+			}
+			continue
+		}
+		n, err := k.writeChecked(msg[written:min(len(msg), written+room)])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
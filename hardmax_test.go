@@ -0,0 +1,106 @@
+package lorekeeper
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithHardMaxSizeSplit(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-hard-max-split"),
+		WithMaxSize(0),
+		WithHardMaxSize(10, HardMaxSplit),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	msg := []byte(strings.Repeat("0123456789", 2) + "01234\n")
+	if n, err := k.Write(msg); err != nil || n != len(msg) {
+		t.Fatalf("expected %d bytes written and no error got %d and %v", len(msg), n, err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	var sizes []int
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if info.Size() > 10 {
+			t.Errorf("expected %s to be at most 10 bytes got %d", entry.Name(), info.Size())
+		}
+		sizes = append(sizes, int(info.Size()))
+	}
+	slices.Sort(sizes)
+	// The small message gets a log file of its own, then the large one fills up the next log files
+	if !slices.Equal(sizes, []int{0, 4, 6, 10, 10}) {
+		t.Errorf("expected sizes [0 4 6 10 10] got %v", sizes)
+	}
+}
+
+func TestWithHardMaxSizeReject(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-hard-max-reject"),
+		WithMaxSize(0),
+		WithHardMaxSize(10, HardMaxReject),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"abcdef\n", "ghijkl\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if _, err := k.Write([]byte("0123456789\n")); !errors.Is(err, ErrHardMaxSize) {
+		t.Fatalf("expected error %v got %v", ErrHardMaxSize, err)
+	}
+	stats := k.Stats()
+	if stats.Rotations != 1 || stats.HardMaxRejections != 1 || stats.Dropped != 1 {
+		t.Errorf("expected 1 rotation and 1 rejected message got %+v", stats)
+	}
+	content, err := os.ReadFile(filepath.Join(folder, "test-hard-max-reject.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "ghijkl\n" {
+		t.Errorf("expected %q got %q", "ghijkl\n", content)
+	}
+}
+
+func TestWithHardMaxSizeInvalid(t *testing.T) {
+	folder := t.TempDir()
+	if _, err := New(WithFolder(folder), WithName("test-hard-max-policy"), WithHardMaxSize(10, HardMaxPolicy(5))); err == nil {
+		t.Errorf("expected an error for an unknown policy")
+	}
+	if _, err := New(
+		WithFolder(folder),
+		WithName("test-hard-max-records"),
+		WithLengthPrefixedRecords(),
+		WithHardMaxSize(10, HardMaxSplit),
+	); err == nil {
+		t.Errorf("expected an error for splitting length-prefixed records")
+	}
+	if err := ValidateOptions(WithMaxSize(20), WithHardMaxSize(10, HardMaxReject)); !errors.Is(err, ErrOptionWarning) {
+		t.Errorf("expected error %v got %v", ErrOptionWarning, err)
+	}
+}
@@ -0,0 +1,129 @@
+package lorekeeper
+
+import (
+	"sync"
+)
+
+// Call fn with the path of every archive once its rotation completes, such as to ship it to an object storage.
+// The path is the one of the compressed archive if the archive is compressed.
+// fn runs outside the lock of the Keeper, on a goroutine dedicated to the hooks, in the order of the rotations,
+// so it may call the methods of the Keeper except [Keeper.Close], and a slow fn does not block the writers.
+// [Keeper.Close] waits for the pending hooks.
+// A nil fn disables the hook, which is the default.
+//
+// Example usage:
+//
+//	lorekeeper.WithOnRotate(func(archivePath string) {
+//		uploads <- archivePath
+//	})
+func WithOnRotate(fn func(archivePath string)) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.onRotate = fn
+		return k, nil
+	}
+}
+
+// Call fn with the path of every archive removed by the retention, such as to record metrics.
+// fn runs like the hook of [WithOnRotate], see [Keeper.Deletions] for why the archive was removed.
+// A nil fn disables the hook, which is the default.
+func WithOnArchiveRemoved(fn func(path string)) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.onArchiveRemoved = fn
+		return k, nil
+	}
+}
+
+// A hookQueue runs the hooks one at a time in order on a goroutine of its own,
+// started when a hook is queued and stopped once the queue is empty.
+// In manual mode, the hooks only run when drained, see [WithManualStepping].
+type hookQueue struct {
+	mu      sync.Mutex
+	idle    *sync.Cond
+	queue   []func()
+	running bool
+	manual  bool
+}
+
+// Queue the hook, it never blocks.
+func (q *hookQueue) enqueue(hook func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queue = append(q.queue, hook)
+	if !q.running && !q.manual {
+		q.running = true
+		go q.run()
+	}
+}
+
+func (q *hookQueue) setManual(manual bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.manual = manual
+}
+
+// Run the queued hooks on the calling goroutine.
+func (q *hookQueue) drain() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.queue) > 0 {
+		hook := q.queue[0]
+		q.queue[0] = nil
+		q.queue = q.queue[1:]
+		q.mu.Unlock()
+		hook()
+		q.mu.Lock()
+	}
+}
+
+func (q *hookQueue) run() {
+	q.mu.Lock()
+	for len(q.queue) > 0 {
+		hook := q.queue[0]
+		q.queue[0] = nil
+		q.queue = q.queue[1:]
+		q.mu.Unlock()
+		hook()
+		q.mu.Lock()
+	}
+	q.running = false
+	if q.idle != nil {
+		q.idle.Broadcast()
+	}
+	q.mu.Unlock()
+}
+
+// Wait until all the queued hooks ran, running them in manual mode.
+func (q *hookQueue) wait() {
+	q.drainIfManual()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.idle == nil {
+		q.idle = sync.NewCond(&q.mu)
+	}
+	for q.running {
+		q.idle.Wait()
+	}
+}
+
+func (q *hookQueue) drainIfManual() {
+	q.mu.Lock()
+	manual := q.manual
+	q.mu.Unlock()
+	if manual {
+		q.drain()
+	}
+}
+
+// Queue the hook of [WithOnRotate] for the archive, the lock of the Keeper must be held.
+func (k *Keeper) notifyRotate(archivePath string) {
+	if fn := k.onRotate; fn != nil {
+		k.hooks.enqueue(k.supervised("hooks", func() { fn(archivePath) }))
+	}
+}
+
+// Queue the hook of [WithOnArchiveRemoved] for the archive, the lock of the Keeper must be held.
+func (k *Keeper) notifyArchiveRemoved(path string) {
+	if fn := k.onArchiveRemoved; fn != nil {
+		k.hooks.enqueue(k.supervised("hooks", func() { fn(path) }))
+	}
+}
@@ -0,0 +1,54 @@
+package lorekeeper
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestKeeperHooks(t *testing.T) {
+	var mu sync.Mutex
+	var rotated, removed []string
+	var k *Keeper
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-hooks"),
+		WithMaxFiles(1),
+		WithOnRotate(func(archivePath string) {
+			// The hooks run outside the lock, so they may call the Keeper
+			_ = k.Archives()
+			mu.Lock()
+			defer mu.Unlock()
+			rotated = append(rotated, archivePath)
+		}),
+		WithOnArchiveRemoved(func(path string) {
+			mu.Lock()
+			defer mu.Unlock()
+			removed = append(removed, path)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if _, err := k.Write([]byte("message\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("message\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(rotated) != 2 {
+		t.Fatalf("expected 2 rotations got %q", rotated)
+	}
+	if len(removed) != 1 || removed[0] != rotated[0] {
+		t.Errorf("expected the first archive %q to be removed got %q", rotated[0], removed)
+	}
+}
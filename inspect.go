@@ -0,0 +1,305 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"regexp"
+	"time"
+)
+
+// An [Inspector] reads the log files of a folder managed by a [Keeper], possibly in another process,
+// without ever writing, renaming or deleting any file.
+// Use [Open] to create a new Inspector.
+type Inspector struct {
+	k *Keeper
+	// See [Inspector.IncludeCurrentFile] for documentation
+	current currentFilePolicy
+}
+
+// Whether the listing and the readers of an [Inspector] see the current log file.
+type currentFilePolicy int
+
+const (
+	// Only the readers see the current log file, the listing does not
+	currentFileInReaders currentFilePolicy = iota
+	currentFileIncluded
+	currentFileExcluded
+)
+
+// A GrepMatch is a line matched by [Inspector.Grep].
+type GrepMatch struct {
+	// Path to the log file containing the line.
+	Path string
+	// Line number of the line in the decompressed log file, starting at 1.
+	Line int
+	// Content of the line, without the trailing new line.
+	Text string
+}
+
+// Create a new [Inspector] of the log files that a Keeper created with the same options manages.
+// The options are only used for the naming and the glob logic,
+// the Inspector does not open any file, start any background goroutine, or register itself.
+// This is useful for tools that need to inspect a folder managed by another process without interfering with it.
+//
+// Example usage:
+//
+//	inspector, err := lorekeeper.Open(lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithName("app"), lorekeeper.WithGzip())
+//	matches, err := inspector.Grep(regexp.MustCompile("ERROR"))
+func Open(opts ...Opt) (*Inspector, error) {
+	k, err := configureDetached(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inspector, caused by %w", err)
+	}
+	return &Inspector{k: k}, nil
+}
+
+// Get the path to the current log file.
+func (i *Inspector) CurrentFilePath() string {
+	return i.k.getCurrentFilePath()
+}
+
+// Get a copy of the Inspector whose [Inspector.ListArchives], [Inspector.Reader], [Inspector.Grep] and [Inspector.Export]
+// all see the current log file if include is true, or only the archives if include is false.
+// Only the finished files suit shipping, since the current log file still grows,
+// while everything suits debugging.
+// By default, the listing only has the archives and the readers also read the current log file.
+//
+// The files are listed first and read afterward, one at a time.
+// The current log file is listed with its size at the time of the listing,
+// and is read up to its end at the time it is reached, so it may have grown in between.
+// If the managing Keeper rotates in between, the content of the current log file is moved to an archive
+// that is not in the listing, and the new current log file is read instead, so that content is missed.
+//
+// Example usage:
+//
+//	finished, err := inspector.IncludeCurrentFile(false).ListArchives()
+func (i *Inspector) IncludeCurrentFile(include bool) *Inspector {
+	current := currentFileExcluded
+	if include {
+		current = currentFileIncluded
+	}
+	return &Inspector{k: i.k, current: current}
+}
+
+// List the archives found in the archive folders, ordered from oldest to newest,
+// followed by the current log file if it exists and [Inspector.IncludeCurrentFile] is set.
+// The folders are scanned again on every call.
+func (i *Inspector) ListArchives() ([]ArchiveInfo, error) {
+	found, _, err := i.k.getArchives()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives, caused by %w", err)
+	}
+	archives := make([]ArchiveInfo, 0, found.Length()+1)
+	for _, archive := range found.All() {
+		archives = append(archives, archive.toArchiveInfo(i.k.isCompressedArchive(archive.filePath)))
+	}
+	if i.current != currentFileIncluded {
+		return archives, nil
+	}
+	current, err := getFileInfo(i.k.fsys, i.CurrentFilePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return archives, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list current log file, caused by %w", err)
+	}
+	return append(archives, current.toArchiveInfo(false)), nil
+}
+
+// Get a reader of the content of all the log files, from the oldest archive to the current log file,
+// unless it is excluded with [Inspector.IncludeCurrentFile].
+// Compressed archives are decompressed on the fly, and files are opened one at a time.
+// Files removed after listing them, for example by the retention of the managing Keeper, are skipped.
+func (i *Inspector) Reader() (io.ReadCloser, error) {
+	paths, err := i.paths()
+	if err != nil {
+		return nil, err
+	}
+	return &logFilesReader{paths: paths, open: i.openLogFile}, nil
+}
+
+// Find the lines matching re in all the log files, from the oldest archive to the current log file.
+// Files removed after listing them are skipped.
+func (i *Inspector) Grep(re *regexp.Regexp) ([]GrepMatch, error) {
+	paths, err := i.paths()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []GrepMatch
+	for _, path := range paths {
+		found, err := i.grepLogFile(path, re)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return matches, fmt.Errorf("failed to grep %q, caused by %w", path, err)
+		}
+		matches = append(matches, found...)
+	}
+	return matches, nil
+}
+
+// Open the log file at path for reading, such as one listed by [Inspector.ListArchives],
+// decompressing it on the fly if it is a compressed archive.
+func (i *Inspector) OpenFile(path string) (io.ReadCloser, error) {
+	reader, err := i.openLogFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q, caused by %w", path, err)
+	}
+	return reader, nil
+}
+
+// Iterate over the records written between from and to, both inclusive, from the oldest to the newest,
+// like [Keeper.Between] does for the log files of a running Keeper. The folders are scanned again on every call,
+// and the current log file is read unless it is excluded with [Inspector.IncludeCurrentFile].
+func (i *Inspector) Between(from, to time.Time) iter.Seq2[[]byte, error] {
+	return trimRecords(i.recordsBetween(from, to), i.k.timestampParser, from, to)
+}
+
+// Iterate over the records written between from and to, both inclusive, with their timestamps,
+// like [Keeper.Timeline] does for the log files of a running Keeper, see [Inspector.Between].
+// The parser of [WithTimestampParser] is required.
+func (i *Inspector) Timeline(from, to time.Time) iter.Seq2[TimedRecord, error] {
+	return timeline(i.recordsBetween(from, to), i.k.timestampParser, from, to)
+}
+
+// Iterate over all the records of the log files that may contain records written between from and to.
+func (i *Inspector) recordsBetween(from, to time.Time) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		found, _, err := i.k.getArchives()
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to list archives, caused by %w", err))
+			return
+		}
+		paths := i.k.logFilesBetween(found, from, to)
+		if i.current == currentFileExcluded && len(paths) > 0 && paths[len(paths)-1] == i.CurrentFilePath() {
+			paths = paths[:len(paths)-1]
+		}
+		for record, err := range i.k.recordsOf(paths) {
+			if !yield(record, err) {
+				return
+			}
+		}
+	}
+}
+
+// Get the paths of all the log files, from the oldest archive to the current log file unless it is excluded.
+func (i *Inspector) paths() ([]string, error) {
+	found, _, err := i.k.getArchives()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives, caused by %w", err)
+	}
+	paths := make([]string, 0, found.Length()+1)
+	for _, archive := range found.All() {
+		paths = append(paths, archive.filePath)
+	}
+	if i.current == currentFileExcluded {
+		return paths, nil
+	}
+	return append(paths, i.CurrentFilePath()), nil
+}
+
+// Open the log file at path for reading, decompressing it if needed, through the cache of [WithReaderCache] if any.
+func (i *Inspector) openLogFile(path string) (io.ReadCloser, error) {
+	return i.k.readerCache.open(i.k.fsys, path, i.k.archiveDecompressor(path))
+}
+
+func (i *Inspector) grepLogFile(path string, re *regexp.Regexp) ([]GrepMatch, error) {
+	reader, err := i.openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var matches []GrepMatch
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1024*Kb)
+	for line := 1; scanner.Scan(); line++ {
+		if re.Match(scanner.Bytes()) {
+			matches = append(matches, GrepMatch{Path: path, Line: line, Text: scanner.Text()})
+		}
+	}
+	return matches, scanner.Err()
+}
+
+// Open a log file for reading, decompressing it with c if it is not nil, see [Keeper.archiveDecompressor].
+func openLogFile(fsys FS, path string, c Compressor) (io.ReadCloser, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return f, nil
+	}
+	reader, err := newDecompressingReader(c, f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to decompress %q, caused by %w", path, err)
+	}
+	return &decompressedFile{ReadCloser: reader, f: f}, nil
+}
+
+// A decompressed reader that closes the underlying file.
+type decompressedFile struct {
+	io.ReadCloser
+	f fs.File
+}
+
+func (d *decompressedFile) Close() error {
+	return errors.Join(d.ReadCloser.Close(), d.f.Close())
+}
+
+// Read multiple log files one after another, opening them one at a time.
+type logFilesReader struct {
+	paths   []string
+	open    func(path string) (io.ReadCloser, error)
+	current io.ReadCloser
+}
+
+func (r *logFilesReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+			reader, err := r.open(r.paths[0])
+			r.paths = r.paths[1:]
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			r.current = reader
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			closeErr := r.current.Close()
+			r.current = nil
+			if closeErr != nil {
+				return n, closeErr
+			}
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *logFilesReader) Close() error {
+	r.paths = nil
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
@@ -0,0 +1,178 @@
+package lorekeeper
+
+import (
+	"io"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestInspector(t *testing.T) {
+	folder := t.TempDir()
+	opts := []Opt{
+		WithFolder(folder),
+		WithName("test-inspector"),
+		WithMaxSize(10),
+		WithGzip(),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{"INFO one\n", "ERROR two\n", "INFO three\n", "ERROR 4\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	inspector, err := Open(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archives, err := inspector.ListArchives()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(archives) != 3 {
+		t.Fatalf("expected 3 archives got %d", len(archives))
+	}
+
+	reader, err := inspector.Reader()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if want := "INFO one\nERROR two\nINFO three\nERROR 4\n"; string(content) != want {
+		t.Errorf("expected %q got %q", want, content)
+	}
+
+	matches, err := inspector.Grep(regexp.MustCompile("^ERROR"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches got %d", len(matches))
+	}
+	if matches[0].Text != "ERROR two" || matches[0].Line != 1 || matches[0].Path != archives[1].Path {
+		t.Errorf("unexpected first match %+v", matches[0])
+	}
+	if matches[1].Text != "ERROR 4" || matches[1].Path != inspector.CurrentFilePath() {
+		t.Errorf("unexpected second match %+v", matches[1])
+	}
+
+	// The inspector never touches the files
+	if len(k.Archives()) != 3 {
+		t.Errorf("expected 3 archives got %d", len(k.Archives()))
+	}
+}
+
+func TestInspectorIncludeCurrentFile(t *testing.T) {
+	opts := []Opt{WithFolder(t.TempDir()), WithName("test-inspector-current")}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{"archived\n", "current\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if msg == "archived\n" {
+			if err := k.Rotate(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+	}
+
+	inspector, err := Open(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, tc := range []struct {
+		include  bool
+		archives int
+		content  string
+	}{
+		{include: true, archives: 2, content: "archived\ncurrent\n"},
+		{include: false, archives: 1, content: "archived\n"},
+	} {
+		scoped := inspector.IncludeCurrentFile(tc.include)
+		archives, err := scoped.ListArchives()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if len(archives) != tc.archives {
+			t.Errorf("expected %d files with include %v got %d", tc.archives, tc.include, len(archives))
+		}
+		reader, err := scoped.Reader()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if string(content) != tc.content {
+			t.Errorf("expected %q with include %v got %q", tc.content, tc.include, content)
+		}
+	}
+}
+
+func TestInspectorBetween(t *testing.T) {
+	folder := t.TempDir()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	opts := []Opt{
+		WithFolder(folder),
+		WithName("test-inspector-between"),
+		WithGzip(),
+		WithTimestampParser(PrefixTimestampParser(time.RFC3339)),
+	}
+	k, err := New(append(opts, WithNowFunc(func() time.Time { return now }))...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for i := range 3 {
+		now = start.Add(time.Duration(i) * time.Minute)
+		if _, err := k.Write([]byte(now.Format(time.RFC3339) + " abc\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	inspector, err := Open(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	var records []string
+	for timed, err := range inspector.Timeline(start.Add(30*time.Second), start.Add(time.Hour)) {
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		records = append(records, string(timed.Record))
+	}
+	if len(records) != 2 || records[0] != "2024-01-01T00:01:00Z abc\n" {
+		t.Errorf("expected the last 2 records got %q", records)
+	}
+	archives, err := inspector.ListArchives()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	reader, err := inspector.OpenFile(archives[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer reader.Close()
+	if b, err := io.ReadAll(reader); err != nil || string(b) != "2024-01-01T00:00:00Z abc\n" {
+		t.Errorf("expected the first record decompressed and no error got %q and %v", b, err)
+	}
+}
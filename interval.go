@@ -0,0 +1,119 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"time"
+)
+
+// The wall-clock boundaries at which the current log file is rotated, see [WithRotateEvery] and [WithDailyRotation].
+type rotationPeriod struct {
+	every  time.Duration
+	daily  bool
+	hour   int
+	minute int
+}
+
+func (p rotationPeriod) enabled() bool {
+	return p.every > 0 || p.daily
+}
+
+// Get the first boundary strictly after t, in the location of t.
+func (p rotationPeriod) next(t time.Time) time.Time {
+	if p.daily {
+		at := time.Date(t.Year(), t.Month(), t.Day(), p.hour, p.minute, 0, 0, t.Location())
+		if !at.After(t) {
+			at = time.Date(t.Year(), t.Month(), t.Day()+1, p.hour, p.minute, 0, 0, t.Location())
+		}
+		return at
+	}
+	// Periods dividing a day are aligned to the local midnight, so that hourly rotations fall on the hour
+	// even in time zones with an offset that is not a whole number of hours
+	if 24*time.Hour%p.every == 0 {
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return midnight.Add((t.Sub(midnight)/p.every + 1) * p.every)
+	}
+	return t.Truncate(p.every).Add(p.every)
+}
+
+// Rotate the current log file lazily on the first write after each boundary of the given period,
+// aligned to the wall clock in the location of the Keeper's clock, e.g. on the hour for time.Hour,
+// or at midnight for 24 * time.Hour. Periods that do not divide a day are aligned to the zero time.
+// Unlike [WithCron], no goroutine is involved and nothing is rotated while nothing is written,
+// the rotation reason is [RotationInterval]. It can be combined with [WithMaxSize].
+// This replaces [WithDailyRotation], set <= 0 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithRotateEvery(time.Hour))
+func WithRotateEvery(d time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if d <= 0 {
+			return NoIntervalRotation()(k)
+		}
+		return withRotationPeriod(rotationPeriod{every: d})(k)
+	}
+}
+
+// Rotate the current log file lazily on the first write after the given time of each day,
+// in the location of the Keeper's clock. See [WithRotateEvery] for the details.
+// This replaces [WithRotateEvery].
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithDailyRotation(0, 0)) // At midnight
+func WithDailyRotation(hour, minute int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+			return nil, fmt.Errorf("failed to set daily rotation, invalid time %02d:%02d", hour, minute)
+		}
+		return withRotationPeriod(rotationPeriod{daily: true, hour: hour, minute: minute})(k)
+	}
+}
+
+// Disable the rotations of [WithRotateEvery] and [WithDailyRotation], this is the default.
+func NoIntervalRotation() Opt {
+	return withRotationPeriod(rotationPeriod{})
+}
+
+func withRotationPeriod(p rotationPeriod) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.rotationPeriod = p
+		// The next boundary is computed again on the next write
+		k.rotateAt = time.Time{}
+		return k, nil
+	}
+}
+
+// Rotate the current log file if a boundary of the rotation period passed since it was started.
+func (k *Keeper) rotateIfDue() error {
+	if !k.rotationPeriod.enabled() || k.breakerOpen() {
+		return nil
+	}
+	now := k.now()
+	if k.rotateAt.IsZero() {
+		k.rotateAt = k.rotationPeriod.next(k.currentFileStart(now))
+	}
+	if now.Before(k.rotateAt) {
+		return nil
+	}
+	// Nothing to rotate, the period starts with this write
+	if k.currentFileSize == 0 {
+		k.rotateAt = k.rotationPeriod.next(now)
+		return nil
+	}
+	return k.rotate(RotationInterval)
+}
+
+// Get when the current log file was started, the last rotation if any,
+// otherwise the last modification of a current log file left by a previous process.
+func (k *Keeper) currentFileStart(now time.Time) time.Time {
+	if !k.lastRotation.IsZero() {
+		return k.lastRotation.In(now.Location())
+	}
+	if k.currentFileSize > 0 {
+		if stat, err := k.fsys.Stat(k.getCurrentFilePath()); err == nil {
+			return stat.ModTime().In(now.Location())
+		}
+	}
+	return now
+}
@@ -0,0 +1,80 @@
+package lorekeeper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithRotateEvery(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-rotate-every"),
+		WithNowFunc(func() time.Time { return now }),
+		WithRotateEvery(time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, tc := range []struct {
+		at       time.Time
+		archives int
+	}{
+		{at: now, archives: 0},
+		{at: time.Date(2024, 3, 1, 10, 59, 59, 0, time.UTC), archives: 0},
+		{at: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), archives: 1},
+		{at: time.Date(2024, 3, 1, 11, 45, 0, 0, time.UTC), archives: 1},
+		// Nothing is rotated while nothing is written, a single rotation covers the missed boundaries
+		{at: time.Date(2024, 3, 1, 14, 10, 0, 0, time.UTC), archives: 2},
+	} {
+		now = tc.at
+		if _, err := k.Write([]byte("msg\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if got := len(k.Archives()); got != tc.archives {
+			t.Errorf("expected %d archives at %v got %d", tc.archives, tc.at, got)
+		}
+	}
+	if reason := k.LastRotationReason(); reason != RotationInterval {
+		t.Errorf("expected reason %q got %q", RotationInterval, reason)
+	}
+}
+
+func TestWithDailyRotation(t *testing.T) {
+	now := time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-daily-rotation"),
+		WithNowFunc(func() time.Time { return now }),
+		WithDailyRotation(0, 30),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, tc := range []struct {
+		at       time.Time
+		archives int
+	}{
+		{at: now, archives: 0},
+		{at: time.Date(2024, 3, 2, 0, 29, 0, 0, time.UTC), archives: 0},
+		{at: time.Date(2024, 3, 2, 0, 31, 0, 0, time.UTC), archives: 1},
+		{at: time.Date(2024, 3, 2, 23, 59, 0, 0, time.UTC), archives: 1},
+		{at: time.Date(2024, 3, 3, 8, 0, 0, 0, time.UTC), archives: 2},
+	} {
+		now = tc.at
+		if _, err := k.Write([]byte("msg\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if got := len(k.Archives()); got != tc.archives {
+			t.Errorf("expected %d archives at %v got %d", tc.archives, tc.at, got)
+		}
+	}
+
+	if _, err := New(WithFolder(t.TempDir()), WithName("test-daily-rotation-invalid"), WithDailyRotation(24, 0)); err == nil {
+		t.Errorf("expected error for an invalid time got nil")
+	}
+}
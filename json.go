@@ -0,0 +1,54 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Returned, wrapped, by [Keeper.Write] for messages that are not JSON lines, see [WithValidateJSON].
+var ErrInvalidJSON = errors.New("invalid JSON line")
+
+// How the messages are checked to be JSON lines.
+type jsonPolicy int
+
+const (
+	jsonOff jsonPolicy = iota
+	jsonReject
+	jsonWrap
+)
+
+// Check that every line of the msg is a JSON object, wrapping the invalid ones if wrap is true.
+// The result is appended to dst and always ends with a new line.
+func normalizeJSON(dst []byte, msg []byte, wrap bool) ([]byte, error) {
+	normalized := dst
+	msg = bytes.TrimSuffix(msg, []byte("\n"))
+	for i := 1; ; i++ {
+		line, rest, more := bytes.Cut(msg, []byte("\n"))
+		if !isJSONObject(line) {
+			if !wrap {
+				return nil, fmt.Errorf("failed to write, line %d is not a JSON object, caused by %w", i, ErrInvalidJSON)
+			}
+			wrapped, err := json.Marshal(struct {
+				Message string `json:"message"`
+			}{Message: string(line)})
+			if err != nil {
+				return nil, fmt.Errorf("failed to wrap invalid JSON line, caused by %w", err)
+			}
+			line = wrapped
+		}
+		normalized = append(normalized, line...)
+		normalized = append(normalized, '\n')
+		if !more {
+			return normalized, nil
+		}
+		msg = rest
+	}
+}
+
+// Check if the line is a single valid JSON object.
+func isJSONObject(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	return len(trimmed) > 0 && trimmed[0] == '{' && json.Valid(trimmed)
+}
@@ -0,0 +1,86 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestKeeperWithValidateJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     Opt
+		msg     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "valid object",
+			opt:  WithValidateJSON(),
+			msg:  `{"level":"info"}` + "\n",
+			want: `{"level":"info"}` + "\n",
+		},
+		{
+			name: "missing new line",
+			opt:  WithValidateJSON(),
+			msg:  `{"level":"info"}`,
+			want: `{"level":"info"}` + "\n",
+		},
+		{
+			name:    "not an object",
+			opt:     WithValidateJSON(),
+			msg:     `["info"]` + "\n",
+			wantErr: true,
+		},
+		{
+			name:    "two objects on one line",
+			opt:     WithValidateJSON(),
+			msg:     `{"a":1}{"b":2}` + "\n",
+			wantErr: true,
+		},
+		{
+			name: "wrapped",
+			opt:  WithWrapInvalidJSON(),
+			msg:  `{"a":1}` + "\n" + `plain "text"` + "\n",
+			want: `{"a":1}` + "\n" + `{"message":"plain \"text\""}` + "\n",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := New(
+				WithFolder(t.TempDir()),
+				WithName(fmt.Sprintf("test-validate-json-%d", i)),
+				tt.opt,
+			)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			defer k.Close()
+
+			n, err := k.Write([]byte(tt.msg))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidJSON) {
+					t.Errorf("expected %v got %v", ErrInvalidJSON, err)
+				}
+				if k.Stats().Dropped != 1 {
+					t.Errorf("expected 1 dropped message got %d", k.Stats().Dropped)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if n != len(tt.msg) {
+				t.Errorf("expected %d bytes written got %d", len(tt.msg), n)
+			}
+			content, err := os.ReadFile(k.CurrentFilePath())
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("expected %q got %q", tt.want, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+)
+
+// The environment variables read by [WithKubernetes], as set from the downward API of Kubernetes.
+const (
+	envPodName               = "POD_NAME"
+	envPodNamespace          = "POD_NAMESPACE"
+	envNodeName              = "NODE_NAME"
+	envEphemeralStorageLimit = "EPHEMERAL_STORAGE_LIMIT"
+)
+
+// The permissions of the created folder and the free space kept on the volume by [WithKubernetes].
+const (
+	kubernetesFolderMode         = 0755
+	kubernetesMinDiskFreePercent = 10
+)
+
+// Configure the Keeper for a container of a Kubernetes pod writing to an emptyDir or a persistent volume,
+// from the environment variables that the downward API sets:
+//   - POD_NAME, or HOSTNAME which Kubernetes sets to the name of the pod, names the Keeper,
+//     so that the replicas sharing a persistent volume do not write to the same files.
+//   - POD_NAME, POD_NAMESPACE and NODE_NAME are available to the layouts as {{ .pod }}, {{ .namespace }} and {{ .node }},
+//     see [WithTemplateData], the missing ones are left out.
+//   - EPHEMERAL_STORAGE_LIMIT, the limit of the ephemeral storage of the container in bytes,
+//     bounds the archives to half of it and the log files to a tenth of it,
+//     since a pod exceeding its limit is evicted, see [WithTotalSize] and [WithMaxSize].
+//
+// The archives are compressed and named like the kubelet rotates container logs, see [LayoutKubernetes],
+// the folder is created if the volume is mounted empty, see [WithCreateFolder],
+// and the oldest archives are removed to keep 10% of the volume free, see [WithMinDiskFreePercent].
+// The options that follow it take precedence, such as [WithName] to name the Keeper otherwise.
+//
+// Example usage:
+//
+//	// env:
+//	//   - name: POD_NAME
+//	//     valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	//   - name: POD_NAMESPACE
+//	//     valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	//   - name: NODE_NAME
+//	//     valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//	//   - name: EPHEMERAL_STORAGE_LIMIT
+//	//     valueFrom: {resourceFieldRef: {resource: limits.ephemeral-storage}}
+//	keeper, err := lorekeeper.New(lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithKubernetes())
+func WithKubernetes() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		data := maps.Clone(k.templateData)
+		if data == nil {
+			data = make(map[string]string)
+		}
+		for key, env := range map[string]string{"pod": envPodName, "namespace": envPodNamespace, "node": envNodeName} {
+			if value := os.Getenv(env); len(value) > 0 {
+				data[key] = value
+			}
+		}
+		opts := []Opt{
+			WithTemplateData(data),
+			WithArchiveLayoutPreset(LayoutKubernetes),
+			WithGzip(),
+			WithCreateFolder(kubernetesFolderMode),
+			WithMinDiskFreePercent(kubernetesMinDiskFreePercent),
+		}
+		pod := os.Getenv(envPodName)
+		if len(pod) == 0 {
+			pod = os.Getenv("HOSTNAME")
+		}
+		if len(pod) > 0 {
+			opts = append(opts, WithName(pod))
+		}
+		if limit := os.Getenv(envEphemeralStorageLimit); len(limit) > 0 {
+			size, err := strconv.Atoi(limit)
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("failed to configure for kubernetes, invalid %s %q", envEphemeralStorageLimit, limit)
+			}
+			maxSize := size / 10
+			if k.maxSize > 0 {
+				maxSize = min(maxSize, k.maxSize)
+			}
+			opts = append(opts, WithTotalSize(size/2), WithMaxSize(maxSize))
+		}
+		k, err := Options(opts...)(k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure for kubernetes, caused by %w", err)
+		}
+		return k, nil
+	}
+}
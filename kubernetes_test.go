@@ -0,0 +1,41 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWithKubernetes(t *testing.T) {
+	t.Setenv("POD_NAME", "api-7d9f-x2x")
+	t.Setenv("POD_NAMESPACE", "shop")
+	t.Setenv("NODE_NAME", "node-1")
+	t.Setenv("EPHEMERAL_STORAGE_LIMIT", "1000000")
+	folder := filepath.Join(t.TempDir(), "empty-dir", "logs")
+
+	k, err := New(
+		WithFolder(folder),
+		WithKubernetes(),
+		WithCurrentNameLayout("{{ .namespace }}-{{ .name }}{{ .extension }}"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if k.Name() != "api-7d9f-x2x" {
+		t.Errorf("expected the keeper to be named after the pod got %q", k.Name())
+	}
+	if k.TotalSize() != 500000 || k.MaxSize() != 100000 {
+		t.Errorf("expected sizes bounded by the storage limit got %d and %d", k.TotalSize(), k.MaxSize())
+	}
+	if k.templateData["node"] != "node-1" {
+		t.Errorf("expected the node in the template data got %v", k.templateData)
+	}
+	if want := filepath.Join(folder, "shop-api-7d9f-x2x.log"); k.CurrentFilePath() != want {
+		t.Errorf("expected current log file %q got %q", want, k.CurrentFilePath())
+	}
+
+	t.Setenv("EPHEMERAL_STORAGE_LIMIT", "1Gi")
+	if err := ValidateOptions(WithKubernetes()); err == nil {
+		t.Errorf("expected an error for an invalid storage limit")
+	}
+}
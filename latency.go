@@ -0,0 +1,149 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// The number of slots of the sliding window of [WithLatencyWindow], each one covering a fraction of the window.
+const latencySlots = 8
+
+// Measure the latency percentiles of [Keeper.Write] over a sliding window instead of since the Keeper started,
+// so that [Stats.WriteLatencyP50], [Stats.WriteLatencyP99] and [Stats.WriteLatencyMax] reflect the recent writes,
+// such as to alert when the logging path becomes a bottleneck for request handling.
+// The window slides by an eighth of its duration, so the percentiles cover between 7/8 and all of it.
+// Changing the window, such as with [Keeper.Reconfigure], starts the measures over.
+// Set window <= 0 to measure since the Keeper started, which is the default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithLatencyWindow(time.Minute))
+//	stats := keeper.Stats()
+//	if stats.WriteLatencyP99 > 10*time.Millisecond {
+//		log.Println("logging is slowing down the requests")
+//	}
+func WithLatencyWindow(window time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if window > 0 && window < latencySlots*time.Millisecond {
+			return nil, fmt.Errorf("failed to set latency window, window must be at least %s got %s", latencySlots*time.Millisecond, window)
+		}
+		k.latency.setWindow(max(window, 0))
+		return k, nil
+	}
+}
+
+// A latencyHistogram counts durations in buckets of powers of two nanoseconds,
+// bucket i holding the durations in [2^(i-1), 2^i) and bucket 0 the zero durations.
+type latencyHistogram struct {
+	buckets [64]uint64
+	count   uint64
+	max     time.Duration
+}
+
+func (h *latencyHistogram) add(d time.Duration) {
+	d = max(d, 0)
+	h.buckets[bits.Len64(uint64(d))]++
+	h.count++
+	h.max = max(h.max, d)
+}
+
+func (h *latencyHistogram) merge(other *latencyHistogram) {
+	for i, n := range other.buckets {
+		h.buckets[i] += n
+	}
+	h.count += other.count
+	h.max = max(h.max, other.max)
+}
+
+// Get the duration below which the given fraction of the durations fall,
+// rounded up to the bound of its bucket but never above the max.
+func (h *latencyHistogram) percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(p * float64(h.count))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for i, n := range h.buckets {
+		if seen += n; seen >= rank {
+			if i == 0 {
+				return 0
+			}
+			return min(time.Duration(1)<<i-1, h.max)
+		}
+	}
+	return h.max
+}
+
+// A latencyRecorder measures the latency of the writes, see [WithLatencyWindow].
+// It has a lock of its own so that the writes of [WithAsyncWrites] and [WithDoubleBuffering],
+// which do not take the lock of the Keeper, are measured as well.
+type latencyRecorder struct {
+	mu     sync.Mutex
+	window time.Duration
+	// The histogram since start when window is zero, or the slots of the sliding window
+	total latencyHistogram
+	slots [latencySlots]latencySlot
+}
+
+type latencySlot struct {
+	// The index of the period covered by the slot, in durations of window/latencySlots since the epoch
+	period int64
+	hist   latencyHistogram
+}
+
+func (r *latencyRecorder) getWindow() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.window
+}
+
+func (r *latencyRecorder) setWindow(window time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if window == r.window {
+		return
+	}
+	r.window = window
+	r.total = latencyHistogram{}
+	r.slots = [latencySlots]latencySlot{}
+}
+
+// Record the latency of a write that started at start.
+func (r *latencyRecorder) record(start time.Time) {
+	now := time.Now()
+	d := now.Sub(start)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.window <= 0 {
+		r.total.add(d)
+		return
+	}
+	period := now.UnixNano() / int64(r.window/latencySlots)
+	slot := &r.slots[period%latencySlots]
+	if slot.period != period {
+		*slot = latencySlot{period: period}
+	}
+	slot.hist.add(d)
+}
+
+// Get the histogram of the latencies since start, or over the sliding window.
+func (r *latencyRecorder) histogram() latencyHistogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.window <= 0 {
+		return r.total
+	}
+	var h latencyHistogram
+	period := time.Now().UnixNano() / int64(r.window/latencySlots)
+	for i := range r.slots {
+		if slot := &r.slots[i]; slot.period > period-latencySlots {
+			h.merge(&slot.hist)
+		}
+	}
+	return h
+}
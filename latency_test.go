@@ -0,0 +1,60 @@
+package lorekeeper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogramPercentile(t *testing.T) {
+	var h latencyHistogram
+	if got := h.percentile(0.5); got != 0 {
+		t.Errorf("expected no latency got %s", got)
+	}
+	for range 98 {
+		h.add(100 * time.Nanosecond)
+	}
+	h.add(5 * time.Microsecond)
+	h.add(time.Millisecond)
+
+	// Rounded up to the next power of two
+	if got := h.percentile(0.5); got != 127*time.Nanosecond {
+		t.Errorf("expected a p50 of 127ns got %s", got)
+	}
+	if got := h.percentile(0.99); got != 8191*time.Nanosecond {
+		t.Errorf("expected a p99 of 8.191µs got %s", got)
+	}
+	// But never above the max
+	if got := h.percentile(1); got != time.Millisecond {
+		t.Errorf("expected a p100 of 1ms got %s", got)
+	}
+}
+
+func TestKeeperWithLatencyWindow(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-latency-window"),
+		WithLatencyWindow(80*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	stats := k.Stats()
+	if stats.WriteLatencyMax <= 0 || stats.WriteLatencyP50 <= 0 || stats.WriteLatencyP50 > stats.WriteLatencyMax {
+		t.Errorf("expected the latency of the write got %s and %s", stats.WriteLatencyP50, stats.WriteLatencyMax)
+	}
+
+	// The write slides out of the window
+	time.Sleep(100 * time.Millisecond)
+	if stats := k.Stats(); stats.WriteLatencyMax != 0 {
+		t.Errorf("expected no latency in the window got %s", stats.WriteLatencyMax)
+	}
+
+	if _, err := New(WithLatencyWindow(time.Millisecond)); err == nil {
+		t.Errorf("expected error since the window is too short")
+	}
+}
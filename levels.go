@@ -0,0 +1,102 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// A LevelPolicy configures the [Keeper] receiving the records of a minimum severity, see [NewLevelKeepers].
+type LevelPolicy struct {
+	// The minimum level of the records received by the Keeper.
+	Level slog.Level
+	// The suffix appended to the base name of the Keeper, e.g. "error" for "app-error.log".
+	// An empty suffix keeps the base name.
+	Suffix string
+	// The options of the Keeper applied on top of the base options, such as its retention and its compression.
+	Opts []Opt
+}
+
+// A [LevelKeepers] manages one [Keeper] per severity, each with its own retention and compression,
+// in a single configuration instead of hand-wiring multiple Keepers.
+// Use [NewLevelKeepers] to create a new LevelKeepers.
+type LevelKeepers struct {
+	// Ordered from the highest to the lowest level
+	policies []LevelPolicy
+	keepers  []*Keeper
+}
+
+// Create a new [LevelKeepers] with a [Keeper] for each of the given policies,
+// created with the base options followed by the options of the policy.
+// A record goes to the Keeper of the highest level that is not above the level of the record.
+//
+// Example usage:
+//
+//	levels, err := lorekeeper.NewLevelKeepers(
+//		[]lorekeeper.Opt{lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithName("app")},
+//		lorekeeper.LevelPolicy{Level: slog.LevelError, Suffix: "error", Opts: []lorekeeper.Opt{lorekeeper.WithMaxFiles(90)}},
+//		lorekeeper.LevelPolicy{Level: slog.LevelInfo, Opts: []lorekeeper.Opt{lorekeeper.WithMaxFiles(14), lorekeeper.WithGzip()}},
+//		lorekeeper.LevelPolicy{Level: slog.LevelDebug, Suffix: "debug", Opts: []lorekeeper.Opt{lorekeeper.WithMaxFiles(2)}},
+//	)
+//	keeper, ok := levels.For(slog.LevelWarn) // app.log
+func NewLevelKeepers(base []Opt, policies ...LevelPolicy) (*LevelKeepers, error) {
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("failed to create level keepers, expected at least one policy")
+	}
+	baseKeeper, err := configureDetached(base...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create level keepers, caused by %w", err)
+	}
+
+	sorted := slices.Clone(policies)
+	slices.SortStableFunc(sorted, func(a, b LevelPolicy) int {
+		return int(b.Level) - int(a.Level)
+	})
+	l := &LevelKeepers{policies: sorted}
+	for i, policy := range sorted {
+		if i > 0 && sorted[i-1].Level == policy.Level {
+			_ = l.Close()
+			return nil, fmt.Errorf("failed to create level keepers, level %v is configured twice", policy.Level)
+		}
+		name := baseKeeper.name
+		if len(policy.Suffix) > 0 {
+			name += "-" + policy.Suffix
+		}
+		opts := append(append(slices.Clone(base), policy.Opts...), WithName(name))
+		keeper, err := New(opts...)
+		if err != nil {
+			_ = l.Close()
+			return nil, fmt.Errorf("failed to create keeper for level %v, caused by %w", policy.Level, err)
+		}
+		l.keepers = append(l.keepers, keeper)
+	}
+	return l, nil
+}
+
+// Get the [Keeper] receiving the records of the given level,
+// ok is false if the level is below the level of every policy, in which case the records are not kept.
+func (l *LevelKeepers) For(level slog.Level) (keeper *Keeper, ok bool) {
+	for i, policy := range l.policies {
+		if level >= policy.Level {
+			return l.keepers[i], true
+		}
+	}
+	return nil, false
+}
+
+// Get the [Keeper]s ordered from the highest to the lowest level.
+func (l *LevelKeepers) Keepers() []*Keeper {
+	return slices.Clone(l.keepers)
+}
+
+// Close all the [Keeper]s.
+func (l *LevelKeepers) Close() error {
+	var errs []error
+	for i, keeper := range l.keepers {
+		if err := keeper.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close keeper for level %v, caused by %w", l.policies[i].Level, err))
+		}
+	}
+	return errors.Join(errs...)
+}
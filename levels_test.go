@@ -0,0 +1,54 @@
+package lorekeeper
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestNewLevelKeepers(t *testing.T) {
+	levels, err := NewLevelKeepers(
+		[]Opt{WithFolder(t.TempDir()), WithName("test-levels")},
+		LevelPolicy{Level: slog.LevelInfo, Opts: []Opt{WithMaxFiles(14), WithGzip()}},
+		LevelPolicy{Level: slog.LevelError, Suffix: "error", Opts: []Opt{WithMaxFiles(90)}},
+		LevelPolicy{Level: slog.LevelDebug, Suffix: "debug", Opts: []Opt{WithMaxFiles(2)}},
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer levels.Close()
+
+	tests := []struct {
+		level    slog.Level
+		name     string
+		maxFiles int
+	}{
+		{slog.LevelError + 4, "test-levels-error", 90},
+		{slog.LevelError, "test-levels-error", 90},
+		{slog.LevelWarn, "test-levels", 14},
+		{slog.LevelDebug, "test-levels-debug", 2},
+	}
+	for _, tt := range tests {
+		keeper, ok := levels.For(tt.level)
+		if !ok {
+			t.Fatalf("expected a keeper for level %v", tt.level)
+		}
+		if keeper.Name() != tt.name || keeper.MaxFiles() != tt.maxFiles {
+			t.Errorf("expected keeper %q with %d max files for level %v got %q with %d",
+				tt.name, tt.maxFiles, tt.level, keeper.Name(), keeper.MaxFiles())
+		}
+	}
+	if _, ok := levels.For(slog.LevelDebug - 4); ok {
+		t.Errorf("expected no keeper below the lowest level")
+	}
+	if len(levels.Keepers()) != 3 {
+		t.Errorf("expected 3 keepers got %d", len(levels.Keepers()))
+	}
+
+	if _, err := NewLevelKeepers(
+		[]Opt{WithFolder(t.TempDir()), WithName("test-levels-twice")},
+		LevelPolicy{Level: slog.LevelInfo},
+		LevelPolicy{Level: slog.LevelInfo, Suffix: "info"},
+	); err == nil {
+		t.Errorf("expected an error for a level configured twice")
+	}
+}
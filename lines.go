@@ -0,0 +1,79 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// Rotate the current log file once it holds the given number of lines, such as for compliance tooling
+// that splits the logs by record count instead of by size.
+// The lines are counted by their new lines, so a message of several lines counts as many,
+// and a message is never split across log files to honor the limit:
+// the current log file is rotated beforehand if the message does not fit, unless the file is empty.
+// The lines of the current log file left by a previous process are counted when the Keeper starts.
+// The limit composes with the other triggers, such as [WithMaxSize] and [WithCron],
+// the rotations it triggers have the reason [RotationLines].
+// Set n <= 0 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithMaxLines(100_000), lorekeeper.WithMaxSize(0))
+func WithMaxLines(n int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.maxLines = max(n, 0)
+		return k, nil
+	}
+}
+
+// Tell whether the current log file must be rotated before writing the msg, and why.
+func (k *Keeper) rotationFor(nextMsg []byte) (RotationReason, bool) {
+	if k.shouldRotate(nextMsg) {
+		return RotationSize, true
+	}
+	if k.maxLines > 0 && k.currentLines > 0 && k.currentLines+max(bytes.Count(nextMsg, newLine), 1) > k.maxLines {
+		return RotationLines, true
+	}
+	return "", false
+}
+
+var newLine = []byte{'\n'}
+
+// Count the lines of the written part of the msg, see [WithMaxLines].
+func (k *Keeper) countLines(written []byte) {
+	if k.maxLines > 0 {
+		k.currentLines += bytes.Count(written, newLine)
+	}
+}
+
+// Count the lines of the current log file, including the ones waiting in the write buffer, see [WithMaxLines].
+func (k *Keeper) scanCurrentLines() error {
+	k.currentLines = 0
+	if k.maxLines <= 0 {
+		return nil
+	}
+	file, err := k.fsys.Open(k.getCurrentFilePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to count lines of current log file, caused by %w", err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, 32*Kb)
+	for {
+		n, err := file.Read(buf)
+		k.currentLines += bytes.Count(buf[:n], newLine)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to count lines of current log file, caused by %w", err)
+		}
+	}
+	k.currentLines += bytes.Count(k.writeBuf, newLine)
+	return nil
+}
@@ -0,0 +1,78 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeeperWithMaxLines(t *testing.T) {
+	folder := t.TempDir()
+	// Left by a previous process
+	if err := os.WriteFile(filepath.Join(folder, "test-max-lines.log"), []byte("old 1\nold 2\n"), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-max-lines"),
+		WithMaxSize(0),
+		WithMaxLines(3),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	for _, msg := range []string{"a\n", "b\n", "c\nd\n", "e\nf\ng\nh\n", "i\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if got := k.LastRotationReason(); got != RotationLines {
+		t.Errorf("expected the reason %q got %q", RotationLines, got)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// A message is never split, even if it has more lines than the limit
+	want := []string{"old 1\nold 2\na\n", "b\nc\nd\n", "e\nf\ng\nh\n", "i\n"}
+	archives := k.Archives()
+	if len(archives) != len(want) {
+		t.Fatalf("expected %d archives got %d", len(want), len(archives))
+	}
+	for i, archive := range archives {
+		content, err := os.ReadFile(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if string(content) != want[i] {
+			t.Errorf("expected archive %d to be %q got %q", i, want[i], content)
+		}
+	}
+}
+
+func TestKeeperWithMaxLinesAndMaxSize(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-max-lines-size"),
+		WithMaxSize(10),
+		WithMaxLines(100),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"123456\n", "123456\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if got := k.LastRotationReason(); got != RotationSize {
+		t.Errorf("expected the reason %q got %q", RotationSize, got)
+	}
+	if got := readArchives(t, k); !strings.HasPrefix(got, "123456\n") {
+		t.Errorf("expected the first message in the archive got %q", got)
+	}
+}
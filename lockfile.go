@@ -0,0 +1,195 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The name of the lock file written in the folder of the current log file, see [WithLockFile].
+const lockFileName = ".lorekeeper.lock"
+
+// The extension of the file next to the lock file, which is locked while the lock file is updated.
+const lockGuardExt = ".guard"
+
+// Identifies this process in the lock files, since a PID is reused by the processes of other hosts sharing the folder,
+// or of other containers, and by the next processes once this one exits.
+var lockInstance = newLockInstance()
+
+func newLockInstance() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Get the host of this process in the lock files.
+func lockHost() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return host
+}
+
+// An entry of the lock file, one per Keeper managing files in the folder.
+type lockEntry struct {
+	pid      int
+	host     string
+	instance string
+	name     string
+	hash     string
+}
+
+// Check whether the process of the entry is running, the processes of other hosts are taken as running
+// since they can not be checked.
+func (e lockEntry) alive(host string) bool {
+	if e.host != host {
+		return true
+	}
+	// The PID of this process was reused from a process that exited without releasing its entry
+	if e.pid == os.Getpid() && e.instance != lockInstance {
+		return false
+	}
+	return processAlive(e.pid)
+}
+
+// Claim the files of the Keeper in the lock file of its folder, see [WithLockFile].
+func (k *Keeper) acquireLockFile() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.lockFile {
+		return nil
+	}
+	hash, host := k.configHash(), lockHost()
+	err := k.updateLockFile(func(entries []lockEntry) ([]lockEntry, error) {
+		kept := entries[:0]
+		for _, entry := range entries {
+			// Keepers of this process are deduplicated by their registry instead
+			if entry.instance == lockInstance && entry.name == k.name {
+				continue
+			}
+			alive := entry.alive(host)
+			if entry.name == k.name && alive {
+				config := "the same configuration"
+				if entry.hash != hash {
+					config = "a different configuration"
+				}
+				return nil, fmt.Errorf("process %d of host %q already manages the files of %q in %q with %s", entry.pid, entry.host, k.name, k.folder, config)
+			}
+			if alive {
+				kept = append(kept, entry)
+			}
+		}
+		return append(kept, lockEntry{pid: os.Getpid(), host: host, instance: lockInstance, name: k.name, hash: hash}), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock folder, caused by %w", err)
+	}
+	k.lockFileHeld = true
+	return nil
+}
+
+// Remove the entry of the Keeper from the lock file of its folder, removing the lock file once empty.
+func (k *Keeper) releaseLockFile() error {
+	if !k.lockFileHeld {
+		return nil
+	}
+	k.lockFileHeld = false
+	err := k.updateLockFile(func(entries []lockEntry) ([]lockEntry, error) {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.instance != lockInstance || entry.name != k.name {
+				kept = append(kept, entry)
+			}
+		}
+		return kept, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unlock folder, caused by %w", err)
+	}
+	return nil
+}
+
+// Rewrite the lock file of the folder with the entries returned by update.
+// The whole update holds an exclusive lock on the guard file of the folder, so that the Keepers of other processes
+// never update the lock file in between and lose an entry. The guard file is never removed,
+// since a process waiting for the lock of a removed file would not exclude the next one.
+// The lock file is replaced atomically, so that other processes never read a partial lock file.
+func (k *Keeper) updateLockFile(update func(entries []lockEntry) ([]lockEntry, error)) error {
+	path := filepath.Join(k.folder, lockFileName)
+	guard, err := os.OpenFile(path+lockGuardExt, os.O_CREATE|os.O_RDWR, k.fileMode)
+	if err != nil {
+		return err
+	}
+	defer guard.Close()
+	if err := lockFileExclusive(guard); err != nil {
+		return fmt.Errorf("failed to lock %q, caused by %w", guard.Name(), err)
+	}
+	defer unlockFile(guard)
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	entries, err := update(parseLockFile(content))
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	var buff bytes.Buffer
+	for _, entry := range entries {
+		fmt.Fprintf(&buff, "%d\t%s\t%s\t%s\t%s\n", entry.pid, entry.host, entry.instance, entry.name, entry.hash)
+	}
+	temp := fmt.Sprintf("%s.%s.tmp", path, lockInstance)
+	if err := os.WriteFile(temp, buff.Bytes(), k.fileMode); err != nil {
+		return err
+	}
+	if err := os.Rename(temp, path); err != nil {
+		_ = os.Remove(temp)
+		return err
+	}
+	return nil
+}
+
+func parseLockFile(content []byte) []lockEntry {
+	var entries []lockEntry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 5 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		entries = append(entries, lockEntry{pid: pid, host: fields[1], instance: fields[2], name: fields[3], hash: fields[4]})
+	}
+	return entries
+}
+
+// Hash the configuration deciding which files the Keeper manages and how.
+func (k *Keeper) configHash() string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%q %q %q %q %q %d %d %d %q",
+		k.getArchiveFolders(), k.name, k.extension, k.timeLayout, k.archiveNameLayoutText,
+		k.maxSize, k.maxFiles, k.totalSize, k.compressionExt,
+	)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
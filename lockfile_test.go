@@ -0,0 +1,143 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestKeeperWithLockFile(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-lock-file"), WithLockFile())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(folder, lockFileName))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !strings.HasPrefix(string(content), fmt.Sprintf("%d\t%s\t%s\ttest-lock-file\t", os.Getpid(), lockHost(), lockInstance)) {
+		t.Errorf("expected the lock file to hold the keeper got %q", content)
+	}
+
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(folder, lockFileName)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the lock file to be removed on close got %v", err)
+	}
+}
+
+func TestKeeperWithLockFileConflict(t *testing.T) {
+	folder := t.TempDir()
+	// The parent process is running, and claims the same name
+	content := fmt.Sprintf("%d\t%s\tparent\ttest-lock-file-conflict\tabc\n", os.Getppid(), lockHost())
+	if err := os.WriteFile(filepath.Join(folder, lockFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	_, err := New(WithFolder(folder), WithName("test-lock-file-conflict"), WithLockFile())
+	if err == nil || !strings.Contains(err.Error(), "a different configuration") {
+		t.Fatalf("expected a conflict error got %v", err)
+	}
+
+	// Another name in the same folder is not a conflict
+	k, err := New(WithFolder(folder), WithName("test-lock-file-other"), WithLockFile())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(folder, lockFileName)); string(got) != content {
+		t.Errorf("expected the entry of the parent process to be kept got %q", got)
+	}
+}
+
+func TestKeeperWithLockFileInstances(t *testing.T) {
+	folder := t.TempDir()
+	// A process of another host can not be checked, while a process of this host reusing the PID of this process exited
+	content := fmt.Sprintf(
+		"%d\tanother-host\tremote\ttest-lock-file-remote\tabc\n%d\t%s\tprevious\ttest-lock-file-reused\tabc\n",
+		os.Getpid(), os.Getpid(), lockHost(),
+	)
+	if err := os.WriteFile(filepath.Join(folder, lockFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	_, err := New(WithFolder(folder), WithName("test-lock-file-remote"), WithLockFile())
+	if err == nil || !strings.Contains(err.Error(), `host "another-host"`) {
+		t.Errorf("expected a conflict with the other host got %v", err)
+	}
+	k, err := New(WithFolder(folder), WithName("test-lock-file-reused"), WithLockFile(), WithFileMode(0600))
+	if err != nil {
+		t.Fatalf("expected the entry of the exited process to be replaced got %v", err)
+	}
+	defer k.Close()
+
+	stat, err := os.Stat(filepath.Join(folder, lockFileName))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if runtime.GOOS != "windows" && stat.Mode().Perm() != 0600 {
+		t.Errorf("expected the lock file to have the file mode 0600 got %v", stat.Mode().Perm())
+	}
+	got, err := os.ReadFile(filepath.Join(folder, lockFileName))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	entries := parseLockFile(got)
+	if len(entries) != 2 || entries[0].instance != "remote" || entries[1].instance != lockInstance {
+		t.Errorf("expected the remote entry and the entry of this process got %q", got)
+	}
+}
+
+func TestKeeperWithLockFileConcurrent(t *testing.T) {
+	folder := t.TempDir()
+	const n = 20
+	keepers := make([]*Keeper, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keepers[i], errs[i] = New(WithFolder(folder), WithName(fmt.Sprintf("test-lock-file-concurrent-%d", i)), WithLockFile())
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	// Every registration must survive the concurrent updates
+	content, err := os.ReadFile(filepath.Join(folder, lockFileName))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if entries := parseLockFile(content); len(entries) != n {
+		t.Errorf("expected %d entries got %d", n, len(entries))
+	}
+
+	for _, k := range keepers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := k.Close(); err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := os.Stat(filepath.Join(folder, lockFileName)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the lock file to be removed once every keeper is closed got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// The default dateformat of [WithDateext], the one of logrotate.
+const defaultDateFormat = "-%Y%m%d"
+
+// Name the archives like the dateext option of logrotate, appending the date of the rotation to the name of the current log file,
+// such as "app.log-20240102", and "app.log-20240102.gz" once compressed,
+// so that teams migrating from logrotate keep the names their tools expect. See [WithOldDir] for the olddir option.
+// The dateformat is the one of the dateformat option of logrotate, with the specifiers %Y, %m, %d, %H, %M and %S,
+// and the literal characters "-", "_" and ".". An empty dateformat is "-%Y%m%d", the default of logrotate.
+// Like logrotate, a rotation whose archive already exists is skipped, and the current log file keeps growing
+// until the date changes, so the dateformat must be as precise as the rotations, such as "-%Y%m%d%H" for hourly rotations.
+// It sets both the archive name layout and the time layout, see [WithArchiveNameLayout] and [WithTimeLayout].
+//
+// Example usage:
+//
+//	// /var/log/app/app.log rotated daily to /var/log/app/old/app.log-20240102.gz
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithFolder("/var/log/app"),
+//		lorekeeper.WithName("app"),
+//		lorekeeper.WithCron("@daily"),
+//		lorekeeper.WithGzip(),
+//		lorekeeper.WithDateext(""),
+//		lorekeeper.WithOldDir("old"),
+//	)
+func WithDateext(dateformat string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if len(dateformat) == 0 {
+			dateformat = defaultDateFormat
+		}
+		timeLayout, err := dateextLayout(dateformat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set dateext, caused by %w", err)
+		}
+		k, err = WithArchiveNameLayout("{{ .name }}{{ .extension }}{{ .time }}")(k)
+		if err != nil {
+			return nil, err
+		}
+		k.timeLayout = timeLayout
+		k.dateFormat = dateformat
+		return k, nil
+	}
+}
+
+// Convert a dateformat of logrotate into a time layout.
+func dateextLayout(dateformat string) (string, error) {
+	specifiers := map[byte]string{'Y': "2006", 'm': "01", 'd': "02", 'H': "15", 'M': "04", 'S': "05"}
+	var layout strings.Builder
+	for i := 0; i < len(dateformat); i++ {
+		c := dateformat[i]
+		switch {
+		case c == '%' && i+1 < len(dateformat) && len(specifiers[dateformat[i+1]]) > 0:
+			layout.WriteString(specifiers[dateformat[i+1]])
+			i++
+		case c == '-' || c == '_' || c == '.':
+			layout.WriteByte(c)
+		default:
+			return "", fmt.Errorf("unsupported dateformat %q, only %%Y, %%m, %%d, %%H, %%M, %%S, \"-\", \"_\" and \".\" are supported", dateformat)
+		}
+	}
+	return layout.String(), nil
+}
+
+// Tell whether the archive of a rotation with [WithDateext] already exists, in which case the rotation is skipped.
+// The archive is only reported once, instead of at every write until the date changes.
+func (k *Keeper) dateextTaken(reason RotationReason, opts RotateOpts) bool {
+	if len(k.dateFormat) == 0 {
+		return false
+	}
+	taken, err := k.archivePathAt(k.archiveNameTime(), reason)
+	if err != nil {
+		return false
+	}
+	if len(opts.Label) > 0 {
+		taken += "." + opts.Label
+	}
+	if _, err := k.fsys.Stat(taken); errors.Is(err, fs.ErrNotExist) && k.compressor != nil && !opts.NoCompression {
+		taken = k.compressedArchivePath(taken)
+	}
+	if _, err := k.fsys.Stat(taken); err != nil {
+		return false
+	}
+	if taken != k.dateextSkipped {
+		k.dateextSkipped = taken
+		k.stats.SkippedRotations++
+		k.handleError(fmt.Errorf("failed to rotate log file, archive %q already exists, skipping rotation, caused by %w", taken, fs.ErrExist))
+	}
+	return true
+}
+
+// Keep the archives in the given folder instead of the folder of the current log file, like the olddir option of logrotate,
+// such as to ship or back up the archives apart from the live logs. A relative dir is relative to the folder of the current log file,
+// see [WithFolder]. The folder is created with [WithCreateFolder], like with the createolddir option of logrotate,
+// and it must exist otherwise. It can not be used together with multiple folders of [WithFolders].
+// Set an empty dir to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithOldDir("/var/log/app-archives"))
+func WithOldDir(dir string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.oldDir = dir
+		return k, nil
+	}
+}
+
+// Reject an old dir together with multiple folders.
+func (k *Keeper) applyOldDir() error {
+	if len(k.oldDir) > 0 && len(k.folders) > 1 {
+		return fmt.Errorf("failed to set old dir, it can not be used together with multiple folders")
+	}
+	return nil
+}
+
+// Get the folder of the archives with [WithOldDir].
+func (k *Keeper) getOldDir() string {
+	if filepath.IsAbs(k.oldDir) {
+		return normalizeFolder(k.oldDir)
+	}
+	return normalizeFolder(filepath.Join(k.folder, k.oldDir))
+}
@@ -0,0 +1,118 @@
+package lorekeeper
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithDateextAndOldDir(t *testing.T) {
+	folder := t.TempDir()
+	var errs []error
+	opts := []Opt{
+		WithFolder(folder),
+		WithName("app"),
+		WithGzip(),
+		WithDateext(""),
+		WithOldDir("old"),
+		WithCreateFolder(0755),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archivePath, err := k.RotateNow()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	want := filepath.Join(folder, "old", "app.log-"+time.Now().Format("20060102")+".gz")
+	if archivePath != want {
+		t.Errorf("expected archive %s got %s", want, archivePath)
+	}
+
+	// The archive of the day exists, the rotation is skipped and the current log file keeps growing
+	if _, err := k.Write([]byte("def\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("ghi\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(folder, "app.log")); err != nil || string(b) != "def\nghi\n" {
+		t.Errorf("expected %q and no error got %q and %v", "def\nghi\n", b, err)
+	}
+	if got := k.Stats().SkippedRotations; got != 1 {
+		t.Errorf("expected 1 skipped rotation got %d", got)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], fs.ErrExist) {
+		t.Errorf("expected an error wrapping fs.ErrExist got %v", errs)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The archives are found in the old dir on the next start
+	k, err = New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if archives := k.Archives(); len(archives) != 1 || archives[0].Path != want {
+		t.Errorf("expected the archive %s got %v", want, archives)
+	}
+}
+
+func TestWithDateextChunkedWrite(t *testing.T) {
+	folder := t.TempDir()
+	var errs []error
+	k, err := New(
+		WithFolder(folder),
+		WithName("app"),
+		WithMaxSize(10),
+		WithDateext(""),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// The second chunk fills up the log file, but the archive of the day is taken, the rest is not split
+	msg := []byte("0123456789abcdefghijklmn\n")
+	for range 2 {
+		if n, err := k.Write(msg); err != nil || n != len(msg) {
+			t.Fatalf("expected %d bytes and no error got %d and %v", len(msg), n, err)
+		}
+	}
+	if b, err := os.ReadFile(filepath.Join(folder, "app.log")); err != nil || string(b) != string(msg[10:])+string(msg) {
+		t.Errorf("expected %q and no error got %q and %v", string(msg[10:])+string(msg), b, err)
+	}
+	if archives := k.Archives(); len(archives) != 1 || archives[0].Size != 10 {
+		t.Errorf("expected 1 archive of 10 bytes got %v", archives)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], fs.ErrExist) {
+		t.Errorf("expected an error wrapping fs.ErrExist got %v", errs)
+	}
+}
+
+func TestWithDateextFormat(t *testing.T) {
+	layout, err := dateextLayout("_%Y-%m-%d.%H%M%S")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if layout != "_2006-01-02.150405" {
+		t.Errorf("expected layout %q got %q", "_2006-01-02.150405", layout)
+	}
+	if _, err := New(WithFolder(t.TempDir()), WithName("test-dateext-invalid"), WithDateext("-%s")); err == nil {
+		t.Errorf("expected an error for an unsupported dateformat")
+	}
+}
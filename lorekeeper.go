@@ -2,12 +2,18 @@ package lorekeeper
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"text/template"
+	"time"
 
 	"github.com/robfig/cron/v3"
 	"github.com/trviph/collection"
@@ -18,6 +24,9 @@ import (
 type Keeper struct {
 	// See [WithFolder] for documentation.
 	folder string
+	// See [WithFolders] for documentation.
+	folders    []string
+	nextFolder int
 	// See [WithName] for documentation.
 	name string
 	// See [WithExtension] for documentation.
@@ -26,22 +35,265 @@ type Keeper struct {
 	timeLayout string
 	// See [WithMaxSize] for documentation
 	maxSize int
+	// See [WithMaxLines] for documentation
+	maxLines int
 	// See [WithArchiveNameLayout] for documentation
-	archiveNameLayout *template.Template
+	archiveNameLayout     *template.Template
+	archiveNameLayoutText string
 	// See [WithMaxFiles] for documentation
 	maxFiles int
 	// See [WithCron] for documentation
-	cronScheduler *cron.Cron
-	cronEntryID   cron.EntryID
-	// See [WithGzip], [WithGzipLevel] for documentation
-	compressorContructor func(w io.Writer) (io.WriteCloser, error)
-	compressionExt       string
+	cronSpec     string
+	cronSchedule cron.Schedule
+	// The timer of the next rotation, and the generation of the schedule it belongs to
+	cronTimer      Timer
+	cronGeneration uint64
+	// See [WithCompressor], [WithGzip], [WithGzipLevel] for documentation
+	compressor     Compressor
+	compressionExt string
 	// See [WithTotalSize] for documentation
 	totalSize int
+	// See [WithMaxAge] for documentation
+	maxAge time.Duration
+	// See [WithCompaction] for documentation
+	compactionAge  time.Duration
+	compactionSize int
+	// Closed once the running compaction is done, nil if none is running
+	compacting chan struct{}
+	// See [WithRetentionPolicy] for documentation
+	retentionPolicy RetentionPolicy
+	// See [WithClock] and [WithNowFunc] for documentation
+	clock Clock
+	// See [WithRegistry] for documentation
+	registry *Registry
+	// See [WithUniqueSuffix] for documentation
+	uniqueSuffix string
+	// See [WithMaxAgeAtStartup] for documentation
+	maxAgeAtStartup time.Duration
+	// See [WithCloseAfterIdle] for documentation
+	closeAfterIdle time.Duration
+	idleTimer      Timer
+	// See [WithFallbackWriter] for documentation
+	fallbackWriter io.Writer
+	// See [WithErrorHandler] for documentation
+	errorHandler func(err error)
+	// See [WithCircuitBreaker] for documentation
+	breakerThreshold     int
+	breakerProbeInterval time.Duration
+	breakerFailures      int
+	breakerProbeAt       time.Time
 
-	mu              sync.Mutex
-	currentFile     io.WriteCloser
-	currentFileSize int
+	// See [WithLengthPrefixedRecords] for documentation
+	lengthPrefixed bool
+	recordBuf      []byte
+	// See [WithRecordPattern] for documentation
+	recordPattern *regexp.Regexp
+	// See [WithTimestampParser] for documentation
+	timestampParser func(record []byte) (time.Time, error)
+	// See [WithValidateJSON], [WithWrapInvalidJSON] for documentation
+	jsonPolicy jsonPolicy
+	jsonBuf    []byte
+	// See [WithPausePolicy] and [Keeper.Pause] for documentation
+	pausePolicy    PausePolicy
+	pauseMaxBuffer int
+	paused         bool
+	pauseBuffer    []*[]byte
+	pauseBuffered  int
+
+	// See [WithDoubleBuffering] for documentation
+	segmentSize int
+	segments    atomic.Pointer[segments]
+	// See [WithAsyncWrites] for documentation
+	asyncSize    int
+	asyncPolicy  DropPolicy
+	async        atomic.Pointer[asyncQueue]
+	asyncDropped atomic.Uint64
+	// See [WithForwarder] for documentation
+	forwarder      *forwardConfig
+	forward        atomic.Pointer[forwardQueue]
+	forwardDropped atomic.Uint64
+	forwarded      atomic.Uint64
+	forwardErrors  atomic.Uint64
+	// See [WithRateLimit] and [WithRateLimitPolicy] for documentation
+	rateLimit         int
+	rateBurst         int
+	ratePolicy        DropPolicy
+	limiter           atomic.Pointer[rateLimiter]
+	rateThrottled     atomic.Uint64
+	rateThrottledTime atomic.Int64
+	rateDropped       atomic.Uint64
+
+	// See [WithSampling] for documentation
+	sampleRate    float64
+	sampleMatcher func(msg []byte) bool
+	// See [WithStrictNames] for documentation
+	strictNames bool
+	// See [WithDeletionHistory] and [WithDeletionLog] for documentation
+	deletionHistory int
+	deletions       []Deletion
+	deletionLog     io.Writer
+	// See [WithRecent] for documentation
+	recent      [][]byte
+	recentNext  int
+	recentCount int
+	// See [WithOnRotate] and [WithOnArchiveRemoved] for documentation
+	onRotate         func(archivePath string)
+	onArchiveRemoved func(path string)
+	hooks            hookQueue
+	// See [Keeper.Tasks] for documentation
+	tasks supervisor
+	// See [Keeper.Shutdown] for documentation
+	shutdown shutdownState
+	// See [WithLatencyWindow] for documentation
+	latency latencyRecorder
+	// See [WithReaderCache] for documentation
+	readerCache *archiveCache
+	// See [WithBufferSize] and [WithFlushInterval] for documentation
+	bufferSize    int
+	flushInterval time.Duration
+	writeBuf      []byte
+	flushTimer    Timer
+	// See [WithManualStepping] for documentation
+	manual     bool
+	steppedAt  time.Time
+	bufferedAt time.Time
+	// See [WithUploader] and [WithUploadRetry] for documentation
+	uploader          Uploader
+	deleteAfterUpload bool
+	uploadAttempts    int
+	uploadBackoff     time.Duration
+	uploads           hookQueue
+	// See [WithReopenSignal] for documentation
+	reopenSignals []os.Signal
+	reopenSignal  chan os.Signal
+	// See [WithEncryption] for documentation
+	encryption *encryptingCompressor
+	// See [WithMaxPooledBufferSize] for documentation, atomic so that the fast paths release their buffers without the lock
+	maxPooledBufferSize atomic.Int64
+	// See [WithRotateEvery] and [WithDailyRotation] for documentation
+	rotationPeriod rotationPeriod
+	rotateAt       time.Time
+	// See [WithMinDiskFree] and [WithMinDiskFreePercent] for documentation
+	minDiskFree        int
+	minDiskFreePercent float64
+	// The sequence number of the last archive, see {{ .seq }} in [WithArchiveNameLayout]
+	archiveSeq uint64
+	// See [WithCurrentNameLayout] for documentation
+	currentNameLayout     *template.Template
+	currentNameLayoutText string
+	// The resolved name of the current log file
+	currentName string
+	// The path of the current log file along with the options it was joined from, see getCurrentFilePath
+	currentPath atomic.Pointer[currentPath]
+	// See [WithCurrentSymlink] for documentation
+	currentSymlink string
+	// See [WithFileHeader] for documentation
+	fileHeader func(w io.Writer) error
+	// The size of the header written into the current log file
+	headerSize int
+	// See [WithTags] for documentation
+	tags []string
+	// See [WithHashChain] for documentation
+	chain *hashChain
+	// See [WithSyncWrites] for documentation
+	syncWrites bool
+	// See [NewAuditKeeper] for documentation
+	audit bool
+	// See [WithChecksum] for documentation
+	checksum ChecksumAlgorithm
+	// See [WithAdoptPattern] for documentation
+	adoptPatterns []string
+	// See [WithArchiveTimeSource] for documentation
+	archiveTimeSource ArchiveTimeSource
+	// See [WithManifest] for documentation
+	manifest          bool
+	currentFirstWrite time.Time
+	// See [WithManifestDiscovery] for documentation
+	manifestDiscovery bool
+	manifestVerify    bool
+	// See [WithPendingUploads] for documentation
+	pendingUploads bool
+	pending        []string
+	pendingQueued  map[string]bool
+	// See [WithRotateOnStart] for documentation
+	rotateOnStart bool
+	// See [Keeper.WithRotationPaused] for documentation
+	rotationBarrier int
+	heldRotation    RotationReason
+	heldPrune       bool
+	heldUploaded    []string
+	// See [WithQuota] for documentation
+	quota int
+	// See [WithHardMaxSize] for documentation
+	hardMaxSize   int
+	hardMaxPolicy HardMaxPolicy
+	// See [WithStartupCompaction] for documentation
+	startupCompaction int
+	// See [WithCurrentFileCheck] for documentation
+	currentFileCheck int
+	uncheckedWrites  int
+	// See [WithDateext] and [WithOldDir] for documentation
+	dateFormat     string
+	dateextSkipped string
+	oldDir         string
+	// See [WithCompressedCurrent] for documentation
+	compressedCurrent   bool
+	compressedFlushSize int
+	finishedCurrentSize int64
+	// See [WithDropSummary] for documentation
+	dropSummary       time.Duration
+	dropSummaryAt     time.Time
+	dropSummaryCounts dropCounts
+	// See [WithReadOnlyArchives] for documentation
+	readOnlyArchives bool
+	// See [WithRotationCoalescing] for documentation
+	coalescingWindow time.Duration
+	// See [WithTemplateData] for documentation
+	templateData map[string]string
+	// See [WithTenant] for documentation
+	tenant     string
+	folderMode os.FileMode
+	// See [WithSkipEmptyRotation] for documentation
+	skipEmptyRotation bool
+	// See [WithTrustModTime] for documentation
+	modTimePolicy modTimePolicy
+	// See [WithCreateFolder] and [WithFileMode] for documentation
+	createFolderMode os.FileMode
+	fileMode         os.FileMode
+	// See [WithLockFile] for documentation
+	lockFile     bool
+	lockFileHeld bool
+	// See [WithBackgroundWorkers] for documentation
+	backgroundWorkers int
+	// See [Group] for documentation
+	group *Group
+
+	// See [WithFS] for documentation
+	fsys FS
+
+	// See [WithDeferredRemoval] for documentation
+	deferredRemoval bool
+	openHandles     map[string]int
+	pendingRemovals map[string]*fileInfo
+
+	// See [Keeper.Stats] for documentation
+	stats   Stats
+	failing bool
+
+	// See [Keeper.Follow] for documentation
+	followers map[chan []byte]struct{}
+
+	mu                 sync.Mutex
+	closed             bool
+	currentFile        io.WriteCloser
+	currentFileSize    int
+	currentRecords     int
+	currentLines       int
+	lastWrite          time.Time
+	lastRotation       time.Time
+	lastRotationReason RotationReason
+	lastArchivePath    string
+	generation         uint64
 
 	archives     *collection.List[*fileInfo]
 	archivesSize int
@@ -67,26 +319,23 @@ var _ io.WriteCloser = (*Keeper)(nil)
 //	 	)
 //		}
 func New(opts ...Opt) (*Keeper, error) {
-	defaultOpts := []Opt{
-		WithFolder(os.TempDir()),
-		WithName(defaultKeeperName()),
-		WithExtension(".log"),
-		WithTimeLayout("2006-01-02-15-04-05.000000000-0700"),
-		WithMaxSize(15 * Mb),
-		WithArchiveNameLayout("{{ .time }}-{{ .name }}{{ .extension }}"),
-		WithMaxFiles(0),
-		NoCron(),
-		NoCompression(),
-		WithTotalSize(0),
-	}
-	finalOpts := append(defaultOpts, opts...)
+	finalOpts := append(DefaultOptions(), opts...)
 
 	keeper := new(Keeper)
 	if err := keeper.applyOpts(finalOpts...); err != nil {
 		return nil, fmt.Errorf("failed to create new keeper, caused by %w", err)
 	}
 
-	keeper, new := register(keeper.name, keeper)
+	keeper, new := keeper.registry.register(keeper.name, keeper)
+	// Only a new Keeper touches the existing files, an already running one is still managing them
+	if new {
+		if err := keeper.acquireLockFile(); err != nil {
+			keeper.registry.unregister(keeper.name)
+			_ = keeper.free()
+			return nil, fmt.Errorf("failed to create new keeper, caused by %w", err)
+		}
+		keeper.startup()
+	}
 	// If loaded old keeper from registry, update it configurations
 	if !new {
 		keeper.mu.Lock()
@@ -99,13 +348,109 @@ func New(opts ...Opt) (*Keeper, error) {
 	return keeper, nil
 }
 
+// Get the options that [New] applies before the user provided options.
+// The result is a new slice on every call, so it is safe to append to it.
+func DefaultOptions() []Opt {
+	tuning := DefaultTuning()
+	return []Opt{
+		WithFolder(os.TempDir()),
+		WithName(defaultKeeperName()),
+		WithExtension(".log"),
+		WithTimeLayout("2006-01-02-15-04-05.000000000-0700"),
+		WithMaxSize(15 * Mb),
+		WithArchiveNameLayout("{{ .time }}-{{ .name }}{{ .extension }}"),
+		WithMaxFiles(0),
+		NoCron(),
+		NoCompression(),
+		WithTotalSize(0),
+		WithMaxAge(0),
+		WithCompaction(0, 0),
+		WithRetentionPolicy(nil),
+		WithClock(nil),
+		WithRegistry(nil),
+		WithMaxAgeAtStartup(0),
+		WithCloseAfterIdle(0),
+		WithFallbackWriter(nil),
+		WithCircuitBreaker(0, 0),
+		WithPausePolicy(PauseBuffer, tuning.PauseMaxBuffer),
+		NoLengthPrefixedRecords(),
+		WithRecordPattern(nil),
+		WithTimestampParser(nil),
+		NoJSONValidation(),
+		WithDoubleBuffering(0),
+		WithErrorHandler(nil),
+		NoDeferredRemoval(),
+		WithBackgroundWorkers(tuning.BackgroundWorkers),
+		NoLockFile(),
+		NoStrictNames(),
+		WithSampling(1, nil),
+		WithTemplateData(nil),
+		WithDeletionHistory(defaultDeletionHistory),
+		WithDeletionLog(nil),
+		WithRecent(0),
+		WithOnRotate(nil),
+		WithOnArchiveRemoved(nil),
+		WithBufferSize(0),
+		WithFlushInterval(defaultFlushInterval),
+		NoManualStepping(),
+		NoIntervalRotation(),
+		WithMaxPooledBufferSize(tuning.MaxPooledBufferSize),
+		WithUploader(nil, false),
+		WithUploadRetry(defaultUploadAttempts, defaultUploadBackoff),
+		WithEncryption(nil),
+		WithReopenSignal(),
+		WithCreateFolder(0),
+		WithFileMode(defaultFileMode),
+		withModTimePolicy(modTimeAuto),
+		NoSkipEmptyRotation(),
+		WithAsyncWrites(0, DropNone),
+		WithRateLimit(0, 0),
+		WithRateLimitPolicy(DropNone),
+		WithRotationCoalescing(0),
+		WithMinDiskFree(0),
+		WithMinDiskFreePercent(0),
+		NoReadOnlyArchives(),
+		WithQuota(0),
+		WithHardMaxSize(0, HardMaxSplit),
+		WithStartupCompaction(0),
+		WithCurrentFileCheck(0),
+		WithOldDir(""),
+		WithDropSummary(0),
+		NoCompressedCurrent(),
+		WithForwarder(nil, 0, DropNone),
+		WithFS(nil),
+		WithCurrentNameLayout(""),
+		NoPendingUploads(),
+		NoRotateOnStart(),
+		WithLatencyWindow(0),
+		WithMaxLines(0),
+		WithReaderCache(0),
+		WithCurrentSymlink(""),
+		WithFileHeader(nil),
+		WithTags(),
+		NoHashChain(),
+		NoSyncWrites(),
+		withAuditProfile(false),
+		NoManifest(),
+		NoManifestDiscovery(),
+		NoChecksum(),
+		WithAdoptPattern(),
+		WithArchiveTimeSource(ArchiveTimeRotation),
+	}
+}
+
 func (k *Keeper) applyOpts(opts ...Opt) error {
-	var err error
-	for _, opt := range opts {
-		k, err = opt(k)
-		if err != nil {
-			return fmt.Errorf("failed to apply option, caused by %w", err)
-		}
+	k, err := configure(k, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to apply options, caused by %w", err)
+	}
+	return k.load()
+}
+
+// Open the current log file and scan the archives according to the configuration of the Keeper.
+func (k *Keeper) load() error {
+	if err := k.createFolder(); err != nil {
+		return fmt.Errorf("failed to apply option, caused by %w", err)
 	}
 
 	file, err := k.getCurrentFile()
@@ -118,194 +463,924 @@ func (k *Keeper) applyOpts(opts ...Opt) error {
 		return fmt.Errorf("failed to apply option, caused by %w", err)
 	}
 	k.currentFileSize = int(stat.Size())
+	if err := k.scanCurrentLines(); err != nil {
+		return fmt.Errorf("failed to apply option, caused by %w", err)
+	}
+	// Count the records of a new Keeper, the records already in the current log file are unknown
+	if k.archives == nil {
+		k.steppedAt = k.now()
+		k.currentRecords = 0
+		if k.currentFileSize > 0 {
+			k.currentRecords = -1
+		}
+	}
+	if k.currentFileSize == 0 {
+		k.writeFileHeader()
+	} else if err := k.scanCurrentChain(); err != nil {
+		return fmt.Errorf("failed to apply option, caused by %w", err)
+	}
+	k.resetIdleTimer()
+	k.configureSegments()
+	k.configureAsync()
+	k.configureForward()
+	k.configureCron()
+	k.configureRateLimit()
 
-	archives, size, err := k.getArchives()
+	archives, size, err := k.discoverArchives()
 	if err != nil {
 		return fmt.Errorf("failed to apply option, caused by %w", err)
 	}
 	k.archives = archives
 	k.archivesSize = size
+	k.lastWrite = k.now()
+	if newest, err := archives.Index(archives.Length() - 1); err == nil {
+		k.lastRotation = newest.modtime
+		if k.modTimePolicy == modTimeDistrusted {
+			k.lastRotation = k.archiveTime(newest)
+		}
+	}
+	k.restoreArchiveSeq()
 	return nil
 }
 
+// Finish the work left over by a previous process managing the same files.
+// The Keeper starts anyway if this fails, the errors go to the error handler.
+func (k *Keeper) startup() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.resumeCompression()
+	k.compactArchives()
+	k.rotateIfStale()
+	k.rotateOnStartup()
+	k.resumePendingUploads()
+}
+
+// Rotate the current log file if it is older than the max age at startup, see [WithMaxAgeAtStartup].
+func (k *Keeper) rotateIfStale() {
+	if k.maxAgeAtStartup <= 0 || k.currentFileSize == 0 {
+		return
+	}
+	stat, err := k.fsys.Stat(k.getCurrentFilePath())
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to check the age of the current log file, caused by %w", err))
+		return
+	}
+	if k.now().Sub(stat.ModTime()) <= k.maxAgeAtStartup {
+		return
+	}
+	if err := k.rotate(RotationStale); err != nil {
+		k.handleError(fmt.Errorf("failed to rotate stale log file, caused by %w", err))
+	}
+}
+
+// Rotate the existing current log file if it is not empty, see [WithRotateOnStart].
+func (k *Keeper) rotateOnStartup() {
+	if !k.rotateOnStart || k.currentFileSize == 0 {
+		return
+	}
+	if err := k.rotate(RotationStartup); err != nil {
+		k.handleError(fmt.Errorf("failed to rotate log file on start, caused by %w", err))
+	}
+}
+
 func (k *Keeper) getArchives() (*collection.List[*fileInfo], int, error) {
-	pattern, err := k.getArchiveGlobPattern()
+	patterns, err := k.getArchiveGlobPatterns()
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get archive pattern, caused by %w", err)
 	}
-	return getArchives(pattern)
+	archives, size, err := getArchives(k.fsys, append(patterns, k.getAdoptGlobPatterns()...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	archives, size = k.excludeCurrentFile(archives, size)
+	return k.orderArchives(archives), size, nil
+}
+
+// Get all the folders that may contain archives, the first one is always the folder of the current log.
+func (k *Keeper) getArchiveFolders() []string {
+	if len(k.oldDir) > 0 {
+		return []string{k.getOldDir()}
+	}
+	return k.getFolders()
+}
+
+// Get the folders of the Keeper, see [WithFolders].
+func (k *Keeper) getFolders() []string {
+	if len(k.folders) == 0 {
+		return []string{k.folder}
+	}
+	return k.folders
+}
+
+// Get the path of an archive relative to the archive folder containing it.
+func getArchiveRelPath(folders []string, archivePath string) (string, error) {
+	for _, folder := range folders {
+		rel, err := filepath.Rel(folder, archivePath)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			return rel, nil
+		}
+	}
+	return "", fmt.Errorf("archive %q is not in any of the archive folders", archivePath)
 }
 
 // Get the current log file descriptor.
-func (k *Keeper) getCurrentFile() (*os.File, error) {
-	return os.OpenFile(k.getCurrentFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+func (k *Keeper) getCurrentFile() (File, error) {
+	file, err := k.fsys.OpenFile(k.getCurrentFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, k.fileMode)
+	if err != nil {
+		return nil, err
+	}
+	if k.compressedCurrent {
+		if file, err = k.compressCurrentFile(file); err != nil {
+			return nil, err
+		}
+	}
+	k.updateCurrentSymlink()
+	return file, nil
 }
 
-// Get the path to the current log file.
+// Get the path to the current log file, with the extension of the compressor if it is compressed, see [WithCompressedCurrent].
 func (k *Keeper) getCurrentFilePath() string {
-	return path.Join(k.folder, fmt.Sprintf("%s%s", k.name, k.extension))
+	// The path is joined once and reused on every write, until one of the options it is joined from changes
+	if c := k.currentPath.Load(); c != nil && c.folder == k.folder && c.name == k.name && c.extension == k.extension &&
+		c.currentName == k.currentName && c.compressed == k.compressedCurrent && c.compressionExt == k.compressionExt {
+		return c.path
+	}
+	path := filepath.Join(k.folder, k.currentName)
+	if len(k.currentName) == 0 {
+		path = filepath.Join(k.folder, fmt.Sprintf("%s%s", k.name, k.extension))
+	}
+	if k.compressedCurrent {
+		path += k.compressionExt
+	}
+	k.currentPath.Store(&currentPath{
+		folder:         k.folder,
+		name:           k.name,
+		extension:      k.extension,
+		currentName:    k.currentName,
+		compressed:     k.compressedCurrent,
+		compressionExt: k.compressionExt,
+		path:           path,
+	})
+	return path
+}
+
+// The path of the current log file, cached by getCurrentFilePath.
+type currentPath struct {
+	folder, name, extension, currentName string
+	compressed                           bool
+	compressionExt                       string
+	path                                 string
 }
 
 // Write the msg to the current log file.
+//
+// A msg that fits into a log file is never split, the current log file is rotated beforehand if needed.
+// A msg larger than the max size is streamed in chunks, each filling up a log file before rotating it,
+// so that no log file exceeds the max size, unless a rotation is skipped, such as when the archive of [WithDateext]
+// already exists, then the rest of the msg is written to the current log file unsplit.
 func (k *Keeper) Write(msg []byte) (int, error) {
+	defer k.latency.record(time.Now())
+	if err := k.throttle(len(msg)); err != nil {
+		return 0, err
+	}
+	if s := k.segments.Load(); s != nil {
+		return s.append(msg, nil)
+	}
+	if q := k.async.Load(); q != nil {
+		return q.append(msg)
+	}
+
 	k.mu.Lock()
 	defer k.mu.Unlock()
+	return k.writeLocked(msg)
+}
+
+// Write the msg, the lock of the Keeper must be held.
+func (k *Keeper) writeLocked(msg []byte) (int, error) {
+	k.summarizeDrops(false)
+	if k.sampledOut(msg) {
+		return len(msg), nil
+	}
+	k.remember(msg)
+	k.forwardMessage(msg)
+	k.notifyFollowers(msg)
+	if k.paused {
+		return k.writePaused(msg)
+	}
+	return k.writeMessage(msg)
+}
+
+// Validate the msg if needed, then write it to the current log file.
+func (k *Keeper) writeMessage(msg []byte) (int, error) {
+	if k.jsonPolicy == jsonOff {
+		return k.writeSplit(msg)
+	}
+	normalized, err := normalizeJSON(k.jsonBuf[:0], msg, k.jsonPolicy == jsonWrap)
+	if err != nil {
+		k.stats.Dropped++
+		return 0, err
+	}
+	// Reuse the buffer for the next message unless a large message grew it
+	if k.reusable(normalized) {
+		k.jsonBuf = normalized
+	}
+	n, err := k.writeSplit(normalized)
+	if err != nil {
+		return min(n, len(msg)), err
+	}
+	return len(msg), nil
+}
 
-	if k.shouldRotate(msg) {
-		if err := k.rotate(); err != nil {
+// Write the msg to the current log file, rotating or splitting it as needed.
+func (k *Keeper) writeSplit(msg []byte) (int, error) {
+	k.checkCurrentFile()
+	if err := k.rotateIfDue(); err != nil {
+		return 0, err
+	}
+	if k.lengthPrefixed {
+		return k.writeRecord(msg)
+	}
+	// Do not rotate while the disk is known to be failing, or while the rotations are held
+	held := k.breakerOpen() || k.rotationBarrier > 0
+	if k.chain != nil {
+		return k.writeChained(msg, !held)
+	}
+	if held {
+		return k.write(msg)
+	}
+	if k.recordPattern != nil {
+		return k.writeLines(msg)
+	}
+	if k.maxSize > 0 && len(msg) > k.maxSize {
+		return k.writeChunked(msg)
+	}
+
+	if reason, ok := k.rotationFor(msg); ok {
+		if err := k.rotate(reason); err != nil {
 			return 0, err
 		}
 	}
+	return k.write(msg)
+}
+
+// Write a msg larger than the max size, splitting it into chunks that fill up each log file.
+func (k *Keeper) writeChunked(msg []byte) (int, error) {
+	written := 0
+	for written < len(msg) {
+		if k.currentFileSize >= k.maxSize {
+			if err := k.rotate(RotationSize); err != nil {
+				return written, err
+			}
+		}
+		// The rotation was skipped, the current log file can not take any chunk
+		if k.currentFileSize >= k.maxSize {
+			n, err := k.write(msg[written:])
+			return written + n, err
+		}
+		end := min(len(msg), written+k.maxSize-k.currentFileSize)
+		n, err := k.write(msg[written:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
 
-	n, err := k.currentFile.Write(msg)
+// Write the msg to the current log file and account for its size.
+// The part of the msg that can not be written to the current log file goes to the fallback writer if any.
+func (k *Keeper) write(msg []byte) (int, error) {
+	if k.hardMaxExceeded(len(msg)) && !k.breakerOpen() {
+		return k.writeHardMax(msg)
+	}
+	return k.writeChecked(msg)
+}
+
+// Write the msg to the current log file unless it exceeds one of the limits, and account for its size.
+func (k *Keeper) writeChecked(msg []byte) (int, error) {
+	var n int
+	var err error
+	wasFailing := k.failing
+	if k.breakerOpen() {
+		err = fmt.Errorf("failed to write to current log file, caused by %w", ErrCircuitOpen)
+	} else if k.quotaExceeded(len(msg)) {
+		err = k.quotaError(len(msg))
+	} else if k.hardMaxExceeded(len(msg)) {
+		err = k.hardMaxError(len(msg))
+	} else {
+		if k.failing {
+			k.stats.Retries++
+		}
+		n, err = k.writeCurrent(msg)
+		if err == nil && k.syncWrites {
+			err = k.syncCurrentFile()
+		}
+		k.recordWriteResult(err)
+		k.countRecord(err)
+		k.recordBreakerResult(err)
+	}
 	if err != nil {
+		if k.fallbackWriter == nil || k.closed {
+			k.stats.Dropped++
+			return n, err
+		}
+		// Drop the broken file descriptor so that the next write tries to reopen the current log file
+		_ = k.closeCurrentFile()
+		m, fallbackErr := k.fallbackWriter.Write(msg[n:])
+		n += m
+		if fallbackErr != nil {
+			k.stats.Dropped++
+			return n, fmt.Errorf("failed to write to fallback writer, caused by %w", errors.Join(err, fallbackErr))
+		}
+		k.stats.FallbackWrites++
+		// The caller does not see the failure, report the start of the outage instead
+		if !wasFailing && !errors.Is(err, ErrCircuitOpen) && !errors.Is(err, ErrQuotaExceeded) && !errors.Is(err, ErrHardMaxSize) {
+			k.handleError(fmt.Errorf("failed to write to current log file, writing to fallback writer, caused by %w", err))
+		}
+	}
+	k.lastWrite = k.now()
+	if n > 0 && k.currentFirstWrite.IsZero() {
+		k.currentFirstWrite = k.lastWrite
+	}
+	return n, nil
+}
+
+// Write the msg to the current log file, reopening it if needed.
+func (k *Keeper) writeCurrent(msg []byte) (int, error) {
+	if err := k.openCurrentFile(); err != nil {
 		return 0, err
 	}
-	k.currentFileSize += n
+	var n int
+	var err error
+	if k.bufferSize > 0 {
+		n, err = k.writeBuffered(msg)
+	} else {
+		n, err = k.writeFile(msg)
+	}
+	k.currentFileSize = k.grownCurrentFileSize(n)
+	k.stats.BytesWritten += uint64(n)
+	k.countLines(msg[:n])
+	if err != nil {
+		return n, err
+	}
+	k.resetIdleTimer()
 	return n, nil
 }
 
+// Open the current log file if it was closed after being idle, see [WithCloseAfterIdle].
+func (k *Keeper) openCurrentFile() error {
+	if k.currentFile != nil {
+		return nil
+	}
+	if k.closed {
+		return fmt.Errorf("failed to reopen current log file, caused by %w", os.ErrClosed)
+	}
+	file, err := k.getCurrentFile()
+	if err != nil {
+		return fmt.Errorf("failed to reopen current log file, caused by %w", err)
+	}
+	k.currentFile = file
+	if stat, err := file.Stat(); err == nil {
+		// The messages left in the buffer by a failed flush are still to be written
+		k.currentFileSize = int(stat.Size()) + len(k.writeBuf)
+	}
+	// The file may have been replaced or truncated in the meantime, such as by copytruncate
+	if err := k.scanCurrentLines(); err != nil {
+		return fmt.Errorf("failed to reopen current log file, caused by %w", err)
+	}
+	if err := k.scanCurrentChain(); err != nil {
+		return fmt.Errorf("failed to reopen current log file, caused by %w", err)
+	}
+	return nil
+}
+
+// Restart the countdown to close the current log file, see [WithCloseAfterIdle].
+func (k *Keeper) resetIdleTimer() {
+	if k.closeAfterIdle <= 0 || k.manual {
+		return
+	}
+	if k.idleTimer == nil {
+		k.idleTimer = k.clock.AfterFunc(k.closeAfterIdle, k.supervised("idle", k.closeIdle))
+		return
+	}
+	k.idleTimer.Reset(k.closeAfterIdle)
+}
+
+// Release the file descriptor of the current log file, the next write reopens it.
+func (k *Keeper) closeIdle() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.idleTimer == nil {
+		// The Keeper was closed
+		return
+	}
+	// The write buffer is kept if it can not be flushed, so there is nothing to lose if closing fails
+	_ = k.closeCurrentFile()
+}
+
+// Stop the countdown to close the current log file.
+func (k *Keeper) stopIdleTimer() {
+	if k.idleTimer != nil {
+		k.idleTimer.Stop()
+		k.idleTimer = nil
+	}
+}
+
+// Get the current time of the Keeper's clock.
+func (k *Keeper) now() time.Time {
+	return k.clock.Now()
+}
+
+// Get the last time the Keeper was written to, or when it was created if it was never written to.
+func (k *Keeper) getLastWrite() time.Time {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lastWrite
+}
+
 // Rotate the current log file and close the Keeper.
 // Any subsequence writes after this may cause error.
+// A paused Keeper is resumed first, see [Keeper.Pause].
+// See [Keeper.Shutdown] to bound the time it takes.
 func (k *Keeper) Close() error {
+	err := k.close()
+	// Outside the lock, since the hooks may call the methods of the Keeper
+	k.hooks.wait()
+	k.uploads.wait()
+	k.waitForward()
+	k.waitCompaction(context.Background())
+	k.mu.Lock()
+	group := k.group
+	k.mu.Unlock()
+	if group != nil {
+		group.detach(k)
+	}
+	return err
+}
+
+func (k *Keeper) close() error {
+	k.stopSegmentsAndWait()
+	k.stopAsyncAndWait()
+	// The messages queued by the drainers above are forwarded, the later ones are not
+	k.stopForward()
 	k.mu.Lock()
 	defer k.mu.Unlock()
+	if err := k.resume(); err != nil {
+		return fmt.Errorf("failed to resume, caused by %w", err)
+	}
+	k.summarizeDrops(true)
 	// Rotate the log
-	if err := k.rotate(); err != nil {
+	if err := k.rotate(RotationClose); err != nil {
 		return fmt.Errorf("failed to rotate file, caused by %w", err)
 	}
 	// Remove this Keeper from the registry
-	unregister(k.name)
+	k.registry.unregister(k.name)
 	// Free it resources
 	return k.free()
 }
 
 func (k *Keeper) free() error {
-	if k.cronScheduler != nil {
-		// Stop the cron scheduler to prevent goroutine leak
-		k.cronScheduler.Stop()
+	k.stopCronTimer()
+	k.stopIdleTimer()
+	k.stopFlushTimer()
+	k.stopReopenSignal()
+	if s := k.segments.Swap(nil); s != nil {
+		s.stop()
+	}
+	if q := k.async.Swap(nil); q != nil {
+		q.stop()
 	}
+	k.stopForward()
+	k.unfollowAll()
+	k.releaseCrashOutput()
+	k.readerCache.close()
+	lockErr := k.releaseLockFile()
+	k.closed = true
 	// Close the opening file descriptor
-	return k.currentFile.Close()
+	return errors.Join(k.closeCurrentFile(), lockErr)
 }
 
 // Rotate to a new file immediately without waiting for the rotation conditions to be met.
+// The writes accepted before this call land in the rotated file, and the writes accepted afterward in the new one,
+// even with [WithDoubleBuffering].
+// This fails with an error wrapping [ErrPaused] while the Keeper is paused, see [Keeper.Pause].
 func (k *Keeper) Rotate() error {
+	_, err := k.rotateBy(RotationManual)
+	return err
+}
+
+// Rotate to a new file immediately like [Keeper.Rotate], returning the path of the archive it produced,
+// which is the path of the compressed archive if the archive is compressed,
+// so that callers rotating programmatically, such as before an upload, do not have to look for the new file.
+// The archive may already be gone if the retention removed it right away, such as with a total size under its size.
+//
+// Example usage:
+//
+//	archivePath, err := keeper.RotateNow()
+//	if err != nil {
+//		return err
+//	}
+//	err = upload(archivePath)
+func (k *Keeper) RotateNow() (archivePath string, err error) {
+	return k.rotateBy(RotationManual)
+}
+
+// Rotate to a new file immediately for the given reason, see [Keeper.RotateNow].
+func (k *Keeper) rotateBy(reason RotationReason) (string, error) {
+	return k.rotateByWith(reason, RotateOpts{})
+}
+
+// Rotate to a new file immediately for the given reason with the overrides of the rotation.
+func (k *Keeper) rotateByWith(reason RotationReason, opts RotateOpts) (string, error) {
+	k.waitAsync()
+	// Queue the rotation behind the buffered messages
+	if s := k.segments.Load(); s != nil {
+		done := make(chan segmentResult, 1)
+		if s.appendRotation(reason, opts, done) {
+			result := <-done
+			return result.archivePath, result.err
+		}
+	}
+
 	k.mu.Lock()
 	defer k.mu.Unlock()
-	return k.rotate()
+	if err := k.rotateLocked(reason, opts); err != nil {
+		return "", err
+	}
+	return k.lastArchivePath, nil
+}
+
+// Rotate to a new file for the given reason unless the Keeper is paused, the lock of the Keeper must be held.
+func (k *Keeper) rotateLocked(reason RotationReason, opts RotateOpts) error {
+	if k.paused {
+		return fmt.Errorf("failed to rotate, caused by %w", ErrPaused)
+	}
+	return k.rotateWith(reason, opts)
+}
+
+// Close and reopen the current log file at its configured path without archiving it.
+// This is the operation expected by external rotation managers such as logrotate,
+// which move the current log file away and then ask the process to reopen its logs.
+// Writes after this call go to a new file if the old one was moved, or keep appending to it otherwise.
+// See [WithReopenSignal] to reopen on a signal such as SIGHUP.
+func (k *Keeper) Reopen() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.closed {
+		return fmt.Errorf("failed to reopen current log file, caused by %w", os.ErrClosed)
+	}
+	if err := k.closeCurrentFile(); err != nil {
+		return fmt.Errorf("failed to close current log file, caused by %w", err)
+	}
+	if err := k.openCurrentFile(); err != nil {
+		return err
+	}
+	k.resetIdleTimer()
+	return nil
+}
+
+// Switch the Keeper to a new folder without interrupting writers.
+// The current log file is moved into the new folder, appending to the log file already there if any,
+// and all subsequent writes go to the new location.
+//
+// If migrate is true, the archives managed by the Keeper are moved into the new folder as well.
+// If migrate is false, the archives are left behind and no longer managed by the Keeper,
+// instead the Keeper will manage any archive that it finds in the new folder.
+//
+// Any additional archive folders configured by [WithFolders] are dropped,
+// after this call the Keeper stores both the current log and archives in the new folder.
+//
+// Moving files across devices falls back to copying them, which may take a while for large archives.
+func (k *Keeper) SetFolder(folder string, migrate bool) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if len(folder) == 0 {
+		return fmt.Errorf("failed to set folder, folder must not be empty")
+	}
+	folder = normalizeFolder(folder)
+	stat, err := k.fsys.Stat(folder)
+	if err != nil {
+		return fmt.Errorf("failed to set folder, caused by %w", err)
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("failed to set folder, %q is not a directory", folder)
+	}
+
+	oldFolder, oldFolders := k.folder, k.folders
+	oldArchiveFolders := k.getArchiveFolders()
+	oldPath := k.getCurrentFilePath()
+	if err := k.closeCurrentFile(); err != nil {
+		return fmt.Errorf("failed to close current log file, caused by %w", err)
+	}
+
+	k.folder = folder
+	if err := appendFile(k.fsys, oldPath, k.getCurrentFilePath()); err != nil {
+		// Keep writing to the old folder if the current log can not be moved
+		k.folder = oldFolder
+		k.folders = oldFolders
+		if file, openErr := k.getCurrentFile(); openErr == nil {
+			k.currentFile = file
+		}
+		return fmt.Errorf("failed to move current log file, caused by %w", err)
+	}
+
+	k.folders = nil
+	k.nextFolder = 0
+
+	file, err := k.getCurrentFile()
+	if err != nil {
+		return fmt.Errorf("failed to open current log file, caused by %w", err)
+	}
+	k.currentFile = file
+	if stat, err := file.Stat(); err == nil {
+		k.currentFileSize = int(stat.Size())
+	}
+	if err := k.scanCurrentLines(); err != nil {
+		return err
+	}
+
+	if !migrate {
+		archives, size, err := k.discoverArchives()
+		if err != nil {
+			return fmt.Errorf("failed to get archives in new folder, caused by %w", err)
+		}
+		k.archives = archives
+		k.archivesSize = size
+		return nil
+	}
+
+	// Archives that fail to move are still tracked at their old path
+	for _, archive := range k.archives.All() {
+		rel, err := getArchiveRelPath(oldArchiveFolders, archive.filePath)
+		if err != nil {
+			return fmt.Errorf("failed to migrate archive %q, caused by %w", archive.filePath, err)
+		}
+		newPath := filepath.Join(folder, rel)
+		if err := moveFile(k.fsys, archive.filePath, newPath); err != nil {
+			return fmt.Errorf("failed to migrate archive %q, caused by %w", archive.filePath, err)
+		}
+		archive.filePath = newPath
+	}
+	return nil
 }
 
 // Archive the current log file and create a new log file.
-func (k *Keeper) rotate() error {
+func (k *Keeper) rotate(reason RotationReason) error {
+	return k.rotateWith(reason, RotateOpts{})
+}
+
+// Archive the current log file with the overrides of the rotation and create a new log file.
+func (k *Keeper) rotateWith(reason RotationReason, opts RotateOpts) error {
+	if k.holdRotation(reason) {
+		return nil
+	}
+	if k.coalesced(reason) {
+		return nil
+	}
+	if k.dateextTaken(reason, opts) {
+		return nil
+	}
+	if k.registry != nil {
+		defer k.registry.acquireRotation()()
+	}
+
 	// Close and rename the old file
-	if err := k.currentFile.Close(); err != nil {
+	if err := k.closeCurrentFile(); err != nil {
 		return fmt.Errorf("failed to rotate log file, caused by %w", err)
 	}
+	if k.skipEmptyRotation && k.isEmptyRotation() {
+		return k.skipRotation()
+	}
 
-	archiveName, err := k.newArchiveName()
+	archiveName, err := k.newArchiveName(reason)
 	if err != nil {
 		return fmt.Errorf("failed to get new archive name, caused by %w", err)
 	}
+	if len(opts.Label) > 0 {
+		archiveName += "." + opts.Label
+	}
 
-	if err := os.Rename(k.getCurrentFilePath(), archiveName); err != nil {
+	if err := k.createArchiveDir(archiveName); err != nil {
+		return fmt.Errorf("failed to rotate log file, caused by %w", err)
+	}
+	// A compressed current log file is archived as it is
+	compress := k.compressor != nil && !opts.NoCompression && !k.compressedCurrent
+	if k.compressedCurrent {
+		archiveName = k.compressedArchivePath(archiveName)
+	}
+	if err := moveFile(k.fsys, k.getCurrentFilePath(), archiveName); err != nil {
 		return fmt.Errorf("failed to rotate log file, caused by %w", err)
 	}
 
 	// Compress if set
-	if k.compressorContructor != nil {
-		if err := k.compress(archiveName); err != nil {
-			return fmt.Errorf("failed to compressed rotated log")
+	var compressed compression
+	if compress {
+		compressedName := k.compressedArchivePath(archiveName)
+		if compressed, err = k.compress(archiveName, compressedName); err != nil {
+			return fmt.Errorf("failed to compress rotated log %q, caused by %w", archiveName, err)
 		}
-		archiveName += k.compressionExt
+		archiveName = compressedName
+	}
+	// The archive is already rotated, it is only left writable
+	if err := k.sealArchive(archiveName); err != nil {
+		k.handleError(err)
+	}
+	if err := k.writeChecksum(archiveName); err != nil {
+		k.handleError(err)
 	}
 
-	archiveInfo, err := getFileInfo(archiveName)
+	archiveInfo, err := getFileInfo(k.fsys, archiveName)
 	if err != nil {
-		return fmt.Errorf("failed to compressed stat")
+		return fmt.Errorf("failed to stat compressed archive, caused by %w", err)
 	}
+	archiveInfo.records = k.currentRecords
+	originalSize := -1
+	if compress {
+		k.countCompression(archiveInfo, compressed)
+		originalSize = compressed.originalSize
+	}
+	k.appendManifest(archiveInfo, reason, originalSize)
 	k.archivesSize += archiveInfo.size
 	k.archives.Append(archiveInfo)
+	k.notifyRotate(archiveInfo.filePath)
+	k.retryPendingUploads()
+	k.queueUpload(archiveInfo.filePath)
+	k.stats.Rotations++
+	k.lastRotation = k.now()
+	k.lastRotationReason = reason
+	k.lastArchivePath = archiveInfo.filePath
+	k.archiveSeq++
+	k.rotateAt = time.Time{}
 
-	// Remove oldest archive
-	for k.shouldDeleteOldest() {
-		oldest, err := k.archives.Dequeue()
-		if err != nil {
-			return fmt.Errorf("failed to get oldest archive, caused by %w", err)
-		}
-		if err := os.Remove(oldest.filePath); err != nil {
-			return fmt.Errorf("failed to remove oldest archive with path %q, caused by %w", oldest.filePath, err)
-		}
-		k.archivesSize -= oldest.size
+	// Create a new file, whose name may depend on the time
+	if err := k.applyCurrentNameLayout(); err != nil {
+		return err
 	}
-
-	// Create a new file
 	file, err := k.getCurrentFile()
 	if err != nil {
 		return err
 	}
 	k.currentFile = file
 	k.currentFileSize = 0
+	k.currentRecords = 0
+	k.currentLines = 0
+	k.currentFirstWrite = time.Time{}
+	k.generation++
+	k.writeFileHeader()
+	k.resetIdleTimer()
+	k.followCrashOutput()
+	k.scheduleCompaction()
 
+	// Remove the oldest archives, the rotation itself succeeded even if some can not be removed
+	if opts.NoRetention {
+		return nil
+	}
+	if err := k.prune(); err != nil {
+		k.handleError(err)
+	}
+	if k.group != nil {
+		k.group.schedulePrune()
+	}
 	return nil
 }
 
-func (k *Keeper) compress(name string) error {
-	f, err := os.Open(name)
+// Report an error that can not be returned to the caller, see [WithErrorHandler].
+func (k *Keeper) handleError(err error) {
+	if k.errorHandler != nil {
+		k.errorHandler(err)
+	}
+}
+
+// The outcome of the compression of an archive, see [Stats.CompressedArchives].
+type compression struct {
+	originalSize int
+	duration     time.Duration
+}
+
+// The buffers copying the archives into their compressor, reused across rotations.
+var copyBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 32*Kb)
+		return &buf
+	},
+}
+
+func (k *Keeper) compress(name, compressedName string) (compression, error) {
+	var result compression
+	start := time.Now()
+	f, err := k.fsys.Open(name)
 	if err != nil {
-		return fmt.Errorf("failed to open file, caused by %w", err)
+		return result, fmt.Errorf("failed to open file, caused by %w", err)
 	}
 	defer f.Close()
 
-	cf, err := os.OpenFile(name+k.compressionExt, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	// Truncate what an interrupted compression may have left behind
+	cf, err := k.fsys.OpenFile(compressedName, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, k.fileMode)
 	if err != nil {
-		return fmt.Errorf("failed to create compressed file, caused by %w", err)
+		return result, fmt.Errorf("failed to create compressed file, caused by %w", err)
 	}
 	defer cf.Close()
 
-	compressor, err := k.compressorContructor(cf)
+	compressor, err := k.compressor.NewWriter(cf)
 	if err != nil {
-		return fmt.Errorf("failed to create compress algorithm, caused by %w", err)
+		return result, fmt.Errorf("failed to create compress algorithm, caused by %w", err)
 	}
-	defer compressor.Close()
 
-	_, err = f.WriteTo(compressor)
+	// Copy through a pooled buffer, hiding the WriterTo of the file which would allocate its own
+	buf := copyBuffers.Get().(*[]byte)
+	// Interrupted by a Shutdown running out of time, the original stays behind for resumeCompression
+	written, err := io.CopyBuffer(compressor, abortableReader{k.shutdown.context(), f}, *buf)
+	copyBuffers.Put(buf)
 	if err != nil {
-		return fmt.Errorf("failed to write to compressed file, caused by %w", err)
+		compressor.Close()
+		return result, fmt.Errorf("failed to write to compressed file, caused by %w", err)
+	}
+	// Flush the compressor so that the compressed size is final
+	if err := compressor.Close(); err != nil {
+		return result, fmt.Errorf("failed to close compressed file, caused by %w", err)
 	}
 
-	if err := os.Remove(name); err != nil {
-		return fmt.Errorf("failed to delete %s, caused by %w", name, err)
+	if err := k.fsys.Remove(name); err != nil {
+		return result, fmt.Errorf("failed to delete %s, caused by %w", name, err)
 	}
-	return nil
+	result.originalSize = int(written)
+	result.duration = time.Since(start)
+	return result, nil
+}
+
+func (k *Keeper) newArchiveName(reason RotationReason) (string, error) {
+	return k.newArchiveNameAt(k.archiveNameTime(), reason)
+}
+
+// Get the name of a new archive rotated at the given time for the given reason.
+func (k *Keeper) newArchiveNameAt(t time.Time, reason RotationReason) (string, error) {
+	name, err := k.archivePathAt(t, reason)
+	if err != nil {
+		return "", err
+	}
+	k.nextFolder = (k.nextFolder + 1) % len(k.getArchiveFolders())
+	return name, nil
 }
 
-func (k *Keeper) newArchiveName() (string, error) {
+// Render the name of an archive rotated at the given time for the given reason, relative to its archive folder.
+func (k *Keeper) renderArchiveName(t time.Time, reason RotationReason) (string, error) {
 	var buff bytes.Buffer
-	err := k.archiveNameLayout.Execute(
-		&buff,
-		map[string]any{
-			"time":      now().Format(k.timeLayout),
-			"name":      k.name,
-			"extension": k.extension,
-		},
-	)
+	err := k.archiveNameLayout.Execute(&buff, dateVars(k.archiveNameData(t.Format(k.timeLayout), string(reason), ""), t))
 	if err != nil {
 		return "", fmt.Errorf("failed to execute template, caused by %w", err)
 	}
-	return path.Join(k.folder, buff.String()), nil
+	name := buff.String()
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("failed to render archive name, %q is not a path within the archive folder", name)
+	}
+	return name, nil
+}
+
+// Get the data of the archive name layout, see [WithArchiveNameLayout].
+func (k *Keeper) archiveNameData(time, reason, compressionExt string) map[string]any {
+	data := make(map[string]any, len(k.templateData)+8)
+	for key, value := range k.templateData {
+		data[key] = value
+	}
+	data["time"] = time
+	data["name"] = k.name
+	data["extension"] = k.extension
+	data["reason"] = reason
+	data["compressionExt"] = compressionExt
+	// The date fields stand for the same placeholder as the time, unless rendered for an actual time
+	withDateVars(data, time)
+	return k.nameVars(data)
 }
 
 func (k *Keeper) getArchiveGlobPattern() (string, error) {
+	pattern, err := k.renderArchiveGlobPattern()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(k.folder, pattern), nil
+}
+
+// Get the archive glob patterns of every archive folders.
+func (k *Keeper) getArchiveGlobPatterns() ([]string, error) {
+	pattern, err := k.renderArchiveGlobPattern()
+	if err != nil {
+		return nil, err
+	}
+	folders := k.getArchiveFolders()
+	patterns := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		patterns = append(patterns, filepath.Join(folder, pattern))
+	}
+	return patterns, nil
+}
+
+// Render the archive glob pattern relative to the archive folders.
+func (k *Keeper) renderArchiveGlobPattern() (string, error) {
 	var buff bytes.Buffer
-	err := k.archiveNameLayout.Execute(
-		&buff,
-		map[string]any{
-			"time":      "*",
-			"name":      k.name,
-			"extension": k.extension,
-		},
-	)
+	err := k.archiveNameLayout.Execute(&buff, withNameVars(k.archiveNameData("*", "*", "*"), "*", "*", "*"))
 	if err != nil {
 		return "", fmt.Errorf("failed to execute template, caused by %w", err)
 	}
@@ -317,14 +1392,12 @@ func (k *Keeper) getArchiveGlobPattern() (string, error) {
 		// Append a star at the end to also get files that are compressed.
 		pattern += "*"
 	}
-	return path.Join(k.folder, pattern), nil
+	return pattern, nil
 }
 
 func (k *Keeper) shouldRotate(nextMsg []byte) bool {
+	if k.rotatesForHardMax(len(nextMsg)) {
+		return true
+	}
 	return k.maxSize > 0 && k.currentFileSize+len(nextMsg) > k.maxSize
 }
-
-func (k *Keeper) shouldDeleteOldest() bool {
-	return (k.totalSize > 0 && k.totalSize < k.archivesSize) ||
-		(k.maxFiles > 0 && k.maxFiles < k.archives.Length())
-}
@@ -1,6 +1,7 @@
 package lorekeeper
 
 import (
+	"bytes"
 	"log"
 	"os"
 	"path/filepath"
@@ -20,7 +21,7 @@ func Test(t *testing.T) {
 		// Set the time layout of archived logs.
 		WithTimeLayout("20060102150405.000"),
 		// Specify the folder where the log files will be stored.
-		WithFolder("."),
+		WithFolder(t.TempDir()),
 		// Each log file hold a maximum of 50 Kibibyte before being rotated.
 		WithMaxSize(50*Kb),
 		// Set the name layout of archived logs.
@@ -67,7 +68,7 @@ func Test(t *testing.T) {
 func BenchmarkKeeperWrite(b *testing.B) {
 	const lorem = "Culpa sequi esse et et expedita aut qui quia. Error minus modi sunt beatae asperiores qui rem. Quia minima cumque laudantium sed rerum. Sunt delectus nesciunt dolor veniam soluta provident porro deserunt. Ullam illo beatae et quos unde maxime repellendus. Beatae itaque totam eum itaque velit et. Sit molestias dolore deserunt rerum amet. Molestiae rem provident minima autem nulla numquam. Illum voluptas ea nam suscipit. Corporis molestias necessitatibus dolore facilis. Nostrum cum nemo vero. Enim dolorem esse ad. Sed numquam odio eum ex. Praesentium incidunt quod perferendis sit est omnis sapiente. Sed rem itaque laboriosam minus eos. Sed fugiat dolores ut. Nam veniam nihil voluptatem accusamus molestias ducimus. Minima aut consequuntur dolores facere inventore libero tempore omnis. Suscipit et aut nostrum. Porro sapiente dignissimos nisi error. Et nulla vel molestiae veniam molestiae eum. Est similique sapiente aperiam voluptate cum occaecati et laboriosam. Praesentium cupiditate et laboriosam aperiam neque ut ut. Provident blanditiis autem pariatur autem animi et sint dicta."
 	k, _ := New(
-		WithFolder("."),
+		WithFolder(b.TempDir()),
 		WithMaxSize(100*KB),
 		WithMaxFiles(1),
 		WithGzip(),
@@ -86,12 +87,13 @@ func BenchmarkKeeperWrite(b *testing.B) {
 }
 
 func TestKeeperNewArchiveName(t *testing.T) {
-	now = func() time.Time {
+	now := func() time.Time {
 		t, _ := time.Parse(time.RFC3339, "2006-01-02T15:04:05Z")
 		return t
 	}
 
 	defaultOpts := []Opt{
+		WithNowFunc(now),
 		WithTimeLayout("20060102"),
 		WithName("testcase 1"),
 		WithExtension(".log"),
@@ -143,7 +145,7 @@ func TestKeeperNewArchiveName(t *testing.T) {
 			if err != nil {
 				t.Fatalf("could not construct receiver type: %v", err)
 			}
-			got, gotErr := k.newArchiveName()
+			got, gotErr := k.newArchiveName(RotationManual)
 			if gotErr != nil {
 				if !tt.wantErr {
 					t.Errorf("newArchiveName() failed: %v", gotErr)
@@ -271,7 +273,7 @@ func TestKeeperClose(t *testing.T) {
 	k, err := New(
 		WithName("Test-Close"),
 	)
-	if _, ok := registry.Load(k.name); !ok {
+	if _, ok := registry.Get(k.name); !ok {
 		t.Error("expected the keeper to be registered")
 	}
 
@@ -284,7 +286,7 @@ func TestKeeperClose(t *testing.T) {
 	if _, err := k.Write([]byte{}); err == nil {
 		t.Errorf("expected error since Keeper is close got %v", err)
 	}
-	if val, ok := registry.Load(k.name); ok {
+	if val, ok := registry.Get(k.name); ok {
 		t.Errorf("expected the keeper to be gone from the registry but got %v", val)
 	}
 }
@@ -302,7 +304,7 @@ func TestNew(t *testing.T) {
 		{
 			name: "fully configured",
 			opts: []Opt{
-				WithFolder("."),
+				WithFolder(t.TempDir()),
 				WithName("fully configured"),
 				WithExtension(".log"),
 				WithMaxSize(10),
@@ -326,6 +328,13 @@ func TestNew(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "no folders",
+			opts: []Opt{
+				WithFolders(),
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid archive name template",
 			opts: []Opt{
@@ -350,16 +359,365 @@ func TestNew(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, gotErr := New(tt.opts...)
+			k, gotErr := New(tt.opts...)
 			if gotErr != nil {
 				if !tt.wantErr {
 					t.Errorf("New() failed: %v", gotErr)
 				}
 				return
 			}
+			// Closed so that the Keeper does not stay in the registry for the other tests
+			t.Cleanup(func() { _ = k.Close() })
 			if tt.wantErr {
 				t.Fatal("New() succeeded unexpectedly")
 			}
 		})
 	}
 }
+
+func TestKeeperSetFolder(t *testing.T) {
+	oldFolder, newFolder := t.TempDir(), t.TempDir()
+	k, err := New(
+		WithName("Test-SetFolder"),
+		WithFolder(oldFolder),
+		WithMaxSize(10),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// Produce one archive and some content in the current log
+	for _, msg := range []string{"0123456789", "abc"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if k.archives.Length() != 1 {
+		t.Fatalf("expected 1 archive got %d", k.archives.Length())
+	}
+
+	if err := k.SetFolder(newFolder, true); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("def")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(newFolder, "test-setfolder.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "abcdef" {
+		t.Errorf("expected current log to be moved, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(oldFolder, "test-setfolder.log")); err == nil {
+		t.Errorf("expected current log to be gone from the old folder")
+	}
+	for _, archive := range k.archives.All() {
+		if filepath.Dir(archive.filePath) != newFolder {
+			t.Errorf("expected archive to be migrated, got %s", archive.filePath)
+		}
+		if _, err := os.Stat(archive.filePath); err != nil {
+			t.Errorf("expected archive to exist, got %v", err)
+		}
+	}
+
+	if err := k.SetFolder(filepath.Join(newFolder, "not-existed"), false); err == nil {
+		t.Errorf("expected error when the folder does not exist")
+	}
+}
+
+func TestKeeperSetFolderSameFolder(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithName("Test-SetFolder-Same"),
+		WithFolder(folder),
+		WithMaxSize(10),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{"0123456789", "abc"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- k.SetFolder(folder+string(filepath.Separator)+".", true) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected setting the same folder to return")
+	}
+	if _, err := k.Write([]byte("def")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(folder, "test-setfolder-same.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "abcdef" {
+		t.Errorf("expected the current log to be kept as is, got %q", content)
+	}
+	for _, archive := range k.archives.All() {
+		if _, err := os.Stat(archive.filePath); err != nil {
+			t.Errorf("expected archive to exist, got %v", err)
+		}
+	}
+}
+
+func TestKeeperWithFolders(t *testing.T) {
+	folders := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+	opts := []Opt{
+		WithName("Test-WithFolders"),
+		WithFolders(folders...),
+		WithMaxSize(10),
+		WithMaxFiles(4),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for i := 0; i < 6; i++ {
+		if _, err := k.Write([]byte("0123456789")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(folders[0], "test-withfolders.log")); err != nil {
+		t.Errorf("expected current log in the first folder, got %v", err)
+	}
+	if k.archives.Length() != 4 {
+		t.Errorf("expected 4 archives got %d", k.archives.Length())
+	}
+
+	counts := make(map[string]int)
+	for _, archive := range k.archives.All() {
+		counts[filepath.Dir(archive.filePath)]++
+	}
+	for _, folder := range folders {
+		if counts[folder] == 0 {
+			t.Errorf("expected archives to be striped into %s", folder)
+		}
+	}
+
+	// Scanning on creation must find archives in all folders
+	archives, _, err := k.getArchives()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if archives.Length() != 4 {
+		t.Errorf("expected 4 archives after scanning got %d", archives.Length())
+	}
+}
+
+func TestKeeperWriteChunked(t *testing.T) {
+	k, err := New(
+		WithName("Test-Write-Chunked"),
+		WithFolder(t.TempDir()),
+		WithMaxSize(10),
+		WithArchiveNameLayout("{{ .name }}-{{ .time }}{{ .extension }}"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("abc")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	msg := []byte("0123456789abcdefghijklmnopqrstu")
+	n, err := k.Write(msg)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if n != len(msg) {
+		t.Errorf("expected %d bytes written got %d", len(msg), n)
+	}
+
+	var content []byte
+	for _, archive := range k.Archives() {
+		if archive.Size > 10 {
+			t.Errorf("expected archive to not exceed the max size got %d", archive.Size)
+		}
+		data, err := os.ReadFile(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		content = append(content, data...)
+	}
+	current, err := os.ReadFile(k.getCurrentFilePath())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content = append(content, current...)
+	if string(content) != "abc"+string(msg) {
+		t.Errorf("expected all bytes to be kept in order got %q", content)
+	}
+	if k.currentFileSize != len(current) {
+		t.Errorf("expected current size of %d got %d", len(current), k.currentFileSize)
+	}
+}
+
+func TestKeeperWithCloseAfterIdle(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-close-after-idle"),
+		WithCloseAfterIdle(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	isOpen := func() bool {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		return k.currentFile != nil
+	}
+
+	if _, err := k.Write([]byte("abc")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for isOpen() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if isOpen() {
+		t.Fatalf("expected the current log file to be closed after being idle")
+	}
+
+	// The next write reopens the file in append mode
+	if _, err := k.Write([]byte("def")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(folder, "test-close-after-idle.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "abcdef" {
+		t.Errorf("expected %q got %q", "abcdef", content)
+	}
+	if k.currentFileSize != 6 {
+		t.Errorf("expected current size 6 got %d", k.currentFileSize)
+	}
+}
+
+func TestKeeperReopen(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-reopen"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if _, err := k.Write([]byte("abc")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// Simulate an external rotation manager moving the current log away
+	current := filepath.Join(folder, "test-reopen.log")
+	moved := filepath.Join(folder, "test-reopen.log.1")
+	if err := os.Rename(current, moved); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Reopen(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("def")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	for path, want := range map[string]string{current: "def", moved: "abc"} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if string(content) != want {
+			t.Errorf("expected %s to contain %q got %q", path, want, content)
+		}
+	}
+	if k.archives.Length() != 0 {
+		t.Errorf("expected no archive got %d", k.archives.Length())
+	}
+
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Reopen(); err == nil {
+		t.Errorf("expected error since Keeper is closed")
+	}
+}
+
+func TestKeeperWithFallbackWriter(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	var fallback bytes.Buffer
+	var reported []error
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-fallback-writer"),
+		WithFallbackWriter(&fallback),
+		WithErrorHandler(func(err error) { reported = append(reported, err) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// Break the primary path
+	if err := os.RemoveAll(folder); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Reopen(); err == nil {
+		t.Fatalf("expected error since the folder is removed")
+	}
+	n, err := k.Write([]byte("abc"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 bytes written got %d", n)
+	}
+	if _, err := k.Write([]byte("\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if fallback.String() != "abc\n" {
+		t.Errorf("expected fallback to contain %q got %q", "abc\n", fallback.String())
+	}
+	// Only the start of the outage is reported
+	if len(reported) != 1 {
+		t.Errorf("expected 1 reported error got %v", reported)
+	}
+
+	// Heal the primary path
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("def")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(folder, "test-fallback-writer.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "def" {
+		t.Errorf("expected %q got %q", "def", content)
+	}
+	if fallback.String() != "abc\n" {
+		t.Errorf("expected fallback to be unchanged got %q", fallback.String())
+	}
+}
@@ -0,0 +1,185 @@
+// Package lorekeepertest provides helpers to test the logging setups built on Lorekeeper,
+// such as Keepers in temporary folders, a fake clock, forced rotations and assertions on the produced files.
+//
+// Example usage:
+//
+//	func TestAuditLog(t *testing.T) {
+//		clock := lorekeepertest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//		keeper := lorekeepertest.New(t, lorekeeper.WithClock(clock))
+//		fmt.Fprintln(keeper, "signed in")
+//		clock.Advance(time.Hour)
+//		lorekeepertest.Rotate(t, keeper)
+//		lorekeepertest.AssertArchives(t, keeper, 1)
+//		lorekeepertest.AssertContains(t, keeper, "signed in")
+//	}
+package lorekeepertest
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/trviph/lorekeeper"
+)
+
+// A Clock is a fake clock for [lorekeeper.WithClock], which only moves when told to.
+// Its timers are called when the clock is moved past them, in order and synchronously,
+// so that the rotations of [lorekeeper.WithCron] are done once [Clock.Advance] returns.
+// It is safe for concurrent use.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+// A timer of a [Clock].
+type timer struct {
+	clock *Clock
+	at    time.Time
+	f     func()
+}
+
+// Create a new [Clock] set at the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Get the time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Call f once the clock moved forward by d, see [lorekeeper.Clock].
+func (c *Clock) AfterFunc(d time.Duration, f func()) lorekeeper.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &timer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Move the clock forward by d, calling the timers that are due on the way.
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set the clock at the given time, calling the timers that are due on the way if it moves forward.
+func (c *Clock) Set(now time.Time) {
+	for {
+		c.mu.Lock()
+		due := -1
+		for i, t := range c.timers {
+			if !t.at.After(now) && (due < 0 || t.at.Before(c.timers[due].at)) {
+				due = i
+			}
+		}
+		if due < 0 {
+			c.now = now
+			c.mu.Unlock()
+			return
+		}
+		t := c.timers[due]
+		c.timers = append(c.timers[:due], c.timers[due+1:]...)
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+		c.mu.Unlock()
+		// Outside the lock, since the timer may read the clock or schedule a new timer
+		t.f()
+	}
+}
+
+// Remove the timer from its clock, reporting whether it was pending, the lock of the clock must be held.
+func (t *timer) remove() bool {
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.remove()
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	pending := t.remove()
+	t.at = t.clock.now.Add(d)
+	t.clock.timers = append(t.clock.timers, t)
+	return pending
+}
+
+// Create a new [lorekeeper.Keeper] in a temporary folder of the test, registered in a registry of its own,
+// so that tests running in parallel never share a Keeper. The Keeper is closed when the test ends.
+// It is named "test" unless opts contain [lorekeeper.WithName], which is applied on top of the defaults like any other option.
+func New(t testing.TB, opts ...lorekeeper.Opt) *lorekeeper.Keeper {
+	t.Helper()
+	defaults := []lorekeeper.Opt{
+		lorekeeper.WithFolder(t.TempDir()),
+		lorekeeper.WithName("test"),
+		lorekeeper.WithRegistry(lorekeeper.NewRegistry()),
+	}
+	k, err := lorekeeper.New(append(defaults, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create keeper, caused by %v", err)
+	}
+	t.Cleanup(func() {
+		_ = k.Close()
+	})
+	return k
+}
+
+// Rotate the Keeper, failing the test if the rotation fails.
+func Rotate(t testing.TB, k *lorekeeper.Keeper) {
+	t.Helper()
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("failed to rotate keeper %q, caused by %v", k.Name(), err)
+	}
+}
+
+// Get the content of all the log files of the Keeper, from the oldest archive to the current log file,
+// with the compressed archives decompressed, failing the test if a file can not be read.
+func Content(t testing.TB, k *lorekeeper.Keeper) []byte {
+	t.Helper()
+	var content bytes.Buffer
+	for record, err := range k.Between(time.Time{}, time.Unix(1<<62, 0)) {
+		if err != nil {
+			t.Fatalf("failed to read keeper %q, caused by %v", k.Name(), err)
+		}
+		content.Write(record)
+	}
+	return content.Bytes()
+}
+
+// Assert that the Keeper has the given number of archives.
+func AssertArchives(t testing.TB, k *lorekeeper.Keeper, want int) {
+	t.Helper()
+	if got := len(k.Archives()); got != want {
+		t.Errorf("expected %d archives of keeper %q got %d", want, k.Name(), got)
+	}
+}
+
+// Assert that the log files of the Keeper contain substr, see [Content].
+func AssertContains(t testing.TB, k *lorekeeper.Keeper, substr string) {
+	t.Helper()
+	if content := Content(t, k); !bytes.Contains(content, []byte(substr)) {
+		t.Errorf("expected the log files of keeper %q to contain %q got %q", k.Name(), substr, content)
+	}
+}
+
+// Assert that the log files of the Keeper contain exactly want, see [Content].
+func AssertContent(t testing.TB, k *lorekeeper.Keeper, want string) {
+	t.Helper()
+	if content := Content(t, k); string(content) != want {
+		t.Errorf("expected the log files of keeper %q to contain %q got %q", k.Name(), want, content)
+	}
+}
@@ -0,0 +1,107 @@
+package lorekeepertest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/trviph/lorekeeper"
+)
+
+func TestHelpers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	k := New(t, lorekeeper.WithNowFunc(clock.Now), lorekeeper.WithGzip())
+
+	fmt.Fprintln(k, "first")
+	clock.Advance(time.Hour)
+	Rotate(t, k)
+	fmt.Fprintln(k, "second")
+
+	if got := k.LastRotation(); !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the rotation at the fake time got %v", got)
+	}
+	AssertArchives(t, k, 1)
+	AssertContains(t, k, "first")
+	AssertContent(t, k, "first\nsecond\n")
+}
+
+func TestClockTimers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	k := New(t, lorekeeper.WithClock(clock), lorekeeper.WithCron("@hourly"), lorekeeper.WithBufferSize(lorekeeper.Kb), lorekeeper.WithFlushInterval(time.Second))
+
+	fmt.Fprintln(k, "first")
+	AssertArchives(t, k, 0)
+	clock.Advance(time.Second)
+	if got := k.Stats().CurrentFileSize; got != int64(len("first\n")) {
+		t.Errorf("expected the buffer to be flushed on the clock got %d bytes", got)
+	}
+
+	// Every boundary of the schedule rotates on the clock
+	clock.Advance(time.Hour)
+	fmt.Fprintln(k, "second")
+	clock.Advance(time.Hour)
+	AssertArchives(t, k, 2)
+	if got := k.LastRotation(); !got.Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("expected the rotation at the boundary got %v", got)
+	}
+	if got := k.NextRotation(); !got.Equal(start.Add(3 * time.Hour)) {
+		t.Errorf("expected the next rotation at 03:00 got %v", got)
+	}
+	AssertContent(t, k, "first\nsecond\n")
+}
+
+func TestClockStopReset(t *testing.T) {
+	clock := NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var calls []string
+	stopped := clock.AfterFunc(time.Minute, func() { calls = append(calls, "stopped") })
+	reset := clock.AfterFunc(time.Minute, func() { calls = append(calls, "reset") })
+	clock.AfterFunc(2*time.Minute, func() { calls = append(calls, "due") })
+	if !stopped.Stop() || stopped.Stop() {
+		t.Errorf("expected only the first stop to stop the timer")
+	}
+	if !reset.Reset(3 * time.Minute) {
+		t.Errorf("expected the timer to be pending")
+	}
+	clock.Advance(2 * time.Minute)
+	clock.Advance(time.Minute)
+	if got := fmt.Sprint(calls); got != "[due reset]" {
+		t.Errorf("expected the timers to be called in order got %s", got)
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	fsys := NewMemFS()
+	k := New(t,
+		lorekeeper.WithFS(fsys),
+		lorekeeper.WithFolder("/logs"),
+		lorekeeper.WithCreateFolder(0755),
+		lorekeeper.WithMaxSize(10),
+		lorekeeper.WithMaxFiles(2),
+		lorekeeper.WithGzip(),
+	)
+	for i := range 5 {
+		fmt.Fprintf(k, "message %d\n", i)
+	}
+
+	AssertArchives(t, k, 2)
+	AssertContent(t, k, "message 2\nmessage 3\nmessage 4\n")
+	entries, err := fsys.ReadDir("/logs")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if len(names) != 3 || names[2] != "test.log" {
+		t.Errorf("expected 2 archives and the current log file got %v", names)
+	}
+	if _, err := os.Stat("/logs/test.log"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected nothing written to the disk got %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package lorekeepertest
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trviph/lorekeeper"
+)
+
+// A MemFS is an in-memory [lorekeeper.FS], so that the rotations and the retention of a Keeper
+// can be tested without touching the disk, see [lorekeeper.WithFS].
+// Like on disk, the folders must be created before their files, such as with [lorekeeper.WithCreateFolder],
+// and the open files keep their content when renamed or removed.
+// The modification times come from the clock of the process, and always move forward,
+// so that the archives keep the order in which they were written. It is safe for concurrent use.
+//
+// Example usage:
+//
+//	fsys := lorekeepertest.NewMemFS()
+//	keeper := lorekeepertest.New(t, lorekeeper.WithFS(fsys), lorekeeper.WithCreateFolder(0755))
+type MemFS struct {
+	mu    sync.Mutex
+	nodes map[string]*memNode
+	// The last modification time given out
+	last time.Time
+}
+
+// Make sure that MemFS implements the [lorekeeper.FS] interface.
+var _ lorekeeper.FS = (*MemFS)(nil)
+
+// A file or a folder, shared by the open files so that they survive renames and removals.
+type memNode struct {
+	data    []byte
+	mode    fs.FileMode
+	modtime time.Time
+}
+
+// Create an empty [MemFS].
+func NewMemFS() *MemFS {
+	return &MemFS{nodes: make(map[string]*memNode)}
+}
+
+// Get the next modification time, the lock must be held.
+func (m *MemFS) tick() time.Time {
+	now := time.Now()
+	if !now.After(m.last) {
+		now = m.last.Add(time.Nanosecond)
+	}
+	m.last = now
+	return now
+}
+
+// Get the node at the cleaned name, the roots always exist, the lock must be held.
+func (m *MemFS) lookup(name string) (*memNode, bool) {
+	if filepath.Dir(name) == name || name == "." {
+		return &memNode{mode: fs.ModeDir | 0755}, true
+	}
+	node, ok := m.nodes[name]
+	return node, ok
+}
+
+// Check that the folder of the cleaned name exists, the lock must be held.
+func (m *MemFS) parentExists(name string) bool {
+	parent, ok := m.lookup(filepath.Dir(name))
+	return ok && parent.mode.IsDir()
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	return m.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (m *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (lorekeeper.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	node, ok := m.lookup(name)
+	switch {
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case !ok && !m.parentExists(name):
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case !ok:
+		node = &memNode{mode: perm.Perm(), modtime: m.tick()}
+		m.nodes[name] = node
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case node.mode.IsDir() && writable:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	case writable && node.mode&0200 == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	if writable && flag&os.O_TRUNC != 0 {
+		node.data = nil
+		node.modtime = m.tick()
+	}
+	return &memFile{fs: m, name: name, node: node, flag: flag}, nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	node, ok := m.lookup(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return node.info(filepath.Base(name)), nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	node, ok := m.lookup(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	if !node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	var entries []fs.DirEntry
+	for path, child := range m.nodes {
+		if filepath.Dir(path) == name && path != name {
+			entries = append(entries, fs.FileInfoToDirEntry(child.info(filepath.Base(path))))
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	node, ok := m.nodes[oldpath]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if !m.parentExists(newpath) {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if existing, ok := m.nodes[newpath]; ok && existing.mode.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrExist}
+	}
+	delete(m.nodes, oldpath)
+	m.nodes[newpath] = node
+	// The content of a folder moves along with it
+	prefix := oldpath + string(filepath.Separator)
+	for path, child := range m.nodes {
+		if strings.HasPrefix(path, prefix) {
+			delete(m.nodes, path)
+			m.nodes[newpath+string(filepath.Separator)+strings.TrimPrefix(path, prefix)] = child
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if node.mode.IsDir() {
+		prefix := name + string(filepath.Separator)
+		for path := range m.nodes {
+			if strings.HasPrefix(path, prefix) {
+				return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+			}
+		}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+func (m *MemFS) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		node, ok := m.lookup(dir)
+		if ok && !node.mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: errors.New("not a directory")}
+		}
+		if ok {
+			break
+		}
+		missing = append(missing, dir)
+	}
+	for _, dir := range missing {
+		m.nodes[dir] = &memNode{mode: fs.ModeDir | perm.Perm(), modtime: m.tick()}
+	}
+	return nil
+}
+
+func (m *MemFS) Chmod(name string, mode fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return &fs.PathError{Op: "chmod", Path: name, Err: fs.ErrNotExist}
+	}
+	node.mode = node.mode.Type() | mode.Perm()
+	return nil
+}
+
+func (m *MemFS) Chtimes(name string, atime, mtime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return &fs.PathError{Op: "chtimes", Path: name, Err: fs.ErrNotExist}
+	}
+	node.modtime = mtime
+	return nil
+}
+
+func (n *memNode) info(name string) fs.FileInfo {
+	return memFileInfo{name: name, size: int64(len(n.data)), mode: n.mode, modtime: n.modtime}
+}
+
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modtime time.Time
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i memFileInfo) ModTime() time.Time { return i.modtime }
+func (i memFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memFileInfo) Sys() any           { return nil }
+
+// An open file of a [MemFS].
+type memFile struct {
+	fs     *MemFS
+	name   string
+	node   *memNode
+	flag   int
+	offset int64
+	closed bool
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if f.closed {
+		return nil, &fs.PathError{Op: "stat", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.node.info(filepath.Base(f.name)), nil
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	n, err := f.readAt(p, f.offset, "read")
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *memFile) ReadAt(p []byte, offset int64) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	n, err := f.readAt(p, offset, "readat")
+	if err == nil && n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Read from the offset, the lock of the file system must be held.
+func (f *memFile) readAt(p []byte, offset int64, op string) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+	if f.flag&os.O_WRONLY != 0 || f.node.mode.IsDir() {
+		return 0, &fs.PathError{Op: op, Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset >= int64(len(f.node.data)) {
+		return 0, io.EOF
+	}
+	return copy(p, f.node.data[offset:]), nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if f.closed {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrClosed}
+	}
+	if f.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if f.flag&os.O_APPEND != 0 {
+		f.offset = int64(len(f.node.data))
+	}
+	if end := f.offset + int64(len(p)); end > int64(len(f.node.data)) {
+		f.node.data = append(f.node.data, make([]byte, end-int64(len(f.node.data)))...)
+	}
+	copy(f.node.data[f.offset:], p)
+	f.offset += int64(len(p))
+	f.node.modtime = f.fs.tick()
+	return len(p), nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += int64(len(f.node.data))
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Sync does nothing, the content is already in memory.
+func (f *memFile) Sync() error {
+	return nil
+}
+
+func (f *memFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
@@ -0,0 +1,283 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/trviph/collection"
+)
+
+// A ManifestEntry records a rotation in the manifest of [WithManifest].
+type ManifestEntry struct {
+	// The path of the archive.
+	Path string `json:"path"`
+	// Why the archive was rotated.
+	Reason RotationReason `json:"reason"`
+	// When the archive was rotated.
+	RotatedAt time.Time `json:"rotatedAt"`
+	// When the first and the last message of the archive were written by the Keeper, zero if it wrote none,
+	// the first is zero as well if unknown, when the log file already had content when the Keeper started.
+	FirstWrite time.Time `json:"firstWrite"`
+	LastWrite  time.Time `json:"lastWrite"`
+	// Number of records in the archive, -1 if unknown, see [ArchiveInfo.Records].
+	Records int `json:"records"`
+	// Size of the archive before compression in bytes.
+	Size int `json:"size"`
+	// Size of the compressed archive in bytes, zero if it is not compressed.
+	CompressedSize int `json:"compressedSize,omitempty"`
+	// The hex of the SHA-256 digest of the archive as stored, empty if it could not be computed.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Append a JSON line to a manifest in the folder of the current log file for every rotation,
+// recording the archive, when its first and last messages were written, its sizes and its SHA-256 digest,
+// so that downstream tools locate the archives covering a time window without opening every one of them.
+// The manifest is named after the Keeper, such as ".app.manifest", see [Keeper.Manifest] and [ReadManifest] to read it.
+// It is a history, the entries of the archives removed since are kept.
+// The write errors go to the handler of [WithErrorHandler]. Is disabled by default.
+//
+// Example usage:
+//
+//	entries, err := keeper.Manifest()
+//	for _, entry := range entries {
+//		if entry.LastWrite.After(from) && entry.FirstWrite.Before(to) {
+//			...
+//		}
+//	}
+func WithManifest() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.manifest = true
+		return k, nil
+	}
+}
+
+// Do not keep a manifest, this is the default.
+func NoManifest() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.manifest = false
+		return k, nil
+	}
+}
+
+// Discover the archives from the manifest of [WithManifest] when the Keeper starts,
+// instead of listing the archive folders and matching every file name against the archive name layout.
+// Each archive recorded in the manifest is stat-ed, the ones removed since are left out, and the records counted
+// at rotation are kept as long as the archive still has the size recorded, see [ArchiveInfo.Records].
+// The folders are listed as usual when the manifest does not exist yet or can not be read.
+//
+// The manifest only records the rotations of the Keeper, so the archives it did not rotate,
+// such as the ones matched by [WithAdoptPattern], rotated before the manifest was enabled, or renamed by [Keeper.Migrate],
+// are only found when verify is true. Then the folders are listed as well, and every archive missing from the manifest
+// is reported to the handler of [WithErrorHandler] and managed anyway.
+// It requires [WithManifest]. Is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithManifest(),
+//		lorekeeper.WithManifestDiscovery(false),
+//	)
+func WithManifestDiscovery(verify bool) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.manifestDiscovery = true
+		k.manifestVerify = verify
+		return k, nil
+	}
+}
+
+// Discover the archives by listing the archive folders, this is the default.
+func NoManifestDiscovery() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.manifestDiscovery = false
+		k.manifestVerify = false
+		return k, nil
+	}
+}
+
+// Check that the manifest used by [WithManifestDiscovery] is kept.
+func (k *Keeper) applyManifestDiscovery() error {
+	if k.manifestDiscovery && !k.manifest {
+		return fmt.Errorf("failed to set manifest discovery, it requires WithManifest")
+	}
+	return nil
+}
+
+// Read the entries of the manifest of the Keeper, from oldest to newest, see [WithManifest].
+// The manifest may have entries even if it is disabled, from when it was enabled.
+func (k *Keeper) Manifest() ([]ManifestEntry, error) {
+	k.mu.Lock()
+	path, fsys := k.manifestPath(), k.fsys
+	k.mu.Unlock()
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest, caused by %w", err)
+	}
+	defer f.Close()
+	return ReadManifest(f)
+}
+
+// Read the entries of a manifest written with [WithManifest], from oldest to newest.
+func ReadManifest(r io.Reader) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*Kb)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry ManifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, fmt.Errorf("failed to read manifest entry at line %d, caused by %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read manifest, caused by %w", err)
+	}
+	return entries, nil
+}
+
+func (k *Keeper) manifestPath() string {
+	return filepath.Join(k.folder, "."+k.name+".manifest")
+}
+
+// Append the rotation of the archive to the manifest, the lock of the Keeper must be held.
+func (k *Keeper) appendManifest(archive *fileInfo, reason RotationReason, originalSize int) {
+	if !k.manifest {
+		return
+	}
+	entry := ManifestEntry{
+		Path:       archive.filePath,
+		Reason:     reason,
+		RotatedAt:  k.now(),
+		FirstWrite: k.currentFirstWrite,
+		Records:    archive.records,
+		Size:       archive.size,
+	}
+	if !k.currentFirstWrite.IsZero() {
+		entry.LastWrite = k.lastWrite
+	}
+	if originalSize >= 0 {
+		entry.Size, entry.CompressedSize = originalSize, archive.size
+	}
+	if archive.records < 0 {
+		entry.FirstWrite = time.Time{}
+	}
+	digest, err := checksum(k.fsys, archive.filePath, ChecksumSHA256)
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to compute digest of %q for manifest, caused by %w", archive.filePath, err))
+	}
+	entry.SHA256 = digest
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to encode manifest entry of %q, caused by %w", archive.filePath, err))
+		return
+	}
+	f, err := k.fsys.OpenFile(k.manifestPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, k.fileMode)
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to open manifest, caused by %w", err))
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		k.handleError(fmt.Errorf("failed to write manifest entry of %q, caused by %w", archive.filePath, err))
+	}
+}
+
+// Get the archives recorded in the manifest, see [WithManifestDiscovery].
+// The returned bool is false when the archives must be found by listing the folders instead.
+func (k *Keeper) getManifestArchives() (*collection.List[*fileInfo], int, bool, error) {
+	f, err := k.fsys.Open(k.manifestPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, false, nil
+	}
+	if err != nil {
+		k.handleError(fmt.Errorf("failed to open manifest, caused by %w", err))
+		return nil, 0, false, nil
+	}
+	entries, err := ReadManifest(f)
+	f.Close()
+	if err != nil {
+		k.handleError(err)
+		return nil, 0, false, nil
+	}
+
+	// A path may have been rotated to again after its archive was removed, the last entry describes it
+	latest := make(map[string]ManifestEntry, len(entries))
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Clean(entry.Path)
+		if _, ok := latest[path]; !ok {
+			paths = append(paths, path)
+		}
+		latest[path] = entry
+	}
+	var archives []*fileInfo
+	for _, path := range paths {
+		entry := latest[path]
+		info, err := getFileInfo(k.fsys, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("failed to get file info %s, caused by %w", path, err)
+		}
+		stored := entry.Size
+		if entry.CompressedSize > 0 {
+			stored = entry.CompressedSize
+		}
+		// An archive changed since its rotation no longer has the records counted then
+		if info.size == stored {
+			info.records = entry.Records
+			if entry.CompressedSize > 0 {
+				info.originalSize = entry.Size
+			}
+		}
+		archives = append(archives, info)
+	}
+
+	if k.manifestVerify {
+		listed, _, err := k.getArchives()
+		if err != nil {
+			return nil, 0, false, err
+		}
+		for _, archive := range listed.All() {
+			if _, ok := latest[filepath.Clean(archive.filePath)]; !ok {
+				k.handleError(fmt.Errorf("archive %q is missing from the manifest", archive.filePath))
+				archives = append(archives, archive)
+			}
+		}
+	}
+
+	slices.SortStableFunc(archives, func(a, b *fileInfo) int {
+		return a.modtime.Compare(b.modtime)
+	})
+	l := collection.NewList[*fileInfo]()
+	size := 0
+	for _, archive := range archives {
+		l.Append(archive)
+		size += archive.size
+	}
+	l, size = k.excludeCurrentFile(l, size)
+	return k.orderArchives(l), size, true, nil
+}
+
+// Get the archives from the manifest when [WithManifestDiscovery] is set, or else by listing the archive folders.
+func (k *Keeper) discoverArchives() (*collection.List[*fileInfo], int, error) {
+	if k.manifestDiscovery {
+		archives, size, ok, err := k.getManifestArchives()
+		if err != nil || ok {
+			return archives, size, err
+		}
+	}
+	return k.getArchives()
+}
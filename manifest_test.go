@@ -0,0 +1,162 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeeperWithManifest(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-manifest"),
+		WithManifest(),
+		WithGzip(),
+		WithNowFunc(func() time.Time { return now }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for range 2 {
+		if _, err := k.Write([]byte("first\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		now = now.Add(time.Minute)
+		if _, err := k.Write([]byte("second\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		now = now.Add(time.Minute)
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	entries, err := k.Manifest()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries got %d", len(entries))
+	}
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	first := entries[0]
+	if !first.FirstWrite.Equal(start) || !first.LastWrite.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected writes from %v to %v got %+v", start, start.Add(time.Minute), first)
+	}
+	if first.Records != 2 || first.Size != len("first\nsecond\n") || first.CompressedSize == 0 {
+		t.Errorf("expected 2 records and their sizes got %+v", first)
+	}
+	content, err := os.ReadFile(first.Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if digest, _ := checksum(OSFS(), first.Path, ChecksumSHA256); len(content) != first.CompressedSize || digest != first.SHA256 {
+		t.Errorf("expected the digest of the archive got %+v", first)
+	}
+	// The rotation on close archived nothing
+	if last := entries[2]; last.Reason != RotationClose || !last.FirstWrite.IsZero() || !last.LastWrite.IsZero() {
+		t.Errorf("expected an empty archive rotated on close got %+v", last)
+	}
+
+	if _, err := ReadManifest(bytes.NewReader([]byte("{\n"))); err == nil {
+		t.Errorf("expected an error for a malformed manifest")
+	}
+}
+
+func TestKeeperWithManifestDiscovery(t *testing.T) {
+	folder := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	rotate := func(opts ...Opt) {
+		k, err := New(append([]Opt{
+			WithFolder(folder),
+			WithName("test-discovery"),
+			WithNowFunc(func() time.Time { return now }),
+		}, opts...)...)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if _, err := k.Write([]byte("message\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		now = now.Add(time.Minute)
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		now = now.Add(time.Minute)
+		if err := k.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	// Rotated before the manifest was enabled
+	rotate()
+	rotate(WithManifest())
+	rotate(WithManifest())
+	entries, err := readManifestFile(t, folder, "test-discovery")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries got %d", len(entries))
+	}
+	if err := os.Remove(entries[0].Path); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		verify   bool
+		archives int
+		errs     int
+	}{
+		{name: "trusted", archives: 3},
+		// The rotation on close of the trusted Keeper is recorded as well
+		{name: "verified", verify: true, archives: 6, errs: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var errs []error
+			k, err := New(
+				WithFolder(folder),
+				WithName("test-discovery"),
+				WithManifest(),
+				WithManifestDiscovery(tc.verify),
+				WithErrorHandler(func(err error) { errs = append(errs, err) }),
+			)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			defer k.Close()
+			if k.archives.Length() != tc.archives || len(errs) != tc.errs {
+				t.Fatalf("expected %d archives and %d errors got %d and %v", tc.archives, tc.errs, k.archives.Length(), errs)
+			}
+			found := false
+			for _, archive := range k.archives.All() {
+				if archive.filePath == entries[2].Path {
+					found = archive.records == 1
+				}
+			}
+			if !found {
+				t.Errorf("expected the archive %q with 1 record", entries[2].Path)
+			}
+		})
+	}
+
+	if _, err := New(WithFolder(folder), WithName("test-discovery"), WithManifestDiscovery(false)); err == nil {
+		t.Errorf("expected an error without WithManifest got nil")
+	}
+}
+
+func readManifestFile(t *testing.T, folder, name string) ([]ManifestEntry, error) {
+	t.Helper()
+	f, err := os.Open(filepath.Join(folder, "."+name+".manifest"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadManifest(f)
+}
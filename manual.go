@@ -0,0 +1,105 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Drive the time-based maintenance of the Keeper manually with [Keeper.Step] instead of background goroutines,
+// so that property-based and fuzz tests can verify invariants, such as "bytes in == bytes across files",
+// without sleeps or flakiness. Together with [WithNowFunc] for the clock, the Keeper then behaves deterministically:
+//   - the rotations of [WithCron], the idle closes of [WithCloseAfterIdle] and the flushes of [WithFlushInterval]
+//     only happen in [Keeper.Step], once due according to the clock of the Keeper.
+//   - the hooks of [WithOnRotate] and [WithOnArchiveRemoved], and the uploads of [WithUploader],
+//     only run in [Keeper.Step] and [Keeper.Close], on the calling goroutine.
+//   - the maintenance of the log files, such as removing the expired archives, runs on the calling goroutine,
+//     unless the Keeper is a member of a [Group].
+//
+// It can not be used together with [WithDoubleBuffering], whose flusher is a background goroutine by design.
+func WithManualStepping() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.manual = true
+		return k, nil
+	}
+}
+
+// Run the time-based maintenance in background goroutines, which is the default.
+func NoManualStepping() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.manual = false
+		return k, nil
+	}
+}
+
+// Stop the background scheduling of a Keeper stepped manually, see [WithManualStepping].
+func (k *Keeper) applyManualStepping() error {
+	k.hooks.setManual(k.manual)
+	k.uploads.setManual(k.manual)
+	if !k.manual {
+		return nil
+	}
+	if k.segmentSize > 0 {
+		return fmt.Errorf("failed to set manual stepping, it can not be used together with double buffering")
+	}
+	k.stopCronTimer()
+	k.stopIdleTimer()
+	k.stopFlushTimer()
+	return nil
+}
+
+// Run the maintenance due at the current time of the clock of the Keeper, see [WithManualStepping]:
+// a scheduled rotation if one is due since the previous step, closing the current log file if it is idle,
+// flushing the write buffer if its flush interval elapsed, then the pending hooks and uploads.
+// It fails if the Keeper is not stepped manually.
+//
+// Example usage:
+//
+//	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+//	keeper, _ := lorekeeper.New(
+//		lorekeeper.WithManualStepping(),
+//		lorekeeper.WithNowFunc(func() time.Time { return clock }),
+//		lorekeeper.WithCron("@hourly"),
+//	)
+//	clock = clock.Add(time.Hour)
+//	err := keeper.Step() // Rotates
+func (k *Keeper) Step() error {
+	err := k.step()
+	// Outside the lock, since the hooks may call the methods of the Keeper
+	k.hooks.drain()
+	k.uploads.drain()
+	return err
+}
+
+func (k *Keeper) step() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if !k.manual {
+		return fmt.Errorf("failed to step, the Keeper is not stepped manually, see WithManualStepping")
+	}
+
+	now := k.now()
+	var errs []error
+	if k.cronSchedule != nil {
+		next := k.cronSchedule.Next(k.steppedAt)
+		if !next.After(now) {
+			if err := k.rotateLocked(RotationCron, RotateOpts{}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if k.closeAfterIdle > 0 && k.currentFile != nil && now.Sub(k.lastWrite) >= k.closeAfterIdle {
+		if err := k.closeCurrentFile(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if k.flushInterval > 0 && len(k.writeBuf) > 0 && now.Sub(k.bufferedAt) >= k.flushInterval {
+		if err := k.flushBuffer(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, run := range k.oldArchiveRuns() {
+		k.compactRun(run)
+	}
+	k.steppedAt = now
+	return errors.Join(errs...)
+}
@@ -0,0 +1,105 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestKeeperManualStepping(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-manual-stepping"),
+		WithManualStepping(),
+		WithNowFunc(func() time.Time { return now }),
+		WithCron("@hourly"),
+		WithBufferSize(Kb),
+		WithFlushInterval(time.Minute),
+		WithMaxSize(64),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// Bytes in == bytes across files, for any sequence of writes and steps
+	rng := rand.New(rand.NewPCG(1, 2))
+	var written []byte
+	for range 200 {
+		msg := bytes.Repeat([]byte{byte('a' + rng.IntN(26))}, 1+rng.IntN(20))
+		msg = append(msg, '\n')
+		if _, err := k.Write(msg); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		written = append(written, msg...)
+		now = now.Add(time.Duration(rng.IntN(120)) * time.Second)
+		if err := k.Step(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := k.Flush(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	var stored []byte
+	for _, archive := range k.Archives() {
+		content, err := os.ReadFile(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		stored = append(stored, content...)
+	}
+	current, err := os.ReadFile(k.CurrentFilePath())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	stored = append(stored, current...)
+	if !bytes.Equal(written, stored) {
+		t.Errorf("expected %d bytes across files got %d", len(written), len(stored))
+	}
+}
+
+func TestKeeperManualSteppingCron(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
+	var rotated []string
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-manual-stepping-cron"),
+		WithManualStepping(),
+		WithNowFunc(func() time.Time { return now }),
+		WithCron("@hourly"),
+		WithOnRotate(func(archivePath string) { rotated = append(rotated, archivePath) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if err := k.Step(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(rotated) != 0 {
+		t.Fatalf("expected no rotation before the hour got %q", rotated)
+	}
+	now = now.Add(30 * time.Minute)
+	if err := k.Step(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// The hook ran on the calling goroutine
+	if len(rotated) != 1 {
+		t.Fatalf("expected 1 rotation at the hour got %q", rotated)
+	}
+	if err := k.Step(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(rotated) != 1 {
+		t.Errorf("expected no other rotation got %q", rotated)
+	}
+
+	if err := ValidateOptions(WithManualStepping(), WithDoubleBuffering(Kb)); err == nil {
+		t.Errorf("expected error with double buffering")
+	}
+}
@@ -0,0 +1,185 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Rename the Keeper and its files to a new naming scheme, so that changing the name or the archive name layout
+// does not orphan the logs written under the old scheme.
+// An empty newName keeps the current name, an empty newLayout keeps the current archive name layout,
+// see [WithName] and [WithArchiveNameLayout] for their formats.
+//
+// The current log file is renamed, appending to an existing file of the new name if any,
+// and every archive is renamed in place.
+// Since the rotation time of an archive is not stored, the {{ .time }} of a renamed archive is its modification time.
+// An archive is never renamed over an existing file: archives that can not be renamed,
+// for example because the new name is already taken, keep their old name,
+// are still managed by the Keeper, and are reported in the returned error.
+func (k *Keeper) Migrate(newName, newLayout string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	oldName := k.name
+	// Tell the compressed archives apart while the old naming scheme is still in place
+	compressed := make([]bool, 0, k.archives.Length())
+	for _, archive := range k.archives.All() {
+		compressed = append(compressed, k.isCompressedArchive(archive.filePath))
+	}
+	if len(newName) > 0 {
+		newName = normalizeName(newName)
+	} else {
+		newName = oldName
+	}
+
+	var templ *template.Template
+	if len(newLayout) > 0 {
+		var err error
+		if templ, err = template.New("lorekeeper-archive-template").Parse(newLayout); err != nil {
+			return fmt.Errorf("failed to migrate, invalid archive name layout, caused by %w", err)
+		}
+	}
+
+	// Claim the new name in the registry
+	if newName != oldName {
+		if other, loaded := k.registry.keepers.LoadOrStore(newName, k); loaded && other != k {
+			return fmt.Errorf("failed to migrate, a keeper named %q already exists", newName)
+		}
+	}
+
+	// Move the current log file
+	oldPath := k.getCurrentFilePath()
+	if err := k.closeCurrentFile(); err != nil {
+		return fmt.Errorf("failed to migrate, caused by %w", err)
+	}
+	oldCurrentName := k.currentName
+	k.name = newName
+	err := k.applyCurrentNameLayout()
+	// Only the archives are renamed if the current log file keeps its path, such as when only the layout changes
+	if newPath := k.getCurrentFilePath(); err == nil && filepath.Clean(newPath) != filepath.Clean(oldPath) {
+		err = appendFile(k.fsys, oldPath, newPath)
+	}
+	if err != nil {
+		k.name = oldName
+		k.currentName = oldCurrentName
+		if newName != oldName {
+			k.registry.unregister(newName)
+		}
+		if file, openErr := k.getCurrentFile(); openErr == nil {
+			k.currentFile = file
+		}
+		return fmt.Errorf("failed to migrate current log file, caused by %w", err)
+	}
+	if newName != oldName {
+		k.registry.unregister(oldName)
+	}
+	file, err := k.getCurrentFile()
+	if err != nil {
+		return fmt.Errorf("failed to migrate, caused by %w", err)
+	}
+	k.currentFile = file
+	if stat, err := file.Stat(); err == nil {
+		k.currentFileSize = int(stat.Size())
+	}
+	if err := k.scanCurrentLines(); err != nil {
+		return fmt.Errorf("failed to migrate, caused by %w", err)
+	}
+
+	if templ != nil {
+		k.archiveNameLayout = templ
+		k.archiveNameLayoutText = newLayout
+	}
+
+	// Rename the archives in place
+	var errs []error
+	for i, archive := range k.archives.All() {
+		newPath, err := k.migrateArchive(archive, compressed[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to migrate archive %q, caused by %w", archive.filePath, err))
+			continue
+		}
+		archive.filePath = newPath
+	}
+	return errors.Join(errs...)
+}
+
+// Rename an archive according to the current naming scheme, returning its new path.
+// A compressed archive keeps its compression extension.
+func (k *Keeper) migrateArchive(archive *fileInfo, compressed bool) (string, error) {
+	name, err := k.renderArchiveName(archive.modtime, "")
+	if err != nil {
+		return "", err
+	}
+
+	folder := filepath.Dir(archive.filePath)
+	if rel, err := getArchiveRelPath(k.getArchiveFolders(), archive.filePath); err == nil {
+		folder = strings.TrimSuffix(archive.filePath, rel)
+	}
+	newPath := filepath.Join(folder, name)
+	if compressed {
+		newPath = k.compressedArchivePath(newPath)
+	}
+	if filepath.Clean(newPath) == filepath.Clean(archive.filePath) {
+		return archive.filePath, nil
+	}
+	if err := k.createArchiveDir(newPath); err != nil {
+		return "", err
+	}
+	// Never replace another file that already has the new name
+	if err := moveFileExclusive(k.fsys, archive.filePath, newPath); err != nil {
+		return "", err
+	}
+	if err := moveChecksums(k.fsys, archive.filePath, newPath); err != nil {
+		return "", err
+	}
+	return newPath, nil
+}
+
+// Rename the files in folder produced under the configuration of oldOpts to the naming scheme of newOpts,
+// without a running [Keeper]. This gives a supported path to change the name, the extension,
+// the time layout, or the archive name layout of a Keeper, run it before creating the Keeper with newOpts.
+// Any folder set by the options is ignored, the files stay in the given folder.
+//
+// The current log file is renamed, appending to an existing file of the new name if any,
+// and every archive is renamed according to its modification time, keeping its compression extension.
+// Files that can not be renamed are left untouched and reported in the returned error.
+//
+// Example usage:
+//
+//	err := lorekeeper.MigrateLayout(
+//		"/var/log/app",
+//		[]lorekeeper.Opt{lorekeeper.WithName("app"), lorekeeper.WithTimeLayout("20060102")},
+//		[]lorekeeper.Opt{lorekeeper.WithName("app"), lorekeeper.WithTimeLayout("2006-01-02T15-04-05")},
+//	)
+func MigrateLayout(folder string, oldOpts, newOpts []Opt) error {
+	oldKeeper, err := configureDetached(append(oldOpts, WithFolder(folder))...)
+	if err != nil {
+		return fmt.Errorf("failed to migrate, invalid old options, caused by %w", err)
+	}
+	newKeeper, err := configureDetached(append(newOpts, WithFolder(folder))...)
+	if err != nil {
+		return fmt.Errorf("failed to migrate, invalid new options, caused by %w", err)
+	}
+
+	archives, _, err := oldKeeper.getArchives()
+	if err != nil {
+		return fmt.Errorf("failed to migrate, caused by %w", err)
+	}
+
+	var errs []error
+	oldPath, newPath := oldKeeper.getCurrentFilePath(), newKeeper.getCurrentFilePath()
+	if _, err := oldKeeper.fsys.Stat(oldPath); err == nil && filepath.Clean(oldPath) != filepath.Clean(newPath) {
+		if err := appendFile(oldKeeper.fsys, oldPath, newPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to migrate current log file %q, caused by %w", oldPath, err))
+		}
+	}
+	for _, archive := range archives.All() {
+		if _, err := newKeeper.migrateArchive(archive, oldKeeper.isCompressedArchive(archive.filePath)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to migrate archive %q, caused by %w", archive.filePath, err))
+		}
+	}
+	return errors.Join(errs...)
+}
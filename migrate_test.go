@@ -0,0 +1,196 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeeperMigrate(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithName("Test-Migrate-Old"),
+		WithFolder(folder),
+		WithMaxSize(10),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"0123456789", "0123456789", "abc"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		// Make sure archives have different modification times
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := k.Migrate("Test-Migrate-New", "{{ .name }}.{{ .time }}{{ .extension }}"); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if _, ok := registry.Get("test-migrate-old"); ok {
+		t.Errorf("expected old name to be unregistered")
+	}
+	if val, ok := registry.Get("test-migrate-new"); !ok || val != k {
+		t.Errorf("expected new name to be registered")
+	}
+	content, err := os.ReadFile(filepath.Join(folder, "test-migrate-new.log"))
+	if err != nil || string(content) != "abc" {
+		t.Errorf("expected current log to be renamed got %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "test-migrate-old.log")); err == nil {
+		t.Errorf("expected old current log to be gone")
+	}
+
+	archives := k.Archives()
+	if len(archives) != 2 {
+		t.Fatalf("expected 2 archives got %d", len(archives))
+	}
+	for _, archive := range archives {
+		name := filepath.Base(archive.Path)
+		if !strings.HasPrefix(name, "test-migrate-new.") || !strings.HasSuffix(name, ".log.gz") {
+			t.Errorf("expected archive to follow the new scheme got %s", name)
+		}
+		if _, err := os.Stat(archive.Path); err != nil {
+			t.Errorf("expected archive to exist got %v", err)
+		}
+	}
+
+	// The migrated archives must be found by the new scheme
+	found, _, err := k.getArchives()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if found.Length() != 2 {
+		t.Errorf("expected 2 archives to be found got %d", found.Length())
+	}
+
+	if err := k.Migrate("", "{{ .time"); err == nil {
+		t.Errorf("expected error for invalid layout")
+	}
+}
+
+func TestMigrateLayout(t *testing.T) {
+	folder := t.TempDir()
+	oldOpts := []Opt{
+		WithName("Test-MigrateLayout"),
+		WithTimeLayout("20060102150405.000000000"),
+		WithArchiveNameLayout("{{ .time }}-{{ .name }}{{ .extension }}"),
+	}
+	newOpts := []Opt{
+		WithName("Test-MigrateLayout"),
+		WithExtension(".txt"),
+		WithTimeLayout("2006-01-02T15-04-05.000000000"),
+		WithArchiveNameLayout("{{ .name }}_{{ .time }}{{ .extension }}"),
+	}
+
+	k, err := New(append([]Opt{WithFolder(folder), WithMaxSize(10)}, oldOpts...)...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, msg := range []string{"0123456789", "0123456789", "abc"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Release the files without rotating
+	registry.unregister(k.name)
+	if err := k.free(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if err := MigrateLayout(folder, oldOpts, newOpts); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	migrated, err := New(append([]Opt{WithFolder(folder)}, newOpts...)...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer migrated.Close()
+	if archives := migrated.Archives(); len(archives) != 2 {
+		t.Errorf("expected 2 archives to be adopted got %d", len(archives))
+	}
+	content, err := os.ReadFile(migrated.CurrentFilePath())
+	if err != nil || string(content) != "abc" {
+		t.Errorf("expected current log to be migrated got %q, %v", content, err)
+	}
+	entries, _ := os.ReadDir(folder)
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".log") {
+			t.Errorf("expected no file left under the old scheme got %s", entry.Name())
+		}
+	}
+}
+
+func TestKeeperMigrateLayoutOnly(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithName("Test-Migrate-Layout-Only"), WithFolder(folder), WithMaxSize(10))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{"0123456789", "abc"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- k.Migrate("", "{{ .name }}.{{ .time }}{{ .extension }}") }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected migrating with the same name to return")
+	}
+
+	content, err := os.ReadFile(filepath.Join(folder, "test-migrate-layout-only.log"))
+	if err != nil || string(content) != "abc" {
+		t.Errorf("expected current log to be kept got %q, %v", content, err)
+	}
+	archives := k.Archives()
+	if len(archives) != 1 || !strings.HasPrefix(filepath.Base(archives[0].Path), "test-migrate-layout-only.") {
+		t.Errorf("expected the archive to follow the new layout got %v", archives)
+	}
+}
+
+func TestKeeperMigrateExistingArchive(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithName("Test-Migrate-Existing"), WithFolder(folder), WithMaxSize(10))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for _, msg := range []string{"0123456789", "abc"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	oldPath := k.Archives()[0].Path
+	taken := filepath.Join(folder, "taken.log")
+	if err := os.WriteFile(taken, []byte("keep"), 0o644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if err := k.Migrate("", "taken{{ .extension }}"); err == nil {
+		t.Errorf("expected error when the new archive name is taken")
+	}
+	if content, err := os.ReadFile(taken); err != nil || string(content) != "keep" {
+		t.Errorf("expected the existing file to be untouched got %q, %v", content, err)
+	}
+	if archives := k.Archives(); len(archives) != 1 || archives[0].Path != oldPath {
+		t.Errorf("expected the archive to keep its old path %s got %v", oldPath, archives)
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Errorf("expected the archive to still exist got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package lorekeeper
+
+import (
+	"slices"
+	"time"
+
+	"github.com/trviph/collection"
+)
+
+// How far the modification times of the archives are trusted to order them, see [WithTrustModTime].
+type modTimePolicy int
+
+const (
+	// Order by modification time, falling back to the rotation time in the archive names for the ties,
+	// such as on FAT volumes whose modification times have a resolution of 2 seconds.
+	modTimeAuto modTimePolicy = iota
+	modTimeTrusted
+	modTimeDistrusted
+)
+
+// Tell whether the modification times of the archives are reliable enough to order them for the retention,
+// which removes the oldest archives first.
+// Where they are not, such as on some network filesystems that set them on copy or on FAT volumes,
+// set false to order the archives by the rotation time recorded in their names instead,
+// see [WithArchiveNameLayout] and [WithTimeLayout], falling back to the modification time for the names without one.
+// The archives are then aged by that time as well, see [WithMaxAge] and [MaxAgePolicy].
+// By default, the archives are ordered by modification time, and by the time in their names when those collide.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithFolder("/mnt/nfs/logs"), lorekeeper.WithTrustModTime(false))
+func WithTrustModTime(trust bool) Opt {
+	if trust {
+		return withModTimePolicy(modTimeTrusted)
+	}
+	return withModTimePolicy(modTimeDistrusted)
+}
+
+func withModTimePolicy(policy modTimePolicy) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.modTimePolicy = policy
+		return k, nil
+	}
+}
+
+// Reorder the archives, found ordered by modification time, according to the modification time policy.
+func (k *Keeper) orderArchives(archives *collection.List[*fileInfo]) *collection.List[*fileInfo] {
+	if k.modTimePolicy == modTimeTrusted || archives.Length() < 2 {
+		return archives
+	}
+	sorted := make([]*fileInfo, 0, archives.Length())
+	for _, archive := range archives.All() {
+		sorted = append(sorted, archive)
+	}
+	if k.modTimePolicy == modTimeAuto {
+		tied := false
+		for i := 1; i < len(sorted) && !tied; i++ {
+			tied = sorted[i].modtime.Equal(sorted[i-1].modtime)
+		}
+		if !tied {
+			return archives
+		}
+	}
+
+	type timed struct {
+		archive *fileInfo
+		time    time.Time
+	}
+	byTime := make([]timed, len(sorted))
+	for i, archive := range sorted {
+		byTime[i] = timed{archive: archive, time: k.archiveTime(archive)}
+	}
+	slices.SortStableFunc(byTime, func(a, b timed) int {
+		if k.modTimePolicy == modTimeAuto && !a.archive.modtime.Equal(b.archive.modtime) {
+			return a.archive.modtime.Compare(b.archive.modtime)
+		}
+		return a.time.Compare(b.time)
+	})
+	ordered := collection.NewList[*fileInfo]()
+	for _, t := range byTime {
+		ordered.Append(t.archive)
+	}
+	return ordered
+}
+
+// Get the [ArchiveInfo] of the archive given to the retention, whose ModTime is the rotation time recorded in its name
+// when the modification times are not trusted, so that [MaxAgePolicy] ages the archives like they are ordered.
+func (k *Keeper) retentionInfo(archive *fileInfo) ArchiveInfo {
+	info := archive.toArchiveInfo(k.isCompressedArchive(archive.filePath))
+	if k.modTimePolicy == modTimeDistrusted {
+		info.ModTime = k.archiveTime(archive)
+	}
+	return info
+}
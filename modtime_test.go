@@ -0,0 +1,61 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWithTrustModTime(t *testing.T) {
+	folder := t.TempDir()
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	k, err := New(WithFolder(folder), WithName("test-trust-mod-time"), WithNowFunc(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	var rotated []string
+	for i := range 3 {
+		now = now.Add(time.Hour)
+		if _, err := k.Write(fmt.Appendf(nil, "%d\n", i)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		archivePath, err := k.RotateNow()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		rotated = append(rotated, archivePath)
+	}
+	k.Close()
+
+	for _, tc := range []struct {
+		name     string
+		opt      Opt
+		modtimes func(i int) time.Time
+	}{
+		// The modification times were reset in the reverse order, such as by a copy
+		{name: "distrusted", opt: WithTrustModTime(false), modtimes: func(i int) time.Time { return now.Add(-time.Duration(i) * time.Minute) }},
+		// The modification times collide, such as on a FAT volume
+		{name: "auto", opt: Options(), modtimes: func(int) time.Time { return now }},
+	} {
+		for i, path := range rotated {
+			if err := os.Chtimes(path, tc.modtimes(i), tc.modtimes(i)); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+		k, err := New(WithFolder(folder), WithName("test-trust-mod-time-"+tc.name), WithArchiveNameLayout("{{ .time }}-test-trust-mod-time{{ .extension }}"), tc.opt)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		archives := k.Archives()
+		k.Close()
+		if len(archives) < len(rotated) {
+			t.Fatalf("expected at least %d archives with %s got %d", len(rotated), tc.name, len(archives))
+		}
+		for i, path := range rotated {
+			if archives[i].Path != path {
+				t.Errorf("expected archive %d to be %q with %s got %q", i, path, tc.name, archives[i].Path)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Check whether the name could escape its folder once used in a path,
+// because it contains a path separator or a NUL byte, or is made only of dots like "..".
+func isUnsafeName(name string) bool {
+	return strings.ContainsAny(name, "/\\\x00") || (len(name) > 0 && strings.Trim(name, ".") == "")
+}
+
+// Make the name safe to use in a path, replacing the path separators and the NUL bytes with "_",
+// and a name made only of dots with as many "_".
+func sanitizeName(name string) string {
+	if len(name) > 0 && strings.Trim(name, ".") == "" {
+		return strings.Repeat("_", len(name))
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', 0:
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// Sanitize the name of the Keeper, or reject it with [WithStrictNames].
+func (k *Keeper) applyNamePolicy() error {
+	if !isUnsafeName(k.name) {
+		return nil
+	}
+	if k.strictNames {
+		return fmt.Errorf("failed to set name, %q contains a path separator or is made only of dots", k.name)
+	}
+	k.name = sanitizeName(k.name)
+	return nil
+}
@@ -0,0 +1,57 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeeperNameSanitizing(t *testing.T) {
+	folder := t.TempDir()
+	for _, name := range []string{"../escape", `..\escape`, ".."} {
+		k, err := New(WithFolder(folder), WithName(name))
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if filepath.Dir(k.CurrentFilePath()) != folder || strings.ContainsAny(k.Name(), `/\`) {
+			t.Errorf("expected %q to stay in %q got %q", name, folder, k.CurrentFilePath())
+		}
+		if err := k.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+
+		if _, err := New(WithFolder(folder), WithName(name), WithStrictNames()); err == nil {
+			t.Errorf("expected %q to be rejected with strict names", name)
+		}
+	}
+}
+
+func TestKeeperSetKeySanitizing(t *testing.T) {
+	root := t.TempDir()
+	set, err := NewKeeperSet("tenant-{{ .key }}", filepath.Join(root, "{{ .key }}"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+
+	if err := os.Mkdir(filepath.Join(root, "..%2F..%2Fetc"), 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	keeper, err := set.Get("../../etc")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if rel, err := filepath.Rel(root, keeper.CurrentFilePath()); err != nil || !filepath.IsLocal(rel) {
+		t.Errorf("expected the keeper to stay in %q got %q", root, keeper.CurrentFilePath())
+	}
+
+	strict, err := NewKeeperSet("", filepath.Join(root, "{{ .key }}"), WithStrictNames())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer strict.Close()
+	if _, err := strict.Get("../../etc"); err == nil {
+		t.Errorf("expected the key to be rejected with strict names")
+	}
+}
@@ -0,0 +1,64 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Placeholders rendered into the archive name layout to locate the sequence number, the PID and the hostname.
+const (
+	layoutSeqMarker      = "\x05"
+	layoutPIDMarker      = "\x06"
+	layoutHostnameMarker = "\x07"
+)
+
+// Get the hostname of the machine for {{ .hostname }}, with the characters that can not be part of a file name replaced.
+var layoutHostname = sync.OnceValue(func() string {
+	hostname, err := os.Hostname()
+	if err != nil || len(hostname) == 0 {
+		return "localhost"
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune("/\\\x00*?[]:", r) {
+			return '_'
+		}
+		return r
+	}, hostname)
+})
+
+// Replace the values of {{ .seq }}, {{ .pid }} and {{ .hostname }} in the data of the archive name layout,
+// so that patterns match the archives of any sequence number, process or machine.
+func withNameVars(data map[string]any, seq, pid, hostname string) map[string]any {
+	data["seq"] = seq
+	data["pid"] = pid
+	data["hostname"] = hostname
+	return data
+}
+
+// Set the values of {{ .seq }}, {{ .pid }} and {{ .hostname }} for the next archive.
+func (k *Keeper) nameVars(data map[string]any) map[string]any {
+	return withNameVars(data, strconv.FormatUint(k.archiveSeq+1, 10), strconv.Itoa(os.Getpid()), layoutHostname())
+}
+
+// Check whether the archive name layout contains {{ .seq }}.
+func (k *Keeper) layoutHasSeq() bool {
+	var buff bytes.Buffer
+	data := withNameVars(k.archiveNameData("", "", ""), layoutSeqMarker, "", "")
+	err := k.archiveNameLayout.Execute(&buff, data)
+	return err == nil && strings.Contains(buff.String(), layoutSeqMarker)
+}
+
+// Continue the sequence number of {{ .seq }} after the largest one of the archives, so that it survives restarts.
+func (k *Keeper) restoreArchiveSeq() {
+	if k.archiveNameLayout == nil || !k.layoutHasSeq() {
+		return
+	}
+	for _, archive := range k.archives.All() {
+		if meta, err := k.parseArchiveName(archive.filePath); err == nil {
+			k.archiveSeq = max(k.archiveSeq, meta.Seq)
+		}
+	}
+}
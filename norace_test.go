@@ -0,0 +1,5 @@
+//go:build !race
+
+package lorekeeper
+
+const raceEnabled = false
@@ -1,12 +1,16 @@
 package lorekeeper
 
 import (
-	"bytes"
-	"compress/gzip"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"os"
+	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -16,13 +20,196 @@ import (
 // An Opt should be used together with [New].
 type Opt func(*Keeper) (*Keeper, error)
 
+// Returned by [ValidateOptions], wrapped, for options that are valid on their own but likely misconfigured together.
+var ErrOptionWarning = errors.New("option warning")
+
+// Apply the options to the Keeper, collecting the errors of all invalid options instead of stopping at the first one.
+func configure(k *Keeper, opts ...Opt) (*Keeper, error) {
+	var errs []error
+	for _, opt := range opts {
+		next, err := opt(k)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		k = next
+	}
+	if err := k.applyNamePolicy(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyManualStepping(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyTenantPolicy(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyUniqueSuffix(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyManifestDiscovery(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyCurrentNameLayout(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyCurrentSymlink(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyEncryption(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyCompressedCurrent(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyAsyncWrites(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyHashChain(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyFS(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyHardMaxSize(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyOldDir(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyArchiveDirs(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyStartupCompaction(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyCompaction(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := k.applyAuditProfile(); err != nil {
+		errs = append(errs, err)
+	}
+	return k, errors.Join(errs...)
+}
+
+// Configure a Keeper on top of the default options without opening any file,
+// starting any background goroutine, or registering it.
+func configureDetached(opts ...Opt) (*Keeper, error) {
+	k, err := configure(new(Keeper), append(DefaultOptions(), opts...)...)
+	k.stopReopenSignal()
+	return k, err
+}
+
+// Validate the options without creating a [Keeper] or touching any file.
+// The returned error joins the errors of every invalid option,
+// and warnings wrapping [ErrOptionWarning] for options that are likely misconfigured together,
+// such as a total size smaller than the max size which deletes archives right after rotation.
+// [New] only fails on invalid options and ignores the warnings.
+//
+// Example usage:
+//
+//	if err := lorekeeper.ValidateOptions(opts...); errors.Is(err, lorekeeper.ErrOptionWarning) {
+//		log.Printf("suspicious log configuration: %v", err)
+//	}
+func ValidateOptions(opts ...Opt) error {
+	k, err := configureDetached(opts...)
+	if err != nil {
+		return err
+	}
+	return errors.Join(k.checkOptions()...)
+}
+
+// Check the options for combinations that are valid but likely misconfigured.
+func (k *Keeper) checkOptions() []error {
+	var warnings []error
+	if k.totalSize > 0 && k.maxSize > 0 && k.totalSize < k.maxSize && k.compressor == nil {
+		warnings = append(warnings, fmt.Errorf(
+			"%w: total size %d is smaller than max size %d, archives may be deleted right after rotation",
+			ErrOptionWarning, k.totalSize, k.maxSize,
+		))
+	}
+
+	if k.hardMaxSize > 0 && k.maxSize > k.hardMaxSize {
+		warnings = append(warnings, fmt.Errorf(
+			"%w: max size %d is larger than hard max size %d, log files are only rotated at the hard max size",
+			ErrOptionWarning, k.maxSize, k.hardMaxSize,
+		))
+	}
+
+	// The names are only rendered, the archive folder of the next rotation is left as is
+	if k.archiveNameLayout != nil {
+		first, firstErr := k.renderArchiveName(time.Unix(0, 0), RotationSize)
+		second, secondErr := k.renderArchiveName(time.Unix(1, 1), RotationSize)
+		if firstErr == nil && secondErr == nil && first == second && !k.layoutHasSeq() {
+			warnings = append(warnings, fmt.Errorf(
+				"%w: archive name layout does not depend on the time, archives will overwrite each other",
+				ErrOptionWarning,
+			))
+		}
+	}
+	return warnings
+}
+
+// Compose multiple options into one, applied in the given order.
+// This is useful to define reusable presets and layer overrides on top of them.
+// For example:
+//
+//	var debugProfile = lorekeeper.Options(
+//		lorekeeper.WithMaxSize(10*lorekeeper.Mb),
+//		lorekeeper.WithMaxFiles(3),
+//	)
+//
+//	keeper, err := lorekeeper.New(
+//		debugProfile,
+//		// Override the preset
+//		lorekeeper.WithMaxFiles(5),
+//	)
+func Options(opts ...Opt) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		var err error
+		for _, opt := range opts {
+			if k, err = opt(k); err != nil {
+				return nil, err
+			}
+		}
+		return k, nil
+	}
+}
+
 // The folder where the log files are stored.
 // The default value is [os.TempDir].
 func WithFolder(path string) Opt {
 	return func(k *Keeper) (*Keeper, error) {
 		if len(path) > 0 {
-			k.folder = path
+			k.folder = normalizeFolder(path)
+			k.folders = nil
+			k.tenant = ""
+			k.folderMode = 0
+		}
+		return k, nil
+	}
+}
+
+// Stripe archives across multiple folders, useful when the host has several small disks.
+// The first folder is where the current log file is stored, same as [WithFolder].
+// Archives are distributed round-robin across all the given folders, including the first one,
+// and retention considers archives in all of them.
+func WithFolders(paths ...string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("failed to set folders, at least one folder is required")
 		}
+		for _, path := range paths {
+			if len(path) == 0 {
+				return nil, fmt.Errorf("failed to set folders, folder must not be empty")
+			}
+		}
+		k.folders = make([]string, 0, len(paths))
+		for _, path := range paths {
+			k.folders = append(k.folders, normalizeFolder(path))
+		}
+		k.folder = k.folders[0]
+		k.tenant = ""
+		k.folderMode = 0
 		return k, nil
 	}
 }
@@ -30,6 +217,7 @@ func WithFolder(path string) Opt {
 // The name of the Keeper.
 // It will be set to the default value if the name is empty.
 // The default value is lorekeeper-<the executable name and extension>.
+// The path separators in the name are replaced with "_" so that it can not escape the folder, see [WithStrictNames].
 //
 // Note(trviph): Name is used to identify the Keeper, so only one instance of
 // the Keeper with the same name can exists in the current process.
@@ -54,12 +242,17 @@ func WithFolder(path string) Opt {
 func WithName(name string) Opt {
 	return func(k *Keeper) (*Keeper, error) {
 		if len(name) > 0 {
-			k.name = strings.ReplaceAll(strings.ToLower(name), " ", "-")
+			k.name = normalizeName(name)
 		}
 		return k, nil
 	}
 }
 
+// Normalize the name of a Keeper to be used in file names.
+func normalizeName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "-")
+}
+
 // The extension of the output log file, can be empty.
 // A "." will be prepended if missing.
 // The default value is ".log".
@@ -110,11 +303,25 @@ func WithMaxSize(size int) Opt {
 // The layout is parsed using the [text/template] package.
 // The supported arguments are:
 //   - {{ .time }} the time when the rotation happened.
+//   - {{ .year }}, {{ .month }}, {{ .day }} and {{ .hour }} the zero-padded fields of the time when the rotation happened,
+//     such as to sort the archives into subdirectories with "{{ .year }}/{{ .month }}/{{ .name }}-{{ .time }}{{ .extension }}".
 //   - {{ .name }} the name of the Keeper.
 //   - {{ .extension }} the extension of the file.
+//   - {{ .reason }} why the rotation happened, see [RotationReason].
+//   - {{ .seq }} the sequence number of the archive, starting at 1 and continuing after the largest one of the archives
+//     when the Keeper starts, so that coarse time layouts do not collide.
+//   - {{ .pid }} the PID of the process that rotated the archive.
+//   - {{ .hostname }} the hostname of the machine, with the characters that can not be part of a file name replaced by "_",
+//     so that the instances of a deployment sharing a folder do not collide.
+//   - {{ .compressionExt }} the extension of the compression, such as ".gz", empty if the archive is not compressed.
+//     Without it, the compression extension is appended at the end of the name.
+//   - any key set with [WithTemplateData], such as {{ .region }}.
+//
+// The layout may contain directories, which are created on rotation with the permissions of [WithCreateFolder]
+// or 0755, and removed by the retention once empty. The rendered path must stay within the archive folder.
 //
 // Note: In order to avoid races in cases where more than one [Keeper]s are running,
-// the layout should contains all the supported arguments
+// the layout should contains the time, the name and the extension arguments
 // or specify another log folder using [WithFolder].
 func WithArchiveNameLayout(layout string) Opt {
 	return func(k *Keeper) (*Keeper, error) {
@@ -126,6 +333,8 @@ func WithArchiveNameLayout(layout string) Opt {
 			return nil, fmt.Errorf("failed to set archive name layout, caused by %w", err)
 		}
 		k.archiveNameLayout = templ
+		k.archiveNameLayoutText = layout
+		k.dateFormat = ""
 		return k, nil
 	}
 }
@@ -142,7 +351,8 @@ func WithMaxFiles(size int) Opt {
 	}
 }
 
-// Setting for cron rotation, this package uses [cron] to handle creating and runnnig cron jobs.
+// Setting for cron rotation, this package uses [cron] to parse the cron spec,
+// the rotations are scheduled on the clock of the Keeper, see [WithClock].
 // See [CRON Expression Format] and [Predefined schedules] for more info on the cron format.
 // This feature is disabled by default.
 //
@@ -151,17 +361,14 @@ func WithMaxFiles(size int) Opt {
 // [Predefined schedules]: https://pkg.go.dev/github.com/robfig/cron/v3#hdr-Predefined_schedules
 func WithCron(spec string) Opt {
 	return func(k *Keeper) (*Keeper, error) {
-		if k.cronScheduler == nil {
-			k.cronScheduler = cron.New()
-			go k.cronScheduler.Run()
-		} else {
-			k.cronScheduler.Remove(k.cronEntryID)
-		}
-
-		var err error
-		if k.cronEntryID, err = k.cronScheduler.AddFunc(spec, func() { _ = k.Rotate() }); err != nil {
+		schedule, err := cron.ParseStandard(spec)
+		if err != nil {
 			return nil, fmt.Errorf("failed to setup cron, caused by %w", err)
 		}
+		// Scheduled on the clock of the Keeper once it is configured
+		k.stopCronTimer()
+		k.cronSchedule = schedule
+		k.cronSpec = spec
 		return k, nil
 	}
 }
@@ -169,51 +376,406 @@ func WithCron(spec string) Opt {
 // No cron
 func NoCron() Opt {
 	return func(k *Keeper) (*Keeper, error) {
-		if k.cronScheduler != nil {
-			k.cronScheduler.Stop()
+		k.stopCronTimer()
+		k.cronSchedule = nil
+		k.cronSpec = ""
+		return k, nil
+	}
+}
+
+// Delete the oldest archive if the total size of all
+// archives exceeds this value. Set < 1 to disable, is disabled by default.
+// If both this and [WithMaxFiles] are set, the Keeper will use whatever condition is met first.
+func WithTotalSize(size int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.totalSize = size
+		return k, nil
+	}
+}
+
+// Set the function telling the time to the Keeper, it is used to name archives and track the last write.
+// The default value is [time.Now], a nil function also falls back to it.
+// This is useful for testing, since each Keeper can use its own simulated clock,
+// see [WithClock] to schedule the timers on it as well.
+//
+// It is a shorthand for [WithClock] with a clock telling the time with nowFunc,
+// and scheduling the timers on the clock set before, so the last of the two options wins:
+// WithNowFunc after WithClock only replaces how the time is told, WithClock after WithNowFunc replaces both.
+func WithNowFunc(nowFunc func() time.Time) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if nowFunc == nil {
+			nowFunc = time.Now
+		}
+		timers := k.clock
+		if c, ok := timers.(nowFuncClock); ok {
+			timers = c.Clock
+		}
+		if timers == nil {
+			timers = systemClock{}
+		}
+		return WithClock(nowFuncClock{now: nowFunc, Clock: timers})(k)
+	}
+}
+
+// Register the Keeper in the given [Registry] instead of the package-level one.
+// Keepers are unique by name within a registry, so Keepers of the same name in different registries are independent,
+// make sure that they do not manage the same files.
+// A nil registry falls back to the package-level registry.
+func WithRegistry(r *Registry) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if r == nil {
+			r = registry
+		}
+		k.registry = r
+		return k, nil
+	}
+}
+
+// Rotate the existing current log file when the Keeper starts if its last write, taken from its modification time,
+// is older than the given duration, so that a service that was down over the weekend
+// does not keep appending Monday's logs to Friday's file. The rotation reason is [RotationStale].
+// Set <= 0 to disable, is disabled by default.
+func WithMaxAgeAtStartup(d time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.maxAgeAtStartup = d
+		return k, nil
+	}
+}
+
+// Rotate the existing current log file when the Keeper starts, so that every process start gets a clean file.
+// An empty current log file is not rotated. The rotation reason is [RotationStartup].
+// Is disabled by default.
+func WithRotateOnStart() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.rotateOnStart = true
+		return k, nil
+	}
+}
+
+// Keep appending to the existing current log file when the Keeper starts, this is the default.
+func NoRotateOnStart() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.rotateOnStart = false
+		return k, nil
+	}
+}
+
+// Close the file descriptor of the current log file after no write for the given duration,
+// the file is transparently reopened in append mode on the next write.
+// This reduces the file descriptor pressure of processes holding many mostly-idle Keepers.
+// Set <= 0 to disable, is disabled by default.
+func WithCloseAfterIdle(d time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.closeAfterIdle = d
+		if d <= 0 {
+			k.stopIdleTimer()
+		}
+		return k, nil
+	}
+}
+
+// Write the messages to w when opening or writing the current log file fails,
+// for example when the folder is unmounted or its permissions changed,
+// so that the messages flow to [os.Stderr] or another Keeper instead of being lost.
+// Every write retries the current log file first, so the Keeper goes back to it once the path is healed,
+// use [WithCircuitBreaker] to only retry it periodically instead.
+// The first failure of an outage goes to the handler of [WithErrorHandler], since the write itself succeeds.
+// A nil writer disables the fallback, which is the default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithFallbackWriter(os.Stderr),
+//		lorekeeper.WithErrorHandler(func(err error) { log.Printf("logging outage: %v", err) }),
+//		lorekeeper.WithCircuitBreaker(3, 10*time.Second),
+//	)
+func WithFallbackWriter(w io.Writer) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.fallbackWriter = w
+		return k, nil
+	}
+}
+
+// Set the function receiving the errors that can not be returned to a caller,
+// such as the failures of scheduled rotations, of background writes, and of the removal of expired archives.
+// The handler is called while the Keeper is locked, so it must not call the methods of the Keeper.
+// A nil handler ignores these errors, which is the default, they are still counted in [Keeper.Stats].
+func WithErrorHandler(handler func(err error)) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.errorHandler = handler
+		return k, nil
+	}
+}
+
+// Set the max number of goroutines the Keeper uses at once for the maintenance of the log files,
+// such as removing the expired archives or compressing the archives left over by a previous process,
+// so that resource-constrained environments can cap the CPU used by log maintenance.
+// Set n to 1 to run the maintenance on a single goroutine, defaults to one per CPU up to 32, see [DefaultTuning],
+// the workers of the [Group] of the Keeper, if any, are used instead.
+func WithBackgroundWorkers(n int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if n < 1 {
+			return nil, fmt.Errorf("failed to set background workers, expected at least 1 worker got %d", n)
+		}
+		k.backgroundWorkers = n
+		return k, nil
+	}
+}
+
+// Keep only a fraction of the messages matched by matcher, for example 1% of the debug lines,
+// while always keeping the other messages, to reduce the disk volume when the upstream loggers can not be changed.
+// Each matching message is kept with the probability rate, between 0 and 1, the others are counted in [Stats.SampledOut].
+// A nil matcher samples every message. Set rate to 1 to disable, is disabled by default.
+// The messages of [Keeper.WriteUrgent] are never sampled out.
+func WithSampling(rate float64, matcher func(msg []byte) bool) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if math.IsNaN(rate) || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("failed to set sampling, expected a rate between 0 and 1 got %v", rate)
 		}
-		k.cronScheduler = nil
-		k.cronEntryID = 0
+		k.sampleRate = rate
+		k.sampleMatcher = matcher
 		return k, nil
 	}
 }
 
-// Archive will be compressed with Gzip
-func WithGzip() Opt {
-	return WithGzipLevel(gzip.DefaultCompression)
+// Reject the names containing path separators or made only of dots, instead of sanitizing them,
+// see [WithName]. The keys of a [KeeperSet] created with this option are rejected the same way.
+// This is useful when names are derived from user or tenant input and a suspicious one should fail loudly.
+func WithStrictNames() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.strictNames = true
+		return k, nil
+	}
 }
 
-// Archive will be compressed with Gzip, see [gzip.NoCompression] for available levels.
-func WithGzipLevel(level int) Opt {
+// Sanitize the names containing path separators or made only of dots, which is the default, see [WithName].
+func NoStrictNames() Opt {
 	return func(k *Keeper) (*Keeper, error) {
-		var temp *bytes.Buffer
-		if _, err := gzip.NewWriterLevel(temp, level); err != nil {
-			return nil, fmt.Errorf("failed to create compress, caused by %w", err)
+		k.strictNames = false
+		return k, nil
+	}
+}
+
+// Claim the files of the Keeper in a .lorekeeper.lock file in the folder of the current log file,
+// holding the PID, the host and a random ID of the process, and a hash of the configuration of each Keeper managing files in the folder.
+// [New] then fails fast with a descriptive error when another running process already manages the files of the same name,
+// instead of both processes silently racing on the rotations. The entries of dead processes are ignored,
+// while the processes of other hosts sharing the folder can not be checked and are taken as running,
+// so that their entries are only removed by themselves, or by hand after a crash.
+// The entry of the Keeper is removed from the lock file on [Keeper.Close].
+// The lock file is created with the mode of [WithFileMode].
+// The processes update the lock file one at a time, by locking a .lorekeeper.lock.guard file kept in the folder.
+func WithLockFile() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.lockFile = true
+		return k, nil
+	}
+}
+
+// Do not write the lock file of [WithLockFile], which is the default.
+func NoLockFile() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.lockFile = false
+		return k, nil
+	}
+}
+
+// Defer the removal of the expired archives that are open for reading in this process,
+// through [Keeper.Between] or [Keeper.FS], until their last reader is closed.
+// This avoids pulling an archive from under a reader, which fails on platforms such as Windows.
+// A deferred archive no longer counts toward the retention limits, see [Stats.DeferredRemovals].
+// Readers in other processes are not tracked, an archive that the OS refuses to remove is retried on the next rotation.
+func WithDeferredRemoval() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.deferredRemoval = true
+		return k, nil
+	}
+}
+
+// Remove the expired archives right away, even when they are open for reading, which is the default.
+func NoDeferredRemoval() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.deferredRemoval = false
+		return k, nil
+	}
+}
+
+// Stop writing to the current log file after threshold consecutive failed writes,
+// so that a dead disk does not cost a failing syscall for every log line.
+// While the breaker is open, writes go straight to the fallback writer of [WithFallbackWriter],
+// or fail with an error wrapping [ErrCircuitOpen] if there is none, and no rotation is attempted.
+// Once every probeInterval, a write probes the current log file again and closes the breaker if it succeeds.
+// Set threshold < 1 to disable, is disabled by default.
+func WithCircuitBreaker(threshold int, probeInterval time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if threshold > 0 && probeInterval <= 0 {
+			return nil, fmt.Errorf("failed to set circuit breaker, probe interval must be positive")
 		}
+		k.breakerThreshold = threshold
+		k.breakerProbeInterval = probeInterval
+		return k, nil
+	}
+}
+
+// Group the lines of the log into records, a line matching re starts a new record
+// and the other lines continue the previous one, such as the lines of a stack trace following its exception header.
+// A record is never split across log files: the current log file is only rotated before a line starting a record,
+// and a message larger than the max size is only split between records.
+// A single record larger than the max size gets a log file of its own.
+// A nil re disables this, which is the default, in which case every message is a record on its own.
+//
+// Example usage:
+//
+//	// Records start with a date, the other lines are continuations
+//	lorekeeper.WithRecordPattern(regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`))
+func WithRecordPattern(re *regexp.Regexp) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.recordPattern = re
+		return k, nil
+	}
+}
+
+// Set the function parsing the timestamp of a record, used by [Keeper.Between] to trim the records within log files
+// and by [Keeper.Timeline] to timestamp them, see [PrefixTimestampParser] and [JSONTimestampParser].
+// Records that can not be parsed, for which parse returns an error, are kept.
+// A nil parse disables this, which is the default, in which case only the archive names are used to select the records.
+func WithTimestampParser(parse func(record []byte) (time.Time, error)) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.timestampParser = parse
+		return k, nil
+	}
+}
+
+// Check that every line of every message is a single valid JSON object,
+// so that the log files are guaranteed to be parseable by downstream pipelines as JSON lines.
+// Messages with an invalid line are rejected with an error wrapping [ErrInvalidJSON] and counted as dropped,
+// use [WithWrapInvalidJSON] to keep them instead.
+// A new line is appended to messages that do not end with one.
+func WithValidateJSON() Opt {
+	return withJSONPolicy(jsonReject)
+}
+
+// Same as [WithValidateJSON], but the invalid lines are wrapped into a JSON object
+// of the form {"message":"<the line>"} instead of being rejected.
+func WithWrapInvalidJSON() Opt {
+	return withJSONPolicy(jsonWrap)
+}
 
-		k.compressorContructor = func(w io.Writer) (io.WriteCloser, error) {
-			return gzip.NewWriterLevel(w, level)
+// Write the messages without checking them, this is the default.
+func NoJSONValidation() Opt {
+	return withJSONPolicy(jsonOff)
+}
+
+func withJSONPolicy(policy jsonPolicy) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.jsonPolicy = policy
+		return k, nil
+	}
+}
+
+// Decouple the writers from the file operations with two in-memory segments of up to size bytes each:
+// [Keeper.Write] copies the message into the active segment under a short lock and returns,
+// while a dedicated goroutine writes the other segment to the log files, rotating them as needed.
+// This way disk latency spikes and rotations no longer serialize every logging goroutine behind the lock of the Keeper.
+// Writes only block when the active segment is full, a message larger than size gets a segment of its own.
+//
+// Since messages are written after Write returns, the file errors are not returned to the writers anymore,
+// they are counted in [Keeper.Stats] and the messages go to the fallback writer of [WithFallbackWriter] if any.
+// [Keeper.Rotate] and [Keeper.Close] write the buffered messages first.
+// Set size < 1 to disable, is disabled by default.
+func WithDoubleBuffering(size int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.segmentSize = size
+		return k, nil
+	}
+}
+
+// Set what happens to the writes while the Keeper is paused, see [Keeper.Pause].
+// With [PauseBuffer], up to maxBuffered bytes of messages are kept in memory and written on resume,
+// the messages that do not fit are dropped. Set maxBuffered < 1 to buffer without limit.
+// With [PauseDrop], all messages are dropped.
+// The default value is [PauseBuffer] with 4 [Mb] of buffer, or less or more depending on the memory, see [DefaultTuning].
+func WithPausePolicy(policy PausePolicy, maxBuffered int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if policy != PauseBuffer && policy != PauseDrop {
+			return nil, fmt.Errorf("failed to set pause policy, unknown policy %d", policy)
 		}
-		k.compressionExt = ".gz"
+		k.pausePolicy = policy
+		k.pauseMaxBuffer = maxBuffered
 		return k, nil
 	}
 }
 
-// No compression
-func NoCompression() Opt {
+// Write every message as a record prefixed by its length, a 4 bytes big-endian unsigned integer,
+// so that binary or multi-line payloads can be read back losslessly with a [RecordReader]
+// instead of relying on new lines to delimit them.
+// A record is never split across log files, even if it is larger than the max size.
+// The fallback writer of [WithFallbackWriter] receives the records with their prefix as well.
+func WithLengthPrefixedRecords() Opt {
 	return func(k *Keeper) (*Keeper, error) {
-		k.compressorContructor = nil
-		k.compressionExt = ""
+		k.lengthPrefixed = true
 		return k, nil
 	}
 }
 
-// Delete the oldest archive if the total size of all
-// archives exceeds this value. Set < 1 to disable, is disabled by default.
-// If both this and [WithMaxFiles] are set, the Keeper will use whatever condition is met first.
-func WithTotalSize(size int) Opt {
+// Write the messages as is, this is the default.
+func NoLengthPrefixedRecords() Opt {
 	return func(k *Keeper) (*Keeper, error) {
-		k.totalSize = size
+		k.lengthPrefixed = false
+		return k, nil
+	}
+}
+
+// Append a suffix unique to the current process to the name of the Keeper,
+// made of the hostname and the PID, or a random token if the hostname is not available.
+// This is a one-liner to avoid races when multiple processes write logs into the same folder, see the Pitfalls section of the package documentation.
+// If the archive name layout does not contain {{ .name }}, the suffix is appended to the layout as well.
+// The suffix is applied after all other options, so the order of [WithName] and this option does not matter.
+//
+// Note that since the name changes with every process, a new process does not manage the logs of the previous ones.
+func WithUniqueSuffix() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		suffix, err := uniqueSuffix()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create unique suffix, caused by %w", err)
+		}
+		k.uniqueSuffix = suffix
 		return k, nil
 	}
 }
+
+// Get a suffix unique to the current process.
+func uniqueSuffix() (string, error) {
+	if hostname, err := os.Hostname(); err == nil && len(hostname) > 0 {
+		return fmt.Sprintf("%s-%d", normalizeName(hostname), os.Getpid()), nil
+	}
+	token := make([]byte, 4)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x-%d", token, os.Getpid()), nil
+}
+
+// Apply the suffix of [WithUniqueSuffix] to the name and the archive name layout.
+func (k *Keeper) applyUniqueSuffix() error {
+	if len(k.uniqueSuffix) == 0 {
+		return nil
+	}
+	suffix := "-" + k.uniqueSuffix
+	if !strings.HasSuffix(k.name, suffix) {
+		k.name += suffix
+	}
+	if k.archiveNameLayout == nil {
+		return nil
+	}
+	if name, err := k.renderArchiveName(time.Time{}, ""); err == nil && !strings.Contains(name, k.name) {
+		layout := k.archiveNameLayoutText + suffix
+		templ, err := template.New("lorekeeper-archive-template").Parse(layout)
+		if err != nil {
+			return fmt.Errorf("failed to set archive name layout, caused by %w", err)
+		}
+		k.archiveNameLayout = templ
+		k.archiveNameLayoutText = layout
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	preset := Options(
+		WithMaxSize(10*Mb),
+		WithMaxFiles(3),
+	)
+	k, err := New(
+		WithName("Test-Options"),
+		WithFolder(t.TempDir()),
+		preset,
+		WithMaxFiles(5),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if k.maxSize != 10*Mb {
+		t.Errorf("expected max size from the preset got %d", k.maxSize)
+	}
+	if k.maxFiles != 5 {
+		t.Errorf("expected max files to be overridden got %d", k.maxFiles)
+	}
+
+	if _, err := New(Options(WithCron("invalid"))); err == nil {
+		t.Errorf("expected error from a composed option")
+	}
+}
+
+func TestDefaultOptions(t *testing.T) {
+	k := new(Keeper)
+	var err error
+	for _, opt := range DefaultOptions() {
+		if k, err = opt(k); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if k.maxSize != 15*Mb || k.extension != ".log" {
+		t.Errorf("expected the default configuration got %+v", k)
+	}
+}
+
+func TestNewAggregatesErrors(t *testing.T) {
+	_, err := New(
+		WithName("Test-Aggregate-Errors"),
+		WithCron("invalid"),
+		WithGzipLevel(1000),
+		WithArchiveNameLayout("{{ .time"),
+	)
+	if err == nil {
+		t.Fatalf("expected error got nil")
+	}
+	for _, want := range []string{"cron", "compress", "archive name layout"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q got %v", want, err)
+		}
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Opt
+		wantErr     bool
+		wantWarning bool
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:    "invalid",
+			opts:    []Opt{WithCron("invalid")},
+			wantErr: true,
+		},
+		{
+			name:        "total size smaller than max size",
+			opts:        []Opt{WithMaxSize(10 * Mb), WithTotalSize(Mb)},
+			wantWarning: true,
+		},
+		{
+			name:        "archive name without time",
+			opts:        []Opt{WithArchiveNameLayout("{{ .name }}.old")},
+			wantWarning: true,
+		},
+		{
+			name:        "archive name without time across folders",
+			opts:        []Opt{WithFolders("a", "b"), WithArchiveNameLayout("{{ .name }}.old")},
+			wantWarning: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOptions(tt.opts...)
+			if gotWarning := errors.Is(err, ErrOptionWarning); gotWarning != tt.wantWarning {
+				t.Errorf("expected warning to be %t got %v", tt.wantWarning, err)
+			}
+			if gotErr := err != nil && !errors.Is(err, ErrOptionWarning); gotErr != tt.wantErr {
+				t.Errorf("expected error to be %t got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckOptionsNoSideEffect(t *testing.T) {
+	k, err := configureDetached(WithFolders("a", "b", "c"), WithArchiveNameLayout("{{ .name }}-{{ .time }}"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	k.checkOptions()
+	if k.nextFolder != 0 {
+		t.Errorf("expected the next archive folder to be left as is got %d", k.nextFolder)
+	}
+}
+
+func TestWithUniqueSuffix(t *testing.T) {
+	suffix, err := uniqueSuffix()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !strings.HasSuffix(suffix, fmt.Sprintf("-%d", os.Getpid())) {
+		t.Errorf("expected suffix to contain the pid got %s", suffix)
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Opt
+		wantName   string
+		wantLayout string
+	}{
+		{
+			name:       "suffix before name",
+			opts:       []Opt{WithUniqueSuffix(), WithName("unique")},
+			wantName:   "unique-" + suffix,
+			wantLayout: "{{ .time }}-{{ .name }}{{ .extension }}",
+		},
+		{
+			name:       "layout without name",
+			opts:       []Opt{WithName("unique"), WithArchiveNameLayout("{{ .time }}.log"), WithUniqueSuffix()},
+			wantName:   "unique-" + suffix,
+			wantLayout: "{{ .time }}.log-" + suffix,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := configureDetached(tt.opts...)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if k.name != tt.wantName {
+				t.Errorf("expected name %s got %s", tt.wantName, k.name)
+			}
+			if k.archiveNameLayoutText != tt.wantLayout {
+				t.Errorf("expected layout %s got %s", tt.wantLayout, k.archiveNameLayoutText)
+			}
+			// Applying the options again must not append the suffix twice
+			if _, err := configure(k, tt.opts...); err != nil || k.name != tt.wantName {
+				t.Errorf("expected name %s got %s, %v", tt.wantName, k.name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package lorekeeper
+
+// Paths are used as is outside of Windows.
+func normalizeFolder(folder string) string {
+	return folder
+}
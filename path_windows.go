@@ -0,0 +1,17 @@
+//go:build windows
+
+package lorekeeper
+
+import "path/filepath"
+
+// Make the folder absolute, so paths longer than MAX_PATH keep working.
+// The os package only adds the \\?\ prefix to absolute paths, relative paths are limited to MAX_PATH.
+// Drive-letter paths (C:\logs), UNC shares (\\server\share\logs),
+// and paths already in the \\?\ form are kept as is beside cleaning.
+func normalizeFolder(folder string) string {
+	abs, err := filepath.Abs(folder)
+	if err != nil {
+		return folder
+	}
+	return abs
+}
@@ -0,0 +1,82 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeeperWindowsPaths(t *testing.T) {
+	tests := []struct {
+		name        string
+		folder      string
+		wantCurrent string
+		wantPattern string
+	}{
+		{
+			name:        "drive letter",
+			folder:      `C:\logs`,
+			wantCurrent: `C:\logs\test-windows.log`,
+			wantPattern: `C:\logs\test-windows.log.*`,
+		},
+		{
+			name:        "drive letter with trailing separator",
+			folder:      `D:\var\logs\`,
+			wantCurrent: `D:\var\logs\test-windows.log`,
+			wantPattern: `D:\var\logs\test-windows.log.*`,
+		},
+		{
+			name:        "UNC share",
+			folder:      `\\server\share\logs`,
+			wantCurrent: `\\server\share\logs\test-windows.log`,
+			wantPattern: `\\server\share\logs\test-windows.log.*`,
+		},
+		{
+			name:        "long path prefix",
+			folder:      `\\?\C:\logs`,
+			wantCurrent: `\\?\C:\logs\test-windows.log`,
+			wantPattern: `\\?\C:\logs\test-windows.log.*`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := configureDetached(
+				WithFolder(tt.folder),
+				WithName("test-windows"),
+				WithArchiveNameLayout("{{ .name }}{{ .extension }}.{{ .time }}"),
+			)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if got := k.getCurrentFilePath(); got != tt.wantCurrent {
+				t.Errorf("getCurrentFilePath() = %q, want %q", got, tt.wantCurrent)
+			}
+			got, err := k.getArchiveGlobPattern()
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if got != tt.wantPattern {
+				t.Errorf("getArchiveGlobPattern() = %q, want %q", got, tt.wantPattern)
+			}
+			archive, err := k.newArchiveName(RotationManual)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if !strings.HasPrefix(archive, filepath.Clean(tt.folder)+`\`) {
+				t.Errorf("expected archive %q to be in folder %q", archive, tt.folder)
+			}
+		})
+	}
+}
+
+func TestNormalizeFolderRelative(t *testing.T) {
+	// Relative paths are made absolute so the os package can handle paths longer than MAX_PATH
+	folder := filepath.Join("logs", strings.Repeat("a", 200), strings.Repeat("b", 200))
+	got := normalizeFolder(folder)
+	if !filepath.IsAbs(got) {
+		t.Errorf("expected %q to be absolute", got)
+	}
+	if !strings.HasSuffix(got, folder) {
+		t.Errorf("expected %q to end with %q", got, folder)
+	}
+}
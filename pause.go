@@ -0,0 +1,81 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A PausePolicy decides what happens to the writes while a [Keeper] is paused, see [WithPausePolicy].
+type PausePolicy int
+
+const (
+	// Buffer the writes in memory and write them on resume.
+	PauseBuffer PausePolicy = iota
+	// Drop the writes.
+	PauseDrop
+)
+
+// Returned, wrapped, by the operations that are suspended while the Keeper is paused, see [Keeper.Pause].
+var ErrPaused = errors.New("keeper is paused")
+
+// Suspend all file operations of the Keeper until [Keeper.Resume] is called,
+// for example while a backup or a snapshot of the log volume is taken so that no file is renamed mid-copy.
+// While paused, writes are buffered or dropped according to [WithPausePolicy], and no rotation or pruning happens,
+// including the rotations scheduled by [WithCron].
+// Pausing a paused Keeper does nothing.
+func (k *Keeper) Pause() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.paused = true
+}
+
+// Resume the Keeper paused by [Keeper.Pause], writing the buffered messages to the log files in order.
+// Resuming a Keeper that is not paused does nothing.
+func (k *Keeper) Resume() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.resume()
+}
+
+func (k *Keeper) resume() error {
+	if !k.paused {
+		return nil
+	}
+	k.paused = false
+
+	buffer := k.pauseBuffer
+	k.pauseBuffer = nil
+	k.pauseBuffered = 0
+	// The buffers go back to the pool whether their messages were written or dropped,
+	// and the slice holding them is kept for the next pause
+	defer func() {
+		for _, buf := range buffer {
+			k.putPooledBuffer(buf)
+		}
+		clear(buffer)
+		if k.pauseBuffer == nil {
+			k.pauseBuffer = buffer[:0]
+		}
+	}()
+	for i, msg := range buffer {
+		if _, err := k.writeMessage(*msg); err != nil {
+			k.stats.Dropped += uint64(len(buffer) - i - 1)
+			return fmt.Errorf("failed to write buffered messages, caused by %w", err)
+		}
+	}
+	return nil
+}
+
+// Buffer or drop the msg according to the pause policy.
+func (k *Keeper) writePaused(msg []byte) (int, error) {
+	if k.pausePolicy == PauseDrop || (k.pauseMaxBuffer > 0 && k.pauseBuffered+len(msg) > k.pauseMaxBuffer) {
+		k.stats.Dropped++
+		return 0, fmt.Errorf("failed to write, message dropped, caused by %w", ErrPaused)
+	}
+	// The caller may reuse msg after this returns
+	buf := getPooledBuffer()
+	*buf = append(*buf, msg...)
+	k.pauseBuffer = append(k.pauseBuffer, buf)
+	k.pauseBuffered += len(msg)
+	return len(msg), nil
+}
@@ -0,0 +1,84 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeeperPause(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      PausePolicy
+		maxBuffered int
+		want        string
+		wantDropped uint64
+	}{
+		{
+			name:        "buffer",
+			policy:      PauseBuffer,
+			maxBuffered: 0,
+			want:        "abcdef",
+		},
+		{
+			name:        "buffer with limit",
+			policy:      PauseBuffer,
+			maxBuffered: 2,
+			want:        "abcef",
+			wantDropped: 1,
+		},
+		{
+			name:        "drop",
+			policy:      PauseDrop,
+			want:        "aef",
+			wantDropped: 2,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			k, err := New(
+				WithFolder(folder),
+				WithName(fmt.Sprintf("test-pause-%d", i)),
+				WithPausePolicy(tt.policy, tt.maxBuffered),
+			)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			defer k.Close()
+
+			if _, err := k.Write([]byte("a")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			k.Pause()
+			for _, msg := range []string{"bc", "d"} {
+				_, _ = k.Write([]byte(msg))
+			}
+			if err := k.Rotate(); !errors.Is(err, ErrPaused) {
+				t.Errorf("expected %v got %v", ErrPaused, err)
+			}
+			if err := k.Resume(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if _, err := k.Write([]byte("ef")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(folder, fmt.Sprintf("test-pause-%d.log", i)))
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("expected %q got %q", tt.want, content)
+			}
+			if got := k.Stats().Dropped; got != tt.wantDropped {
+				t.Errorf("expected %d dropped messages got %d", tt.wantDropped, got)
+			}
+			if k.archives.Length() != 0 {
+				t.Errorf("expected no rotation while paused got %d archives", k.archives.Length())
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+)
+
+// The default mode of the log files, see [WithFileMode].
+const defaultFileMode os.FileMode = 0644
+
+// Create the folder of the current log file and the archive folders, along with their missing parents,
+// with the given permissions when the Keeper is created, instead of failing if they do not exist,
+// which is convenient in containers with ephemeral volumes.
+// The permissions are subject to the umask of the process. Set 0 to disable, is disabled by default.
+// The folders of [WithTenant] are always created.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithCreateFolder(0750))
+func WithCreateFolder(perm os.FileMode) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if perm&^os.ModePerm != 0 {
+			return nil, fmt.Errorf("failed to set folder creation, %v is not a permission", perm)
+		}
+		k.createFolderMode = perm
+		return k, nil
+	}
+}
+
+// Set the permissions of the log files created by the Keeper, the current log file and its archives,
+// instead of 0644, such as 0600 for logs that only the service may read.
+// The permissions are subject to the umask of the process, and the existing files are left as they are.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithFileMode(0600))
+func WithFileMode(perm os.FileMode) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if perm == 0 || perm&^os.ModePerm != 0 {
+			return nil, fmt.Errorf("failed to set file mode, %v is not a valid permission", perm)
+		}
+		k.fileMode = perm
+		return k, nil
+	}
+}
+
+// Make the archives read-only once rotated and compressed, removing the write permissions of [WithFileMode],
+// so that the history is protected from accidental modification, such as by the application itself.
+// The retention still removes the expired archives, since removing a file depends on the permissions of its folder.
+// The immutable attribute of some filesystems, such as chattr +i on Linux, is not set,
+// since it requires privileges and would prevent the retention from removing the archives.
+// Is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithReadOnlyArchives())
+func WithReadOnlyArchives() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.readOnlyArchives = true
+		return k, nil
+	}
+}
+
+// Keep the archives writable, this is the default.
+func NoReadOnlyArchives() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.readOnlyArchives = false
+		return k, nil
+	}
+}
+
+// Remove the write permissions of a rotated archive, see [WithReadOnlyArchives].
+func (k *Keeper) sealArchive(path string) error {
+	if !k.readOnlyArchives {
+		return nil
+	}
+	if err := k.fsys.Chmod(path, k.fileMode&^0222); err != nil {
+		return fmt.Errorf("failed to make archive %q read-only, caused by %w", path, err)
+	}
+	return nil
+}
+
+// Create the folders of the Keeper if it owns them, see [WithCreateFolder] and [WithTenant].
+func (k *Keeper) createFolder() error {
+	mode := k.createFolderMode
+	if k.folderMode != 0 {
+		mode = k.folderMode
+	}
+	if mode == 0 {
+		return nil
+	}
+	for _, folder := range k.getArchiveFolders() {
+		if err := k.fsys.MkdirAll(folder, mode); err != nil {
+			return fmt.Errorf("failed to create folder %s, caused by %w", folder, err)
+		}
+	}
+	return nil
+}
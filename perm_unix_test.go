@@ -0,0 +1,68 @@
+//go:build unix
+
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWithCreateFolderAndFileMode(t *testing.T) {
+	// The permissions are subject to the umask
+	umask := syscall.Umask(0)
+	defer syscall.Umask(umask)
+
+	root := t.TempDir()
+	folder := filepath.Join(root, "missing", "logs")
+	archiveFolder := filepath.Join(root, "missing", "archives")
+	k, err := New(
+		WithFolders(folder, archiveFolder),
+		WithName("test-create-folder"),
+		WithCreateFolder(0750),
+		WithFileMode(0600),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, dir := range []string{folder, archiveFolder} {
+		stat, err := os.Stat(dir)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if stat.Mode().Perm() != 0750 {
+			t.Errorf("expected folder %q with mode 0750 got %v", dir, stat.Mode().Perm())
+		}
+	}
+
+	if _, err := k.Write([]byte("private\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	paths := []string{filepath.Join(folder, "test-create-folder.log")}
+	for _, archive := range k.Archives() {
+		paths = append(paths, archive.Path)
+	}
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if stat.Mode().Perm() != 0600 {
+			t.Errorf("expected file %q with mode 0600 got %v", path, stat.Mode().Perm())
+		}
+	}
+
+	if _, err := New(WithFolder(filepath.Join(root, "not-created")), WithName("test-create-folder-missing")); err == nil {
+		t.Errorf("expected error for a missing folder got nil")
+	}
+	if _, err := New(WithFolder(root), WithName("test-file-mode-invalid"), WithFileMode(0)); err == nil {
+		t.Errorf("expected error for an empty file mode got nil")
+	}
+}
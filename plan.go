@@ -0,0 +1,220 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A PlanAction is the file operation of a [PlanStep].
+type PlanAction string
+
+const (
+	// The file is renamed to the target, such as the current log file becoming an archive.
+	PlanRename PlanAction = "rename"
+	// The file is compressed into the target, then removed.
+	PlanCompress PlanAction = "compress"
+	// The archive is removed by the retention.
+	PlanRemove PlanAction = "remove"
+)
+
+// A PlanStep is a file operation that a rotation or the retention would perform, see [Plan].
+type PlanStep struct {
+	// The operation on the file.
+	Action PlanAction `json:"action"`
+	// The path of the file.
+	Path string `json:"path"`
+	// The path the file is renamed or compressed to, empty for a removal.
+	Target string `json:"target,omitempty"`
+	// The size of the file in bytes.
+	Size int `json:"size"`
+	// The retention limit that expires the archive, empty unless it is removed.
+	Policy DeletionPolicy `json:"policy,omitempty"`
+	// A human readable explanation of the removal, such as "12 archives over the max files of 10".
+	Reason string `json:"reason,omitempty"`
+}
+
+func (s PlanStep) String() string {
+	switch s.Action {
+	case PlanRemove:
+		return fmt.Sprintf("remove %s (%s)", s.Path, s.Reason)
+	default:
+		return fmt.Sprintf("%s %s to %s", s.Action, s.Path, s.Target)
+	}
+}
+
+// A Plan lists the file operations of a rotation or of the retention in the order they would run,
+// such as to preview a configuration in a dry run, or to audit what a Keeper is about to remove.
+// See [Keeper.PlanRotation] and [Keeper.PlanPrune].
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+}
+
+// Get the removals of the plan.
+func (p Plan) Removals() []PlanStep {
+	var removals []PlanStep
+	for _, step := range p.Steps {
+		if step.Action == PlanRemove {
+			removals = append(removals, step)
+		}
+	}
+	return removals
+}
+
+func (p Plan) String() string {
+	steps := make([]string, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		steps = append(steps, step.String())
+	}
+	return strings.Join(steps, "\n")
+}
+
+// Get the [Plan] of a rotation of the current log file happening now, without touching any file:
+// the current log file renamed to its archive, the compression of the archive if any,
+// then the archives that the retention would remove once the archive is added.
+// The compressed size is not known before the compression, so the retention is planned with the uncompressed size,
+// and the plan may remove more archives than the rotation does.
+// The rotations skipped by [WithSkipEmptyRotation] or held by [Keeper.WithRotationPaused] are planned anyway.
+//
+// Example usage:
+//
+//	plan, err := keeper.PlanRotation()
+//	if err != nil {
+//		return err
+//	}
+//	for _, step := range plan.Removals() {
+//		fmt.Println("would remove", step.Path, "because", step.Reason)
+//	}
+func (k *Keeper) PlanRotation() (Plan, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	archiveName, err := k.archivePathAt(k.archiveNameTime(), RotationManual)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to plan rotation, caused by %w", err)
+	}
+	size := k.currentFileSize
+	if k.compressedCurrent {
+		archiveName = k.compressedArchivePath(archiveName)
+	}
+	steps := []PlanStep{{Action: PlanRename, Path: k.getCurrentFilePath(), Target: archiveName, Size: size}}
+	if k.compressor != nil && !k.compressedCurrent {
+		compressedName := k.compressedArchivePath(archiveName)
+		steps = append(steps, PlanStep{Action: PlanCompress, Path: archiveName, Target: compressedName, Size: size})
+		archiveName = compressedName
+	}
+
+	archives := append(k.archiveList(), &fileInfo{filePath: archiveName, size: size, modtime: k.now()})
+	for _, e := range k.planExpiry(archives, k.diskDeficit(), k.now()) {
+		steps = append(steps, e.step())
+	}
+	return Plan{Steps: steps}, nil
+}
+
+// Get the [Plan] of the retention as it stands now, without removing any archive:
+// the archives expired by [WithMaxFiles], [WithTotalSize], [WithMaxAge], [WithRetentionPolicy], or [WithMinDiskFree],
+// in the order they would be removed.
+// The archives of a [Group] are planned against the limits of the Keeper only.
+func (k *Keeper) PlanPrune() Plan {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var steps []PlanStep
+	for _, e := range k.planExpiry(k.archiveList(), k.diskDeficit(), k.now()) {
+		steps = append(steps, e.step())
+	}
+	return Plan{Steps: steps}
+}
+
+// An archive that the retention expires.
+type expiry struct {
+	archive *fileInfo
+	policy  DeletionPolicy
+	reason  string
+}
+
+func (e expiry) step() PlanStep {
+	return PlanStep{Action: PlanRemove, Path: e.archive.filePath, Size: e.archive.size, Policy: e.policy, Reason: e.reason}
+}
+
+// Get the archives that the retention expires at the given time, given the missing disk space:
+// the oldest ones evicted by [TotalSizePolicy], [MaxFilesPolicy] and [MaxAgePolicy] for the limits of the Keeper,
+// then the ones evicted by the retention policy, then the oldest ones left for the disk space.
+func (k *Keeper) planExpiry(archives []*fileInfo, deficit int64, now time.Time) []expiry {
+	infos := make([]ArchiveInfo, len(archives))
+	size := 0
+	for i, archive := range archives {
+		infos[i] = k.retentionInfo(archive)
+		size += archive.size
+	}
+	count := len(archives)
+	var expired []expiry
+	add := func(e expiry) {
+		size -= e.archive.size
+		count--
+		deficit -= int64(e.archive.size)
+		expired = append(expired, e)
+	}
+
+	// Each limit evicts the oldest archives, an archive is expired by the first limit it is over
+	bySize := len(TotalSizePolicy(k.totalSize).Evict(infos))
+	byFiles := len(MaxFilesPolicy(k.maxFiles).Evict(infos))
+	byAge := len(MaxAgePolicy{MaxAge: k.maxAge, Now: func() time.Time { return now }}.Evict(infos))
+	oldest := max(bySize, byFiles, byAge)
+	for i, archive := range archives[:oldest] {
+		e := expiry{archive: archive}
+		switch {
+		case i < bySize:
+			e.policy, e.reason = DeletionTotalSize, fmt.Sprintf("%d bytes of archives over the total size of %d", size, k.totalSize)
+		case i < byFiles:
+			e.policy, e.reason = DeletionMaxFiles, fmt.Sprintf("%d archives over the max files of %d", count, k.maxFiles)
+		default:
+			age := now.Sub(infos[i].ModTime)
+			e.policy, e.reason = DeletionMaxAge, fmt.Sprintf("%s old over the max age of %s", age.Truncate(time.Second), k.maxAge)
+		}
+		add(e)
+	}
+
+	remaining := archives[oldest:]
+	if evicted := k.evictedByPolicy(remaining, infos[oldest:]); len(evicted) > 0 {
+		reason := k.retentionPolicyReason()
+		var kept []*fileInfo
+		for _, archive := range remaining {
+			if evicted[archive] {
+				add(expiry{archive: archive, policy: DeletionRetentionPolicy, reason: reason})
+				continue
+			}
+			kept = append(kept, archive)
+		}
+		remaining = kept
+	}
+
+	for _, archive := range remaining {
+		if deficit <= 0 {
+			break
+		}
+		add(expiry{archive: archive, policy: DeletionDiskFree, reason: fmt.Sprintf("%d bytes short of the min disk free", deficit)})
+	}
+	return expired
+}
+
+// Get the archives from oldest to newest.
+func (k *Keeper) archiveList() []*fileInfo {
+	archives := make([]*fileInfo, 0, k.archives.Length())
+	for _, archive := range k.archives.All() {
+		archives = append(archives, archive)
+	}
+	return archives
+}
+
+// Get the path of a new archive rotated at the given time for the given reason, in the next archive folder.
+func (k *Keeper) archivePathAt(t time.Time, reason RotationReason) (string, error) {
+	name, err := k.renderArchiveName(t, reason)
+	if err != nil {
+		return "", err
+	}
+	// Round-robin archives across all the archive folders
+	folders := k.getArchiveFolders()
+	return filepath.Join(folders[k.nextFolder%len(folders)], name), nil
+}
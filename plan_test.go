@@ -0,0 +1,91 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanRotation(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-plan-rotation"),
+		WithMaxSize(0),
+		WithMaxFiles(2),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for range 2 {
+		if _, err := k.Write([]byte("abc\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if _, err := k.Write([]byte("def\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archives := k.Archives()
+
+	plan, err := k.PlanRotation()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(plan.Steps) != 3 {
+		t.Fatalf("expected 3 steps got %v", plan)
+	}
+	rename, compress, remove := plan.Steps[0], plan.Steps[1], plan.Steps[2]
+	if rename.Action != PlanRename || rename.Path != filepath.Join(folder, "test-plan-rotation.log") || rename.Size != 4 {
+		t.Errorf("expected the current log file to be renamed got %v", rename)
+	}
+	if compress.Action != PlanCompress || compress.Path != rename.Target || compress.Target != rename.Target+".gz" {
+		t.Errorf("expected the archive to be compressed got %v", compress)
+	}
+	if remove.Action != PlanRemove || remove.Path != archives[0].Path || remove.Policy != DeletionMaxFiles {
+		t.Errorf("expected the oldest archive to be removed got %v", remove)
+	}
+
+	// Planning touches no file
+	if got := len(k.Archives()); got != 2 {
+		t.Errorf("expected 2 archives got %d", got)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	deletions := k.Deletions()
+	if len(deletions) != 1 || deletions[0].Path != remove.Path || deletions[0].Reason != remove.Reason {
+		t.Errorf("expected the planned removal got %v", deletions)
+	}
+}
+
+func TestPlanPrune(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-plan-prune"), WithMaxSize(0))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for range 3 {
+		if _, err := k.Write([]byte("abc\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if plan := k.PlanPrune(); len(plan.Steps) != 0 {
+		t.Errorf("expected an empty plan got %v", plan)
+	}
+
+	// Lowered without pruning, which reconfiguring would do
+	k.totalSize = 8
+	plan := k.PlanPrune()
+	archives := k.Archives()
+	if len(plan.Steps) != 1 || plan.Steps[0].Path != archives[0].Path || plan.Steps[0].Policy != DeletionTotalSize {
+		t.Errorf("expected the oldest archive to be removed for the total size got %v", plan)
+	}
+}
@@ -0,0 +1,50 @@
+package lorekeeper
+
+import "fmt"
+
+// A LayoutPreset is a ready-made naming scheme for the archives, see [WithArchiveLayoutPreset].
+// Every preset includes the name of the Keeper and a timestamp down to the nanosecond, so archive names do not collide.
+type LayoutPreset int
+
+const (
+	// Append the date of the rotation after the extension, like the dateext option of logrotate,
+	// for example "app.log-2024-01-02T15-04-05.000000000".
+	LayoutDatedSuffix LayoutPreset = iota + 1
+	// Number the archives with the time of the rotation as an increasing number,
+	// for example "app.20240102150405.000000000.log".
+	LayoutNumbered
+	// Name the archives like the kubelet rotates container logs, for example "app.log.20240102-150405.000000000",
+	// so that log collectors configured for Kubernetes pick them up.
+	LayoutKubernetes
+)
+
+// Get the archive name layout and the time layout of the preset.
+func (p LayoutPreset) layouts() (archiveNameLayout, timeLayout string, ok bool) {
+	switch p {
+	case LayoutDatedSuffix:
+		return "{{ .name }}{{ .extension }}-{{ .time }}", "2006-01-02T15-04-05.000000000", true
+	case LayoutNumbered:
+		return "{{ .name }}.{{ .time }}{{ .extension }}", "20060102150405.000000000", true
+	case LayoutKubernetes:
+		return "{{ .name }}{{ .extension }}.{{ .time }}", "20060102-150405.000000000", true
+	default:
+		return "", "", false
+	}
+}
+
+// Name the archives with the given preset, setting both the archive name layout and the time layout,
+// so that sensible and collision-free names do not require learning the template syntax,
+// see [WithArchiveNameLayout] and [WithTimeLayout] for a custom naming scheme.
+func WithArchiveLayoutPreset(p LayoutPreset) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		archiveNameLayout, timeLayout, ok := p.layouts()
+		if !ok {
+			return nil, fmt.Errorf("failed to set archive layout preset, unknown preset %d", p)
+		}
+		k, err := WithArchiveNameLayout(archiveNameLayout)(k)
+		if err != nil {
+			return nil, err
+		}
+		return WithTimeLayout(timeLayout)(k)
+	}
+}
@@ -0,0 +1,59 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithArchiveLayoutPreset(t *testing.T) {
+	tests := []struct {
+		preset   LayoutPreset
+		expected string
+	}{
+		{LayoutDatedSuffix, "test-preset.log-2024-01-02T15-04-05.000000000"},
+		{LayoutNumbered, "test-preset.20240102150405.000000000.log"},
+		{LayoutKubernetes, "test-preset.log.20240102-150405.000000000"},
+	}
+	for _, tt := range tests {
+		now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+		k, err := New(
+			WithFolder(t.TempDir()),
+			WithName("test-preset"),
+			WithArchiveLayoutPreset(tt.preset),
+			WithNowFunc(func() time.Time { return now }),
+		)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if _, err := k.Write([]byte("hello\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		now = now.Add(time.Second)
+		archives := k.Archives()
+		if len(archives) != 1 || filepath.Base(archives[0].Path) != tt.expected {
+			t.Errorf("expected archive %q got %+v", tt.expected, archives)
+		}
+		// The archives are found again by a new Keeper
+		if err := k.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		k, err = New(WithFolder(k.Folder()), WithName("test-preset"), WithArchiveLayoutPreset(tt.preset))
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if len(k.Archives()) != 2 {
+			t.Errorf("expected 2 archives got %d", len(k.Archives()))
+		}
+		if err := k.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	if _, err := New(WithArchiveLayoutPreset(LayoutPreset(0))); err == nil {
+		t.Errorf("expected an error for an unknown preset")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !(unix || windows)
+
+package lorekeeper
+
+// Checking a process is not supported on this platform, such as plan9, so the process is taken as running,
+// and its entry in the lock file of [WithLockFile] is kept until it releases it.
+func processAlive(pid int) bool {
+	return true
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package lorekeeper
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Check whether the process of the given PID is running, with the null signal of kill(2).
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	// A process of another user can not be signaled, but it is running
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package lorekeeper
+
+import "os"
+
+// Check whether the process of the given PID is running, finding a process opens a handle to it on Windows.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
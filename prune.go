@@ -0,0 +1,77 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// Remove the archives expired by the retention, see planExpiry.
+// Archives are removed concurrently, and a failed removal does not stop the others.
+// Archives that already disappeared count as removed,
+// while the archives that could not be removed, such as permission-locked ones, are kept so that the next rotation retries them.
+func (k *Keeper) prune() error {
+	if k.holdPrune() {
+		return nil
+	}
+	archives := k.archiveList()
+	planned := k.planExpiry(archives, k.diskDeficit(), k.now())
+	if len(planned) == 0 {
+		return nil
+	}
+	removed := make(map[*fileInfo]bool, len(planned))
+	expired := make([]*fileInfo, 0, len(planned))
+	for _, e := range planned {
+		removed[e.archive] = true
+		k.archivesSize -= e.archive.size
+		expired = append(expired, expire(e.archive, e.policy, e.reason))
+	}
+	k.archives = keptArchives(archives, removed)
+	if k.deferredRemoval {
+		expired = k.deferOpenArchives(expired)
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(expired))
+	k.runBackground(len(expired), func(i int) {
+		errs[i] = removeArchive(k.fsys, expired[i])
+	})
+	var failures []error
+	for i, err := range errs {
+		if err != nil {
+			// Kept in place
+			delete(removed, expired[i])
+			failures = append(failures, err)
+			k.archivesSize += expired[i].size
+			continue
+		}
+		k.recordDeletion(expired[i])
+	}
+	k.stats.RemovedArchives += uint64(len(expired) - len(failures))
+	k.stats.RemoveErrors += uint64(len(failures))
+	if len(failures) > 0 {
+		k.archives = keptArchives(archives, removed)
+		return fmt.Errorf(
+			"failed to remove %d of %d expired archives, caused by %w",
+			len(failures), len(expired), errors.Join(failures...),
+		)
+	}
+	return nil
+}
+
+func removeArchive(fsys FS, archive *fileInfo) error {
+	err := fsys.Remove(archive.filePath)
+	// Read-only files can not be removed on Windows, see WithReadOnlyArchives
+	if errors.Is(err, fs.ErrPermission) && fsys.Chmod(archive.filePath, 0600) == nil {
+		err = fsys.Remove(archive.filePath)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove archive with path %q, caused by %w", archive.filePath, err)
+	}
+	removeChecksums(fsys, archive.filePath)
+	// The upload of ResumableUploader can not be resumed without its archive
+	_ = fsys.Remove(archive.filePath + uploadStateExt)
+	return nil
+}
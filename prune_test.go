@@ -0,0 +1,123 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeeperPrune(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-prune"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// Simulate a huge folder right after enabling the retention
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	modtime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 20 {
+		path := filepath.Join(folder, fmt.Sprintf("%02d-test-prune.log", i))
+		if err := os.WriteFile(path, []byte("a"), 0644); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		k.archives.Append(&fileInfo{filePath: path, size: 1, modtime: modtime.Add(time.Duration(i) * time.Minute)})
+		k.archivesSize++
+	}
+	// These archives can not be removed, since they are non-empty folders
+	for _, i := range []int{3, 7} {
+		path := filepath.Join(folder, fmt.Sprintf("%02d-test-prune.log", i))
+		if err := os.Remove(path); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := os.MkdirAll(filepath.Join(path, "locked"), 0755); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	k.maxFiles = 5
+	if err := k.prune(); err == nil {
+		t.Errorf("expected error since 2 archives can not be removed")
+	}
+	if k.stats.RemovedArchives != 13 || k.stats.RemoveErrors != 2 {
+		t.Errorf("expected 13 removed and 2 errors got %+v", k.stats)
+	}
+
+	// The failed archives are kept in order, oldest first, to be retried on the next rotation
+	var names []string
+	for _, archive := range k.archives.All() {
+		names = append(names, filepath.Base(archive.filePath))
+	}
+	want := []string{"03-test-prune.log", "07-test-prune.log", "15-test-prune.log", "16-test-prune.log", "17-test-prune.log", "18-test-prune.log", "19-test-prune.log"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("expected %v got %v", want, names)
+	}
+	if k.archivesSize != 7 {
+		t.Errorf("expected archives size 7 got %d", k.archivesSize)
+	}
+	for i := range 15 {
+		_, err := os.Stat(filepath.Join(folder, fmt.Sprintf("%02d-test-prune.log", i)))
+		if exists := err == nil; exists != (i == 3 || i == 7) {
+			t.Errorf("unexpected existence %v of archive %d", exists, i)
+		}
+	}
+}
+
+func TestKeeperPruneSkipsFailures(t *testing.T) {
+	folder := t.TempDir()
+	var handled []error
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-prune-skip"),
+		WithErrorHandler(func(err error) { handled = append(handled, err) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for range 3 {
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	archives := k.Archives()
+	// The oldest archive disappeared, the second one is locked
+	if err := os.Remove(archives[0].Path); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := os.Remove(archives[1].Path); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(archives[1].Path, "locked"), 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	k.mu.Lock()
+	k.maxFiles = 1
+	k.mu.Unlock()
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected the rotation to succeed got %v", err)
+	}
+	if len(handled) != 1 {
+		t.Fatalf("expected 1 handled error got %v", handled)
+	}
+	stats := k.Stats()
+	if stats.RemovedArchives != 2 || stats.RemoveErrors != 1 {
+		t.Errorf("expected 2 removed and 1 error got %+v", stats)
+	}
+	got := k.Archives()
+	if len(got) != 2 || got[0].Path != archives[1].Path {
+		t.Errorf("expected the locked archive to be kept got %+v", got)
+	}
+	if err := os.RemoveAll(archives[1].Path); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"regexp"
+	"time"
+
+	"github.com/trviph/collection"
+)
+
+// Iterate over the records written between from and to, both inclusive, from the oldest to the newest.
+// The timestamps in the archive names are used to skip the log files that can not contain such records,
+// and the parser of [WithTimestampParser], if any, to trim the records within the remaining log files.
+//
+// Records are the lines of the log files, grouped by [WithRecordPattern] if set,
+// or the records of [WithLengthPrefixedRecords] if set. A record keeps its trailing new line.
+// The log files are selected when this is called, files removed by the retention afterward are skipped.
+//
+// Example usage:
+//
+//	for record, err := range keeper.Between(time.Now().Add(-time.Hour), time.Now()) {
+//		if err != nil {
+//			return err
+//		}
+//		os.Stdout.Write(record)
+//	}
+func (k *Keeper) Between(from, to time.Time) iter.Seq2[[]byte, error] {
+	k.mu.Lock()
+	parse := k.timestampParser
+	k.mu.Unlock()
+	return trimRecords(k.recordsBetween(from, to), parse, from, to)
+}
+
+// Leave out the records whose timestamp, if it can be parsed, is not between from and to.
+func trimRecords(records iter.Seq2[[]byte, error], parse func([]byte) (time.Time, error), from, to time.Time) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		for record, err := range records {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if parse != nil {
+				if t, err := parse(record); err == nil && (t.Before(from) || t.After(to)) {
+					continue
+				}
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over all the records of the log files that may contain records written between from and to,
+// without trimming the records within the log files.
+func (k *Keeper) recordsBetween(from, to time.Time) iter.Seq2[[]byte, error] {
+	k.mu.Lock()
+	paths := k.pathsBetween(from, to)
+	k.mu.Unlock()
+	return k.recordsOf(paths)
+}
+
+// Iterate over all the records of the log files at paths, in order.
+func (k *Keeper) recordsOf(paths []string) iter.Seq2[[]byte, error] {
+	k.mu.Lock()
+	decompressors := make([]Compressor, len(paths))
+	for i, path := range paths {
+		decompressors[i] = k.logFileDecompressor(path)
+	}
+	lengthPrefixed, pattern, cache, fsys := k.lengthPrefixed, k.recordPattern, k.readerCache, k.fsys
+	k.mu.Unlock()
+
+	return func(yield func([]byte, error) bool) {
+		for i, path := range paths {
+			k.acquireHandle(path)
+			reader, err := cache.open(fsys, path, decompressors[i])
+			if errors.Is(err, fs.ErrNotExist) {
+				k.releaseHandle(path)
+				continue
+			}
+			if err != nil {
+				k.releaseHandle(path)
+				yield(nil, fmt.Errorf("failed to open %q, caused by %w", path, err))
+				return
+			}
+			reader = &trackedReader{ReadCloser: reader, release: func() { k.releaseHandle(path) }}
+			for record, err := range readRecords(reader, lengthPrefixed, pattern) {
+				if err != nil {
+					reader.Close()
+					yield(nil, fmt.Errorf("failed to read %q, caused by %w", path, err))
+					return
+				}
+				if !yield(record, nil) {
+					reader.Close()
+					return
+				}
+			}
+			reader.Close()
+		}
+	}
+}
+
+// Get the paths of the log files that may contain records written between from and to.
+// An archive contains the records written between the previous rotation and its own rotation,
+// and the current log file the records written since the last rotation.
+func (k *Keeper) pathsBetween(from, to time.Time) []string {
+	return k.logFilesBetween(k.archives, from, to)
+}
+
+// Get the paths of the archives, followed by the current log file, that may contain records written between from and to.
+func (k *Keeper) logFilesBetween(archives *collection.List[*fileInfo], from, to time.Time) []string {
+	var paths []string
+	var previous time.Time
+	for _, archive := range archives.All() {
+		rotated := k.archiveTime(archive)
+		if !rotated.Before(from) && (previous.IsZero() || !previous.After(to)) {
+			paths = append(paths, archive.filePath)
+		}
+		previous = rotated
+	}
+	if previous.IsZero() || !previous.After(to) {
+		paths = append(paths, k.getCurrentFilePath())
+	}
+	return paths
+}
+
+// Get the rotation time of the archive from its name, falling back to its modification time
+// if the name does not contain a timestamp.
+func (k *Keeper) archiveTime(archive *fileInfo) time.Time {
+	meta, err := k.parseArchiveName(archive.filePath)
+	if err != nil || meta.Time.IsZero() {
+		return archive.modtime
+	}
+	return meta.Time
+}
+
+// Iterate over the records of r, see [Keeper.Between] for what a record is.
+func readRecords(r io.Reader, lengthPrefixed bool, pattern *regexp.Regexp) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		if lengthPrefixed {
+			reader := NewRecordReader(r)
+			for {
+				record, err := reader.Next()
+				if err == io.EOF {
+					return
+				}
+				if !yield(record, err) || err != nil {
+					return
+				}
+			}
+		}
+
+		reader := bufio.NewReader(r)
+		var record []byte
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				yield(nil, err)
+				return
+			}
+			if len(line) > 0 {
+				if pattern == nil || (len(record) > 0 && pattern.Match(firstLine(line))) {
+					if len(record) > 0 && !yield(record, nil) {
+						return
+					}
+					record = nil
+				}
+				record = append(record, line...)
+			}
+			if err == io.EOF {
+				if len(record) > 0 {
+					yield(record, nil)
+				}
+				return
+			}
+		}
+	}
+}
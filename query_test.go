@@ -0,0 +1,92 @@
+package lorekeeper
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestKeeperBetween(t *testing.T) {
+	clock := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-between"),
+		WithNowFunc(func() time.Time { return clock }),
+		WithTimestampParser(func(record []byte) (time.Time, error) {
+			return time.Parse(time.RFC3339, string(record[:20]))
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// Each archive contains one record written before its rotation
+	for _, step := range []struct {
+		record string
+		rotate time.Duration
+	}{
+		{record: "2000-01-01T00:00:00Z a\n", rotate: time.Hour},
+		{record: "2000-01-01T01:30:00Z b\n", rotate: time.Hour},
+		{record: "2000-01-01T02:30:00Z c\n"},
+	} {
+		if _, err := k.Write([]byte(step.record)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if step.rotate > 0 {
+			clock = clock.Add(step.rotate)
+			if err := k.Rotate(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+	}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2000, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name      string
+		from, to  time.Time
+		wantFiles int
+		want      []string
+	}{
+		{
+			name:      "within one archive",
+			from:      at(1, 15),
+			to:        at(1, 45),
+			wantFiles: 1,
+			want:      []string{"2000-01-01T01:30:00Z b\n"},
+		},
+		{
+			name:      "trimmed within files",
+			from:      at(1, 15),
+			to:        at(2, 15),
+			wantFiles: 2,
+			want:      []string{"2000-01-01T01:30:00Z b\n"},
+		},
+		{
+			name:      "everything",
+			from:      at(0, 0),
+			to:        at(3, 0),
+			wantFiles: 3,
+			want:      []string{"2000-01-01T00:00:00Z a\n", "2000-01-01T01:30:00Z b\n", "2000-01-01T02:30:00Z c\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(k.pathsBetween(tt.from, tt.to)); got != tt.wantFiles {
+				t.Errorf("expected %d files got %d", tt.wantFiles, got)
+			}
+			var got []string
+			for record, err := range k.Between(tt.from, tt.to) {
+				if err != nil {
+					t.Fatalf("expected no error got %v", err)
+				}
+				got = append(got, string(record))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %q got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Returned, wrapped, by [Keeper.Write] when the message does not fit in the quota, see [WithQuota].
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Cap the combined size of the current log file and the archives to size bytes,
+// so that logging can not grow without bound, such as with the default options which delete no archive.
+// The quota is a hard limit: the retention of [WithMaxFiles], [WithTotalSize] and [WithMinDiskFree] still runs on rotation,
+// but once it can free nothing more, the messages that do not fit go to the fallback writer of [WithFallbackWriter],
+// or fail with an error wrapping [ErrQuotaExceeded] if there is none.
+// The rejected messages are counted in [Stats.QuotaRejections].
+// Set size < 1 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithQuota(10 * lorekeeper.Gb))
+//	if _, err := keeper.Write(msg); errors.Is(err, lorekeeper.ErrQuotaExceeded) {
+//		// Alert on the lost message
+//	}
+func WithQuota(size int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.quota = max(size, 0)
+		return k, nil
+	}
+}
+
+// Check whether writing n more bytes would exceed the quota of [WithQuota].
+func (k *Keeper) quotaExceeded(n int) bool {
+	return k.quota > 0 && k.currentFileSize+k.archivesSize+n > k.quota
+}
+
+// Get the error of a message of n bytes rejected by the quota of [WithQuota].
+func (k *Keeper) quotaError(n int) error {
+	k.stats.QuotaRejections++
+	return fmt.Errorf(
+		"failed to write %d bytes to current log file, the log files use %d of the %d bytes of the quota, caused by %w",
+		n, k.currentFileSize+k.archivesSize, k.quota, ErrQuotaExceeded,
+	)
+}
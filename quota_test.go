@@ -0,0 +1,54 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeeperWithQuota(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-quota"),
+		WithMaxSize(10),
+		WithQuota(25),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// Two archives and the current log file fill the quota
+	for range 2 {
+		if _, err := k.Write([]byte("012345678\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	_, err = k.Write([]byte("01234\n"))
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected error %v got %v", ErrQuotaExceeded, err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "write 6 bytes") || !strings.Contains(msg, "use 20 of the 25 bytes") {
+		t.Errorf("expected the size of the message, the usage and the quota in %q", msg)
+	}
+	if _, err := k.Write([]byte("0123\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	stats := k.Stats()
+	if stats.QuotaRejections != 1 || stats.Dropped != 1 {
+		t.Errorf("expected 1 rejected and dropped message got %+v", stats)
+	}
+
+	// The rejected messages go to the fallback writer if any
+	var fallback bytes.Buffer
+	if _, err := Options(WithFallbackWriter(&fallback))(k); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if fallback.String() != "abc\n" {
+		t.Errorf("expected fallback to contain %q got %q", "abc\n", fallback.String())
+	}
+}
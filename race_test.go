@@ -0,0 +1,6 @@
+//go:build race
+
+package lorekeeper
+
+// The race detector makes sync.Pool drop items at random, so pooling can not be measured.
+const raceEnabled = true
@@ -0,0 +1,147 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Returned, wrapped, by [Keeper.Write] when the message is dropped by the rate limit, see [WithRateLimit].
+var ErrRateLimited = errors.New("rate limited")
+
+// The token bucket of [WithRateLimit], with a lock of its own so that the throttled writers wait
+// without holding the lock of the Keeper.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	policy DropPolicy
+	now    func() time.Time
+	// The bytes that can be written right away, negative while the writers wait for them
+	tokens  float64
+	updated time.Time
+	// The counters of the Keeper, so that they survive the limiter
+	throttled     *atomic.Uint64
+	throttledTime *atomic.Int64
+	dropped       *atomic.Uint64
+}
+
+// Limit the writes to bytesPerSecond on average, allowing bursts of up to burst bytes,
+// so that a runaway component can not monopolize the disk bandwidth.
+// When the limit is exceeded, the policy of [WithRateLimitPolicy] decides whether the write waits for its turn,
+// or the message is dropped with an error wrapping [ErrRateLimited].
+// A message larger than the burst is let through once the burst is available, and the writes that follow pay for it.
+// The waiting writes are counted in [Stats.Throttled] and the dropped messages in [Stats.RateLimited].
+// The writes wait without holding the Keeper, so that the rotations, [Keeper.Stats] and [Keeper.Close] go on meanwhile.
+// Set burst < 1 to allow bursts of bytesPerSecond bytes. Set bytesPerSecond < 1 to disable, is disabled by default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithRateLimit(10*lorekeeper.Mb, 50*lorekeeper.Mb))
+func WithRateLimit(bytesPerSecond, burst int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.rateLimit = max(bytesPerSecond, 0)
+		k.rateBurst = burst
+		if burst < 1 {
+			k.rateBurst = k.rateLimit
+		}
+		return k, nil
+	}
+}
+
+// Decide what happens to a write that exceeds the rate limit of [WithRateLimit]:
+// [DropNone] waits until the write fits in the limit, so that no message is lost, which is the default,
+// and [DropNewest] drops the message.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithRateLimit(lorekeeper.Mb, 0),
+//		lorekeeper.WithRateLimitPolicy(lorekeeper.DropNewest),
+//	)
+func WithRateLimitPolicy(policy DropPolicy) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if policy != DropNone && policy != DropNewest {
+			return nil, fmt.Errorf("failed to set rate limit policy, unsupported drop policy %d", policy)
+		}
+		k.ratePolicy = policy
+		return k, nil
+	}
+}
+
+// Start, update or stop the rate limiter of [WithRateLimit] to match the options, the lock of the Keeper must be held.
+func (k *Keeper) configureRateLimit() {
+	if k.rateLimit <= 0 {
+		k.limiter.Store(nil)
+		return
+	}
+	l := &rateLimiter{
+		rate:          float64(k.rateLimit),
+		burst:         float64(k.rateBurst),
+		policy:        k.ratePolicy,
+		now:           k.clock.Now,
+		tokens:        float64(k.rateBurst),
+		updated:       k.now(),
+		throttled:     &k.rateThrottled,
+		throttledTime: &k.rateThrottledTime,
+		dropped:       &k.rateDropped,
+	}
+	// Keep the tokens already spent, so that reconfiguring does not grant a new burst
+	if old := k.limiter.Load(); old != nil {
+		old.mu.Lock()
+		old.refill()
+		l.tokens = min(old.tokens, l.burst)
+		old.mu.Unlock()
+	}
+	k.limiter.Store(l)
+}
+
+// Take n bytes from the rate limit of [WithRateLimit], waiting or failing according to its policy.
+// The lock of the Keeper must not be held.
+func (k *Keeper) throttle(n int) error {
+	l := k.limiter.Load()
+	if l == nil {
+		return nil
+	}
+	wait, err := l.take(n)
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+	return err
+}
+
+// Take n bytes from the bucket, returning how long to wait for them.
+func (l *rateLimiter) take(n int) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill()
+	if l.policy == DropNewest {
+		if l.tokens < min(float64(n), l.burst) {
+			l.dropped.Add(1)
+			return 0, fmt.Errorf("failed to write, over the rate limit of %.0f bytes per second, caused by %w", l.rate, ErrRateLimited)
+		}
+		l.tokens -= float64(n)
+		return 0, nil
+	}
+	// A message larger than the burst only waits for a full bucket
+	debt := min(float64(n), l.burst) - l.tokens
+	l.tokens -= float64(n)
+	if debt <= 0 {
+		return 0, nil
+	}
+	wait := time.Duration(debt / l.rate * float64(time.Second))
+	l.throttled.Add(1)
+	l.throttledTime.Add(int64(wait))
+	return wait, nil
+}
+
+// Add the tokens earned since the last update.
+func (l *rateLimiter) refill() {
+	now := l.now()
+	if elapsed := now.Sub(l.updated); elapsed > 0 {
+		l.tokens = min(l.tokens+elapsed.Seconds()*l.rate, l.burst)
+	}
+	l.updated = now
+}
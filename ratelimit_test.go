@@ -0,0 +1,80 @@
+package lorekeeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimitDrop(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-rate-limit-drop"),
+		WithNowFunc(func() time.Time { return now }),
+		WithRateLimit(10, 0),
+		WithRateLimitPolicy(DropNewest),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("a")); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited got %v", err)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if _, err := k.Write([]byte("abcde")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if stats := k.Stats(); stats.RateLimited != 1 || stats.BytesWritten != 15 {
+		t.Errorf("expected 1 rate limited message and 15 bytes written got %d and %d", stats.RateLimited, stats.BytesWritten)
+	}
+}
+
+func TestWithRateLimitBlock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-rate-limit-block"),
+		WithNowFunc(func() time.Time { return now }),
+		WithRateLimit(1000, 10),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// The burst goes through right away, and the message larger than the burst as well once it is available
+	if _, err := k.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	now = now.Add(10 * time.Millisecond)
+	if _, err := k.Write(make([]byte, 60)); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if stats := k.Stats(); stats.Throttled != 0 {
+		t.Fatalf("expected no throttled write got %d", stats.Throttled)
+	}
+
+	// The next write pays for the 50 bytes over the burst
+	start := time.Now()
+	if _, err := k.Write([]byte("a")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected the write to wait for the rate limit got %v", elapsed)
+	}
+	if stats := k.Stats(); stats.Throttled != 1 || stats.ThrottledTime != 51*time.Millisecond || stats.RateLimited != 0 {
+		t.Errorf("expected 1 throttled write for 51ms and no drop got %d for %v and %d", stats.Throttled, stats.ThrottledTime, stats.RateLimited)
+	}
+}
+
+func TestWithRateLimitPolicyInvalid(t *testing.T) {
+	if _, err := New(WithFolder(t.TempDir()), WithName("test-rate-limit-policy"), WithRateLimitPolicy(DropOldest)); err == nil {
+		t.Errorf("expected an error for DropOldest")
+	}
+}
@@ -0,0 +1,154 @@
+// Package reader reads the folders managed by Lorekeeper without a Keeper, for ops tools and central collectors
+// that never write, such as to list the log files of a folder, read them decompressed, filter their records
+// by time, or merge the records of several folders by their timestamps.
+// A Reader never writes, renames or deletes any file, so it can read a folder while another process manages it.
+//
+// Example usage:
+//
+//	api, err := reader.Open("/var/log/api", "", lorekeeper.WithName("api"), lorekeeper.WithGzip(),
+//		lorekeeper.WithTimestampParser(lorekeeper.PrefixTimestampParser(time.RFC3339)))
+//	if err != nil {
+//		return err
+//	}
+//	worker, err := reader.Open("/var/log/worker", "", lorekeeper.WithName("worker"), lorekeeper.WithGzip(),
+//		lorekeeper.WithTimestampParser(lorekeeper.PrefixTimestampParser(time.RFC3339)))
+//	if err != nil {
+//		return err
+//	}
+//	for record, err := range reader.Merge(incidentStart, incidentEnd, api, worker) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Printf("%s: %s", record.Folder, record.Record)
+//	}
+package reader
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/trviph/lorekeeper"
+)
+
+// A Reader reads the log files of a folder managed by a Keeper. Use [Open] to create a new Reader.
+// It is safe for concurrent use, and the folder is scanned again on every call.
+type Reader struct {
+	folder    string
+	inspector *lorekeeper.Inspector
+}
+
+// A Record is a record read by [Merge], with its timestamp and the folder it was read from.
+type Record struct {
+	lorekeeper.TimedRecord
+	// The folder of the [Reader] that read the record.
+	Folder string
+}
+
+// Create a new [Reader] of the log files in folder, whose archives are named after layout,
+// see [lorekeeper.WithArchiveNameLayout], or after the default layout if it is empty.
+// The options are the ones of the Keeper managing the folder that tell its log files apart,
+// such as [lorekeeper.WithName], [lorekeeper.WithExtension] and [lorekeeper.WithGzip],
+// and [lorekeeper.WithTimestampParser] to filter and merge the records by their timestamps,
+// they override the folder and the layout. Nothing is read until the Reader is used.
+func Open(folder, layout string, opts ...lorekeeper.Opt) (*Reader, error) {
+	base := []lorekeeper.Opt{lorekeeper.WithFolder(folder)}
+	if len(layout) > 0 {
+		base = append(base, lorekeeper.WithArchiveNameLayout(layout))
+	}
+	inspector, err := lorekeeper.Open(append(base, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reader of %s, caused by %w", folder, err)
+	}
+	return &Reader{folder: folder, inspector: inspector.IncludeCurrentFile(true)}, nil
+}
+
+// Get the folder of the Reader.
+func (r *Reader) Folder() string {
+	return r.folder
+}
+
+// List the archives, ordered from oldest to newest, followed by the current log file if it exists.
+func (r *Reader) Files() ([]lorekeeper.ArchiveInfo, error) {
+	return r.inspector.ListArchives()
+}
+
+// Open the log file at path for reading, such as one listed by [Reader.Files], decompressing it on the fly if needed.
+func (r *Reader) Open(path string) (io.ReadCloser, error) {
+	return r.inspector.OpenFile(path)
+}
+
+// Get a reader of the content of all the log files, from the oldest archive to the current log file, decompressed.
+func (r *Reader) Reader() (io.ReadCloser, error) {
+	return r.inspector.Reader()
+}
+
+// Iterate over the records written between from and to, both inclusive, from the oldest to the newest,
+// see [lorekeeper.Keeper.Between] for what a record is and how the records are filtered.
+func (r *Reader) Between(from, to time.Time) iter.Seq2[[]byte, error] {
+	return r.inspector.Between(from, to)
+}
+
+// Iterate over the records written between from and to, both inclusive, with their timestamps,
+// see [lorekeeper.Keeper.Timeline]. The Reader must have been opened with [lorekeeper.WithTimestampParser].
+func (r *Reader) Timeline(from, to time.Time) iter.Seq2[lorekeeper.TimedRecord, error] {
+	return r.inspector.Timeline(from, to)
+}
+
+// Iterate over the records written between from and to, both inclusive, of all the readers,
+// merged by their timestamps from the oldest to the newest, such as to follow a request across services.
+// The records of the same time keep the order of the readers. The readers must have been opened
+// with [lorekeeper.WithTimestampParser], see [Reader.Timeline]. The iteration stops at the first error.
+func Merge(from, to time.Time, readers ...*Reader) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		type source struct {
+			next func() (lorekeeper.TimedRecord, error, bool)
+			head Record
+			ok   bool
+		}
+		sources := make([]*source, len(readers))
+		for i, r := range readers {
+			next, stop := iter.Pull2(r.Timeline(from, to))
+			defer stop()
+			sources[i] = &source{next: next}
+		}
+		// Advance the source to its next record, reporting whether it failed
+		advance := func(s *source, folder string) error {
+			timed, err, ok := s.next()
+			if !ok {
+				s.ok = false
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to merge %s, caused by %w", folder, err)
+			}
+			s.head, s.ok = Record{TimedRecord: timed, Folder: folder}, true
+			return nil
+		}
+		for i, s := range sources {
+			if err := advance(s, readers[i].folder); err != nil {
+				yield(Record{}, err)
+				return
+			}
+		}
+		for {
+			oldest := -1
+			for i, s := range sources {
+				if s.ok && (oldest < 0 || s.head.Time.Before(sources[oldest].head.Time)) {
+					oldest = i
+				}
+			}
+			if oldest < 0 {
+				return
+			}
+			if !yield(sources[oldest].head, nil) {
+				return
+			}
+			if err := advance(sources[oldest], readers[oldest].folder); err != nil {
+				yield(Record{}, err)
+				return
+			}
+		}
+	}
+}
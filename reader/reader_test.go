@@ -0,0 +1,115 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trviph/lorekeeper"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Write the messages at the given seconds after start into a new folder, rotating in between, and open a Reader of it.
+func writeFolder(t *testing.T, name string, seconds ...int) *Reader {
+	t.Helper()
+	folder := t.TempDir()
+	now := start
+	opts := []lorekeeper.Opt{
+		lorekeeper.WithName(name),
+		lorekeeper.WithGzip(),
+		lorekeeper.WithTimestampParser(lorekeeper.PrefixTimestampParser(time.RFC3339)),
+	}
+	k, err := lorekeeper.New(append([]lorekeeper.Opt{
+		lorekeeper.WithFolder(folder),
+		lorekeeper.WithNowFunc(func() time.Time { return now }),
+		lorekeeper.WithSkipEmptyRotation(),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for i, second := range seconds {
+		now = start.Add(time.Duration(second) * time.Second)
+		if _, err := fmt.Fprintf(k, "%s %s %d\n", now.Format(time.RFC3339), name, i); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if i == 0 {
+			if err := k.Rotate(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	r, err := Open(folder, "", opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	return r
+}
+
+func TestReader(t *testing.T) {
+	r := writeFolder(t, "api", 1, 3, 5)
+	files, err := r.Files()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(files) != 3 || !files[0].Compressed {
+		t.Fatalf("expected 2 compressed archives and the current log file got %+v", files)
+	}
+	f, err := r.Open(files[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	b, err := io.ReadAll(f)
+	f.Close()
+	if err != nil || string(b) != "2024-01-01T00:00:01Z api 0\n" {
+		t.Errorf("expected the first record decompressed and no error got %q and %v", b, err)
+	}
+
+	var records []string
+	for record, err := range r.Between(start.Add(2*time.Second), start.Add(4*time.Second)) {
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		records = append(records, string(record))
+	}
+	if want := "2024-01-01T00:00:03Z api 1\n"; strings.Join(records, "") != want {
+		t.Errorf("expected %q got %q", want, records)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	api := writeFolder(t, "api", 1, 4, 5)
+	worker := writeFolder(t, "worker", 2, 3, 6)
+
+	var merged []string
+	for record, err := range Merge(start, start.Add(5*time.Second), api, worker) {
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if record.Folder != api.Folder() && record.Folder != worker.Folder() {
+			t.Errorf("expected the folder of a reader got %s", record.Folder)
+		}
+		merged = append(merged, strings.Fields(string(record.Record))[1]+" "+strings.Fields(string(record.Record))[2])
+	}
+	want := "api 0, worker 0, worker 1, api 1, api 2"
+	if got := strings.Join(merged, ", "); got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+}
+
+func TestMergeWithoutTimestampParser(t *testing.T) {
+	r, err := Open(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, err := range Merge(start, start.Add(time.Hour), r) {
+		if err == nil {
+			t.Errorf("expected an error without a timestamp parser")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package lorekeeper
+
+import (
+	"os"
+	"testing"
+)
+
+func TestKeeperWithReadOnlyArchives(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-read-only-archives"),
+		WithReadOnlyArchives(),
+		WithMaxFiles(1),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for range 2 {
+		if _, err := k.Write([]byte("msg\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	// The retention still removes the read-only archives
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	stat, err := os.Stat(archives[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if stat.Mode().Perm()&0222 != 0 {
+		t.Errorf("expected a read-only archive got %v", stat.Mode().Perm())
+	}
+	if removed := k.Stats().RemovedArchives; removed != 1 {
+		t.Errorf("expected 1 removed archive got %d", removed)
+	}
+}
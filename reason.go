@@ -0,0 +1,27 @@
+package lorekeeper
+
+// A RotationReason tells why a rotation happened.
+// It is available as {{ .reason }} in the archive name layout, see [WithArchiveNameLayout],
+// and through [Keeper.LastRotationReason].
+type RotationReason string
+
+const (
+	// The current log file reached the max size, see [WithMaxSize].
+	RotationSize RotationReason = "size"
+	// The current log file reached the max number of lines, see [WithMaxLines].
+	RotationLines RotationReason = "lines"
+	// The schedule of [WithCron] triggered the rotation.
+	RotationCron RotationReason = "cron"
+	// [Keeper.Rotate] was called.
+	RotationManual RotationReason = "manual"
+	// [Keeper.Close] was called.
+	RotationClose RotationReason = "close"
+	// A boundary of [WithRotateEvery] or [WithDailyRotation] passed.
+	RotationInterval RotationReason = "interval"
+	// The current log file was too old when the Keeper started, see [WithMaxAgeAtStartup].
+	RotationStale RotationReason = "stale"
+	// The Keeper started with a current log file left by a previous process, see [WithRotateOnStart].
+	RotationStartup RotationReason = "startup"
+	// A panic was recovered by [Keeper.RecoverPanic].
+	RotationPanic RotationReason = "panic"
+)
@@ -0,0 +1,182 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestKeeperRotationReason(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-rotation-reason"),
+		WithMaxSize(4),
+		WithArchiveNameLayout("{{ .name }}-{{ .reason }}-{{ .time }}{{ .extension }}"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := k.LastRotationReason(); got != "" {
+		t.Errorf("expected no reason got %q", got)
+	}
+
+	if _, err := k.Write([]byte("abc")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("def")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := k.LastRotationReason(); got != RotationSize {
+		t.Errorf("expected %q got %q", RotationSize, got)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := k.LastRotationReason(); got != RotationManual {
+		t.Errorf("expected %q got %q", RotationManual, got)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	var reasons []string
+	for _, reason := range []RotationReason{RotationSize, RotationManual, RotationClose} {
+		matches, err := filepath.Glob(filepath.Join(folder, "test-rotation-reason-"+string(reason)+"-*.log"))
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if len(matches) == 1 {
+			reasons = append(reasons, string(reason))
+		}
+	}
+	if want := []string{"size", "manual", "close"}; !slices.Equal(reasons, want) {
+		t.Errorf("expected one archive for each of %v got %v", want, reasons)
+	}
+}
+
+func TestKeeperWithMaxAgeAtStartup(t *testing.T) {
+	folder := t.TempDir()
+	path := filepath.Join(folder, "test-max-age-at-startup.log")
+	if err := os.WriteFile(path, []byte("friday\n"), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	friday := time.Now().Add(-72 * time.Hour)
+	if err := os.Chtimes(path, friday, friday); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-max-age-at-startup"),
+		WithMaxAgeAtStartup(24*time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if len(k.Archives()) != 1 {
+		t.Fatalf("expected the stale log file to be archived got %d archives", len(k.Archives()))
+	}
+	if reason := k.LastRotationReason(); reason != RotationStale {
+		t.Errorf("expected reason %q got %q", RotationStale, reason)
+	}
+	content, err := os.ReadFile(k.Archives()[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "friday\n" {
+		t.Errorf("expected the archive to contain %q got %q", "friday\n", content)
+	}
+}
+
+func TestKeeperWithMaxAgeAtStartupFresh(t *testing.T) {
+	folder := t.TempDir()
+	path := filepath.Join(folder, "test-max-age-at-startup-fresh.log")
+	if err := os.WriteFile(path, []byte("today\n"), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-max-age-at-startup-fresh"),
+		WithMaxAgeAtStartup(24*time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if len(k.Archives()) != 0 {
+		t.Errorf("expected no archive got %d", len(k.Archives()))
+	}
+}
+
+func TestKeeperWithRotateOnStart(t *testing.T) {
+	folder := t.TempDir()
+	opts := []Opt{WithFolder(folder), WithName("test-rotate-on-start"), WithRotateOnStart()}
+
+	// An empty current log file is not rotated
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(k.Archives()) != 0 {
+		t.Errorf("expected no archive got %d", len(k.Archives()))
+	}
+	if _, err := k.Write([]byte("previous\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// Leave the current log file behind, as a crashed process would
+	k.registry.unregister(k.name)
+	if err := k.free(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	k, err = New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected the previous log file to be archived got %d archives", len(archives))
+	}
+	if reason := k.LastRotationReason(); reason != RotationStartup {
+		t.Errorf("expected reason %q got %q", RotationStartup, reason)
+	}
+	content, err := os.ReadFile(archives[0].Path)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "previous\n" {
+		t.Errorf("expected the archive to contain %q got %q", "previous\n", content)
+	}
+}
+
+func TestKeeperRotateNow(t *testing.T) {
+	for i, opt := range []Opt{WithGzip(), WithDoubleBuffering(Mb)} {
+		k, err := New(WithFolder(t.TempDir()), WithName(fmt.Sprintf("test-rotate-now-%d", i)), opt)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if _, err := k.Write([]byte("rotated\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		archivePath, err := k.RotateNow()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		archives := k.Archives()
+		if len(archives) != 1 || archives[0].Path != archivePath {
+			t.Errorf("expected the archive %q got %v", archivePath, archives)
+		}
+		if err := k.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+}
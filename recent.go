@@ -0,0 +1,68 @@
+package lorekeeper
+
+import (
+	"fmt"
+)
+
+// Keep the last n messages written to the Keeper in memory, see [Keeper.Recent],
+// so that crash reporters and admin endpoints can show the latest log lines instantly without touching the disk.
+// The messages are kept whether they reached the current log file, the fallback writer, or the pause buffer,
+// except the ones left out by the sampling of [WithSampling].
+// Set n to zero to disable it, which is the default.
+func WithRecent(n int) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if n < 0 {
+			return nil, fmt.Errorf("failed to set recent messages, size must not be negative")
+		}
+		if n != len(k.recent) {
+			// Keep the messages already in memory that still fit
+			recent := k.recentMessages()
+			k.recent = make([][]byte, n)
+			k.recentNext = 0
+			k.recentCount = 0
+			for _, msg := range recent[max(len(recent)-n, 0):] {
+				k.remember(msg)
+			}
+		}
+		return k, nil
+	}
+}
+
+// Get copies of the last messages written to the Keeper, from oldest to newest, see [WithRecent].
+//
+// Example usage:
+//
+//	for _, line := range keeper.Recent() {
+//		fmt.Fprintf(w, "%s", line)
+//	}
+func (k *Keeper) Recent() [][]byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	recent := k.recentMessages()
+	for i, msg := range recent {
+		recent[i] = append([]byte(nil), msg...)
+	}
+	return recent
+}
+
+// Remember the msg as the most recent one, overwriting the oldest one if the ring is full.
+// The slots are reused so that remembering does not allocate once the ring is warm.
+func (k *Keeper) remember(msg []byte) {
+	if len(k.recent) == 0 {
+		return
+	}
+	k.recent[k.recentNext] = append(k.recent[k.recentNext][:0], msg...)
+	k.recentNext = (k.recentNext + 1) % len(k.recent)
+	k.recentCount = min(k.recentCount+1, len(k.recent))
+}
+
+// Get the remembered messages from oldest to newest, without copying them.
+func (k *Keeper) recentMessages() [][]byte {
+	recent := make([][]byte, 0, k.recentCount)
+	start := (k.recentNext - k.recentCount + len(k.recent)) % max(len(k.recent), 1)
+	for i := range k.recentCount {
+		recent = append(recent, k.recent[(start+i)%len(k.recent)])
+	}
+	return recent
+}
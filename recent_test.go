@@ -0,0 +1,37 @@
+package lorekeeper
+
+import (
+	"testing"
+)
+
+func TestKeeperRecent(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-recent"), WithRecent(2))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if got := k.Recent(); len(got) != 0 {
+		t.Errorf("expected no recent messages got %q", got)
+	}
+	for _, msg := range []string{"a\n", "b\n", "c\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if _, err := k.WriteUrgent([]byte("d\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	recent := k.Recent()
+	if len(recent) != 2 || string(recent[0]) != "c\n" || string(recent[1]) != "d\n" {
+		t.Errorf("expected the last 2 messages got %q", recent)
+	}
+	// The copies are not affected by later writes
+	if _, err := k.Write([]byte("e\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(recent[0]) != "c\n" {
+		t.Errorf("expected the copy to be unchanged got %q", recent[0])
+	}
+}
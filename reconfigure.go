@@ -0,0 +1,91 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Apply the options to the running Keeper, such as when a service reloads its configuration.
+// The options are validated on top of the current configuration first, so that either all of them apply or none.
+// The archives are only scanned again if the options change how the log files are named or where they are,
+// such as [WithFolder], [WithExtension] or [WithArchiveNameLayout], in which case the current log file is reopened.
+// The retention runs right away, so that tightened limits apply without waiting for the next rotation.
+// The name of the Keeper can not be changed, use [Keeper.Migrate] instead.
+//
+// Example usage:
+//
+//	// On SIGHUP
+//	err := keeper.Reconfigure(lorekeeper.WithMaxSize(cfg.MaxSize), lorekeeper.WithMaxFiles(cfg.MaxFiles))
+func (k *Keeper) Reconfigure(opts ...Opt) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return fmt.Errorf("failed to reconfigure, caused by %w", os.ErrClosed)
+	}
+
+	// Without touching the Keeper, a failing option leaves it as it is
+	check, err := configureDetached(append(k.optionsLocked(), opts...)...)
+	if err != nil {
+		return fmt.Errorf("failed to reconfigure, caused by %w", err)
+	}
+	if check.name != k.name {
+		return fmt.Errorf("failed to reconfigure, the name can not change from %q to %q, see Keeper.Migrate", k.name, check.name)
+	}
+
+	before, maxLines, chained := k.namingScheme(), k.maxLines, k.chain != nil
+	if _, err := configure(k, opts...); err != nil {
+		return fmt.Errorf("failed to reconfigure, caused by %w", err)
+	}
+	if k.namingScheme() != before {
+		if err := k.closeCurrentFile(); err != nil {
+			return fmt.Errorf("failed to reconfigure, caused by %w", err)
+		}
+		if err := k.load(); err != nil {
+			return fmt.Errorf("failed to reconfigure, caused by %w", err)
+		}
+	} else {
+		k.resetIdleTimer()
+		k.configureSegments()
+		k.configureAsync()
+		k.configureForward()
+		k.configureCron()
+		k.configureRateLimit()
+		// The lines are only counted with a limit
+		if k.maxLines > 0 && maxLines <= 0 {
+			if err := k.scanCurrentLines(); err != nil {
+				return fmt.Errorf("failed to reconfigure, caused by %w", err)
+			}
+		}
+		if k.chain != nil && !chained {
+			if err := k.scanCurrentChain(); err != nil {
+				return fmt.Errorf("failed to reconfigure, caused by %w", err)
+			}
+		}
+	}
+	if err := k.prune(); err != nil {
+		k.handleError(err)
+	}
+	return nil
+}
+
+// What tells the log files of the Keeper apart, see [Keeper.Reconfigure].
+type namingScheme struct {
+	folders        string
+	currentPath    string
+	timeLayout     string
+	layout         string
+	compressionExt string
+	adoptPatterns  string
+}
+
+func (k *Keeper) namingScheme() namingScheme {
+	return namingScheme{
+		folders:        strings.Join(k.getArchiveFolders(), "\x00"),
+		currentPath:    k.getCurrentFilePath(),
+		timeLayout:     k.timeLayout,
+		layout:         k.archiveNameLayoutText,
+		compressionExt: k.compressionExt,
+		adoptPatterns:  strings.Join(k.adoptPatterns, "\x00"),
+	}
+}
@@ -0,0 +1,72 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestKeeperReconfigure(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-reconfigure"),
+		WithMaxSize(10),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for range 4 {
+		if _, err := k.Write([]byte("012345678\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if got := len(k.Archives()); got != 3 {
+		t.Fatalf("expected 3 archives got %d", got)
+	}
+
+	// All or nothing
+	if err := k.Reconfigure(WithMaxFiles(1), WithBackgroundWorkers(0)); err == nil {
+		t.Errorf("expected error for invalid background workers")
+	}
+	if k.MaxFiles() != 0 {
+		t.Errorf("expected max files to be unchanged got %d", k.MaxFiles())
+	}
+	if err := k.Reconfigure(WithName("test-reconfigure-other")); err == nil {
+		t.Errorf("expected error since the name can not change")
+	}
+
+	// The retention applies right away
+	if err := k.Reconfigure(WithMaxFiles(1), WithMaxSize(100)); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := len(k.Archives()); got != 1 {
+		t.Errorf("expected 1 archive got %d", got)
+	}
+	if k.MaxSize() != 100 {
+		t.Errorf("expected max size 100 got %d", k.MaxSize())
+	}
+
+	// Moving the folder scans the new one
+	other := t.TempDir()
+	if err := k.Reconfigure(WithFolder(other)); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := len(k.Archives()); got != 0 {
+		t.Errorf("expected no archive in the new folder got %d", got)
+	}
+	if path := k.CurrentFilePath(); path != filepath.Join(other, "test-reconfigure.log") {
+		t.Errorf("expected the current log file in the new folder got %q", path)
+	}
+	if _, err := k.Write([]byte("moved\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Reconfigure(WithMaxFiles(2)); err == nil {
+		t.Errorf("expected error since Keeper is closed")
+	}
+}
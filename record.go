@@ -0,0 +1,126 @@
+package lorekeeper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The size of the length prefix of a record, see [WithLengthPrefixedRecords].
+const recordHeaderSize = 4
+
+// Write the msg as a length-prefixed record, rotating beforehand if it does not fit into the current log file.
+func (k *Keeper) writeRecord(msg []byte) (int, error) {
+	if uint64(len(msg)) > math.MaxUint32 {
+		return 0, fmt.Errorf("failed to write record, record of %d bytes is too large", len(msg))
+	}
+	// Reuse the buffer of the previous record to avoid allocating on every write
+	k.recordBuf = binary.BigEndian.AppendUint32(k.recordBuf[:0], uint32(len(msg)))
+	k.recordBuf = append(k.recordBuf, msg...)
+	record := k.recordBuf
+	defer k.releaseRecordBuf()
+
+	// A record larger than the max size gets a log file of its own
+	if reason, ok := k.rotationFor(record); ok && !k.breakerOpen() && k.currentFileSize > 0 {
+		if err := k.rotate(reason); err != nil {
+			return 0, err
+		}
+	}
+	n, err := k.write(record)
+	return max(0, n-recordHeaderSize), err
+}
+
+// Write the msg split into the records of the record pattern, see [WithRecordPattern].
+func (k *Keeper) writeLines(msg []byte) (int, error) {
+	written := 0
+	for first := true; written < len(msg); first = false {
+		record := msg[written : written+k.nextRecordLen(msg[written:])]
+		// The continuation of the last record of the previous message stays in the same log file
+		continuation := first && !k.recordPattern.Match(firstLine(record))
+		if reason, ok := k.rotationFor(record); ok && !continuation && k.currentFileSize > 0 {
+			if err := k.rotate(reason); err != nil {
+				return written, err
+			}
+		}
+		n, err := k.write(record)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Get the length of the first record of the msg, which ends before the next line matching the record pattern.
+func (k *Keeper) nextRecordLen(msg []byte) int {
+	offset := bytes.IndexByte(msg, '\n') + 1
+	for offset > 0 && offset < len(msg) {
+		if k.recordPattern.Match(firstLine(msg[offset:])) {
+			return offset
+		}
+		next := bytes.IndexByte(msg[offset:], '\n')
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+	return len(msg)
+}
+
+// Get the first line of the msg without the new line.
+func firstLine(msg []byte) []byte {
+	if i := bytes.IndexByte(msg, '\n'); i >= 0 {
+		return msg[:i]
+	}
+	return msg
+}
+
+// Drop the record buffer if a large record grew it, so that it is not held forever.
+func (k *Keeper) releaseRecordBuf() {
+	if !k.reusable(k.recordBuf) {
+		k.recordBuf = nil
+	}
+}
+
+// A RecordReader reads the records written by a [Keeper] with [WithLengthPrefixedRecords].
+// Use [Inspector.Reader] to read the records of all the log files of a Keeper at once.
+//
+// Example usage:
+//
+//	reader := lorekeeper.NewRecordReader(file)
+//	for {
+//		record, err := reader.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+type RecordReader struct {
+	r *bufio.Reader
+}
+
+// Create a new [RecordReader] reading the records from r.
+func NewRecordReader(r io.Reader) *RecordReader {
+	return &RecordReader{r: bufio.NewReader(r)}
+}
+
+// Read the next record.
+// It returns [io.EOF] when there is no record left, and [io.ErrUnexpectedEOF] if the last record is truncated.
+func (r *RecordReader) Next() ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return nil, err
+	}
+	record := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := io.ReadFull(r.r, record); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,125 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestKeeperWithLengthPrefixedRecords(t *testing.T) {
+	folder := t.TempDir()
+	opts := []Opt{
+		WithFolder(folder),
+		WithName("test-length-prefixed"),
+		WithMaxSize(16),
+		WithLengthPrefixedRecords(),
+	}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	records := [][]byte{
+		[]byte("multi\nline"),
+		{0, 1, 2, 3},
+		[]byte("a record larger than the max size"),
+		{},
+	}
+	for _, record := range records {
+		n, err := k.Write(record)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if n != len(record) {
+			t.Errorf("expected %d bytes written got %d", len(record), n)
+		}
+	}
+	if len(k.Archives()) != 3 {
+		t.Errorf("expected 3 archives got %d", len(k.Archives()))
+	}
+
+	inspector, err := Open(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content, err := inspector.Reader()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer content.Close()
+	reader := NewRecordReader(content)
+	for _, want := range records {
+		got, err := reader.Next()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("expected %q got %q", want, got)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("expected %v got %v", io.EOF, err)
+	}
+}
+
+func TestRecordReaderTruncated(t *testing.T) {
+	reader := NewRecordReader(bytes.NewReader([]byte{0, 0, 0, 5, 'a', 'b'}))
+	if _, err := reader.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected %v got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestKeeperWithRecordPattern(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-record-pattern"),
+		WithMaxSize(32),
+		WithRecordPattern(regexp.MustCompile(`^\d{4} `)),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	msgs := []string{
+		"2000 ok\n",
+		"2001 panic: boom\n\tat main.go:1\n",
+		// A continuation written separately stays with its header
+		"\tat main.go:2\n",
+		"2002 ok\n2003 ok\n2004 ok\n2005 ok\n",
+	}
+	for _, msg := range msgs {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+
+	var files []string
+	for _, archive := range k.Archives() {
+		content, err := os.ReadFile(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		files = append(files, string(content))
+	}
+	current, err := os.ReadFile(k.CurrentFilePath())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	files = append(files, string(current))
+
+	want := []string{
+		"2000 ok\n",
+		"2001 panic: boom\n\tat main.go:1\n\tat main.go:2\n",
+		"2002 ok\n2003 ok\n2004 ok\n2005 ok\n",
+	}
+	if !slices.Equal(files, want) {
+		t.Errorf("expected %q got %q", want, files)
+	}
+}
@@ -0,0 +1,71 @@
+package lorekeeper
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+)
+
+// Call [Keeper.Reopen] whenever the process receives one of the given signals, such as syscall.SIGHUP,
+// so that the Keeper coexists with external logrotate setups, which move the current log file away
+// and then signal the process to reopen its logs.
+// With copytruncate, the current log file is truncated in place and reopening it resets its size.
+// The errors of the reopening go to the error handler, see [WithErrorHandler].
+// No signal disables it, which is the default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithReopenSignal(syscall.SIGHUP), lorekeeper.WithMaxSize(0))
+func WithReopenSignal(sigs ...os.Signal) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.stopReopenSignal()
+		k.reopenSignals = slices.Clone(sigs)
+		if len(sigs) == 0 {
+			return k, nil
+		}
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, sigs...)
+		k.reopenSignal = ch
+		k.goSupervised("reopen", func() { k.reopenOnSignal(ch) }, func() {})
+		return k, nil
+	}
+}
+
+func (k *Keeper) reopenOnSignal(ch <-chan os.Signal) {
+	for range ch {
+		if err := k.Reopen(); err != nil {
+			k.mu.Lock()
+			k.handleError(err)
+			k.mu.Unlock()
+		}
+	}
+}
+
+// Stop reopening the current log file on signals.
+func (k *Keeper) stopReopenSignal() {
+	if k.reopenSignal == nil {
+		return
+	}
+	signal.Stop(k.reopenSignal)
+	close(k.reopenSignal)
+	k.reopenSignal = nil
+}
+
+// Write to the current log file, reopening it and retrying once if its descriptor became invalid,
+// such as after being closed by a forked child or by a library closing the descriptors it does not own.
+func (k *Keeper) writeFile(p []byte) (int, error) {
+	n, err := k.currentFile.Write(p)
+	if err == nil || !isBadDescriptor(err) {
+		return n, err
+	}
+	// The descriptor is closed right away, since the finalizer of the file would close it
+	// at a later time, when its number may have been reused by another file of the process
+	_ = k.currentFile.Close()
+	k.currentFile = nil
+	if err := k.openCurrentFile(); err != nil {
+		return n, err
+	}
+	k.stats.Reopens++
+	m, err := k.currentFile.Write(p[n:])
+	return n + m, err
+}
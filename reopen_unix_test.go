@@ -0,0 +1,76 @@
+//go:build unix
+
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWithReopenSignal(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-reopen-signal"), WithReopenSignal(syscall.SIGUSR1))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if _, err := k.Write([]byte("abc")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// Simulate logrotate moving the current log away then signaling the process
+	current := filepath.Join(folder, "test-reopen-signal.log")
+	if err := os.Rename(current, current+".1"); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(current); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the current log file to be reopened on the signal")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReopenOnBadDescriptor(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(WithFolder(folder), WithName("test-reopen-ebadf"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// Simulate the descriptor being closed behind the back of the Keeper
+	k.mu.Lock()
+	if err := syscall.Close(int(k.currentFile.(*os.File).Fd())); err != nil {
+		k.mu.Unlock()
+		t.Fatalf("expected no error got %v", err)
+	}
+	k.mu.Unlock()
+
+	if _, err := k.Write([]byte("def\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(folder, "test-reopen-ebadf.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "abc\ndef\n" {
+		t.Errorf("expected %q got %q", "abc\ndef\n", content)
+	}
+	if stats := k.Stats(); stats.Reopens != 1 || stats.WriteErrors != 0 {
+		t.Errorf("expected 1 reopen and no write error got %d and %d", stats.Reopens, stats.WriteErrors)
+	}
+}
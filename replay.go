@@ -0,0 +1,37 @@
+package lorekeeper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Stream the records written between from and to, both inclusive, across the archives and the current log file into w,
+// such as re-sending a window of logs to a collector that had an outage.
+// The records are selected as in [Keeper.Between] and written one at a time, each with a single call to w.Write.
+// Replay stops at the first error, or as soon as ctx is done, returning the number of bytes written so far.
+// w must not be this Keeper, since the records written to the current log file would be read again.
+//
+// Example usage:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//	n, err := keeper.Replay(ctx, outageStart, outageEnd, collector)
+func (k *Keeper) Replay(ctx context.Context, from, to time.Time, w io.Writer) (int64, error) {
+	var written int64
+	for record, err := range k.Between(from, to) {
+		if err != nil {
+			return written, fmt.Errorf("failed to replay records, caused by %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return written, fmt.Errorf("failed to replay records, caused by %w", err)
+		}
+		n, err := w.Write(record)
+		written += int64(n)
+		if err != nil {
+			return written, fmt.Errorf("failed to replay records, caused by %w", err)
+		}
+	}
+	return written, nil
+}
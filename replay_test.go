@@ -0,0 +1,44 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeeperReplay(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-replay"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"archived\n", "current\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if msg == "archived\n" {
+			if err := k.Rotate(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+	}
+
+	var collector bytes.Buffer
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	n, err := k.Replay(context.Background(), from, to, &collector)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if want := "archived\ncurrent\n"; collector.String() != want || n != int64(len(want)) {
+		t.Errorf("expected %q got %q with %d bytes", want, collector.String(), n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := k.Replay(ctx, from, to, &collector); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled error got %v", err)
+	}
+}
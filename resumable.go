@@ -0,0 +1,197 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// The default size of the parts of [ResumableUploader], the min size of the parts of an S3 multipart upload.
+const defaultUploadPartSize = 5 * Mb
+
+// The extension of the sidecar file recording the progress of an upload of [ResumableUploader].
+const uploadStateExt = ".upload"
+
+// A MultipartUploader ships an archive to a remote storage in parts,
+// such as with the multipart uploads of S3 or the resumable uploads of GCS, see [ResumableUploader].
+type MultipartUploader interface {
+	// Start the upload of the archive at the local path, returning the ID its parts are uploaded with.
+	CreateUpload(ctx context.Context, localPath string) (uploadID string, err error)
+	// Upload the part of the given number, counted from 1, returning the ID of the stored part, such as its ETag.
+	UploadPart(ctx context.Context, uploadID string, number int, part io.Reader) (partID string, err error)
+	// Assemble the uploaded parts, ordered by their number, into the remote archive.
+	CompleteUpload(ctx context.Context, uploadID string, parts []UploadedPart) error
+}
+
+// An UploadedPart is a part of an archive stored by a [MultipartUploader].
+type UploadedPart struct {
+	// The number of the part, counted from 1.
+	Number int `json:"number"`
+	// The ID of the stored part, such as its ETag.
+	ID string `json:"id"`
+}
+
+// The progress of an upload of [ResumableUploader], persisted in a sidecar file next to the archive.
+type uploadState struct {
+	UploadID string `json:"uploadID"`
+	PartSize int    `json:"partSize"`
+	// The size of the archive when the upload started, the upload starts over if the archive changed.
+	Size  int64          `json:"size"`
+	Parts []UploadedPart `json:"parts"`
+}
+
+// The context key of the [FS] of the Keeper uploading an archive, see [ResumableUploader].
+type uploadFSKey struct{}
+
+// Get the [FS] the archive being uploaded is read from, the one of the Keeper uploading it, or the OS one otherwise.
+func uploadFS(ctx context.Context) FS {
+	if fsys, ok := ctx.Value(uploadFSKey{}).(FS); ok {
+		return fsys
+	}
+	return OSFS()
+}
+
+// Create an [Uploader] that uploads the archives in parts of the given size with the [MultipartUploader],
+// recording the parts stored so far in a sidecar file next to the archive, named after it with a ".upload" extension.
+// An upload interrupted by a failure, a cancellation of its context or a restart resumes from the first part not stored yet
+// when it is tried again, instead of starting a multi-GB archive from scratch.
+// The progress is recorded under the given id, which must be unique and stable across restarts,
+// so that several ResumableUploaders, such as the ones of a [FailoverUploader], never resume the uploads of each other.
+// The context is checked before every part, and given to every call of the MultipartUploader.
+// The archives of a Keeper, and their sidecar files, are read and written with its [FS], see [WithFS].
+// The progress is removed once the upload completes, and the sidecar file with the archive.
+// A part size below 1 defaults to 5 Mb, the min size of the parts of an S3 multipart upload.
+//
+// Example usage:
+//
+//	uploader := lorekeeper.FailoverUploader(
+//		lorekeeper.ResumableUploader("s3", s3Multipart, 64*lorekeeper.Mb),
+//		lorekeeper.ResumableUploader("gcs", gcsResumable, 64*lorekeeper.Mb),
+//	)
+//	keeper, err := lorekeeper.New(lorekeeper.WithUploader(uploader, false))
+func ResumableUploader(id string, m MultipartUploader, partSize int) Uploader {
+	if partSize < 1 {
+		partSize = defaultUploadPartSize
+	}
+	return UploaderFunc(func(ctx context.Context, localPath string) error {
+		return uploadResumable(ctx, uploadFS(ctx), id, m, localPath, partSize)
+	})
+}
+
+func uploadResumable(ctx context.Context, fsys FS, id string, m MultipartUploader, localPath string, partSize int) error {
+	f, err := fsys.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive, caused by %w", err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat archive, caused by %w", err)
+	}
+
+	statePath := localPath + uploadStateExt
+	states, err := loadUploadStates(fsys, statePath)
+	if err != nil {
+		return err
+	}
+	state := states[id]
+	if state == nil || state.PartSize != partSize || state.Size != stat.Size() {
+		uploadID, err := m.CreateUpload(ctx, localPath)
+		if err != nil {
+			return fmt.Errorf("failed to create upload, caused by %w", err)
+		}
+		state = &uploadState{UploadID: uploadID, PartSize: partSize, Size: stat.Size()}
+		states[id] = state
+		if err := saveUploadStates(fsys, statePath, states); err != nil {
+			return err
+		}
+	}
+
+	// An empty archive is still uploaded as one empty part
+	parts := max(int((stat.Size()+int64(partSize)-1)/int64(partSize)), 1)
+	for number := len(state.Parts) + 1; number <= parts; number++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("failed to upload part %d of %d, caused by %w", number, parts, err)
+		}
+		offset := int64(number-1) * int64(partSize)
+		part, err := readPart(f, offset, min(int64(partSize), stat.Size()-offset))
+		if err != nil {
+			return fmt.Errorf("failed to read part %d of %d, caused by %w", number, parts, err)
+		}
+		partID, err := m.UploadPart(ctx, state.UploadID, number, part)
+		if err != nil {
+			return fmt.Errorf("failed to upload part %d of %d, caused by %w", number, parts, err)
+		}
+		state.Parts = append(state.Parts, UploadedPart{Number: number, ID: partID})
+		if err := saveUploadStates(fsys, statePath, states); err != nil {
+			return err
+		}
+	}
+
+	if err := m.CompleteUpload(ctx, state.UploadID, state.Parts); err != nil {
+		return fmt.Errorf("failed to complete upload, caused by %w", err)
+	}
+	delete(states, id)
+	if len(states) > 0 {
+		return saveUploadStates(fsys, statePath, states)
+	}
+	if err := fsys.Remove(statePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove upload state, caused by %w", err)
+	}
+	return nil
+}
+
+// Get the part of the archive of size bytes from offset, read into memory if the archive is not an [io.ReaderAt].
+func readPart(f fs.File, offset, size int64) (io.Reader, error) {
+	if r, ok := f.(io.ReaderAt); ok {
+		return io.NewSectionReader(r, offset, size), nil
+	}
+	part := make([]byte, size)
+	if err := readAt(f, part, offset); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(part), nil
+}
+
+// Load the progress of the uploads of an archive by the id of their uploader, empty if none has started yet.
+func loadUploadStates(fsys FS, path string) (map[string]*uploadState, error) {
+	states := make(map[string]*uploadState)
+	data, err := readFile(fsys, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load upload state, caused by %w", err)
+	}
+	// A corrupted state starts the uploads over
+	if err := json.Unmarshal(data, &states); err != nil {
+		return make(map[string]*uploadState), nil
+	}
+	return states, nil
+}
+
+// Persist the progress of the uploads of an archive, replacing the file atomically so that a crash does not lose it.
+func saveUploadStates(fsys FS, path string, states map[string]*uploadState) error {
+	data, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("failed to save upload state, caused by %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := writeFile(fsys, tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to save upload state, caused by %w", err)
+	}
+	if err := fsys.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save upload state, caused by %w", err)
+	}
+	return nil
+}
+
+// Whether the path is the sidecar file of an upload of [ResumableUploader], or its temporary file.
+func isUploadState(path string) bool {
+	return strings.HasSuffix(path, uploadStateExt) || strings.HasSuffix(path, uploadStateExt+".tmp")
+}
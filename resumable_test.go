@@ -0,0 +1,216 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// A multipartStore is a [MultipartUploader] keeping the parts in memory, failing the part of failAt once.
+type multipartStore struct {
+	creates  int
+	uploaded []int
+	parts    map[string]map[int][]byte
+	stored   map[string][]byte
+	failAt   int
+}
+
+func (s *multipartStore) CreateUpload(ctx context.Context, localPath string) (string, error) {
+	s.creates++
+	id := fmt.Sprintf("upload-%d", s.creates)
+	if s.parts == nil {
+		s.parts = make(map[string]map[int][]byte)
+		s.stored = make(map[string][]byte)
+	}
+	s.parts[id] = make(map[int][]byte)
+	return id, nil
+}
+
+func (s *multipartStore) UploadPart(ctx context.Context, uploadID string, number int, part io.Reader) (string, error) {
+	if number == s.failAt {
+		s.failAt = 0
+		return "", errors.New("connection reset")
+	}
+	data, err := io.ReadAll(part)
+	if err != nil {
+		return "", err
+	}
+	s.uploaded = append(s.uploaded, number)
+	s.parts[uploadID][number] = data
+	return fmt.Sprintf("etag-%d", number), nil
+}
+
+func (s *multipartStore) CompleteUpload(ctx context.Context, uploadID string, parts []UploadedPart) error {
+	var data []byte
+	for _, part := range parts {
+		data = append(data, s.parts[uploadID][part.Number]...)
+	}
+	s.stored[uploadID] = data
+	return nil
+}
+
+func TestResumableUploader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test-resumable.log")
+	content := bytes.Repeat([]byte("0123456789"), 3)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	store := &multipartStore{failAt: 3}
+	uploader := ResumableUploader("store", store, 8)
+
+	if err := uploader.Upload(context.Background(), path); err == nil {
+		t.Fatalf("expected the third part to fail got nil")
+	}
+	if _, err := os.Stat(path + uploadStateExt); err != nil {
+		t.Fatalf("expected the progress to be recorded got %v", err)
+	}
+	// Resumed from the third part, as after a restart
+	if err := uploader.Upload(context.Background(), path); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if store.creates != 1 || fmt.Sprint(store.uploaded) != "[1 2 3 4]" {
+		t.Errorf("expected 1 upload of the parts [1 2 3 4] got %d uploads of %v", store.creates, store.uploaded)
+	}
+	if !bytes.Equal(store.stored["upload-1"], content) {
+		t.Errorf("expected %q got %q", content, store.stored["upload-1"])
+	}
+	if _, err := os.Stat(path + uploadStateExt); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the progress to be removed once complete got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := uploader.Upload(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+}
+
+func TestResumableUploaderState(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-resumable"), WithMaxFiles(1))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if _, err := k.Write([]byte("shipped\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archive := k.Archives()[0].Path
+	if err := ResumableUploader("store", &multipartStore{failAt: 1}, 0).Upload(context.Background(), archive); err == nil {
+		t.Fatalf("expected the first part to fail got nil")
+	}
+	if _, err := os.Stat(archive + uploadStateExt); err != nil {
+		t.Fatalf("expected the progress of the failed upload got %v", err)
+	}
+
+	// The sidecar file is never taken for an archive, and goes with its archive
+	archives, _, err := k.getArchives()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if archives.Length() != 1 {
+		t.Errorf("expected the sidecar file to not be an archive got %d archives", archives.Length())
+	}
+	if _, err := k.Write([]byte("kept\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := os.Stat(archive + uploadStateExt); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the progress to be removed with the archive got %v", err)
+	}
+}
+
+func TestResumableUploaderFailover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test-resumable-failover.log")
+	content := bytes.Repeat([]byte("0123456789"), 3)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	primary, secondary := &multipartStore{failAt: 2}, &multipartStore{}
+	uploader := FailoverUploader(ResumableUploader("primary", primary, 8), ResumableUploader("secondary", secondary, 8))
+
+	if err := uploader.Upload(context.Background(), path); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// The secondary starts its own upload instead of resuming the one of the primary
+	if secondary.creates != 1 || !bytes.Equal(secondary.stored["upload-1"], content) {
+		t.Errorf("expected the secondary to upload %q got %d uploads storing %q", content, secondary.creates, secondary.stored["upload-1"])
+	}
+	// The primary still resumes its own upload
+	if err := ResumableUploader("primary", primary, 8).Upload(context.Background(), path); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if primary.creates != 1 || fmt.Sprint(primary.uploaded) != "[1 2 3 4]" {
+		t.Errorf("expected 1 upload of the parts [1 2 3 4] got %d uploads of %v", primary.creates, primary.uploaded)
+	}
+	if _, err := os.Stat(path + uploadStateExt); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the progress to be removed once both complete got %v", err)
+	}
+}
+
+// An openedFS records the files opened through it.
+type openedFS struct {
+	FS
+	mu     sync.Mutex
+	opened []string
+}
+
+func (f *openedFS) Open(name string) (fs.File, error) {
+	f.record(name)
+	return f.FS.Open(name)
+}
+
+func (f *openedFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	f.record(name)
+	return f.FS.OpenFile(name, flag, perm)
+}
+
+func (f *openedFS) record(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opened = append(f.opened, filepath.Base(name))
+}
+
+func TestResumableUploaderFS(t *testing.T) {
+	fsys := &openedFS{FS: OSFS()}
+	store := &multipartStore{failAt: 1}
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-resumable-fs"),
+		WithFS(fsys),
+		WithUploader(ResumableUploader("store", store, 0), false),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("shipped\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archive := filepath.Base(k.Archives()[0].Path)
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	for _, name := range []string{archive, archive + uploadStateExt + ".tmp"} {
+		if !slices.Contains(fsys.opened, name) {
+			t.Errorf("expected %q to be opened with the FS of the keeper got %v", name, fsys.opened)
+		}
+	}
+}
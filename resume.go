@@ -0,0 +1,78 @@
+package lorekeeper
+
+import (
+	"fmt"
+
+	"github.com/trviph/collection"
+)
+
+// Compress the archives left uncompressed by a previous process, for example one that exited between a rotation
+// and the compression of its archive. The uncompressed archive itself records the pending work,
+// since it is only removed once its compressed copy is complete.
+// The compressed archives keep the modification time of the originals, so that they keep their place in the archives.
+func (k *Keeper) resumeCompression() {
+	if k.compressor == nil {
+		return
+	}
+
+	// The compressed paths of the uncompressed archives
+	pending := make(map[string]bool)
+	for _, archive := range k.archives.All() {
+		if !k.isCompressedArchive(archive.filePath) && !k.isLabeledArchive(archive.filePath) && !k.isAdoptedArchive(archive.filePath) {
+			pending[k.compressedArchivePath(archive.filePath)] = true
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	var archives []*fileInfo
+	for _, archive := range k.archives.All() {
+		// A partial compressed copy is replaced by compressing its original again
+		if pending[archive.filePath] {
+			k.archivesSize -= archive.size
+			continue
+		}
+		archives = append(archives, archive)
+	}
+
+	compressed := make([]*fileInfo, len(archives))
+	results := make([]compression, len(archives))
+	errs := make([]error, len(archives))
+	k.runBackground(len(archives), func(i int) {
+		if !k.isCompressedArchive(archives[i].filePath) && !k.isLabeledArchive(archives[i].filePath) && !k.isAdoptedArchive(archives[i].filePath) {
+			compressed[i], results[i], errs[i] = k.compressArchive(archives[i])
+		}
+	})
+
+	resumed := collection.NewList[*fileInfo]()
+	for i, archive := range archives {
+		if errs[i] != nil {
+			k.handleError(errs[i])
+		}
+		if compressed[i] == nil {
+			resumed.Append(archive)
+			continue
+		}
+		if err := k.writeChecksum(compressed[i].filePath); err != nil {
+			k.handleError(err)
+		}
+		compressed[i].records = archive.records
+		k.countCompression(compressed[i], results[i])
+		k.archivesSize += compressed[i].size - archive.size
+		resumed.Append(compressed[i])
+	}
+	k.archives = resumed
+}
+
+func (k *Keeper) compressArchive(archive *fileInfo) (*fileInfo, compression, error) {
+	name := k.compressedArchivePath(archive.filePath)
+	result, err := k.compress(archive.filePath, name)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to resume compression of %q, caused by %w", archive.filePath, err)
+	}
+	// The archive only loses its place if its time can not be kept
+	_ = k.fsys.Chtimes(name, archive.modtime, archive.modtime)
+	info, err := getFileInfo(k.fsys, name)
+	return info, result, err
+}
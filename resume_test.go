@@ -0,0 +1,71 @@
+package lorekeeper
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeeperResumeCompression(t *testing.T) {
+	folder := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	// An archive left uncompressed, and another one whose compression was interrupted
+	for _, file := range []struct{ name, content string }{
+		{"2024-01-01-test-resume-compression.log", "first\n"},
+		{"2024-01-02-test-resume-compression.log", "second\n"},
+		{"2024-01-02-test-resume-compression.log.gz", "partial"},
+	} {
+		path := filepath.Join(folder, file.name)
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		old = old.Add(time.Minute)
+	}
+
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-resume-compression"),
+		WithArchiveNameLayout("{{ .time }}-{{ .name }}{{ .extension }}"),
+		WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	archives := k.Archives()
+	if len(archives) != 2 {
+		t.Fatalf("expected 2 archives got %d", len(archives))
+	}
+	for i, expected := range []string{"first\n", "second\n"} {
+		if !strings.HasSuffix(archives[i].Path, ".gz") {
+			t.Fatalf("expected %q to be compressed", archives[i].Path)
+		}
+		f, err := os.Open(archives[i].Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		reader, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		content, err := io.ReadAll(reader)
+		f.Close()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if string(content) != expected {
+			t.Errorf("expected %q got %q", expected, content)
+		}
+	}
+	if _, err := os.Stat(strings.TrimSuffix(archives[0].Path, ".gz")); !os.IsNotExist(err) {
+		t.Errorf("expected the uncompressed archive to be removed got %v", err)
+	}
+}
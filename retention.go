@@ -0,0 +1,60 @@
+package lorekeeper
+
+import (
+	"time"
+)
+
+// A RetentionReport summarizes the usage of the archives against the retention limits of a [Keeper],
+// suitable for JSON rendering on status pages, see [Keeper.RetentionReport].
+// A limit of zero means unlimited.
+type RetentionReport struct {
+	// The number of archives, and the max number of archives of [WithMaxFiles].
+	Files    int `json:"files"`
+	MaxFiles int `json:"max_files"`
+	// The total size of the archives in bytes, and the max total size of [WithTotalSize].
+	Bytes     int `json:"bytes"`
+	TotalSize int `json:"total_size"`
+	// The max age of the archives of [WithMaxAge].
+	MaxAge time.Duration `json:"max_age"`
+	// The size of the current log file in bytes, and the size rotating it of [WithMaxSize].
+	CurrentFileSize int `json:"current_file_size"`
+	MaxSize         int `json:"max_size"`
+	// The modification time of the oldest and the newest archives, zero if there is no archive.
+	OldestArchive time.Time `json:"oldest_archive"`
+	NewestArchive time.Time `json:"newest_archive"`
+	// The age of the oldest and the newest archives, zero if there is no archive.
+	OldestArchiveAge time.Duration `json:"oldest_archive_age"`
+	NewestArchiveAge time.Duration `json:"newest_archive_age"`
+}
+
+// Get a summary of the usage of the archives against the retention limits.
+//
+// Example usage:
+//
+//	http.HandleFunc("/status/logs", func(w http.ResponseWriter, r *http.Request) {
+//		_ = json.NewEncoder(w).Encode(keeper.RetentionReport())
+//	})
+func (k *Keeper) RetentionReport() RetentionReport {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	report := RetentionReport{
+		Files:           k.archives.Length(),
+		MaxFiles:        max(k.maxFiles, 0),
+		Bytes:           k.archivesSize,
+		TotalSize:       max(k.totalSize, 0),
+		MaxAge:          k.maxAge,
+		CurrentFileSize: k.currentFileSize,
+		MaxSize:         max(k.maxSize, 0),
+	}
+	now := k.now()
+	if oldest, err := k.archives.Index(0); err == nil {
+		report.OldestArchive = oldest.modtime
+		report.OldestArchiveAge = now.Sub(oldest.modtime)
+	}
+	if newest, err := k.archives.Index(k.archives.Length() - 1); err == nil {
+		report.NewestArchive = newest.modtime
+		report.NewestArchiveAge = now.Sub(newest.modtime)
+	}
+	return report
+}
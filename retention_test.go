@@ -0,0 +1,54 @@
+package lorekeeper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeeperRetentionReport(t *testing.T) {
+	now := time.Now()
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-retention-report"),
+		WithMaxFiles(5),
+		WithTotalSize(Mb),
+		WithNowFunc(func() time.Time { return now }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if report := k.RetentionReport(); report.Files != 0 || !report.OldestArchive.IsZero() {
+		t.Errorf("expected no archive got %+v", report)
+	}
+	for _, msg := range []string{"first\n", "second\n", "current\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if msg != "current\n" {
+			if err := k.Rotate(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+	}
+	now = now.Add(time.Hour)
+
+	report := k.RetentionReport()
+	archives := k.Archives()
+	want := RetentionReport{
+		Files:            2,
+		MaxFiles:         5,
+		Bytes:            len("first\nsecond\n"),
+		TotalSize:        Mb,
+		CurrentFileSize:  len("current\n"),
+		MaxSize:          k.MaxSize(),
+		OldestArchive:    archives[0].ModTime,
+		NewestArchive:    archives[1].ModTime,
+		OldestArchiveAge: now.Sub(archives[0].ModTime),
+		NewestArchiveAge: now.Sub(archives[1].ModTime),
+	}
+	if report != want {
+		t.Errorf("expected %+v got %+v", want, report)
+	}
+}
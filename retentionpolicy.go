@@ -0,0 +1,155 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/trviph/collection"
+)
+
+// A RetentionPolicy decides which archives to remove, for retention rules that the built-in limits can not express,
+// see [WithRetentionPolicy]. [MaxFilesPolicy], [TotalSizePolicy] and [MaxAgePolicy] are the policies
+// that the Keeper applies for [WithMaxFiles], [WithTotalSize] and [WithMaxAge], which custom policies can build upon.
+type RetentionPolicy interface {
+	// Get the archives to remove among the given ones, which are ordered from oldest to newest.
+	// The archives are told apart by their paths, the ones returned that are not among the given ones are ignored.
+	// It is called on every rotation while the Keeper is locked, so it must not call the methods of the Keeper.
+	// With [WithTrustModTime] false, the ModTime of the archives is the rotation time recorded in their names.
+	Evict(archives []ArchiveInfo) []ArchiveInfo
+}
+
+// A MaxFilesPolicy evicts the oldest archives over the given number of archives, like [WithMaxFiles].
+// It evicts nothing if it is < 1.
+type MaxFilesPolicy int
+
+func (p MaxFilesPolicy) Evict(archives []ArchiveInfo) []ArchiveInfo {
+	if p < 1 || len(archives) <= int(p) {
+		return nil
+	}
+	return archives[:len(archives)-int(p)]
+}
+
+// A TotalSizePolicy evicts the oldest archives until their total size in bytes is within the policy, like [WithTotalSize].
+// It evicts nothing if it is < 1.
+type TotalSizePolicy int
+
+func (p TotalSizePolicy) Evict(archives []ArchiveInfo) []ArchiveInfo {
+	if p < 1 {
+		return nil
+	}
+	size := 0
+	for _, archive := range archives {
+		size += archive.Size
+	}
+	evicted := 0
+	for ; evicted < len(archives) && size > int(p); evicted++ {
+		size -= archives[evicted].Size
+	}
+	return archives[:evicted]
+}
+
+// A MaxAgePolicy evicts the oldest archives, up to the first one modified within the max age, like [WithMaxAge].
+type MaxAgePolicy struct {
+	// The max age of the archives, nothing is evicted if it is <= 0.
+	MaxAge time.Duration
+	// The clock telling the current time, [time.Now] if nil.
+	// The Keeper evicts the archives of [WithMaxAge] with this policy, telling the time of its clock, see [WithClock].
+	Now func() time.Time
+}
+
+func (p MaxAgePolicy) Evict(archives []ArchiveInfo) []ArchiveInfo {
+	if p.MaxAge <= 0 {
+		return nil
+	}
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	oldest := now().Add(-p.MaxAge)
+	evicted := 0
+	for evicted < len(archives) && archives[evicted].ModTime.Before(oldest) {
+		evicted++
+	}
+	return archives[:evicted]
+}
+
+// Remove the oldest archives once they are older than d, based on their modification time,
+// or on the rotation time recorded in their names with [WithTrustModTime] false,
+// with the clock of the Keeper, see [WithClock]. The archives are checked on every rotation.
+// If it is combined with [WithMaxFiles] or [WithTotalSize], the Keeper will use whatever condition is met first.
+// Set <= 0 to disable, is disabled by default.
+func WithMaxAge(d time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.maxAge = max(d, 0)
+		return k, nil
+	}
+}
+
+// Remove the archives evicted by the given policy on every rotation as well, such as to keep one archive per day
+// beyond a week, or to always keep the archives matching a pattern. The policy is given the archives left
+// by the limits of [WithMaxFiles], [WithTotalSize] and [WithMaxAge], while [WithMinDiskFree] removes the oldest
+// of the archives left by the policy, so a policy that must keep some archives no matter what should replace these limits
+// by calling the Evict of [MaxFilesPolicy], [TotalSizePolicy] or [MaxAgePolicy] on the other archives.
+// The archives it removes are recorded with [DeletionRetentionPolicy], see [Keeper.Deletions].
+// Set nil to disable, which is the default.
+//
+// Example usage:
+//
+//	// Keep the last 10 archives, and the archives of the first day of every month
+//	type monthly struct{}
+//
+//	func (monthly) Evict(archives []lorekeeper.ArchiveInfo) []lorekeeper.ArchiveInfo {
+//		var others []lorekeeper.ArchiveInfo
+//		for _, archive := range archives {
+//			if archive.ModTime.Day() != 1 {
+//				others = append(others, archive)
+//			}
+//		}
+//		return lorekeeper.MaxFilesPolicy(10).Evict(others)
+//	}
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithRetentionPolicy(monthly{}))
+func WithRetentionPolicy(p RetentionPolicy) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.retentionPolicy = p
+		return k, nil
+	}
+}
+
+// Get the archives among the given ones that the retention policy of [WithRetentionPolicy] evicts,
+// infos are the [ArchiveInfo] of the archives.
+func (k *Keeper) evictedByPolicy(archives []*fileInfo, infos []ArchiveInfo) map[*fileInfo]bool {
+	if k.retentionPolicy == nil || len(archives) == 0 {
+		return nil
+	}
+	byPath := make(map[string]*fileInfo, len(archives))
+	for _, archive := range archives {
+		byPath[archive.filePath] = archive
+	}
+	evicted := make(map[*fileInfo]bool)
+	for _, info := range k.retentionPolicy.Evict(infos) {
+		if archive, ok := byPath[info.Path]; ok {
+			evicted[archive] = true
+		}
+	}
+	return evicted
+}
+
+// Get the reason recorded for the archives evicted by the retention policy.
+func (k *Keeper) retentionPolicyReason() string {
+	if s, ok := k.retentionPolicy.(fmt.Stringer); ok {
+		return fmt.Sprintf("evicted by the retention policy %s", s)
+	}
+	return "evicted by the retention policy"
+}
+
+// Get the archives without the removed ones, in the same order.
+func keptArchives(archives []*fileInfo, removed map[*fileInfo]bool) *collection.List[*fileInfo] {
+	kept := collection.NewList[*fileInfo]()
+	for _, archive := range archives {
+		if !removed[archive] {
+			kept.Append(archive)
+		}
+	}
+	return kept
+}
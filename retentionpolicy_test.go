@@ -0,0 +1,173 @@
+package lorekeeper
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicies(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	archives := []ArchiveInfo{
+		{Path: "a", Size: 10, ModTime: now.Add(-72 * time.Hour)},
+		{Path: "b", Size: 20, ModTime: now.Add(-48 * time.Hour)},
+		{Path: "c", Size: 30, ModTime: now.Add(-time.Hour)},
+	}
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   int
+	}{
+		{name: "max files", policy: MaxFilesPolicy(1), want: 2},
+		{name: "max files disabled", policy: MaxFilesPolicy(0), want: 0},
+		{name: "total size", policy: TotalSizePolicy(50), want: 1},
+		{name: "total size disabled", policy: TotalSizePolicy(0), want: 0},
+		{name: "max age", policy: MaxAgePolicy{MaxAge: 24 * time.Hour, Now: func() time.Time { return now }}, want: 2},
+		{name: "max age disabled", policy: MaxAgePolicy{}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evicted := tt.policy.Evict(archives)
+			if len(evicted) != tt.want {
+				t.Fatalf("expected %d evicted archives got %v", tt.want, evicted)
+			}
+			for i, archive := range evicted {
+				if archive.Path != archives[i].Path {
+					t.Errorf("expected the oldest archives to be evicted got %v", evicted)
+				}
+			}
+		})
+	}
+}
+
+// Keep the oldest and the newest archives.
+type keepEnds struct{}
+
+func (keepEnds) Evict(archives []ArchiveInfo) []ArchiveInfo {
+	if len(archives) < 3 {
+		return nil
+	}
+	return archives[1 : len(archives)-1]
+}
+
+func (keepEnds) String() string {
+	return "keep-ends"
+}
+
+func TestWithRetentionPolicy(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-retention-policy"),
+		WithNowFunc(func() time.Time { return now }),
+		WithRetentionPolicy(keepEnds{}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	var paths []string
+	for range 4 {
+		now = now.Add(time.Hour)
+		if _, err := k.Write([]byte("abc\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		path, err := k.RotateNow()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		paths = append(paths, path)
+	}
+	archives := k.Archives()
+	if len(archives) != 2 || archives[0].Path != paths[0] || archives[1].Path != paths[3] {
+		t.Fatalf("expected the oldest and the newest archives to be kept got %+v", archives)
+	}
+	for _, path := range paths[1:3] {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed got %v", path, err)
+		}
+	}
+	deletions := k.Deletions()
+	if len(deletions) != 2 || deletions[0].Policy != DeletionRetentionPolicy || deletions[0].Reason != "evicted by the retention policy keep-ends" {
+		t.Errorf("expected 2 deletions by the retention policy got %+v", deletions)
+	}
+}
+
+func TestWithMaxAge(t *testing.T) {
+	folder := t.TempDir()
+	opts := []Opt{WithFolder(folder), WithName("test-max-age"), WithMaxAge(time.Hour), WithSkipEmptyRotation()}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	old, err := k.RotateNow()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	backdated := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(old, backdated, backdated); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	k, err = New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if _, err := k.Write([]byte("def\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	recent, err := k.RotateNow()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if archives := k.Archives(); len(archives) != 1 || archives[0].Path != recent {
+		t.Errorf("expected only the recent archive to be kept got %+v", archives)
+	}
+	if deletions := k.Deletions(); len(deletions) != 1 || deletions[0].Policy != DeletionMaxAge {
+		t.Errorf("expected a deletion by the max age got %+v", deletions)
+	}
+}
+
+func TestWithMaxAgeDistrustedModTime(t *testing.T) {
+	for _, trust := range []bool{true, false} {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		k, err := New(
+			WithFolder(t.TempDir()),
+			WithName("test-max-age-distrusted"),
+			WithNowFunc(func() time.Time { return now }),
+			WithMaxAge(time.Hour),
+			WithTrustModTime(trust),
+		)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		// The archives are modified now, long after the time of the Keeper recorded in their names
+		for range 2 {
+			if _, err := k.Write([]byte("abc\n")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if _, err := k.RotateNow(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			now = now.Add(2 * time.Hour)
+		}
+		want := 2
+		if !trust {
+			want = 1
+		}
+		if archives := k.Archives(); len(archives) != want {
+			t.Errorf("expected %d archives when trusting the modification time is %t got %+v", want, trust, archives)
+		}
+		if err := k.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+}
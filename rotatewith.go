@@ -0,0 +1,62 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// The labels allowed by [RotateOpts].
+var rotationLabelRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// RotateOpts are the one-off overrides of a rotation, see [Keeper.RotateWith].
+// The zero value rotates like [Keeper.RotateNow].
+type RotateOpts struct {
+	// A label appended to the name of the archive after a dot, before the compression extension,
+	// such as "pre-deploy" for "2024-01-02-app.log.pre-deploy.gz", see [ArchiveMeta.Label].
+	// It may only contain letters, digits, "_" and "-", and can not be used with an archive name layout
+	// that places the compression extension itself with {{ .compressionExt }}.
+	Label string
+	// Keep the archive uncompressed even if the Keeper compresses its archives, this requires a label,
+	// since the next Keeper compresses the unlabeled archives left uncompressed, see [WithGzip].
+	NoCompression bool
+	// Do not remove the expired archives after this rotation.
+	// The archive still counts toward the retention of the following rotations.
+	NoRetention bool
+}
+
+// Rotate to a new file immediately like [Keeper.RotateNow], with one-off overrides for this rotation only,
+// such as a specially labeled snapshot before a risky change.
+//
+// Example usage:
+//
+//	archivePath, err := keeper.RotateWith(lorekeeper.RotateOpts{Label: "pre-deploy", NoRetention: true})
+func (k *Keeper) RotateWith(opts RotateOpts) (archivePath string, err error) {
+	k.mu.Lock()
+	err = k.checkRotateOpts(opts)
+	k.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	return k.rotateByWith(RotationManual, opts)
+}
+
+func (k *Keeper) checkRotateOpts(opts RotateOpts) error {
+	if len(opts.Label) > 0 {
+		if !rotationLabelRegexp.MatchString(opts.Label) {
+			return fmt.Errorf("failed to rotate, invalid label %q, expected letters, digits, \"_\" or \"-\"", opts.Label)
+		}
+		if k.layoutHasCompressionExt() {
+			return fmt.Errorf("failed to rotate, a label can not be used with an archive name layout containing {{ .compressionExt }}")
+		}
+	}
+	if opts.NoCompression && k.compressor != nil && len(opts.Label) == 0 {
+		return fmt.Errorf("failed to rotate, skipping the compression requires a label")
+	}
+	return nil
+}
+
+// Tell whether the archive is left uncompressed on purpose by [RotateOpts.NoCompression].
+func (k *Keeper) isLabeledArchive(path string) bool {
+	meta, err := k.parseArchiveName(path)
+	return err == nil && len(meta.Label) > 0
+}
@@ -0,0 +1,68 @@
+package lorekeeper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeeperRotateWith(t *testing.T) {
+	opts := []Opt{WithFolder(t.TempDir()), WithName("test-rotate-with"), WithGzip(), WithMaxFiles(1)}
+	k, err := New(opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("before\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("snapshot\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archivePath, err := k.RotateWith(RotateOpts{Label: "pre-deploy", NoCompression: true, NoRetention: true})
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !strings.HasSuffix(archivePath, ".log.pre-deploy") {
+		t.Errorf("expected an uncompressed labeled archive got %q", archivePath)
+	}
+	// The retention was skipped, so the previous archive is kept over the max files
+	if archives := k.Archives(); len(archives) != 2 {
+		t.Errorf("expected 2 archives got %d", len(archives))
+	}
+	meta, err := k.ParseArchiveName(archivePath)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if meta.Label != "pre-deploy" || meta.Compressed {
+		t.Errorf("expected an uncompressed archive labeled pre-deploy got %+v", meta)
+	}
+	// Keep the labeled archive through the rotation of Close
+	if _, err := Options(WithMaxFiles(0))(k); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The labeled archive is not compressed by the next Keeper
+	k, err = New(append(opts, WithMaxFiles(0))...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	found := false
+	for _, archive := range k.Archives() {
+		found = found || archive.Path == archivePath
+	}
+	if !found {
+		t.Errorf("expected the labeled archive %q to stay uncompressed", archivePath)
+	}
+
+	for _, invalid := range []RotateOpts{{Label: "pre deploy"}, {Label: "../x"}, {NoCompression: true}} {
+		if _, err := k.RotateWith(invalid); err == nil {
+			t.Errorf("expected error for %+v got nil", invalid)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package lorekeeper
+
+import "math/rand/v2"
+
+// Decide whether the msg is left out by the sampling of [WithSampling], counting it if so.
+func (k *Keeper) sampledOut(msg []byte) bool {
+	if k.sampleRate >= 1 {
+		return false
+	}
+	if k.sampleMatcher != nil && !k.sampleMatcher(msg) {
+		return false
+	}
+	if rand.Float64() < k.sampleRate {
+		return false
+	}
+	k.stats.SampledOut++
+	return true
+}
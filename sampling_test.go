@@ -0,0 +1,47 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestKeeperWithSampling(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-sampling"),
+		WithSampling(0, func(msg []byte) bool { return bytes.HasPrefix(msg, []byte("DEBUG")) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{"DEBUG a\n", "INFO b\n", "DEBUG c\n"} {
+		if n, err := k.Write([]byte(msg)); err != nil || n != len(msg) {
+			t.Fatalf("expected %d bytes and no error got %d and %v", len(msg), n, err)
+		}
+	}
+	if _, err := k.WriteUrgent([]byte("DEBUG urgent\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	content, err := os.ReadFile(k.CurrentFilePath())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "INFO b\nDEBUG urgent\n" {
+		t.Errorf("expected only the unmatched and urgent messages got %q", content)
+	}
+	if got := k.Stats().SampledOut; got != 2 {
+		t.Errorf("expected 2 sampled out messages got %d", got)
+	}
+
+	if _, err := New(WithName("test-sampling-invalid"), WithSampling(1.5, nil)); err == nil {
+		t.Errorf("expected an error for a rate above 1")
+	}
+	if _, err := New(WithName("test-sampling-invalid"), WithSampling(math.NaN(), nil)); err == nil {
+		t.Errorf("expected an error for a rate that is not a number")
+	}
+}
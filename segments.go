@@ -0,0 +1,211 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// The in-memory segments of [WithDoubleBuffering].
+// Writers append to the active segment under a short lock,
+// while the flusher writes the other segment to the log files under the lock of the Keeper.
+type segments struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	// The max size of the active segment in bytes
+	size     int
+	active   segment
+	flushed  segment
+	stopping bool
+	done     chan struct{}
+}
+
+// The operations of a segment, the messages are stored back to back in data.
+type segment struct {
+	data []byte
+	ops  []segmentOp
+	// The number of operations the flusher is done with, the others are abandoned if it panics
+	written int
+}
+
+// An operation of a segment, either a message or a rotation.
+type segmentOp struct {
+	// The end offset of the message in the data of the segment
+	end int
+	// The rotation to do instead of writing a message
+	rotate   bool
+	reason   RotationReason
+	rotation RotateOpts
+	// Whether the message is written with [Keeper.WriteUrgent]
+	urgent bool
+	// Where to send the result of the operation, if the caller waits for it
+	done chan<- segmentResult
+}
+
+// The result of an operation of a segment.
+type segmentResult struct {
+	n          int
+	generation uint64
+	err        error
+	// The archive produced by a rotation
+	archivePath string
+}
+
+func newSegments(size int) *segments {
+	s := &segments{size: size, done: make(chan struct{})}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Copy the msg into the active segment, waiting for the flusher if the segment is full.
+// The result of the write is sent to done if it is not nil.
+func (s *segments) append(msg []byte, done chan<- segmentResult) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for !s.stopping && len(s.active.ops) > 0 && len(s.active.data)+len(msg) > s.size {
+		s.cond.Wait()
+	}
+	if s.stopping {
+		return 0, fmt.Errorf("failed to write, caused by %w", os.ErrClosed)
+	}
+	s.active.data = append(s.active.data, msg...)
+	s.active.ops = append(s.active.ops, segmentOp{end: len(s.active.data), done: done})
+	s.cond.Broadcast()
+	return len(msg), nil
+}
+
+// Copy the urgent msg into the active segment without waiting for room, see [Keeper.WriteUrgent].
+func (s *segments) appendUrgent(msg []byte, done chan<- segmentResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping {
+		return fmt.Errorf("failed to write, caused by %w", os.ErrClosed)
+	}
+	s.active.data = append(s.active.data, msg...)
+	s.active.ops = append(s.active.ops, segmentOp{end: len(s.active.data), urgent: true, done: done})
+	s.cond.Broadcast()
+	return nil
+}
+
+// Queue a rotation after the messages appended so far, so that they land in the rotated file
+// and the messages appended afterward land in the new one.
+// It returns false if the segments are stopped.
+func (s *segments) appendRotation(reason RotationReason, opts RotateOpts, done chan<- segmentResult) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping {
+		return false
+	}
+	s.active.ops = append(s.active.ops, segmentOp{end: len(s.active.data), rotate: true, reason: reason, rotation: opts, done: done})
+	s.cond.Broadcast()
+	return true
+}
+
+// Stop the flusher once it has written every message, refusing new ones.
+func (s *segments) stop() {
+	s.mu.Lock()
+	s.stopping = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// Write the segments to the log files until the segments are stopped.
+func (k *Keeper) flushSegments(s *segments) {
+	// Restarted after a panic, fail the operations of the segment being written
+	s.mu.Lock()
+	for _, op := range s.flushed.ops[s.flushed.written:] {
+		if op.done != nil {
+			op.done <- segmentResult{err: fmt.Errorf("failed to write, the flusher of the segments panicked")}
+		}
+	}
+	s.flushed.data = s.flushed.data[:0]
+	s.flushed.ops = s.flushed.ops[:0]
+	s.flushed.written = 0
+	s.mu.Unlock()
+
+	for {
+		s.mu.Lock()
+		for len(s.active.ops) == 0 && !s.stopping {
+			s.cond.Wait()
+		}
+		if len(s.active.ops) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		// Swap the segments so that writers can keep appending while this one is written
+		s.active, s.flushed = s.flushed, s.active
+		s.cond.Broadcast()
+		s.mu.Unlock()
+
+		k.writeFlushed(s)
+
+		s.mu.Lock()
+		s.flushed.data = s.flushed.data[:0]
+		s.flushed.ops = s.flushed.ops[:0]
+		s.flushed.written = 0
+		s.mu.Unlock()
+	}
+}
+
+// Write the flushed segment to the log files.
+func (k *Keeper) writeFlushed(s *segments) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	start := 0
+	for i, op := range s.flushed.ops {
+		s.flushed.written = i
+		if op.rotate {
+			result := segmentResult{err: k.rotateLocked(op.reason, op.rotation), generation: k.generation}
+			if result.err == nil {
+				result.archivePath = k.lastArchivePath
+			}
+			op.done <- result
+			continue
+		}
+		var n int
+		var err error
+		if op.urgent {
+			n, err = k.writeUrgentLocked(s.flushed.data[start:op.end])
+		} else {
+			n, err = k.writeLocked(s.flushed.data[start:op.end])
+		}
+		start = op.end
+		if op.done != nil {
+			op.done <- segmentResult{n: n, generation: k.generation, err: err}
+			continue
+		}
+		// The writer is long gone, report the failure instead
+		if err != nil {
+			k.handleError(err)
+		}
+	}
+}
+
+// Start, resize or stop the segments according to the configured size, see [WithDoubleBuffering].
+func (k *Keeper) configureSegments() {
+	s := k.segments.Load()
+	switch {
+	case k.segmentSize > 0 && s == nil:
+		s = newSegments(k.segmentSize)
+		k.segments.Store(s)
+		k.goSupervised("segments", func() { k.flushSegments(s) }, func() { close(s.done) })
+	case k.segmentSize > 0:
+		s.mu.Lock()
+		s.size = k.segmentSize
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	case s != nil:
+		k.segments.Store(nil)
+		s.stop()
+	}
+}
+
+// Stop the flusher of [WithDoubleBuffering] once every buffered message is written,
+// the lock of the Keeper must not be held.
+func (k *Keeper) stopSegmentsAndWait() {
+	if s := k.segments.Swap(nil); s != nil {
+		s.stop()
+		<-s.done
+	}
+}
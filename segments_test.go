@@ -0,0 +1,79 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeeperWithDoubleBuffering(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-double-buffering"),
+		WithMaxSize(64),
+		WithDoubleBuffering(32),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// Writes do not wait for the file operations
+	k.mu.Lock()
+	written := make(chan error)
+	go func() {
+		_, err := k.Write([]byte("first\n"))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Errorf("expected no error got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected the write to not wait for the lock of the Keeper")
+	}
+	k.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 10 {
+				if _, err := k.Write([]byte(fmt.Sprintf("%d-%d\n", i, j))); err != nil {
+					t.Errorf("expected no error got %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	archives := k.Archives()
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("closed\n")); err == nil {
+		t.Errorf("expected error since Keeper is closed")
+	}
+
+	// Every message ends up in the log files exactly once
+	var content strings.Builder
+	for _, archive := range k.Archives() {
+		b, err := os.ReadFile(archive.Path)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		content.Write(b)
+	}
+	lines := strings.Split(strings.TrimSpace(content.String()), "\n")
+	if len(lines) != 101 {
+		t.Errorf("expected 101 lines got %d", len(lines))
+	}
+	if len(archives) == 0 {
+		t.Errorf("expected the flusher to rotate the log files")
+	}
+}
@@ -0,0 +1,317 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// A [KeeperSet] creates and caches [Keeper]s keyed by an arbitrary string, such as a tenant, a shard, or a job ID.
+// Use [NewKeeperSet] to create a new KeeperSet.
+type KeeperSet struct {
+	nameLayout   *template.Template
+	folderLayout *template.Template
+	opts         []Opt
+	// See [WithStrictNames] for documentation.
+	strictNames bool
+	// See [WithClock] for documentation.
+	clock Clock
+
+	mu      sync.Mutex
+	keepers map[string]*Keeper
+	// The keys whose evicted Keeper is being closed, the channel is closed once it is
+	closing map[string]chan struct{}
+	// See [KeeperSet.SetMaxKeepers] for documentation.
+	maxKeepers int
+	// See [KeeperSet.SetIdleTimeout] for documentation.
+	idleTimeout     time.Duration
+	evictTimer      Timer
+	evictGeneration uint64
+}
+
+// The shortest interval between two checks for idle Keepers, see [KeeperSet.SetIdleTimeout].
+const minIdleCheckInterval = 10 * time.Millisecond
+
+// A Keeper removed from the set, which must be closed once the lock of the set is released.
+type evictedKeeper struct {
+	key    string
+	keeper *Keeper
+}
+
+// Create a new [KeeperSet].
+//
+// The nameLayout and folderLayout are parsed using the [text/template] package,
+// and are rendered with the key of the Keeper to decide the name and the folder of that Keeper.
+// The supported arguments are:
+//   - {{ .key }} the key used to get the Keeper, escaped like a URL path segment with its capital letters escaped as well,
+//     such as "%41cme%2Feu" for "Acme/eu", so that different keys never share a Keeper.
+//
+// If nameLayout is empty, the key is used as the name.
+// If folderLayout is empty, the folder is decided by the given options.
+// The opts are applied to every Keeper created by this set, see [Opt] for all available options.
+// The idle eviction of [KeeperSet.SetIdleTimeout] runs on the clock of the opts, see [WithClock].
+//
+// Example usage:
+//
+//	tenants, err := lorekeeper.NewKeeperSet(
+//		"tenant-{{ .key }}",
+//		"/var/log/tenants",
+//		lorekeeper.WithMaxSize(10*lorekeeper.Mb),
+//	)
+//	keeper, err := tenants.Get("acme")
+func NewKeeperSet(nameLayout, folderLayout string, opts ...Opt) (*KeeperSet, error) {
+	set := &KeeperSet{
+		opts:    opts,
+		clock:   systemClock{},
+		keepers: make(map[string]*Keeper),
+		closing: make(map[string]chan struct{}),
+	}
+	if k, err := configureDetached(opts...); err == nil {
+		set.strictNames = k.strictNames
+		set.clock = k.clock
+	}
+	if len(nameLayout) == 0 {
+		nameLayout = "{{ .key }}"
+	}
+	templ, err := template.New("lorekeeper-set-name-template").Parse(nameLayout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse name layout, caused by %w", err)
+	}
+	set.nameLayout = templ
+
+	if len(folderLayout) > 0 {
+		templ, err := template.New("lorekeeper-set-folder-template").Parse(folderLayout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse folder layout, caused by %w", err)
+		}
+		set.folderLayout = templ
+	}
+	return set, nil
+}
+
+// Get the [Keeper] of the given key, creating it if it does not exist yet.
+func (s *KeeperSet) Get(key string) (*Keeper, error) {
+	for {
+		keeper, evicted, wait, err := s.getOrEvict(key)
+		if wait != nil {
+			// The previous Keeper of the key is still being closed
+			<-wait
+			continue
+		}
+		if evicted == nil {
+			return keeper, err
+		}
+		if err := s.closeEvicted(*evicted); err != nil {
+			return nil, fmt.Errorf("failed to evict keeper, caused by %w", err)
+		}
+	}
+}
+
+// Get or create the Keeper of the given key, unless a Keeper must be evicted first to make room for it,
+// or the previous Keeper of the key is still being closed, in which case the channel to wait for is returned.
+func (s *KeeperSet) getOrEvict(key string) (*Keeper, *evictedKeeper, chan struct{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if keeper, ok := s.keepers[key]; ok {
+		return keeper, nil, nil, nil
+	}
+	if wait, ok := s.closing[key]; ok {
+		return nil, nil, wait, nil
+	}
+	if s.strictNames && isUnsafeName(key) {
+		return nil, nil, nil, fmt.Errorf("failed to get keeper, key %q contains a path separator or is made only of dots", key)
+	}
+
+	name, err := renderKey(s.nameLayout, key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to render name of %q, caused by %w", key, err)
+	}
+	opts := append(append([]Opt(nil), s.opts...), WithName(name))
+	if s.folderLayout != nil {
+		folder, err := renderKey(s.folderLayout, key)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to render folder of %q, caused by %w", key, err)
+		}
+		opts = append(opts, WithFolder(folder))
+	}
+
+	// Make room for the new Keeper
+	if s.maxKeepers > 0 && len(s.keepers) >= s.maxKeepers {
+		evicted := s.evictLeastRecentlyUsed()
+		return nil, &evicted, nil, nil
+	}
+
+	keeper, err := New(opts...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create keeper for %q, caused by %w", key, err)
+	}
+	s.keepers[key] = keeper
+	return keeper, nil, nil, nil
+}
+
+// Set the maximum number of open [Keeper]s in this set.
+// When the set is full, getting a new key closes the Keeper that was least recently written to.
+// Set this value to zero or negative will disable this feature, which is the default.
+func (s *KeeperSet) SetMaxKeepers(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxKeepers = n
+}
+
+// Automatically close [Keeper]s that have not been written to for the given duration,
+// capping the number of open file descriptors for workloads with many sporadic keys.
+// The Keepers are checked every half of the duration, but no more often than every 10ms,
+// on the clock of the options of the set, see [WithClock].
+// A closed Keeper is recreated on the next [KeeperSet.Get] of its key,
+// so callers should always get the Keeper from the set instead of holding on to it.
+// Set this value to zero or negative will disable this feature, which is the default.
+func (s *KeeperSet) SetIdleTimeout(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopEvictTimer()
+	s.idleTimeout = d
+	if d > 0 {
+		s.scheduleEviction()
+	}
+}
+
+// Schedule the next check for idle Keepers, the lock of the set must be held.
+func (s *KeeperSet) scheduleEviction() {
+	generation := s.evictGeneration
+	s.evictTimer = s.clock.AfterFunc(max(s.idleTimeout/2, minIdleCheckInterval), func() {
+		s.evictOnTimer(generation)
+	})
+}
+
+// Close the idle Keepers then schedule the next check, unless the idle timeout changed in the meantime.
+func (s *KeeperSet) evictOnTimer(generation uint64) {
+	s.mu.Lock()
+	if generation != s.evictGeneration {
+		s.mu.Unlock()
+		return
+	}
+	evicted := s.evictIdle()
+	s.mu.Unlock()
+
+	_ = s.closeEvicted(evicted...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if generation == s.evictGeneration {
+		s.scheduleEviction()
+	}
+}
+
+// Stop the checks for idle Keepers, the lock of the set must be held.
+func (s *KeeperSet) stopEvictTimer() {
+	s.evictGeneration++
+	if s.evictTimer != nil {
+		s.evictTimer.Stop()
+		s.evictTimer = nil
+	}
+}
+
+// Remove all the Keepers that have been idle for longer than the idle timeout, the lock of the set must be held.
+func (s *KeeperSet) evictIdle() []evictedKeeper {
+	var evicted []evictedKeeper
+	for key, keeper := range s.keepers {
+		if keeper.now().Sub(keeper.getLastWrite()) >= s.idleTimeout {
+			evicted = append(evicted, s.evict(key))
+		}
+	}
+	return evicted
+}
+
+// Remove the Keeper that was least recently written to, the lock of the set must be held.
+func (s *KeeperSet) evictLeastRecentlyUsed() evictedKeeper {
+	var (
+		lruKey  string
+		lruTime time.Time
+	)
+	for key, keeper := range s.keepers {
+		if lastWrite := keeper.getLastWrite(); len(lruKey) == 0 || lastWrite.Before(lruTime) {
+			lruKey, lruTime = key, lastWrite
+		}
+	}
+	return s.evict(lruKey)
+}
+
+// Remove the Keeper of the key from the set, which holds off the next Keeper of the key until closeEvicted,
+// the lock of the set must be held.
+func (s *KeeperSet) evict(key string) evictedKeeper {
+	keeper := s.keepers[key]
+	delete(s.keepers, key)
+	s.closing[key] = make(chan struct{})
+	return evictedKeeper{key: key, keeper: keeper}
+}
+
+// Close the evicted Keepers without holding the lock of the set, so that a slow close does not block [KeeperSet.Get].
+func (s *KeeperSet) closeEvicted(evicted ...evictedKeeper) error {
+	var errs []error
+	for _, e := range evicted {
+		if err := e.keeper.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close keeper for %q, caused by %w", e.key, err))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range evicted {
+		close(s.closing[e.key])
+		delete(s.closing, e.key)
+	}
+	return errors.Join(errs...)
+}
+
+// Close all the [Keeper]s created by this set and stop the idle eviction.
+// The set can still be used afterward, calling [KeeperSet.Get] will create new Keepers.
+func (s *KeeperSet) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopEvictTimer()
+
+	var errs []error
+	for key, keeper := range s.keepers {
+		if err := keeper.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close keeper for %q, caused by %w", key, err))
+		}
+		delete(s.keepers, key)
+	}
+	return errors.Join(errs...)
+}
+
+// The key is encoded first, so that keys derived from user input can not inject paths, see [WithStrictNames].
+func renderKey(templ *template.Template, key string) (string, error) {
+	var buff bytes.Buffer
+	if err := templ.Execute(&buff, map[string]any{"key": encodeKey(key)}); err != nil {
+		return "", fmt.Errorf("failed to execute template, caused by %w", err)
+	}
+	return buff.String(), nil
+}
+
+// Encode the key like the folder of a tenant, escaping its capital letters as well since [WithName] lowercases the name,
+// the encoding is reversible so different keys never share a Keeper.
+func encodeKey(key string) string {
+	escaped := tenantFolderName(key)
+	var b strings.Builder
+	for i := 0; i < len(escaped); i++ {
+		switch c := escaped[i]; {
+		case c == '%' && i+2 < len(escaped):
+			// Keep the escaped bytes as they are, their hex digits are decoded regardless of case
+			b.WriteString(escaped[i : i+3])
+			i += 2
+		case 'A' <= c && c <= 'Z':
+			fmt.Fprintf(&b, "%%%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
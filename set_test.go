@@ -0,0 +1,237 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeeperSet(t *testing.T) {
+	folder := t.TempDir()
+	set, err := NewKeeperSet("tenant-{{ .key }}", folder, WithMaxSize(Mb))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	acme, err := set.Get("acme")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	again, err := set.Get("acme")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if acme != again {
+		t.Errorf("expected the same keeper for the same key")
+	}
+	other, err := set.Get("globex")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if acme == other {
+		t.Errorf("expected different keepers for different keys")
+	}
+
+	if _, err := acme.Write([]byte("hello acme")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(folder, "tenant-acme.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "hello acme" {
+		t.Errorf("expected message in the tenant log got %q", content)
+	}
+
+	if err := set.Close(); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+	if _, ok := registry.Get(acme.name); ok {
+		t.Errorf("expected keeper to be closed")
+	}
+}
+
+func TestKeeperSetKeyCollisions(t *testing.T) {
+	folder := t.TempDir()
+	set, err := NewKeeperSet("tenant-{{ .key }}", folder)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+
+	for _, keys := range [][2]string{{"Acme", "acme"}, {"x/y", "x_y"}, {"a b", "a-b"}, {"x%2Fy", "x/y"}} {
+		first, err := set.Get(keys[0])
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		second, err := set.Get(keys[1])
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if first == second || first.CurrentFilePath() == second.CurrentFilePath() {
+			t.Errorf("expected different keepers for %q and %q got %q", keys[0], keys[1], first.CurrentFilePath())
+		}
+	}
+}
+
+func TestNewKeeperSetInvalidLayout(t *testing.T) {
+	if _, err := NewKeeperSet("{{ key }}", ""); err == nil {
+		t.Errorf("expected error for invalid name layout")
+	}
+	if _, err := NewKeeperSet("", "{{ .key"); err == nil {
+		t.Errorf("expected error for invalid folder layout")
+	}
+}
+
+func TestKeeperSetMaxKeepers(t *testing.T) {
+	set, err := NewKeeperSet("max-keepers-{{ .key }}", t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+	set.SetMaxKeepers(2)
+
+	first, _ := set.Get("first")
+	second, _ := set.Get("second")
+	if _, err := second.Write([]byte("recently used")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := set.Get("third"); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if len(set.keepers) != 2 {
+		t.Errorf("expected 2 keepers got %d", len(set.keepers))
+	}
+	if _, ok := set.keepers["first"]; ok {
+		t.Errorf("expected the least recently used keeper to be evicted")
+	}
+	if _, err := first.Write([]byte("closed")); err == nil {
+		t.Errorf("expected evicted keeper to be closed")
+	}
+}
+
+func TestKeeperSetIdleTimeout(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &stubClock{now: start}
+	set, err := NewKeeperSet("idle-{{ .key }}", t.TempDir(), WithClock(clock))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+	set.SetIdleTimeout(time.Minute)
+	if len(clock.delays) != 1 || clock.delays[0] != 30*time.Second {
+		t.Fatalf("expected the check to be scheduled in 30s on the clock got %v", clock.delays)
+	}
+
+	sporadic, err := set.Get("sporadic")
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := sporadic.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	clock.fire(start.Add(30 * time.Second))
+	if _, err := sporadic.Write([]byte("def\n")); err != nil {
+		t.Errorf("expected the keeper to be kept before the idle timeout got %v", err)
+	}
+
+	clock.fire(start.Add(2 * time.Minute))
+	set.mu.Lock()
+	n := len(set.keepers)
+	set.mu.Unlock()
+	if n != 0 {
+		t.Errorf("expected idle keeper to be evicted, got %d keepers", n)
+	}
+	if _, err := sporadic.Write([]byte("closed")); err == nil {
+		t.Errorf("expected evicted keeper to be closed")
+	}
+	if len(clock.delays) != 3 {
+		t.Errorf("expected the next check to be scheduled after each check got %v", clock.delays)
+	}
+}
+
+func TestKeeperSetIdleTimeoutTiny(t *testing.T) {
+	clock := &stubClock{now: time.Now()}
+	set, err := NewKeeperSet("idle-tiny-{{ .key }}", t.TempDir(), WithClock(clock))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+	set.SetIdleTimeout(time.Nanosecond)
+	if len(clock.delays) != 1 || clock.delays[0] != minIdleCheckInterval {
+		t.Errorf("expected the check interval to be clamped to %v got %v", minIdleCheckInterval, clock.delays)
+	}
+
+	// The wall clock must not panic either
+	set, err = NewKeeperSet("idle-tiny-wall-{{ .key }}", t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+	set.SetIdleTimeout(time.Nanosecond)
+	time.Sleep(3 * minIdleCheckInterval)
+}
+
+func TestKeeperSetEvictionDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	set, err := NewKeeperSet("evict-slow-{{ .key }}", t.TempDir(), WithOnRotate(func(archivePath string) {
+		if strings.Contains(archivePath, "evict-slow-slow") {
+			<-release
+		}
+	}))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+	set.SetMaxKeepers(2)
+	for _, key := range []string{"slow", "other"} {
+		k, err := set.Get(key)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if _, err := k.Write([]byte(key + "\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Evicting slow blocks until its rotation hook returns
+	done := make(chan error, 1)
+	go func() {
+		_, err := set.Get("third")
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	got := make(chan error, 1)
+	go func() {
+		_, err := set.Get("other")
+		got <- err
+	}()
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Errorf("expected no error got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected getting another keeper not to wait for the eviction")
+	}
+
+	select {
+	case <-done:
+		t.Fatalf("expected the eviction to wait for the rotation hook")
+	default:
+	}
+	close(release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected no error got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the eviction to finish")
+	}
+}
@@ -0,0 +1,131 @@
+package lorekeeper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Close the Keeper like [Keeper.Close], giving up on the work left once ctx is done,
+// so that a service with a bounded graceful shutdown window does not get killed in the middle of a compression.
+// The buffered messages are flushed and the current log file is rotated, then Shutdown waits for the hooks
+// of [WithOnRotate], the uploads of [WithUploader] and the messages queued by [WithForwarder].
+// Once ctx is done, a compression in progress is interrupted, leaving the uncompressed archive to be compressed
+// on the next start, the context of the upload in progress is cancelled, the hooks, uploads and messages still queued
+// are dropped, and the Keeper is closed anyway.
+// The returned error wraps the error of ctx and tells what was left unfinished.
+//
+// Example usage:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := keeper.Shutdown(ctx); err != nil {
+//		// Handle error
+//	}
+func (k *Keeper) Shutdown(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, k.shutdown.abort)
+	err := k.close()
+	stop()
+	var unfinished []string
+	if err != nil && k.shutdown.aborted() {
+		unfinished = append(unfinished, "the rotation of the current log file")
+		k.mu.Lock()
+		if !k.closed {
+			k.registry.unregister(k.name)
+			err = errors.Join(err, k.free())
+		}
+		k.mu.Unlock()
+	}
+	// Outside the lock, since the hooks may call the methods of the Keeper
+	if n := k.hooks.waitContext(ctx); n > 0 {
+		unfinished = append(unfinished, fmt.Sprintf("%d hooks", n))
+	}
+	if n := k.uploads.waitContext(ctx); n > 0 {
+		unfinished = append(unfinished, fmt.Sprintf("%d uploads", n))
+	}
+	if n := k.waitForwardContext(ctx); n > 0 {
+		unfinished = append(unfinished, fmt.Sprintf("%d messages to forward", n))
+	}
+	if !k.waitCompaction(ctx) {
+		unfinished = append(unfinished, "the compaction of the archives")
+	}
+	// Cancel the hook or the upload left running, once it is no longer counted as finished
+	if ctx.Err() != nil {
+		k.shutdown.abort()
+	}
+	if len(unfinished) > 0 {
+		return fmt.Errorf("failed to shut down in time, left %s unfinished, caused by %w", strings.Join(unfinished, " and "), errors.Join(ctx.Err(), err))
+	}
+	return err
+}
+
+// The background work of a Keeper, interrupted when a [Keeper.Shutdown] runs out of time.
+type shutdownState struct {
+	once   sync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *shutdownState) init() {
+	s.once.Do(func() {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	})
+}
+
+// Get the context of the background work, done once it is interrupted.
+func (s *shutdownState) context() context.Context {
+	s.init()
+	return s.ctx
+}
+
+// Interrupt the background work.
+func (s *shutdownState) abort() {
+	s.init()
+	s.cancel()
+}
+
+func (s *shutdownState) aborted() bool {
+	return s.context().Err() != nil
+}
+
+// A Reader failing once its context is done, so that a long copy can be interrupted between two reads.
+type abortableReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r abortableReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// Wait until all the queued hooks ran like wait, or until ctx is done,
+// in which case the hooks still queued are dropped. Returns the number of hooks left unfinished.
+func (q *hookQueue) waitContext(ctx context.Context) int {
+	done := make(chan struct{})
+	go func() {
+		q.wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return 0
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.queue)
+	if q.running {
+		// The running hook can not be stopped, it is left to finish in the background
+		n++
+	}
+	clear(q.queue)
+	q.queue = nil
+	return n
+}
@@ -0,0 +1,140 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A Compressor copying slowly, so that a shutdown can run out of time during a compression.
+type slowCompressor struct{}
+
+func (slowCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return slowWriter{w}, nil
+}
+
+func (slowCompressor) Extension() string {
+	return ".slow"
+}
+
+type slowWriter struct {
+	io.Writer
+}
+
+func (w slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	return w.Writer.Write(p)
+}
+
+func (slowWriter) Close() error {
+	return nil
+}
+
+func TestKeeperShutdown(t *testing.T) {
+	folder := t.TempDir()
+	var uploaded []string
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-shutdown"),
+		WithUploader(UploaderFunc(func(ctx context.Context, localPath string) error {
+			uploaded = append(uploaded, localPath)
+			return nil
+		}), false),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(uploaded) != 1 {
+		t.Errorf("expected the archive rotated on shutdown to be uploaded got %v", uploaded)
+	}
+	if _, err := k.Write([]byte("def\n")); err == nil {
+		t.Errorf("expected an error writing after shutdown")
+	}
+}
+
+func TestKeeperShutdownCancelsUploads(t *testing.T) {
+	started := make(chan struct{}, 2)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-shutdown-uploads"),
+		WithUploader(UploaderFunc(func(ctx context.Context, localPath string) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		}), false),
+		WithUploadRetry(1, 0),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("abc\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = k.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected an error wrapping context.DeadlineExceeded got %v", err)
+	}
+	// The upload of the manual rotation is running, the one of the closing rotation is queued
+	if !strings.Contains(err.Error(), "2 uploads") {
+		t.Errorf("expected 2 uploads left unfinished got %v", err)
+	}
+}
+
+func TestKeeperShutdownInterruptsCompression(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-shutdown-compression"),
+		WithMaxSize(0),
+		WithCompressor(slowCompressor{}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	msg := bytes.Repeat([]byte("0123456789abcdef"), Mb/16)
+	if _, err := k.Write(msg); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = k.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected an error wrapping context.DeadlineExceeded got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the compression to be interrupted got a shutdown of %v", elapsed)
+	}
+	if !k.closed {
+		t.Errorf("expected the keeper to be closed")
+	}
+
+	// The uncompressed archive is left for the next start
+	matches, err := filepath.Glob(filepath.Join(folder, "*-test-shutdown-compression.log"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected 1 uncompressed archive got %v and %v", matches, err)
+	}
+	if b, err := os.ReadFile(matches[0]); err != nil || !bytes.Equal(b, msg) {
+		t.Errorf("expected the uncompressed archive to be complete got %d bytes and %v", len(b), err)
+	}
+}
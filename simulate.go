@@ -0,0 +1,148 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// The maximum number of rotations a simulation may perform, to keep it from running forever.
+const maxSimulatedRotations = 1_000_000
+
+// A [Simulation] describes the projected workload used by [Simulate].
+type Simulation struct {
+	// How long into the future to simulate.
+	Duration time.Duration
+	// The projected number of bytes written per day.
+	BytesPerDay int
+	// The size of an archive relative to the size of the rotated log,
+	// useful to account for compression. Defaults to 1 if it is zero or negative.
+	CompressionRatio float64
+}
+
+// A [SimulationReport] describes the outcome of a [Simulate] run.
+type SimulationReport struct {
+	// Number of rotations happened during the simulation.
+	Rotations int
+	// Archives that would be deleted by retention, in the order of deletion.
+	Deletions []SimulatedDeletion
+	// Number of archives at the end of the simulation.
+	Archives int
+	// Total size of all archives at the end of the simulation.
+	ArchivesSize int
+	// Size of the current log file at the end of the simulation.
+	CurrentFileSize int
+}
+
+// A [SimulatedDeletion] is an archive that would be deleted by retention.
+type SimulatedDeletion struct {
+	// When the archive would be deleted.
+	Time time.Time
+	// Path to the archive.
+	Path string
+	// Size of the archive in bytes.
+	Size int
+	// Whether the archive already exists today, or would be created during the simulation.
+	Existing bool
+}
+
+// Simulate the growth of the files managed by a [Keeper] configured with opts,
+// starting from the current content of its folder, and report when and which files would be deleted.
+// This helps with capacity planning before rolling out a configuration.
+//
+// The simulation never writes, rotates or deletes files, and does not register a Keeper.
+// Writes are assumed to happen at a constant rate, so size based rotations happen exactly at the max size.
+//
+// Example usage:
+//
+//	report, err := lorekeeper.Simulate(
+//		lorekeeper.Simulation{Duration: 30 * 24 * time.Hour, BytesPerDay: 2 * lorekeeper.GB},
+//		lorekeeper.WithFolder("/var/log/app"),
+//		lorekeeper.WithMaxSize(100*lorekeeper.MB),
+//		lorekeeper.WithTotalSize(10*lorekeeper.GB),
+//	)
+func Simulate(sim Simulation, opts ...Opt) (*SimulationReport, error) {
+	if sim.BytesPerDay <= 0 {
+		return nil, fmt.Errorf("failed to simulate, bytes per day must be positive")
+	}
+	ratio := sim.CompressionRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	k, err := configureDetached(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate, caused by %w", err)
+	}
+	schedule := k.cronSchedule
+
+	if k.archives, k.archivesSize, err = k.discoverArchives(); err != nil {
+		return nil, fmt.Errorf("failed to simulate, caused by %w", err)
+	}
+	existing := make(map[string]bool)
+	for _, archive := range k.archives.All() {
+		existing[archive.filePath] = true
+	}
+	if stat, err := k.fsys.Stat(k.getCurrentFilePath()); err == nil {
+		k.currentFileSize = int(stat.Size())
+	}
+
+	report := new(SimulationReport)
+	// Bytes written per nanosecond
+	rate := float64(sim.BytesPerDay) / float64(24*time.Hour)
+	t := k.now()
+	end := t.Add(sim.Duration)
+	for {
+		next := end
+		if k.maxSize > 0 {
+			fill := time.Duration(math.Ceil(float64(max(k.maxSize-k.currentFileSize, 0)) / rate))
+			if t.Add(fill).Before(next) {
+				next = t.Add(fill)
+			}
+		}
+		if schedule != nil {
+			if scheduled := schedule.Next(t); scheduled.Before(next) {
+				next = scheduled
+			}
+		}
+		k.currentFileSize += int(math.Round(float64(next.Sub(t)) * rate))
+		t = next
+		if !t.Before(end) {
+			break
+		}
+
+		report.Rotations++
+		if report.Rotations > maxSimulatedRotations {
+			return nil, fmt.Errorf("failed to simulate, more than %d rotations", maxSimulatedRotations)
+		}
+		archiveName, err := k.newArchiveNameAt(t, RotationSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate, caused by %w", err)
+		}
+		size := int(float64(k.currentFileSize) * ratio)
+		k.archives.Append(&fileInfo{filePath: k.compressedArchivePath(archiveName), size: size, modtime: t})
+		k.archivesSize += size
+		k.currentFileSize = 0
+
+		archives := k.archiveList()
+		removed := make(map[*fileInfo]bool)
+		for _, e := range k.planExpiry(archives, 0, t) {
+			removed[e.archive] = true
+			k.archivesSize -= e.archive.size
+			report.Deletions = append(report.Deletions, SimulatedDeletion{
+				Time:     t,
+				Path:     e.archive.filePath,
+				Size:     e.archive.size,
+				Existing: existing[e.archive.filePath],
+			})
+		}
+		if len(removed) > 0 {
+			k.archives = keptArchives(archives, removed)
+		}
+	}
+
+	report.Archives = k.archives.Length()
+	report.ArchivesSize = k.archivesSize
+	report.CurrentFileSize = k.currentFileSize
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSimulate(t *testing.T) {
+	folder := t.TempDir()
+	existing := filepath.Join(folder, "2000-01-01-simulate.log")
+	if err := os.WriteFile(existing, make([]byte, Kb), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	opts := []Opt{
+		WithName("simulate"),
+		WithFolder(folder),
+		WithTimeLayout("2006-01-02-15"),
+		WithMaxSize(Kb),
+		WithMaxFiles(3),
+	}
+
+	report, err := Simulate(Simulation{Duration: 10*time.Hour + time.Minute, BytesPerDay: 24 * Kb}, opts...)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if report.Rotations != 10 {
+		t.Errorf("expected 10 rotations got %d", report.Rotations)
+	}
+	if len(report.Deletions) != 8 {
+		t.Fatalf("expected 8 deletions got %d", len(report.Deletions))
+	}
+	if first := report.Deletions[0]; first.Path != existing || !first.Existing {
+		t.Errorf("expected the existing archive to be deleted first got %+v", first)
+	}
+	if report.Archives != 3 || report.ArchivesSize != 3*Kb {
+		t.Errorf("expected 3 archives of %d bytes got %d archives of %d bytes", 3*Kb, report.Archives, report.ArchivesSize)
+	}
+
+	// The simulation must not touch the folder
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected folder to be untouched got %d entries", len(entries))
+	}
+	if _, ok := registry.Get("simulate"); ok {
+		t.Errorf("expected simulation to not register a keeper")
+	}
+
+	if _, err := Simulate(Simulation{Duration: time.Hour}, opts...); err == nil {
+		t.Errorf("expected error when bytes per day is not set")
+	}
+}
+
+func TestSimulateMaxAge(t *testing.T) {
+	// The clock is far from the wall clock, the max age must be told by the simulated time
+	clock := &stubClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	report, err := Simulate(
+		Simulation{Duration: 10*time.Hour + time.Minute, BytesPerDay: 24 * Kb},
+		WithName("simulate-max-age"),
+		WithFolder(t.TempDir()),
+		WithClock(clock),
+		WithMaxSize(Kb),
+		WithMaxAge(3*time.Hour+30*time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if report.Rotations != 10 {
+		t.Errorf("expected 10 rotations got %d", report.Rotations)
+	}
+	if len(report.Deletions) != 6 || report.Archives != 4 {
+		t.Errorf("expected 6 deletions and 4 archives got %d deletions and %d archives", len(report.Deletions), report.Archives)
+	}
+}
@@ -0,0 +1,59 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Skip the rotations that would create an empty archive, holding at most the header of [WithFileHeader],
+// such as with [WithMaxAgeAtStartup] or [WithCron] when nothing was written, so that the archives stay meaningful.
+// A current log file with content is always archived, even if it is the same as the newest archive.
+// The skipped rotations are counted in [Stats.SkippedRotations], and [Keeper.RotateNow] returns an empty path for them.
+// Is disabled by default.
+func WithSkipEmptyRotation() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.skipEmptyRotation = true
+		return k, nil
+	}
+}
+
+// Archive every rotation even if it is empty, this is the default.
+func NoSkipEmptyRotation() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.skipEmptyRotation = false
+		return k, nil
+	}
+}
+
+// Tell whether rotating the closed current log file would create an empty archive, holding at most the file header.
+func (k *Keeper) isEmptyRotation() bool {
+	return k.currentFileSize <= k.headerSize
+}
+
+// Keep the empty current log file instead of archiving it, emptying it first if it holds the file header,
+// which is written again.
+func (k *Keeper) skipRotation() error {
+	if k.currentFileSize > 0 {
+		file, err := k.fsys.OpenFile(k.getCurrentFilePath(), os.O_WRONLY|os.O_TRUNC, k.fileMode)
+		if err != nil {
+			return fmt.Errorf("failed to empty log file, caused by %w", err)
+		}
+		_ = file.Close()
+	}
+	file, err := k.getCurrentFile()
+	if err != nil {
+		return err
+	}
+	k.currentFile = file
+	k.currentFileSize = 0
+	k.currentRecords = 0
+	k.currentLines = 0
+	k.currentFirstWrite = time.Time{}
+	k.writeFileHeader()
+	k.lastArchivePath = ""
+	k.rotateAt = time.Time{}
+	k.stats.SkippedRotations++
+	k.resetIdleTimer()
+	return nil
+}
@@ -0,0 +1,57 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithSkipEmptyRotation(t *testing.T) {
+	for i, opt := range []Opt{NoCompression(), WithGzip()} {
+		folder := t.TempDir()
+		name := fmt.Sprintf("test-skip-empty-rotation-%d", i)
+		k, err := New(WithFolder(folder), WithName(name), WithSkipEmptyRotation(), opt)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+
+		// Nothing was written
+		archivePath, err := k.RotateNow()
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if archivePath != "" || len(k.Archives()) != 0 {
+			t.Errorf("expected the empty rotation to be skipped got %q", archivePath)
+		}
+
+		// Identical content, such as a heartbeat, is still archived
+		for _, msg := range []string{"same\n", "same\n", "different\n"} {
+			if _, err := k.Write([]byte(msg)); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if err := k.Rotate(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+		if archives := k.Archives(); len(archives) != 3 {
+			t.Errorf("expected 3 archives got %d", len(archives))
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(folder, name+".log"))
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if len(content) != 0 {
+			t.Errorf("expected an empty current log file got %q", content)
+		}
+		if skipped := k.Stats().SkippedRotations; skipped != 2 {
+			t.Errorf("expected 2 skipped rotations got %d", skipped)
+		}
+		if err := k.Close(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+}
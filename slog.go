@@ -0,0 +1,166 @@
+package lorekeeper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// The number of route keys whose handlers a [SlogRouter] keeps, the handler of an evicted key is created again on its next record.
+const slogRouterMaxHandlers = 1024
+
+// A [SlogRouter] is a [slog.Handler] that routes each record into a [Keeper] of a [KeeperSet],
+// picking the Keeper by the value of an attribute of the record, e.g. "tenant" or "component".
+// Use [NewSlogRouter] to create a new SlogRouter.
+type SlogRouter struct {
+	set        *KeeperSet
+	attr       string
+	fallback   string
+	newHandler func(w io.Writer) slog.Handler
+	// A handler created once by newHandler to answer [SlogRouter.Enabled].
+	enabler slog.Handler
+	// The route key found in the attributes given to [SlogRouter.WithAttrs].
+	key string
+	// Operations from [SlogRouter.WithAttrs] and [SlogRouter.WithGroup] to replay on every new handler.
+	ops []func(slog.Handler) slog.Handler
+	// Whether a group was opened, in which case attributes are no longer top-level.
+	grouped bool
+
+	mu       *sync.Mutex
+	handlers map[string]slog.Handler
+}
+
+// Make sure that SlogRouter implements the [slog.Handler] interface.
+var _ slog.Handler = (*SlogRouter)(nil)
+
+// Create a new [SlogRouter] that routes records into the Keepers of the given set.
+//
+// The Keeper is picked by the value of the top-level attribute named attr,
+// either added to the record or to the logger with [slog.Logger.With].
+// Records without the attribute are routed to the Keeper of the fallback key.
+// The newHandler function creates the handler that formats records for a Keeper,
+// if it is nil, [slog.NewTextHandler] is used.
+//
+// Example usage:
+//
+//	tenants, _ := lorekeeper.NewKeeperSet("tenant-{{ .key }}", "/var/log/tenants")
+//	logger := slog.New(lorekeeper.NewSlogRouter(tenants, "tenant", "unknown", nil))
+//	// This lands in /var/log/tenants/tenant-acme.log
+//	logger.Info("signed in", "tenant", "acme")
+func NewSlogRouter(set *KeeperSet, attr, fallback string, newHandler func(w io.Writer) slog.Handler) *SlogRouter {
+	if newHandler == nil {
+		newHandler = func(w io.Writer) slog.Handler {
+			return slog.NewTextHandler(w, nil)
+		}
+	}
+	return &SlogRouter{
+		set:        set,
+		attr:       attr,
+		fallback:   fallback,
+		newHandler: newHandler,
+		enabler:    newHandler(io.Discard),
+		mu:         new(sync.Mutex),
+		handlers:   make(map[string]slog.Handler),
+	}
+}
+
+// Enabled reports whether the handlers created by newHandler handle records at the given level.
+func (r *SlogRouter) Enabled(ctx context.Context, level slog.Level) bool {
+	return r.enabler.Enabled(ctx, level)
+}
+
+// Handle writes the record into the Keeper picked by the record's attributes.
+func (r *SlogRouter) Handle(ctx context.Context, record slog.Record) error {
+	key := r.key
+	if !r.grouped {
+		record.Attrs(func(attr slog.Attr) bool {
+			if attr.Key == r.attr {
+				key = attr.Value.Resolve().String()
+				return false
+			}
+			return true
+		})
+	}
+	if len(key) == 0 {
+		key = r.fallback
+	}
+	return r.handler(key).Handle(ctx, record)
+}
+
+// WithAttrs returns a new router whose records also contain the given attributes.
+func (r *SlogRouter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := r.clone(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+	if !r.grouped {
+		for _, attr := range attrs {
+			if attr.Key == r.attr {
+				child.key = attr.Value.Resolve().String()
+			}
+		}
+	}
+	return child
+}
+
+// WithGroup returns a new router whose following attributes are nested in the given group.
+func (r *SlogRouter) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return r
+	}
+	child := r.clone(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+	child.grouped = true
+	return child
+}
+
+func (r *SlogRouter) clone(op func(slog.Handler) slog.Handler) *SlogRouter {
+	return &SlogRouter{
+		set:        r.set,
+		attr:       r.attr,
+		fallback:   r.fallback,
+		newHandler: r.newHandler,
+		enabler:    r.enabler,
+		key:        r.key,
+		grouped:    r.grouped,
+		ops:        append(append([]func(slog.Handler) slog.Handler(nil), r.ops...), op),
+		mu:         new(sync.Mutex),
+		handlers:   make(map[string]slog.Handler),
+	}
+}
+
+// Get the handler writing into the Keeper of the given key.
+func (r *SlogRouter) handler(key string) slog.Handler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if h, ok := r.handlers[key]; ok {
+		return h
+	}
+	// Resolve the Keeper on every write, so that Keepers closed by the set are recreated.
+	h := r.newHandler(&keyedWriter{set: r.set, key: key})
+	for _, op := range r.ops {
+		h = op(h)
+	}
+	// Keys may be unbounded, such as request or tenant IDs, evict any handler to make room
+	if len(r.handlers) >= slogRouterMaxHandlers {
+		for evicted := range r.handlers {
+			delete(r.handlers, evicted)
+			break
+		}
+	}
+	r.handlers[key] = h
+	return h
+}
+
+// A keyedWriter writes into the Keeper of a key in a [KeeperSet].
+type keyedWriter struct {
+	set *KeeperSet
+	key string
+}
+
+func (w *keyedWriter) Write(msg []byte) (int, error) {
+	keeper, err := w.set.Get(w.key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get keeper, caused by %w", err)
+	}
+	return keeper.Write(msg)
+}
@@ -0,0 +1,93 @@
+package lorekeeper
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// A [SlogHandler] is a [slog.Handler] that routes each record by its level into the [Keeper]s of a [LevelKeepers],
+// e.g. errors into app-error.log and everything else into app.log.
+// Use [NewSlogHandler] to create a new SlogHandler.
+type SlogHandler struct {
+	levels *LevelKeepers
+	// One handler per Keeper, in the order of [LevelKeepers.Keepers]
+	handlers []slog.Handler
+}
+
+// Make sure that SlogHandler implements the [slog.Handler] interface.
+var _ slog.Handler = (*SlogHandler)(nil)
+
+// Create a new [SlogHandler] writing each record into the Keeper of its level, see [LevelKeepers.For].
+// Records below the level of every policy are discarded.
+// The newHandler function creates the handler that formats records for a Keeper,
+// if it is nil, [slog.NewTextHandler] is used without a minimum level of its own, leaving the filtering to the policies.
+//
+// Example usage:
+//
+//	levels, err := lorekeeper.NewLevelKeepers(
+//		[]lorekeeper.Opt{lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithName("app")},
+//		lorekeeper.LevelPolicy{Level: slog.LevelError, Suffix: "error"},
+//		lorekeeper.LevelPolicy{Level: slog.LevelInfo},
+//	)
+//	logger := slog.New(lorekeeper.NewSlogHandler(levels, nil))
+//	// This lands in /var/log/app/app-error.log
+//	logger.Error("payment failed")
+func NewSlogHandler(levels *LevelKeepers, newHandler func(w io.Writer) slog.Handler) *SlogHandler {
+	if newHandler == nil {
+		newHandler = func(w io.Writer) slog.Handler {
+			return slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug - 4})
+		}
+	}
+	h := &SlogHandler{levels: levels}
+	for _, keeper := range levels.keepers {
+		h.handlers = append(h.handlers, newHandler(keeper))
+	}
+	return h
+}
+
+// Enabled reports whether a Keeper receives the records of the given level and its handler handles them.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	i, ok := h.index(level)
+	return ok && h.handlers[i].Enabled(ctx, level)
+}
+
+// Handle writes the record into the Keeper of its level.
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	i, ok := h.index(record.Level)
+	if !ok {
+		return nil
+	}
+	return h.handlers[i].Handle(ctx, record)
+}
+
+// WithAttrs returns a new handler whose records also contain the given attributes.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.clone(func(inner slog.Handler) slog.Handler { return inner.WithAttrs(attrs) })
+}
+
+// WithGroup returns a new handler whose following attributes are nested in the given group.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	return h.clone(func(inner slog.Handler) slog.Handler { return inner.WithGroup(name) })
+}
+
+func (h *SlogHandler) clone(op func(slog.Handler) slog.Handler) *SlogHandler {
+	child := &SlogHandler{levels: h.levels, handlers: make([]slog.Handler, len(h.handlers))}
+	for i, inner := range h.handlers {
+		child.handlers[i] = op(inner)
+	}
+	return child
+}
+
+// Get the index of the Keeper receiving the records of the given level.
+func (h *SlogHandler) index(level slog.Level) (int, bool) {
+	for i, policy := range h.levels.policies {
+		if level >= policy.Level {
+			return i, true
+		}
+	}
+	return 0, false
+}
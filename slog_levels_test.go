@@ -0,0 +1,42 @@
+package lorekeeper
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	folder := t.TempDir()
+	levels, err := NewLevelKeepers(
+		[]Opt{WithFolder(folder), WithName("test-slog-handler")},
+		LevelPolicy{Level: slog.LevelError, Suffix: "error"},
+		LevelPolicy{Level: slog.LevelDebug},
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer levels.Close()
+
+	logger := slog.New(NewSlogHandler(levels, nil)).With("service", "api")
+	logger.Debug("cache miss")
+	logger.Error("payment failed")
+	logger.Log(context.Background(), slog.LevelDebug-1, "below every policy")
+
+	for name, want := range map[string]string{
+		"test-slog-handler.log":       "cache miss",
+		"test-slog-handler-error.log": "payment failed",
+	} {
+		content, err := os.ReadFile(filepath.Join(folder, name))
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if len(lines) != 1 || !strings.Contains(lines[0], want) || !strings.Contains(lines[0], "service=api") {
+			t.Errorf("expected only %q in %s got %q", want, name, content)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package lorekeeper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlogRouter(t *testing.T) {
+	folder := t.TempDir()
+	set, err := NewKeeperSet("slog-router-{{ .key }}", folder)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+
+	logger := slog.New(NewSlogRouter(set, "tenant", "unknown", nil))
+	logger.Info("from record", "tenant", "acme")
+	logger.With("tenant", "globex").Info("from logger")
+	logger.Info("without tenant")
+	logger.WithGroup("request").Info("nested attribute", "tenant", "acme")
+
+	tests := []struct {
+		key      string
+		contains []string
+		excludes []string
+	}{
+		{key: "acme", contains: []string{"from record"}, excludes: []string{"nested attribute"}},
+		{key: "globex", contains: []string{"from logger"}},
+		{key: "unknown", contains: []string{"without tenant", "nested attribute"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join(folder, "slog-router-"+tt.key+".log"))
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			for _, msg := range tt.contains {
+				if !strings.Contains(string(content), msg) {
+					t.Errorf("expected %q in %q", msg, content)
+				}
+			}
+			for _, msg := range tt.excludes {
+				if strings.Contains(string(content), msg) {
+					t.Errorf("expected no %q in %q", msg, content)
+				}
+			}
+		})
+	}
+}
+
+func TestSlogRouterHandlers(t *testing.T) {
+	set, err := NewKeeperSet("slog-router-{{ .key }}", t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer set.Close()
+
+	created := 0
+	router := NewSlogRouter(set, "tenant", "unknown", func(w io.Writer) slog.Handler {
+		created++
+		return slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelWarn})
+	})
+	for range 3 {
+		if router.Enabled(context.Background(), slog.LevelInfo) || !router.Enabled(context.Background(), slog.LevelError) {
+			t.Errorf("expected only the records from the warn level to be enabled")
+		}
+	}
+	if created != 1 {
+		t.Errorf("expected 1 handler to be created got %d", created)
+	}
+
+	for i := range slogRouterMaxHandlers + 10 {
+		router.handler(fmt.Sprintf("tenant-%d", i))
+	}
+	if got := len(router.handlers); got != slogRouterMaxHandlers {
+		t.Errorf("expected %d handlers got %d", slogRouterMaxHandlers, got)
+	}
+}
@@ -0,0 +1,162 @@
+package lorekeeper
+
+import "time"
+
+// A Stats is a snapshot of the counters of a [Keeper], see [Keeper.Stats].
+// The counters start at zero when the Keeper is created and only go up,
+// so that "are we losing logs?" has a concrete answer.
+// See [Keeper.PublishExpvar] to expose them to the monitoring of the process.
+type Stats struct {
+	// The number of bytes written to the log files.
+	BytesWritten uint64
+	// The size of the current log file in bytes, including the messages waiting in the buffer of [WithBufferSize].
+	CurrentFileSize int64
+	// The number of rotations, not counting the ones skipped or coalesced.
+	Rotations uint64
+	// The number of writes to the current log file that failed.
+	WriteErrors uint64
+	// The number of writes to the current log file attempted after a failed one.
+	Retries uint64
+	// The number of times a write to the current log file succeeded after a failed one.
+	Recoveries uint64
+	// The number of times the current log file was reopened because its descriptor became invalid.
+	Reopens uint64
+	// The number of times the current log file was reopened because it was removed, replaced or truncated, see [WithCurrentFileCheck].
+	LostCurrentFiles uint64
+	// The number of messages written to the fallback writer instead of the current log file, see [WithFallbackWriter].
+	FallbackWrites uint64
+	// The number of messages that could not be written anywhere.
+	Dropped uint64
+	// The number of messages rejected by the quota of [WithQuota], they are also counted as dropped or fallback writes.
+	QuotaRejections uint64
+	// The number of messages rejected by the hard max size of [WithHardMaxSize], they are also counted as dropped or fallback writes.
+	HardMaxRejections uint64
+	// The number of archives removed by the retention, see [Keeper.Deletions] for the last ones.
+	RemovedArchives uint64
+	// The number of tiny archives merged into an older one at startup, see [WithStartupCompaction].
+	CompactedArchives uint64
+	// The number of archives that the retention failed to remove, they are retried on the next rotation.
+	RemoveErrors uint64
+	// The number of records written to the log files, a message split across log files counts in each of them.
+	// A record is a message, a length-prefixed record of [WithLengthPrefixedRecords], or a record of [WithRecordPattern].
+	Records uint64
+	// The number of messages left out by the sampling of [WithSampling].
+	SampledOut uint64
+	// The number of expired archives whose removal waited for their readers, see [WithDeferredRemoval].
+	DeferredRemovals uint64
+	// The number of archives compressed by the Keeper, see [WithGzip].
+	CompressedArchives uint64
+	// The total size of the compressed archives before and after their compression, in bytes.
+	CompressionInputBytes  uint64
+	CompressionOutputBytes uint64
+	// The total wall time spent compressing archives.
+	CompressionTime time.Duration
+	// The number of messages dropped because the queue of [WithAsyncWrites] was full.
+	AsyncDropped uint64
+	// The number of messages waiting in the queue of [WithAsyncWrites].
+	AsyncQueued uint64
+	// The number of messages forwarded by the forwarder of [WithForwarder], the ones it failed to forward,
+	// the ones dropped because its queue was full, and the ones waiting in its queue.
+	Forwarded      uint64
+	ForwardErrors  uint64
+	ForwardDropped uint64
+	ForwardQueued  uint64
+	// The number of messages a follower of [Keeper.Follow] missed because it fell behind.
+	FollowDropped uint64
+	// The number of writes that waited for the rate limit of [WithRateLimit], and the total time they waited.
+	Throttled     uint64
+	ThrottledTime time.Duration
+	// The number of messages dropped by the rate limit of [WithRateLimit], see [WithRateLimitPolicy].
+	RateLimited uint64
+	// The number of rotations skipped by [WithSkipEmptyRotation], or because their archive already exists with [WithDateext].
+	SkippedRotations uint64
+	// The number of scheduled rotations coalesced with a previous rotation, see [WithRotationCoalescing].
+	CoalescedRotations uint64
+	// The number of archives uploaded by the uploader of [WithUploader].
+	Uploads uint64
+	// The number of archives that could not be uploaded after all the attempts of [WithUploadRetry].
+	UploadErrors uint64
+	// The latency percentiles of [Keeper.Write], since the Keeper started or over the window of [WithLatencyWindow],
+	// including the time spent waiting for the lock of the Keeper or for room in the queues.
+	// The percentiles are rounded up to the next power of two nanoseconds, but never above the max.
+	WriteLatencyP50 time.Duration
+	WriteLatencyP99 time.Duration
+	WriteLatencyMax time.Duration
+	// The number of panics recovered from the background tasks, and the number of restarts of the tasks, see [Keeper.Tasks].
+	TaskPanics   uint64
+	TaskRestarts uint64
+}
+
+// Get the ratio of the size of the compressed archives to their size before compression,
+// such as 0.1 when the compression saves 90% of the disk space, or zero if no archive was compressed.
+func (s Stats) CompressionRatio() float64 {
+	if s.CompressionInputBytes == 0 {
+		return 0
+	}
+	return float64(s.CompressionOutputBytes) / float64(s.CompressionInputBytes)
+}
+
+// Get a snapshot of the counters of the Keeper.
+func (k *Keeper) Stats() Stats {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	stats := k.stats
+	stats.CurrentFileSize = int64(k.currentFileSize)
+	stats.AsyncDropped = k.asyncDropped.Load()
+	stats.AsyncQueued = k.asyncQueued()
+	stats.Forwarded = k.forwarded.Load()
+	stats.ForwardErrors = k.forwardErrors.Load()
+	stats.ForwardDropped = k.forwardDropped.Load()
+	stats.ForwardQueued = k.forwardQueued()
+	stats.Throttled = k.rateThrottled.Load()
+	stats.ThrottledTime = time.Duration(k.rateThrottledTime.Load())
+	stats.RateLimited = k.rateDropped.Load()
+	stats.TaskPanics, stats.TaskRestarts = k.tasks.totals()
+	latency := k.latency.histogram()
+	stats.WriteLatencyP50 = latency.percentile(0.5)
+	stats.WriteLatencyP99 = latency.percentile(0.99)
+	stats.WriteLatencyMax = latency.max
+	return stats
+}
+
+// Count the record written to the current log file, unless the write failed.
+func (k *Keeper) countRecord(err error) {
+	if err != nil {
+		return
+	}
+	k.stats.Records++
+	if k.currentRecords >= 0 {
+		k.currentRecords++
+	}
+}
+
+// Get the number of records written to the current log file, see [Stats.Records] for what a record is.
+// It is -1 if unknown, when the current log file already had content when the Keeper started.
+func (k *Keeper) CurrentRecords() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.currentRecords
+}
+
+// Record the compression of an archive, archive being the compressed file.
+func (k *Keeper) countCompression(archive *fileInfo, result compression) {
+	archive.originalSize = result.originalSize
+	archive.compressionTime = result.duration
+	k.stats.CompressedArchives++
+	k.stats.CompressionInputBytes += uint64(result.originalSize)
+	k.stats.CompressionOutputBytes += uint64(archive.size)
+	k.stats.CompressionTime += result.duration
+}
+
+// Record the result of a write to the current log file.
+func (k *Keeper) recordWriteResult(err error) {
+	if err != nil {
+		k.stats.WriteErrors++
+		k.failing = true
+		return
+	}
+	if k.failing {
+		k.stats.Recoveries++
+		k.failing = false
+	}
+}
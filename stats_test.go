@@ -0,0 +1,160 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeeperStats(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	var fallback bytes.Buffer
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-stats"),
+		WithFallbackWriter(&fallback),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("a")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := counters(k.Stats()); got != (Stats{BytesWritten: 1, CurrentFileSize: 1, Records: 1}) {
+		t.Errorf("expected 1 record got %+v", got)
+	}
+
+	// Fail twice, then recover
+	if err := os.RemoveAll(folder); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	_ = k.Reopen()
+	for _, msg := range []string{"b", "c"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("d")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	want := Stats{BytesWritten: 2, CurrentFileSize: 1, WriteErrors: 2, Retries: 2, Recoveries: 1, FallbackWrites: 2, Records: 2}
+	if got := counters(k.Stats()); got != want {
+		t.Errorf("expected %+v got %+v", want, got)
+	}
+
+	// Without a fallback writer the messages are dropped
+	k.mu.Lock()
+	k.fallbackWriter = nil
+	k.mu.Unlock()
+	if err := os.RemoveAll(folder); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	_ = k.Reopen()
+	if _, err := k.Write([]byte("e")); err == nil {
+		t.Fatalf("expected error since the folder is removed")
+	}
+	if got := k.Stats(); got.Dropped != 1 {
+		t.Errorf("expected 1 dropped message got %d", got.Dropped)
+	}
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// Recreate the current log file so that it can be closed
+	if _, err := k.Write([]byte("f")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+}
+
+// Get the counters of the stats, without the latencies that vary from run to run.
+func counters(s Stats) Stats {
+	s.WriteLatencyP50, s.WriteLatencyP99, s.WriteLatencyMax = 0, 0, 0
+	return s
+}
+
+func TestKeeperRecordCounting(t *testing.T) {
+	folder := t.TempDir()
+	if err := os.WriteFile(filepath.Join(folder, "test-record-counting.log"), []byte("old\n"), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	k, err := New(WithFolder(folder), WithName("test-record-counting"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	// The records already in the current log file are unknown
+	if _, err := k.Write([]byte("a\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if got := k.CurrentRecords(); got != -1 {
+		t.Errorf("expected unknown records got %d", got)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	for _, msg := range []string{"b\n", "c\n", "d\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if got := k.CurrentRecords(); got != 3 {
+		t.Errorf("expected 3 records got %d", got)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	archives := k.Archives()
+	if len(archives) != 2 || archives[0].Records != -1 || archives[1].Records != 3 {
+		t.Errorf("expected -1 and 3 records in the archives got %+v", archives)
+	}
+	if got := k.Stats().Records; got != 4 {
+		t.Errorf("expected 4 records in total got %d", got)
+	}
+}
+
+func TestKeeperCompressionStats(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-compression-stats"), WithGzip())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	msg := bytes.Repeat([]byte("compressible message\n"), 100)
+	if _, err := k.Write(msg); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	archives := k.Archives()
+	if len(archives) != 1 {
+		t.Fatalf("expected 1 archive got %d", len(archives))
+	}
+	if archives[0].OriginalSize != len(msg) || archives[0].CompressionTime <= 0 {
+		t.Errorf("expected original size %d and a compression time got %+v", len(msg), archives[0])
+	}
+	stats := k.Stats()
+	if stats.CompressedArchives != 1 || stats.CompressionInputBytes != uint64(len(msg)) ||
+		stats.CompressionOutputBytes != uint64(archives[0].Size) || stats.CompressionTime != archives[0].CompressionTime {
+		t.Errorf("expected the compression of the archive in the stats got %+v", stats)
+	}
+	if stats.Rotations != 1 || stats.BytesWritten != uint64(len(msg)) || stats.CurrentFileSize != 0 {
+		t.Errorf("expected 1 rotation of the written bytes got %+v", stats)
+	}
+	if ratio := stats.CompressionRatio(); ratio <= 0 || ratio >= 1 {
+		t.Errorf("expected a compression ratio between 0 and 1 got %v", ratio)
+	}
+}
@@ -0,0 +1,135 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The delays before restarting a background task that panicked, doubled on every restart.
+const (
+	taskRestartDelay    = 10 * time.Millisecond
+	maxTaskRestartDelay = time.Second
+)
+
+// A TaskState is a snapshot of a background task of a [Keeper], see [Keeper.Tasks].
+type TaskState struct {
+	// The name of the task, such as "async", "segments", "hooks", "uploads", "cron" or "reopen"
+	Name string
+	// Whether the task is running at the time of the snapshot
+	Running bool
+	// The number of panics recovered from the task
+	Panics uint64
+	// The number of times the task was restarted after a panic
+	Restarts uint64
+	// The value of the last panic, and when it happened
+	LastPanic   string
+	LastPanicAt time.Time
+}
+
+// A supervisor keeps the states of the background tasks of a Keeper.
+// It has a lock of its own, since tasks start and stop both with and without the lock of the Keeper.
+type supervisor struct {
+	mu    sync.Mutex
+	tasks map[string]*taskState
+}
+
+type taskState struct {
+	TaskState
+	running int
+}
+
+func (s *supervisor) update(name string, fn func(t *taskState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tasks == nil {
+		s.tasks = make(map[string]*taskState)
+	}
+	t, ok := s.tasks[name]
+	if !ok {
+		t = &taskState{TaskState: TaskState{Name: name}}
+		s.tasks[name] = t
+	}
+	fn(t)
+}
+
+// Get the counters summed over all the tasks.
+func (s *supervisor) totals() (panics, restarts uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tasks {
+		panics += t.Panics
+		restarts += t.Restarts
+	}
+	return panics, restarts
+}
+
+// Get a snapshot of the background tasks of the Keeper, sorted by name,
+// including the tasks that ran once and are done, such as to tell whether a task keeps panicking.
+// Every background task, from the drainer of [WithAsyncWrites] to the hooks and the cron job,
+// recovers from its panics, which are reported to the error handler, see [WithErrorHandler].
+// The long-running tasks are then restarted with an increasing delay,
+// while a hook or a scheduled rotation that panicked is simply done.
+func (k *Keeper) Tasks() []TaskState {
+	k.tasks.mu.Lock()
+	defer k.tasks.mu.Unlock()
+	tasks := make([]TaskState, 0, len(k.tasks.tasks))
+	for _, t := range k.tasks.tasks {
+		state := t.TaskState
+		state.Running = t.running > 0
+		tasks = append(tasks, state)
+	}
+	slices.SortFunc(tasks, func(a, b TaskState) int { return strings.Compare(a.Name, b.Name) })
+	return tasks
+}
+
+// Run fn on a goroutine of its own as the background task name, restarting it whenever it panics,
+// then call done once fn returns.
+func (k *Keeper) goSupervised(name string, fn func(), done func()) {
+	k.tasks.update(name, func(t *taskState) { t.running++ })
+	go func() {
+		defer done()
+		defer k.tasks.update(name, func(t *taskState) { t.running-- })
+		for delay := taskRestartDelay; !k.runTask(name, fn); delay = min(2*delay, maxTaskRestartDelay) {
+			time.Sleep(delay)
+			k.tasks.update(name, func(t *taskState) { t.Restarts++ })
+		}
+	}()
+}
+
+// Wrap fn as a one-shot background task, such as a hook or a timer, whose panic is recovered.
+func (k *Keeper) supervised(name string, fn func()) func() {
+	return func() {
+		k.tasks.update(name, func(t *taskState) { t.running++ })
+		defer k.tasks.update(name, func(t *taskState) { t.running-- })
+		k.runTask(name, fn)
+	}
+}
+
+// Call fn as the background task name, it returns false if fn panicked.
+// The lock of the Keeper must not be held, fn must release it on panic by unlocking it with defer.
+func (k *Keeper) runTask(name string, fn func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			k.taskPanicked(name, r, debug.Stack())
+		}
+	}()
+	fn()
+	return true
+}
+
+// Record the panic of the task and report it to the error handler.
+func (k *Keeper) taskPanicked(name string, r any, stack []byte) {
+	now := time.Now()
+	k.tasks.update(name, func(t *taskState) {
+		t.Panics++
+		t.LastPanic = fmt.Sprint(r)
+		t.LastPanicAt = now
+	})
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.handleError(fmt.Errorf("background task %q panicked, caused by %v\n%s", name, r, stack))
+}
@@ -0,0 +1,109 @@
+package lorekeeper
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeeperTasksRecoverPanickingHook(t *testing.T) {
+	var mu sync.Mutex
+	var errs []error
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-tasks-hook"),
+		WithOnRotate(func(string) { panic("hook failed") }),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// Waits for the hooks, a panicking one must not hang it
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if stats := k.Stats(); stats.TaskPanics != 2 || stats.TaskRestarts != 0 {
+		t.Errorf("expected 2 panics and no restarts got %d and %d", stats.TaskPanics, stats.TaskRestarts)
+	}
+	tasks := k.Tasks()
+	if len(tasks) != 1 || tasks[0].Name != "hooks" || tasks[0].Running || tasks[0].Panics != 2 || tasks[0].LastPanic != "hook failed" {
+		t.Errorf("expected the hooks task to have panicked twice got %+v", tasks)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), `background task "hooks" panicked, caused by hook failed`) {
+		t.Errorf("expected the panics to be reported got %v", errs)
+	}
+}
+
+func TestKeeperTasksRestartPanickingLoop(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-tasks-restart"),
+		// The matcher exempts every message from the sampling, except it panics on "boom"
+		WithSampling(0.5, func(msg []byte) bool {
+			if string(msg) == "boom\n" {
+				panic("matcher failed")
+			}
+			return false
+		}),
+		WithAsyncWrites(16, DropNone),
+		WithErrorHandler(func(error) {}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// The drainer of the async writes panics on the second message
+	for _, msg := range []string{"first line\n", "boom\n"} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for k.Stats().TaskRestarts == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := k.Write([]byte("after restart\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if stats := k.Stats(); stats.TaskPanics != 1 || stats.TaskRestarts != 1 {
+		t.Errorf("expected 1 panic and 1 restart got %d and %d", stats.TaskPanics, stats.TaskRestarts)
+	}
+	tasks := k.Tasks()
+	if len(tasks) != 1 || tasks[0].Name != "async" || tasks[0].Running || tasks[0].Restarts != 1 {
+		t.Errorf("expected the async task to have restarted once and stopped got %+v", tasks)
+	}
+	if content := readArchives(t, k); !strings.Contains(content, "after restart\n") {
+		t.Errorf("expected the writes to go on after the restart got %q", content)
+	}
+}
+
+func TestRunWorkersRaisesPanicOnCaller(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "worker failed" {
+			t.Errorf("expected the panic of the worker got %v", r)
+		}
+	}()
+	runWorkers(4, 16, func(i int) {
+		if i == 3 {
+			panic("worker failed")
+		}
+	})
+	t.Errorf("expected runWorkers to panic")
+}
@@ -0,0 +1,98 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Maintain a symlink of the given name, such as "app-current.log", in the folder of the current log file
+// pointing to the current log file, for the tools that follow a stable path, such as tail -F, Promtail or Filebeat,
+// while the name of the current log file changes, see [WithCurrentNameLayout].
+// The symlink is replaced atomically whenever the current log file is created or reopened under another name,
+// and is left in place when the Keeper is closed. A regular file of the same name is never replaced.
+// The failures to maintain the symlink go to the error handler, see [WithErrorHandler],
+// such as on Windows without the privilege to create symlinks.
+// The name must not match the archive name layout, nor be the name of the current log file.
+// An empty name disables it, which is the default.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithName("app"),
+//		lorekeeper.WithCurrentNameLayout("{{ .name }}-{{ .time }}{{ .extension }}"),
+//		lorekeeper.WithCurrentSymlink("app-current.log"),
+//	)
+func WithCurrentSymlink(name string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if len(name) > 0 && (strings.ContainsAny(name, "/\\\x00") || name == "." || name == "..") {
+			return nil, fmt.Errorf("failed to set current symlink, %q is not a valid file name", name)
+		}
+		k.currentSymlink = name
+		return k, nil
+	}
+}
+
+// Check that the symlink of [WithCurrentSymlink] can not be mistaken for a log file.
+func (k *Keeper) applyCurrentSymlink() error {
+	if len(k.currentSymlink) == 0 {
+		return nil
+	}
+	if k.currentSymlink == filepath.Base(k.getCurrentFilePath()) {
+		return fmt.Errorf("failed to set current symlink, %q is the name of the current log file", k.currentSymlink)
+	}
+	if k.archiveNameLayout != nil {
+		if pattern, err := k.renderArchiveGlobPattern(); err == nil {
+			if match, _ := filepath.Match(pattern, k.currentSymlink); match {
+				return fmt.Errorf("failed to set current symlink, %q matches the archive name layout", k.currentSymlink)
+			}
+		}
+	}
+	return nil
+}
+
+// Point the symlink of [WithCurrentSymlink] to the current log file, the lock of the Keeper must be held.
+func (k *Keeper) updateCurrentSymlink() {
+	if len(k.currentSymlink) == 0 {
+		return
+	}
+	if err := k.linkCurrentFile(); err != nil {
+		k.handleError(fmt.Errorf("failed to update current symlink, caused by %w", err))
+	}
+}
+
+func (k *Keeper) linkCurrentFile() error {
+	current := k.getCurrentFilePath()
+	link := filepath.Join(filepath.Dir(current), k.currentSymlink)
+	// Relative, so that the folder can be moved or mounted elsewhere
+	target := filepath.Base(current)
+
+	stat, err := os.Lstat(link)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case stat.Mode()&fs.ModeSymlink == 0:
+		return fmt.Errorf("%q exists and is not a symlink", link)
+	default:
+		if existing, err := os.Readlink(link); err == nil && existing == target {
+			return nil
+		}
+	}
+
+	// Renaming a new symlink over the old one replaces it atomically
+	temp := filepath.Join(filepath.Dir(link), "."+k.currentSymlink+".tmp-"+strconv.Itoa(os.Getpid()))
+	_ = os.Remove(temp)
+	if err := os.Symlink(target, temp); err != nil {
+		return err
+	}
+	if err := os.Rename(temp, link); err != nil {
+		_ = os.Remove(temp)
+		return err
+	}
+	return nil
+}
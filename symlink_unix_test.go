@@ -0,0 +1,84 @@
+//go:build unix
+
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeeperWithCurrentSymlink(t *testing.T) {
+	folder := t.TempDir()
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-symlink"),
+		WithNowFunc(func() time.Time { return clock }),
+		WithTimeLayout("2006-01-02"),
+		WithCurrentNameLayout("{{ .name }}-{{ .time }}.current{{ .extension }}"),
+		WithCurrentSymlink("test-symlink.log"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	link := filepath.Join(folder, "test-symlink.log")
+	if target, err := os.Readlink(link); err != nil || target != "test-symlink-2024-01-01.current.log" {
+		t.Errorf("expected the symlink to point to the current log file got %q and %v", target, err)
+	}
+
+	if _, err := k.Write([]byte("first\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	clock = clock.AddDate(0, 0, 1)
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.Write([]byte("second\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if target, err := os.Readlink(link); err != nil || target != "test-symlink-2024-01-02.current.log" {
+		t.Errorf("expected the symlink to follow the rotation got %q and %v", target, err)
+	}
+	content, err := os.ReadFile(link)
+	if err != nil || string(content) != "second\n" {
+		t.Errorf("expected to read the current log file through the symlink got %q and %v", content, err)
+	}
+	if got := len(k.Archives()); got != 1 {
+		t.Errorf("expected the symlink not to be an archive got %d archives", got)
+	}
+}
+
+func TestKeeperWithCurrentSymlinkInvalid(t *testing.T) {
+	folder := t.TempDir()
+	for _, name := range []string{"test-symlink-invalid.log", "sub/link.log", "2024-01-01-test-symlink-invalid.log"} {
+		if _, err := New(WithFolder(folder), WithName("test-symlink-invalid"), WithCurrentSymlink(name)); err == nil {
+			t.Errorf("expected error for the symlink name %q", name)
+		}
+	}
+
+	// A regular file is never replaced
+	var errs []error
+	if err := os.WriteFile(filepath.Join(folder, "current.log"), []byte("mine\n"), 0644); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-symlink-invalid"),
+		WithCurrentSymlink("current.log"),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if len(errs) == 0 {
+		t.Errorf("expected an error since current.log is a regular file")
+	}
+	if content, err := os.ReadFile(filepath.Join(folder, "current.log")); err != nil || string(content) != "mine\n" {
+		t.Errorf("expected current.log to be kept got %q and %v", content, err)
+	}
+}
@@ -0,0 +1,94 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+)
+
+// The max length of a tag, see [Keeper.WriteTagged].
+const maxTagLength = 64
+
+// Restrict the tags of [Keeper.WriteTagged] to the given ones, such as "access", "audit" and "debug",
+// so that a typo does not silently create a new logical stream.
+// No tags allows any valid tag, which is the default.
+func WithTags(tags ...string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		for _, tag := range tags {
+			if err := validateTag(tag); err != nil {
+				return nil, fmt.Errorf("failed to set tags, caused by %w", err)
+			}
+		}
+		k.tags = slices.Clone(tags)
+		return k, nil
+	}
+}
+
+// Write the msg framed with the tag, as "[tag] msg", so that one Keeper serves several logical streams,
+// such as access, audit and debug logs, that share its rotation and retention budgets,
+// and downstream tools route the records by their tag, see [SplitTag].
+// A tag is made of letters, digits, '_', '-' and '.', up to 64 bytes, and must be one of [WithTags] if set.
+// The tag can not be embedded into JSON lines, so it fails with [WithValidateJSON] and [WithWrapInvalidJSON].
+// The returned count is the part of msg written, without the frame.
+//
+// Example usage:
+//
+//	keeper.WriteTagged("audit", []byte("user 42 deleted invoice 7\n"))
+//	// [audit] user 42 deleted invoice 7
+func (k *Keeper) WriteTagged(tag string, msg []byte) (int, error) {
+	if err := validateTag(tag); err != nil {
+		return 0, fmt.Errorf("failed to write tagged message, caused by %w", err)
+	}
+	k.mu.Lock()
+	jsonLines, allowed := k.jsonPolicy != jsonOff, len(k.tags) == 0 || slices.Contains(k.tags, tag)
+	k.mu.Unlock()
+	if jsonLines {
+		return 0, fmt.Errorf("failed to write tagged message, tags can not be embedded into JSON lines")
+	}
+	if !allowed {
+		return 0, fmt.Errorf("failed to write tagged message, unknown tag %q, see WithTags", tag)
+	}
+
+	buf := getPooledBuffer()
+	defer k.putPooledBuffer(buf)
+	*buf = append(*buf, '[')
+	*buf = append(*buf, tag...)
+	*buf = append(*buf, "] "...)
+	frame := len(*buf)
+	*buf = append(*buf, msg...)
+	n, err := k.Write(*buf)
+	return max(n-frame, 0), err
+}
+
+// Split a record written with [Keeper.WriteTagged] into its tag and its message,
+// ok is false if the record is not tagged.
+//
+// Example usage:
+//
+//	for record, err := range keeper.Between(from, to) {
+//		if tag, msg, ok := lorekeeper.SplitTag(record); ok && tag == "audit" {
+//			audit.Write(msg)
+//		}
+//	}
+func SplitTag(record []byte) (tag string, msg []byte, ok bool) {
+	if len(record) < 4 || record[0] != '[' {
+		return "", record, false
+	}
+	end := bytes.Index(record, []byte("] "))
+	if end < 0 || validateTag(string(record[1:end])) != nil {
+		return "", record, false
+	}
+	return string(record[1:end]), record[end+2:], true
+}
+
+func validateTag(tag string) error {
+	if len(tag) == 0 || len(tag) > maxTagLength {
+		return fmt.Errorf("tag %q must be between 1 and %d bytes", tag, maxTagLength)
+	}
+	for _, c := range []byte(tag) {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.') {
+			return fmt.Errorf("tag %q contains %q, only letters, digits, '_', '-' and '.' are allowed", tag, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package lorekeeper
+
+import (
+	"os"
+	"testing"
+)
+
+func TestKeeperWriteTagged(t *testing.T) {
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-write-tagged"),
+		WithTags("access", "audit"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	msg := []byte("GET /health 200\n")
+	if n, err := k.WriteTagged("access", msg); err != nil || n != len(msg) {
+		t.Fatalf("expected %d bytes and no error got %d and %v", len(msg), n, err)
+	}
+	if _, err := k.WriteTagged("audit", []byte("user 42 deleted invoice 7\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, tag := range []string{"debug", "", "with space", "a]b"} {
+		if _, err := k.WriteTagged(tag, msg); err == nil {
+			t.Errorf("expected error for the tag %q", tag)
+		}
+	}
+
+	content, err := os.ReadFile(k.CurrentFilePath())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	want := "[access] GET /health 200\n[audit] user 42 deleted invoice 7\n"
+	if string(content) != want {
+		t.Errorf("expected %q got %q", want, content)
+	}
+}
+
+func TestKeeperWriteTaggedJSON(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-write-tagged-json"), WithValidateJSON())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if _, err := k.WriteTagged("audit", []byte(`{"user":42}`+"\n")); err == nil {
+		t.Errorf("expected error since tags can not be embedded into JSON lines")
+	}
+}
+
+func TestSplitTag(t *testing.T) {
+	cases := []struct {
+		record string
+		tag    string
+		msg    string
+		ok     bool
+	}{
+		{record: "[audit] user 42\n", tag: "audit", msg: "user 42\n", ok: true},
+		{record: "[a.b-c_d] x", tag: "a.b-c_d", msg: "x", ok: true},
+		{record: "plain line\n", msg: "plain line\n"},
+		{record: "[not a tag] x", msg: "[not a tag] x"},
+		{record: "[] x", msg: "[] x"},
+	}
+	for _, c := range cases {
+		tag, msg, ok := SplitTag([]byte(c.record))
+		if tag != c.tag || string(msg) != c.msg || ok != c.ok {
+			t.Errorf("expected %q, %q and %v for %q got %q, %q and %v", c.tag, c.msg, c.ok, c.record, tag, msg, ok)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// A TeeKeeper writes every message to several [Keeper]s, each with its own rotation and retention,
+// such as a fast uncompressed local log plus a long-retention compressed archive on another disk.
+// Use [MultiWriter] to create a new TeeKeeper.
+type TeeKeeper struct {
+	keepers []*Keeper
+}
+
+// Create a new [TeeKeeper] writing to all the given [Keeper]s, which are closed with it.
+// It is safe for concurrent use, like the Keepers themselves.
+//
+// Example usage:
+//
+//	local, _ := lorekeeper.New(lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithMaxFiles(3))
+//	archive, _ := lorekeeper.New(lorekeeper.WithFolder("/mnt/archive/app"), lorekeeper.WithGzip(), lorekeeper.WithMaxFiles(365))
+//	tee := lorekeeper.MultiWriter(local, archive)
+//	defer tee.Close()
+//	logger := slog.New(slog.NewJSONHandler(tee, nil))
+func MultiWriter(keepers ...*Keeper) *TeeKeeper {
+	return &TeeKeeper{keepers: slices.Clone(keepers)}
+}
+
+// Write the msg to every [Keeper], even if writing to one of them fails.
+// The errors of the Keepers are joined together, and n is then the least written by any of them.
+func (t *TeeKeeper) Write(msg []byte) (n int, err error) {
+	n = len(msg)
+	var errs []error
+	for _, k := range t.keepers {
+		written, err := k.Write(msg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to write to keeper %q, caused by %w", k.Name(), err))
+			n = min(n, written)
+		}
+	}
+	return n, errors.Join(errs...)
+}
+
+// Commit the messages written so far to stable storage for every [Keeper], see [Keeper.Sync].
+func (t *TeeKeeper) Sync() error {
+	var errs []error
+	for _, k := range t.keepers {
+		if err := k.Sync(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync keeper %q, caused by %w", k.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Get the [Keeper]s written to, in the order given to [MultiWriter].
+func (t *TeeKeeper) Keepers() []*Keeper {
+	return slices.Clone(t.keepers)
+}
+
+// Close all the [Keeper]s, even if closing one of them fails.
+func (t *TeeKeeper) Close() error {
+	var errs []error
+	for _, k := range t.keepers {
+		if err := k.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close keeper %q, caused by %w", k.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMultiWriter(t *testing.T) {
+	// A private registry, since the archive Keeper fails to close below and would stay in the registry for the other tests
+	r := NewRegistry()
+	local, err := r.New(WithFolder(t.TempDir()), WithName("test-tee-local"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	archive, err := r.New(WithFolder(t.TempDir()), WithName("test-tee-archive"), WithMaxSize(8))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	tee := MultiWriter(local, archive)
+
+	for _, msg := range []string{"first\n", "second\n"} {
+		if n, err := tee.Write([]byte(msg)); err != nil || n != len(msg) {
+			t.Fatalf("expected %d bytes and no error got %d and %v", len(msg), n, err)
+		}
+	}
+	if err := tee.Sync(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	for _, k := range tee.Keepers() {
+		// The archive Keeper rotated on its own
+		content, err := os.ReadFile(k.getCurrentFilePath())
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if k == archive {
+			content = append([]byte(readArchives(t, k)), content...)
+		}
+		if string(content) != "first\nsecond\n" {
+			t.Errorf("expected both messages in %s got %q", k.Name(), content)
+		}
+	}
+	if got := len(archive.Archives()); got != 1 {
+		t.Errorf("expected 1 archive got %d", got)
+	}
+	if got := len(local.Archives()); got != 0 {
+		t.Errorf("expected no archive got %d", got)
+	}
+
+	// A failing member does not keep the others from writing
+	if err := os.RemoveAll(filepath.Dir(archive.getCurrentFilePath())); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	_ = archive.Reopen()
+	if _, err := tee.Write([]byte("third\n")); err == nil || !strings.Contains(err.Error(), `keeper "test-tee-archive"`) {
+		t.Errorf("expected error from the archive keeper got %v", err)
+	}
+	if err := tee.Close(); err == nil {
+		t.Errorf("expected error since the archive folder is removed")
+	}
+	// Close rotates the current log file
+	if content := readArchives(t, local); content != "first\nsecond\nthird\n" {
+		t.Errorf("expected the local keeper to keep writing got %q", content)
+	}
+	if _, err := local.Write([]byte("closed\n")); err == nil {
+		t.Errorf("expected error since the local keeper is closed")
+	}
+}
@@ -0,0 +1,43 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// The arguments of the archive name layout that [WithTemplateData] must not override.
+var reservedTemplateKeys = []string{"time", "name", "extension", "reason", "compressionExt", "seq", "pid", "hostname"}
+
+// Set static values that can be referenced in the archive name layout by their key, such as {{ .region }} or {{ .service }},
+// so that naming conventions mandated by the organization do not require forking the template logic.
+// The keys must not override the arguments documented in [WithArchiveNameLayout].
+// The values must not contain a path separator, a NUL byte or a glob meta character, since they end up in the archive names.
+// Calling it again replaces all the values set before.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithName("api"),
+//		lorekeeper.WithTemplateData(map[string]string{"region": "eu-west-1"}),
+//		lorekeeper.WithArchiveNameLayout("{{ .region }}-{{ .name }}-{{ .time }}{{ .extension }}"),
+//	)
+func WithTemplateData(data map[string]string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		for key, value := range data {
+			if len(key) == 0 {
+				return nil, fmt.Errorf("failed to set template data, key must not be empty")
+			}
+			for _, reserved := range reservedTemplateKeys {
+				if key == reserved {
+					return nil, fmt.Errorf("failed to set template data, key %q is reserved", key)
+				}
+			}
+			if strings.ContainsAny(value, "/\\\x00*?[") {
+				return nil, fmt.Errorf("failed to set template data, value %q of key %q contains a path separator or a glob meta character", value, key)
+			}
+		}
+		k.templateData = maps.Clone(data)
+		return k, nil
+	}
+}
@@ -0,0 +1,53 @@
+package lorekeeper
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWithTemplateData(t *testing.T) {
+	folder := t.TempDir()
+	k, err := New(
+		WithFolder(folder),
+		WithName("test-template-data"),
+		WithTemplateData(map[string]string{"region": "eu-west-1", "service": "api"}),
+		WithArchiveNameLayout("{{ .region }}-{{ .service }}-{{ .name }}-{{ .time }}{{ .extension }}"),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("message\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(folder, "eu-west-1-api-test-template-data-*.log"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 archive named with the template data got %v", matches)
+	}
+	if got := len(k.Archives()); got != 1 {
+		t.Errorf("expected 1 archive got %d", got)
+	}
+	if _, err := k.ParseArchiveName(matches[0]); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+}
+
+func TestWithTemplateDataInvalid(t *testing.T) {
+	for _, data := range []map[string]string{
+		{"name": "override"},
+		{"": "empty"},
+		{"region": "eu/west"},
+		{"region": "eu-*"},
+	} {
+		if err := ValidateOptions(WithTemplateData(data)); err == nil {
+			t.Errorf("expected error for %v", data)
+		}
+	}
+}
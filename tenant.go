@@ -0,0 +1,59 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The mode of the tenant folders created by [WithTenant], only the owner and its group may list the logs.
+const tenantFolderMode os.FileMode = 0750
+
+// Store the logs in a folder of their own for the tenant under the root folder, creating it if needed.
+// The folder name is the tenant ID with the path separators, the glob meta characters and the other
+// special characters percent-encoded, so that two different tenant IDs never share a folder
+// and the archives of a tenant never match the archive patterns of another one.
+// With [WithStrictNames], a tenant ID containing a path separator or made only of dots is rejected instead.
+// This overrides [WithFolder] and [WithFolders].
+// Keepers are identified by their name, so each tenant still needs a name of its own.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(
+//		lorekeeper.WithTenant("/var/log/tenants", customerID),
+//		lorekeeper.WithName("api-"+customerID),
+//	)
+func WithTenant(root, tenantID string) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if len(tenantID) == 0 {
+			return nil, fmt.Errorf("failed to set tenant, the tenant ID is empty")
+		}
+		if len(root) == 0 {
+			root = os.TempDir()
+		}
+		k.tenant = tenantID
+		k.folder = normalizeFolder(filepath.Join(root, tenantFolderName(tenantID)))
+		k.folders = nil
+		k.folderMode = tenantFolderMode
+		return k, nil
+	}
+}
+
+// Encode the tenant ID into a folder name, the encoding is reversible so different IDs never collide.
+func tenantFolderName(tenantID string) string {
+	// A name made only of dots is not encoded by url.PathEscape but would refer to the root or its parent
+	if strings.Trim(tenantID, ".") == "" {
+		return strings.Repeat("%2E", len(tenantID))
+	}
+	return url.PathEscape(tenantID)
+}
+
+// Reject an unsafe tenant ID with [WithStrictNames].
+func (k *Keeper) applyTenantPolicy() error {
+	if k.strictNames && isUnsafeName(k.tenant) {
+		return fmt.Errorf("failed to set tenant, %q contains a path separator or is made only of dots", k.tenant)
+	}
+	return nil
+}
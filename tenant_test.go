@@ -0,0 +1,63 @@
+package lorekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithTenant(t *testing.T) {
+	root := t.TempDir()
+	ids := []string{"acme", "a/b", "a%2Fb", "..", "a*"}
+	keepers := make([]*Keeper, 0, len(ids))
+	for i, id := range ids {
+		k, err := New(WithTenant(root, id), WithName(fmt.Sprintf("test-tenant-%d", i)))
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		defer k.Close()
+		keepers = append(keepers, k)
+	}
+
+	seen := make(map[string]bool)
+	for i, k := range keepers {
+		folder := k.folder
+		if filepath.Dir(folder) != root {
+			t.Errorf("expected tenant %q directly under the root got %s", ids[i], folder)
+		}
+		if seen[folder] {
+			t.Errorf("expected tenant %q in a folder of its own got %s", ids[i], folder)
+		}
+		seen[folder] = true
+		stat, err := os.Stat(folder)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if stat.Mode().Perm()&0007 != 0 {
+			t.Errorf("expected no access for others got %v", stat.Mode().Perm())
+		}
+	}
+
+	// Each Keeper only sees its own archives
+	for _, k := range keepers {
+		if _, err := k.Write([]byte("message\n")); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	for i, k := range keepers {
+		if got := len(k.Archives()); got != 1 {
+			t.Errorf("expected 1 archive for tenant %q got %d", ids[i], got)
+		}
+	}
+
+	if _, err := New(WithTenant(root, "a/b"), WithName("test-tenant-strict"), WithStrictNames()); err == nil {
+		t.Errorf("expected error for an unsafe tenant ID with strict names")
+	}
+	if _, err := New(WithTenant(root, ""), WithName("test-tenant-empty")); err == nil {
+		t.Errorf("expected error for an empty tenant ID")
+	}
+}
@@ -0,0 +1,122 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// A TimedRecord is a record with its timestamp, see [Keeper.Timeline].
+type TimedRecord struct {
+	// The timestamp parsed from the record, or inherited from the previous record.
+	Time time.Time
+	// The record, keeping its trailing new line.
+	Record []byte
+}
+
+// Iterate over the records written between from and to, both inclusive, with their timestamps,
+// from the oldest to the newest across the archives and the current log file,
+// enabling precise replay of a time window for incident reconstruction.
+// The timestamps are parsed by the parser of [WithTimestampParser], which is required,
+// see [PrefixTimestampParser] and [JSONTimestampParser] for common formats.
+// A record that can not be parsed, such as a stray continuation line, inherits the timestamp of the previous record,
+// or is skipped if it comes before any parseable one, so every record is placed within the window precisely.
+// See [Keeper.Between] for what a record is.
+//
+// Example usage:
+//
+//	for timed, err := range keeper.Timeline(incidentStart, incidentEnd) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Printf("%s %s", timed.Time.Format(time.RFC3339Nano), timed.Record)
+//	}
+func (k *Keeper) Timeline(from, to time.Time) iter.Seq2[TimedRecord, error] {
+	k.mu.Lock()
+	parse := k.timestampParser
+	k.mu.Unlock()
+	return timeline(k.recordsBetween(from, to), parse, from, to)
+}
+
+// Timestamp the records with parse, leaving out the ones that are not between from and to, see [Keeper.Timeline].
+func timeline(records iter.Seq2[[]byte, error], parse func([]byte) (time.Time, error), from, to time.Time) iter.Seq2[TimedRecord, error] {
+	return func(yield func(TimedRecord, error) bool) {
+		if parse == nil {
+			yield(TimedRecord{}, fmt.Errorf("failed to read timeline, no timestamp parser, see WithTimestampParser"))
+			return
+		}
+		var last time.Time
+		for record, err := range records {
+			if err != nil {
+				yield(TimedRecord{}, err)
+				return
+			}
+			if t, err := parse(record); err == nil {
+				last = t
+			}
+			if last.IsZero() || last.Before(from) || last.After(to) {
+				continue
+			}
+			if !yield(TimedRecord{Time: last, Record: record}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Get a parser for [WithTimestampParser] of the timestamp at the start of the records, formatted with layout,
+// such as the records written by [log.Logger] or by most text loggers.
+// The timestamp spans as many space-separated fields as the layout does.
+// Timestamps without a time zone are in UTC.
+//
+// Example usage:
+//
+//	lorekeeper.WithTimestampParser(lorekeeper.PrefixTimestampParser("2006/01/02 15:04:05"))
+func PrefixTimestampParser(layout string) func(record []byte) (time.Time, error) {
+	fields := bytes.Count([]byte(layout), []byte(" ")) + 1
+	return func(record []byte) (time.Time, error) {
+		end := 0
+		for i := 0; i < fields; i++ {
+			next := bytes.IndexAny(record[end:], " \n")
+			if next < 0 {
+				end = len(record)
+				break
+			}
+			end += next
+			if i < fields-1 {
+				end++
+			}
+		}
+		return time.Parse(layout, string(record[:end]))
+	}
+}
+
+// Get a parser for [WithTimestampParser] of the timestamp in the given top-level field of JSON records,
+// formatted with layout, or with [time.RFC3339Nano] if layout is empty,
+// such as the records written by [slog.JSONHandler] with the field "time".
+//
+// Example usage:
+//
+//	lorekeeper.WithTimestampParser(lorekeeper.JSONTimestampParser("time", ""))
+func JSONTimestampParser(field, layout string) func(record []byte) (time.Time, error) {
+	if len(layout) == 0 {
+		layout = time.RFC3339Nano
+	}
+	return func(record []byte) (time.Time, error) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(record, &fields); err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse record as JSON, caused by %w", err)
+		}
+		raw, ok := fields[field]
+		if !ok {
+			return time.Time{}, fmt.Errorf("failed to parse timestamp, the record has no field %s", field)
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse timestamp of field %s, caused by %w", field, err)
+		}
+		return time.Parse(layout, value)
+	}
+}
@@ -0,0 +1,76 @@
+package lorekeeper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeeperTimeline(t *testing.T) {
+	// Rotate between the first and the second records
+	now := time.Date(2024, 1, 1, 11, 30, 0, 0, time.UTC)
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-timeline"),
+		WithNowFunc(func() time.Time { return now }),
+		WithTimestampParser(PrefixTimestampParser("2006/01/02 15:04:05")),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	for _, msg := range []string{
+		"2024/01/01 10:00:00 before\n",
+		"2024/01/01 11:00:00 first\n",
+		"\tcontinuation\n",
+		"2024/01/01 12:00:00 second\n",
+		"2024/01/01 13:00:00 after\n",
+		"\tcontinuation after\n",
+	} {
+		if _, err := k.Write([]byte(msg)); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		if msg == "\tcontinuation\n" {
+			if err := k.Rotate(); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+		}
+	}
+
+	from := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var got []TimedRecord
+	for timed, err := range k.Timeline(from, to) {
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		got = append(got, timed)
+	}
+	want := []TimedRecord{
+		{Time: from, Record: []byte("2024/01/01 11:00:00 first\n")},
+		{Time: from, Record: []byte("\tcontinuation\n")},
+		{Time: to, Record: []byte("2024/01/01 12:00:00 second\n")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d records got %d", len(want), len(got))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || string(got[i].Record) != string(want[i].Record) {
+			t.Errorf("expected %v %q got %v %q", want[i].Time, want[i].Record, got[i].Time, got[i].Record)
+		}
+	}
+}
+
+func TestJSONTimestampParser(t *testing.T) {
+	parse := JSONTimestampParser("time", "")
+	got, err := parse([]byte(`{"time":"2024-01-01T11:00:00.5Z","msg":"hello"}` + "\n"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if want := time.Date(2024, 1, 1, 11, 0, 0, 5e8, time.UTC); !got.Equal(want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+	if _, err := parse([]byte(`{"msg":"no time"}`)); err == nil {
+		t.Errorf("expected error for a record without the field")
+	}
+}
@@ -0,0 +1,115 @@
+package lorekeeper
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A Tuning holds the defaults of the resource-bound options of the Keepers, see [DefaultTuning].
+type Tuning struct {
+	// The default of [WithBackgroundWorkers], which also bounds the compressions running at once.
+	BackgroundWorkers int
+	// The default of [WithMaxPooledBufferSize].
+	MaxPooledBufferSize int
+	// The default max size of the buffer of [PauseBuffer], see [WithPausePolicy].
+	PauseMaxBuffer int
+}
+
+var (
+	tuningMu sync.Mutex
+	tuning   *Tuning
+)
+
+// Get the defaults of the resource-bound options used by [DefaultOptions], and thus by every new Keeper,
+// so that small containers do not get desktop-sized buffers and big hosts are not under-parallelized.
+// Unless overridden with [SetDefaultTuning], they are computed by [AutoTuning] from [runtime.GOMAXPROCS]
+// and the memory available to the process, from GOMEMLIMIT or the cgroup memory limit of the container if any.
+// The options of a Keeper, such as [WithBackgroundWorkers], still take precedence over these defaults.
+func DefaultTuning() Tuning {
+	tuningMu.Lock()
+	defer tuningMu.Unlock()
+	if tuning == nil {
+		auto := AutoTuning(runtime.GOMAXPROCS(0), availableMemory())
+		tuning = &auto
+	}
+	return *tuning
+}
+
+// Override the defaults returned by [DefaultTuning] for the Keepers created afterward,
+// such as to pin them in tests or to tune them to a host the heuristics get wrong.
+// The fields that are zero keep their automatic value.
+//
+// Example usage:
+//
+//	tuning := lorekeeper.DefaultTuning()
+//	tuning.BackgroundWorkers = 2
+//	lorekeeper.SetDefaultTuning(tuning)
+func SetDefaultTuning(t Tuning) {
+	auto := AutoTuning(runtime.GOMAXPROCS(0), availableMemory())
+	if t.BackgroundWorkers <= 0 {
+		t.BackgroundWorkers = auto.BackgroundWorkers
+	}
+	if t.MaxPooledBufferSize <= 0 {
+		t.MaxPooledBufferSize = auto.MaxPooledBufferSize
+	}
+	if t.PauseMaxBuffer <= 0 {
+		t.PauseMaxBuffer = auto.PauseMaxBuffer
+	}
+	tuningMu.Lock()
+	defer tuningMu.Unlock()
+	tuning = &t
+}
+
+// Compute the defaults of the resource-bound options for procs usable CPUs and memory bytes of memory,
+// memory being zero if unknown:
+//   - one background worker per CPU, from 1 up to 32;
+//   - pooled buffers up to 64 Kb, or 16 Kb with less than 512 Mb of memory;
+//   - a pause buffer of 1/256 of the memory, from 256 Kb up to 64 Mb, or 4 Mb if the memory is unknown.
+func AutoTuning(procs int, memory int64) Tuning {
+	t := Tuning{
+		BackgroundWorkers:   min(max(procs, 1), 32),
+		MaxPooledBufferSize: defaultMaxPooledBufferSize,
+		PauseMaxBuffer:      4 * Mb,
+	}
+	if memory <= 0 {
+		return t
+	}
+	if memory < int64(512*Mb) {
+		t.MaxPooledBufferSize = 16 * Kb
+	}
+	t.PauseMaxBuffer = int(min(max(memory/256, int64(256*Kb)), int64(64*Mb)))
+	return t
+}
+
+// The cgroup files holding the memory limit of the container, for cgroup v2 and v1.
+var cgroupMemoryFiles = []string{"/sys/fs/cgroup/memory.max", "/sys/fs/cgroup/memory/memory.limit_in_bytes"}
+
+// Get the memory available to the process in bytes, the lowest of GOMEMLIMIT and the cgroup memory limit,
+// or zero if neither is set.
+func availableMemory() int64 {
+	var memory int64
+	if limit := debug.SetMemoryLimit(-1); limit < math.MaxInt64 {
+		memory = limit
+	}
+	for _, path := range cgroupMemoryFiles {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+		// "max" in cgroup v2, and a huge number in cgroup v1, for no limit
+		if err != nil || limit <= 0 || limit >= 1<<62 {
+			break
+		}
+		if memory == 0 || limit < memory {
+			memory = limit
+		}
+		break
+	}
+	return memory
+}
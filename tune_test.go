@@ -0,0 +1,51 @@
+package lorekeeper
+
+import "testing"
+
+func TestAutoTuning(t *testing.T) {
+	cases := []struct {
+		procs  int
+		memory int64
+		want   Tuning
+	}{
+		{procs: 1, memory: 0, want: Tuning{BackgroundWorkers: 1, MaxPooledBufferSize: 64 * Kb, PauseMaxBuffer: 4 * Mb}},
+		{procs: 2, memory: int64(128 * Mb), want: Tuning{BackgroundWorkers: 2, MaxPooledBufferSize: 16 * Kb, PauseMaxBuffer: 512 * Kb}},
+		{procs: 8, memory: int64(32 * Mb), want: Tuning{BackgroundWorkers: 8, MaxPooledBufferSize: 16 * Kb, PauseMaxBuffer: 256 * Kb}},
+		{procs: 128, memory: int64(64 * Gb), want: Tuning{BackgroundWorkers: 32, MaxPooledBufferSize: 64 * Kb, PauseMaxBuffer: 64 * Mb}},
+	}
+	for _, c := range cases {
+		if got := AutoTuning(c.procs, c.memory); got != c.want {
+			t.Errorf("expected %+v for %d procs and %d bytes got %+v", c.want, c.procs, c.memory, got)
+		}
+	}
+}
+
+func TestSetDefaultTuning(t *testing.T) {
+	previous := DefaultTuning()
+	defer SetDefaultTuning(previous)
+
+	SetDefaultTuning(Tuning{BackgroundWorkers: 3})
+	tuning := DefaultTuning()
+	if tuning.BackgroundWorkers != 3 || tuning.MaxPooledBufferSize <= 0 || tuning.PauseMaxBuffer <= 0 {
+		t.Errorf("expected 3 workers and automatic buffers got %+v", tuning)
+	}
+
+	k, err := New(WithFolder(t.TempDir()), WithName("test-tuning"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	if k.backgroundWorkers != 3 {
+		t.Errorf("expected 3 workers got %d", k.backgroundWorkers)
+	}
+
+	// The options still take precedence
+	k2, err := New(WithFolder(t.TempDir()), WithName("test-tuning-option"), WithBackgroundWorkers(5))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k2.Close()
+	if k2.backgroundWorkers != 5 {
+		t.Errorf("expected 5 workers got %d", k2.backgroundWorkers)
+	}
+}
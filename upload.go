@@ -0,0 +1,169 @@
+package lorekeeper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/trviph/collection"
+)
+
+// The default attempts and initial backoff of the uploads, see [WithUploadRetry].
+const (
+	defaultUploadAttempts = 3
+	defaultUploadBackoff  = time.Second
+)
+
+// An Uploader ships the archives to a remote storage, such as an S3-compatible bucket, see [WithUploader].
+type Uploader interface {
+	// Upload the archive at the local path, returning once it is safely stored.
+	Upload(ctx context.Context, localPath string) error
+}
+
+// An UploaderFunc is an [Uploader] calling itself, so that a client of a remote storage can be adapted in place.
+type UploaderFunc func(ctx context.Context, localPath string) error
+
+// Make sure that UploaderFunc implements the [Uploader] interface.
+var _ Uploader = UploaderFunc(nil)
+
+func (fn UploaderFunc) Upload(ctx context.Context, localPath string) error {
+	return fn(ctx, localPath)
+}
+
+// Upload every archive with the given [Uploader] once its rotation and compression complete,
+// turning the Keeper into a complete log shipping layer for containerized apps.
+// The uploads run one at a time in the order of the rotations, on a goroutine of their own,
+// and a failed upload is retried according to [WithUploadRetry] before it goes to the error handler.
+// If deleteAfterUpload is set, the archive is removed once uploaded, and recorded in [Keeper.Deletions]
+// with the [DeletionUploaded] policy, otherwise it stays subject to the retention.
+// [Keeper.Close] waits for the pending uploads, including the one of the archive it rotates.
+// A nil uploader disables the uploads, which is the default.
+//
+// Example usage:
+//
+//	uploader := lorekeeper.UploaderFunc(func(ctx context.Context, localPath string) error {
+//		f, err := os.Open(localPath)
+//		if err != nil {
+//			return err
+//		}
+//		defer f.Close()
+//		_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{Bucket: &bucket, Key: aws.String(filepath.Base(localPath)), Body: f})
+//		return err
+//	})
+//	keeper, err := lorekeeper.New(lorekeeper.WithGzip(), lorekeeper.WithUploader(uploader, true))
+func WithUploader(u Uploader, deleteAfterUpload bool) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.uploader = u
+		k.deleteAfterUpload = u != nil && deleteAfterUpload
+		return k, nil
+	}
+}
+
+// Attempt each upload of [WithUploader] up to the given number of times,
+// waiting the given backoff after the first failure, then doubling it after each following one.
+// Defaults to 3 attempts with a backoff of 1 second.
+func WithUploadRetry(attempts int, backoff time.Duration) Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		if attempts < 1 {
+			return nil, fmt.Errorf("failed to set upload retry, expected at least one attempt")
+		}
+		if backoff < 0 {
+			return nil, fmt.Errorf("failed to set upload retry, backoff must not be negative")
+		}
+		k.uploadAttempts = attempts
+		k.uploadBackoff = backoff
+		return k, nil
+	}
+}
+
+// Queue the upload of the archive, the lock of the Keeper must be held.
+func (k *Keeper) queueUpload(archivePath string) {
+	u := k.uploader
+	if u == nil {
+		return
+	}
+	attempts, backoff := k.uploadAttempts, k.uploadBackoff
+	pending := k.pendingUploads
+	// The archive is read with the FS of the Keeper, see ResumableUploader
+	ctx := context.WithValue(k.shutdown.context(), uploadFSKey{}, k.fsys)
+	k.uploads.enqueue(k.supervised("uploads", func() {
+		err := upload(ctx, u, archivePath, attempts, backoff)
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if pending {
+			// Removed by the retention in the meantime, there is nothing left to upload
+			_, statErr := k.fsys.Stat(archivePath)
+			gone := errors.Is(statErr, fs.ErrNotExist)
+			k.settlePendingUpload(archivePath, err != nil && !gone)
+			if gone {
+				return
+			}
+		}
+		if err != nil {
+			k.stats.UploadErrors++
+			k.handleError(err)
+			return
+		}
+		k.stats.Uploads++
+		if k.deleteAfterUpload {
+			k.removeUploaded(archivePath)
+		}
+	}))
+}
+
+// Upload the archive, retrying with an exponential backoff until ctx is done.
+func upload(ctx context.Context, u Uploader, archivePath string, attempts int, backoff time.Duration) error {
+	var err error
+	for attempt := range attempts {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return fmt.Errorf("failed to upload %q after %d attempts, caused by %w", archivePath, attempt, errors.Join(ctx.Err(), err))
+			}
+			backoff *= 2
+		}
+		if err = u.Upload(ctx, archivePath); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to upload %q after %d attempts, caused by %w", archivePath, attempts, err)
+}
+
+// Remove the uploaded archive, unless the retention already did.
+func (k *Keeper) removeUploaded(archivePath string) {
+	// Removed once the removals are no longer held, see WithRotationPaused
+	if k.rotationBarrier > 0 {
+		k.heldUploaded = append(k.heldUploaded, archivePath)
+		return
+	}
+	var uploaded *fileInfo
+	kept := collection.NewList[*fileInfo]()
+	for _, archive := range k.archives.All() {
+		if archive.filePath == archivePath {
+			uploaded = archive
+			continue
+		}
+		kept.Append(archive)
+	}
+	if uploaded == nil {
+		return
+	}
+	expire(uploaded, DeletionUploaded, "uploaded to the remote storage")
+	if k.deferredRemoval && len(k.deferOpenArchives([]*fileInfo{uploaded})) == 0 {
+		k.archives = kept
+		k.archivesSize -= uploaded.size
+		return
+	}
+	if err := removeArchive(k.fsys, uploaded); err != nil {
+		k.stats.RemoveErrors++
+		k.handleError(err)
+		return
+	}
+	k.archives = kept
+	k.archivesSize -= uploaded.size
+	k.stats.RemovedArchives++
+	k.recordDeletion(uploaded)
+}
@@ -0,0 +1,109 @@
+package lorekeeper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithUploader(t *testing.T) {
+	var mu sync.Mutex
+	var uploaded []string
+	failures := 1
+	uploader := UploaderFunc(func(ctx context.Context, localPath string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		// The first attempt fails, so that the upload is retried
+		if failures > 0 {
+			failures--
+			return errors.New("unavailable")
+		}
+		content, err := os.ReadFile(localPath)
+		if err != nil {
+			return err
+		}
+		uploaded = append(uploaded, string(content))
+		return nil
+	})
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-uploader"),
+		WithManualStepping(),
+		WithUploader(uploader, true),
+		WithUploadRetry(2, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("shipped\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Step(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	if len(uploaded) != 1 || uploaded[0] != "shipped\n" {
+		t.Fatalf("expected the archive to be uploaded got %q", uploaded)
+	}
+	if archives := k.Archives(); len(archives) != 0 {
+		t.Errorf("expected the uploaded archive to be removed got %v", archives)
+	}
+	deletions := k.Deletions()
+	if len(deletions) != 1 || deletions[0].Policy != DeletionUploaded {
+		t.Errorf("expected an uploaded deletion got %+v", deletions)
+	}
+	if stats := k.Stats(); stats.Uploads != 1 || stats.UploadErrors != 0 {
+		t.Errorf("expected 1 upload and no error got %+v", stats)
+	}
+}
+
+func TestWithUploaderFailure(t *testing.T) {
+	var handled []error
+	uploader := UploaderFunc(func(ctx context.Context, localPath string) error {
+		return errors.New("unavailable")
+	})
+	k, err := New(
+		WithFolder(t.TempDir()),
+		WithName("test-uploader-failure"),
+		WithManualStepping(),
+		WithUploader(uploader, true),
+		WithUploadRetry(3, 0),
+		WithErrorHandler(func(err error) { handled = append(handled, err) }),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if _, err := k.Write([]byte("kept\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Step(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	// The archive is kept when it could not be uploaded
+	if archives := k.Archives(); len(archives) != 1 {
+		t.Errorf("expected 1 archive got %d", len(archives))
+	}
+	if len(handled) != 1 {
+		t.Errorf("expected 1 handled error got %v", handled)
+	}
+	if stats := k.Stats(); stats.UploadErrors != 1 {
+		t.Errorf("expected 1 upload error got %d", stats.UploadErrors)
+	}
+	if _, err := New(WithFolder(t.TempDir()), WithName("test-uploader-invalid"), WithUploadRetry(0, 0)); err == nil {
+		t.Errorf("expected error for no attempt got nil")
+	}
+}
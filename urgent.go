@@ -0,0 +1,81 @@
+package lorekeeper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sync the current log file to disk after every write, like [Keeper.WriteUrgent] does for one message,
+// so that no acknowledged message is lost if the host crashes, at the cost of the write throughput.
+// A failed sync fails the write. The buffer of [WithBufferSize] is flushed by every write,
+// and with [WithAsyncWrites] or [WithDoubleBuffering] the messages are synced in the background, after Write returned.
+// Is disabled by default.
+func WithSyncWrites() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.syncWrites = true
+		return k, nil
+	}
+}
+
+// Leave the syncing of the current log file to the operating system, this is the default.
+func NoSyncWrites() Opt {
+	return func(k *Keeper) (*Keeper, error) {
+		k.syncWrites = false
+		return k, nil
+	}
+}
+
+// Write the msg and sync the current log file to disk before returning,
+// for panic handlers and fatal-error paths where losing the last message is unacceptable.
+// The msg bypasses the buffering of [WithDoubleBuffering], waiting for the messages buffered before it to be written first,
+// and is written even while the Keeper is paused, in which case it is appended to the current log file as is,
+// ahead of the messages buffered by the pause and without rotating.
+func (k *Keeper) WriteUrgent(msg []byte) (int, error) {
+	if s := k.segments.Load(); s != nil {
+		done := make(chan segmentResult, 1)
+		if err := s.appendUrgent(msg, done); err == nil {
+			result := <-done
+			return result.n, result.err
+		}
+		// The segments are stopped, the Keeper is closing, write directly
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.writeUrgentLocked(msg)
+}
+
+// Write the msg and sync the current log file, the lock of the Keeper must be held.
+func (k *Keeper) writeUrgentLocked(msg []byte) (int, error) {
+	var n int
+	var err error
+	k.remember(msg)
+	k.forwardMessage(msg)
+	k.notifyFollowers(msg)
+	if k.paused && k.chain != nil {
+		n, err = k.writeChained(msg, false)
+	} else if k.paused {
+		n, err = k.write(msg)
+	} else {
+		n, err = k.writeMessage(msg)
+	}
+	if syncErr := k.syncCurrentFile(); syncErr != nil {
+		err = errors.Join(err, syncErr)
+	}
+	return n, err
+}
+
+// Flush the buffer, then sync the current log file to disk, if it is open.
+func (k *Keeper) syncCurrentFile() error {
+	if err := k.flushBuffer(); err != nil {
+		return err
+	}
+	syncer, ok := k.currentFile.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+	if err := syncer.Sync(); err != nil {
+		return fmt.Errorf("failed to sync current log file, caused by %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package lorekeeper
+
+import (
+	"os"
+	"testing"
+)
+
+func TestKeeperWriteUrgent(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Opt
+	}{
+		{name: "direct", opt: WithDoubleBuffering(0)},
+		{name: "double buffering", opt: WithDoubleBuffering(Kb)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := New(
+				WithFolder(t.TempDir()),
+				WithName("test-write-urgent"),
+				WithRegistry(NewRegistry()),
+				tt.opt,
+			)
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			defer k.Close()
+
+			if _, err := k.Write([]byte("before\n")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if _, err := k.WriteUrgent([]byte("panic\n")); err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			// The messages are on disk once WriteUrgent returns
+			content, err := os.ReadFile(k.CurrentFilePath())
+			if err != nil {
+				t.Fatalf("expected no error got %v", err)
+			}
+			if string(content) != "before\npanic\n" {
+				t.Errorf("expected %q got %q", "before\npanic\n", content)
+			}
+		})
+	}
+}
+
+func TestKeeperWriteUrgentWhilePaused(t *testing.T) {
+	k, err := New(WithFolder(t.TempDir()), WithName("test-write-urgent-paused"))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	k.Pause()
+	if _, err := k.Write([]byte("buffered\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if _, err := k.WriteUrgent([]byte("panic\n")); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	content, err := os.ReadFile(k.CurrentFilePath())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if string(content) != "panic\n" {
+		t.Errorf("expected %q got %q", "panic\n", content)
+	}
+	if err := k.Resume(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+}
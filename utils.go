@@ -3,16 +3,13 @@ package lorekeeper
 import (
 	"fmt"
 	"os"
-	"path"
-	"time"
+	"path/filepath"
 )
 
-var now func() time.Time = time.Now
-
 // Get default name for the [Keeper].
 func defaultKeeperName() string {
 	if len(os.Args) > 1 && len(os.Args[0]) > 1 {
-		_, execName := path.Split(os.Args[0])
+		execName := filepath.Base(os.Args[0])
 		return fmt.Sprintf("lorekeeper-%s", execName)
 	}
 	return "lorekeeper"
@@ -0,0 +1,85 @@
+package lorekeeper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// The content written by [Keeper.Validate].
+const validationContent = "lorekeeper validation\n"
+
+// Check that the Keeper can go through a whole rotation cycle, reporting actionable errors at startup
+// rather than when the first rotation fails in the middle of the night.
+// It performs a dry write and rotation with temporary files next to the current log file:
+// it creates and writes a file in the folder of the current log file, moves it into every archive folder,
+// then compresses it and reads it back if the Keeper compresses its archives.
+// The temporary files are removed afterward, the log files of the Keeper are never touched.
+// The returned error joins the failure of every folder.
+//
+// Example usage:
+//
+//	keeper, err := lorekeeper.New(lorekeeper.WithFolder("/var/log/app"), lorekeeper.WithGzip())
+//	if err != nil {
+//		return err
+//	}
+//	if err := keeper.Validate(); err != nil {
+//		return fmt.Errorf("log folder is misconfigured: %w", err)
+//	}
+func (k *Keeper) Validate() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	name := "." + k.name + "-validate-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	var errs []error
+	for _, folder := range k.getArchiveFolders() {
+		if err := k.validateFolder(name, folder); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate a rotation cycle into the archive folder with temporary files of the given name.
+func (k *Keeper) validateFolder(name, folder string) error {
+	current := filepath.Join(k.folder, name)
+	if err := writeFile(k.fsys, current, []byte(validationContent), k.fileMode); err != nil {
+		return fmt.Errorf("failed to validate writes to %q, check that the folder exists and is writable, caused by %w", k.folder, err)
+	}
+	defer k.fsys.Remove(current)
+
+	archive := filepath.Join(folder, name+k.extension)
+	if err := moveFile(k.fsys, current, archive); err != nil {
+		return fmt.Errorf("failed to validate rotations into %q, check that the folder exists and is writable, caused by %w", folder, err)
+	}
+	defer k.fsys.Remove(archive)
+	if k.compressor == nil {
+		return nil
+	}
+
+	compressed := archive + k.compressionExt
+	defer k.fsys.Remove(compressed)
+	if _, err := k.compress(archive, compressed); err != nil {
+		return fmt.Errorf("failed to validate compression with %T in %q, caused by %w", k.compressor, folder, err)
+	}
+	if _, ok := k.compressor.(Decompressor); !ok {
+		return nil
+	}
+	reader, err := openLogFile(k.fsys, compressed, k.compressor)
+	if err != nil {
+		return fmt.Errorf("failed to validate decompression with %T in %q, caused by %w", k.compressor, folder, err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to validate decompression with %T in %q, caused by %w", k.compressor, folder, err)
+	}
+	if !bytes.Equal(content, []byte(validationContent)) {
+		return fmt.Errorf("failed to validate compression with %T in %q, the archive does not decompress to its content", k.compressor, folder)
+	}
+	return nil
+}
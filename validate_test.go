@@ -0,0 +1,44 @@
+package lorekeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeeperValidate(t *testing.T) {
+	folder := t.TempDir()
+	archiveFolder := filepath.Join(t.TempDir(), "archives")
+	if err := os.Mkdir(archiveFolder, 0755); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	k, err := New(WithFolders(folder, archiveFolder), WithName("test-validate"), WithGzip())
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+
+	if err := k.Validate(); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	// The temporary files are removed
+	for _, dir := range []string{folder, archiveFolder} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+		for _, entry := range entries {
+			if entry.Name() != "test-validate.log" {
+				t.Errorf("unexpected file %q left in %q", entry.Name(), dir)
+			}
+		}
+	}
+
+	// A missing archive folder is reported
+	if err := os.Remove(archiveFolder); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if err := k.Validate(); err == nil {
+		t.Errorf("expected error for a missing archive folder got nil")
+	}
+}
@@ -0,0 +1,60 @@
+package lorekeeper
+
+import "sync"
+
+// Call fn for every index in [0, count) with the background workers of the Keeper, or of its group if any.
+func (k *Keeper) runBackground(count int, fn func(i int)) {
+	if k.group != nil {
+		k.group.run(count, fn)
+		return
+	}
+	if k.manual {
+		runWorkers(1, count, fn)
+		return
+	}
+	runWorkers(k.backgroundWorkers, count, fn)
+}
+
+// Call fn for every index in [0, count) with a pool of up to workers goroutines, and wait for all of them.
+// A single task, or a single worker, runs on the calling goroutine.
+// A panic of fn is raised again on the calling goroutine once all the workers are done,
+// so that it reaches the task that asked for the work instead of crashing the process.
+func runWorkers(workers, count int, fn func(i int)) {
+	if count == 1 || workers <= 1 {
+		for i := range count {
+			fn(i)
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicked any
+	for range min(count, workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if r := callRecovered(fn, i); r != nil {
+					panicOnce.Do(func() { panicked = r })
+				}
+			}
+		}()
+	}
+	for i := range count {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// Call fn with i, returning the value of its panic if any.
+func callRecovered(fn func(i int), i int) (r any) {
+	defer func() { r = recover() }()
+	fn(i)
+	return nil
+}
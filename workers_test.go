@@ -0,0 +1,58 @@
+package lorekeeper
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunWorkers(t *testing.T) {
+	for _, workers := range []int{1, 3, 8} {
+		var running, peak atomic.Int32
+		var mu sync.Mutex
+		done := make(map[int]bool)
+		runWorkers(workers, 20, func(i int) {
+			n := running.Add(1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+
+			mu.Lock()
+			done[i] = true
+			mu.Unlock()
+		})
+		if len(done) != 20 {
+			t.Errorf("expected 20 tasks to run got %d", len(done))
+		}
+		if int(peak.Load()) > workers {
+			t.Errorf("expected at most %d concurrent tasks got %d", workers, peak.Load())
+		}
+	}
+}
+
+func TestWithBackgroundWorkers(t *testing.T) {
+	if _, err := New(WithName("test-background-workers"), WithFolder(t.TempDir()), WithBackgroundWorkers(0)); err == nil {
+		t.Errorf("expected an error for 0 background workers")
+	}
+
+	k, err := New(
+		WithName("test-background-workers"),
+		WithFolder(t.TempDir()),
+		WithMaxFiles(1),
+		WithBackgroundWorkers(1),
+	)
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	defer k.Close()
+	for range 3 {
+		if err := k.Rotate(); err != nil {
+			t.Fatalf("expected no error got %v", err)
+		}
+	}
+	if stats := k.Stats(); stats.RemovedArchives != 2 {
+		t.Errorf("expected 2 removed archives got %d", stats.RemovedArchives)
+	}
+}